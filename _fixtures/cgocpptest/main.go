@@ -0,0 +1,14 @@
+package main
+
+// #include "shapes.h"
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func main() {
+	runtime.Breakpoint()
+	fmt.Println(C.shapes_area(2, 3))
+}
@@ -0,0 +1,38 @@
+#include "shapes.h"
+
+namespace shapes {
+
+class Rect {
+public:
+	Rect(int w, int h) : w(w), h(h) {}
+	int area() const;
+	int scale(int n);
+	int scale(double f);
+
+private:
+	int w, h;
+};
+
+int Rect::area() const {
+	return w * h;
+}
+
+int Rect::scale(int n) {
+	w *= n;
+	h *= n;
+	return area();
+}
+
+int Rect::scale(double f) {
+	w = (int)(w * f);
+	h = (int)(h * f);
+	return area();
+}
+
+}
+
+extern "C" int shapes_area(int w, int h) {
+	shapes::Rect r(w, h);
+	r.scale(2);
+	return r.scale(0.5);
+}
@@ -0,0 +1,9 @@
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+int shapes_area(int w, int h);
+
+#ifdef __cplusplus
+}
+#endif
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+func main() {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	<-ch
+	ch <- 4 // the circular buffer now wraps around: 2, 3, 4
+	go func() {
+		ch <- 5
+	}()
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	fmt.Println(<-ch, <-ch, <-ch, <-ch)
+}
@@ -0,0 +1,16 @@
+package main
+
+import "runtime"
+
+func f2(a int8, b int32) {
+}
+
+func call(x int8, y int32) {
+	defer f2(42, 61)
+	defer f2(x, y)
+	runtime.Breakpoint()
+}
+
+func main() {
+	call(1, -1)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+var spins int
+
+func spin() int {
+	for {
+		spins++
+	}
+}
+
+func block() int {
+	for {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func quick() int {
+	return 42
+}
+
+func main() {
+	runtime.Breakpoint()
+	f := quick
+	if len(os.Args) > 1 {
+		f = block
+	}
+	if len(os.Args) > 2 {
+		f = spin
+	}
+	fmt.Println(f())
+}
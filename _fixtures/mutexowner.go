@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+func main() {
+	var unlocked, locked sync.Mutex
+	var rwunlocked, rlocked, wlocked sync.RWMutex
+	plocked := &locked
+	notamutex := 1
+
+	locked.Lock()
+	rlocked.RLock()
+	wlocked.Lock()
+
+	runtime.Breakpoint()
+	fmt.Println(&unlocked, plocked, &rwunlocked, &rlocked, &wlocked, notamutex)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, syscall.SIGUSR1)
+	received := 0
+	for i := 0; i < 2; i++ {
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		select {
+		case <-c:
+			received++
+		case <-time.After(time.Second):
+		}
+	}
+	os.Exit(received)
+}
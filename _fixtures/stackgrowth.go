@@ -0,0 +1,18 @@
+package main
+
+import "fmt"
+
+func grow(n int) int {
+	var buf [128]byte
+	buf[0] = byte(n)
+	if n == 0 {
+		return int(buf[0])
+	}
+	return grow(n-1) + int(buf[0])
+}
+
+func main() {
+	fmt.Println("start")
+	n := grow(1000)
+	fmt.Println(n)
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+func main() {
+	s := []string{"ccc", "a", "bb", "dddd"}
+	sort.Slice(s, func(i, j int) bool {
+		return len(s[i]) < len(s[j])
+	})
+	fmt.Println(s)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+func main() {
+	var wg sync.WaitGroup
+	var once, notdone sync.Once
+	var m, empty sync.Map
+
+	wg.Add(2)
+	once.Do(func() {})
+	m.Store("a", 1)
+	m.Store("b", 2)
+	m.Store("c", 3)
+	m.Delete("b")
+
+	runtime.Breakpoint()
+	fmt.Println(&wg, &once, &notdone, &m, &empty)
+}
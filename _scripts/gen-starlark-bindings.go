@@ -26,7 +26,7 @@ func getSuitableMethods(pkg *types.Package, typename string) []*types.Func {
 			continue
 		}
 
-		if fn.Name() == "Command" || fn.Name() == "Restart" || fn.Name() == "State" {
+		if fn.Name() == "Command" || fn.Name() == "Restart" || fn.Name() == "State" || fn.Name() == "CallMethod" {
 			r = append(r, fn)
 			continue
 		}
@@ -127,6 +127,8 @@ func processServerMethods(serverMethods []*types.Func) []binding {
 			retType = "rpc2.RestartOut"
 		case "State":
 			retType = "rpc2.StateOut"
+		case "CallMethod":
+			retType = "rpc2.CallMethodOut"
 		}
 
 		bindings[i] = binding{
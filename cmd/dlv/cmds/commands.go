@@ -60,6 +60,9 @@ var (
 	tty string
 	// disableASLR is used to disable ASLR
 	disableASLR bool
+	// streamOutput is true if the output of the target should be reported
+	// to the client instead of being written to the terminal.
+	streamOutput bool
 
 	// backend selection
 	backend string
@@ -134,6 +137,7 @@ func New(docCall bool) *cobra.Command {
 	rootCommand.PersistentFlags().StringArrayVarP(&redirects, "redirect", "r", []string{}, "Specifies redirect rules for target process (see 'dlv help redirect')")
 	rootCommand.PersistentFlags().BoolVar(&allowNonTerminalInteractive, "allow-non-terminal-interactive", false, "Allows interactive sessions of Delve that don't have a terminal as stdin, stdout and stderr")
 	rootCommand.PersistentFlags().BoolVar(&disableASLR, "disable-aslr", false, "Disables address space randomization")
+	rootCommand.PersistentFlags().BoolVar(&streamOutput, "stream-output", false, "Reports stdout and stderr of the target to the client while it runs, as intermediate states of continue, and when it stops (headless mode only).")
 
 	// 'attach' subcommand.
 	attachCommand := &cobra.Command{
@@ -294,6 +298,10 @@ The core command will open the specified core file and the associated
 executable and let you examine the state of the process when the
 core dump was taken.
 
+For core files of Go programs that crashed with GOTRACEBACK=crash the
+thread and goroutine that caused the crash are selected, even when the
+runtime raised the fatal signal on a different thread.
+
 Currently supports linux/amd64 and linux/arm64 core files, windows/amd64 minidumps and core files generated by Delve's 'dump' command.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 2 {
@@ -801,6 +809,11 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 		return 1
 	}
 
+	if streamOutput && (!headless || tty != "") {
+		fmt.Fprintf(os.Stderr, "--stream-output can only be used in headless mode and without --tty\n")
+		return 1
+	}
+
 	redirects, err := parseRedirects(redirects)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -854,6 +867,7 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 				TTY:                  tty,
 				Redirects:            redirects,
 				DisableASLR:          disableASLR,
+				StreamOutput:         streamOutput,
 			},
 		})
 	default:
@@ -0,0 +1,799 @@
+// Package demangle demangles the names of C++ functions, mangled as
+// described by the Itanium C++ ABI (used by gcc and clang on all the
+// platforms supported by delve except windows).
+//
+// Only the subset of the mangling scheme that is needed to describe
+// functions is supported: special names (vtables, typeinfo, thunks...),
+// expressions in template arguments and a few other rarely used constructs
+// make Demangle fail. The return type of template functions is omitted.
+package demangle
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var errUnsupported = errors.New("unsupported mangled name")
+
+// maxNumber is the largest number accepted by number and seqID, it leaves
+// room for the +1 applied to sequence IDs without overflowing on 32bit
+// platforms.
+const maxNumber = math.MaxInt32 - 1
+
+// Demangle returns the demangled form of the mangled name 'name', for
+// example:
+//
+//	_ZN2ns7MyClass6methodEi
+//
+// is demangled to:
+//
+//	ns::MyClass::method(int)
+//
+// The second return value is false if name is not a mangled C++ name or it
+// uses parts of the mangling scheme that are not supported.
+func Demangle(name string) (string, bool) {
+	if !strings.HasPrefix(name, "_Z") {
+		return "", false
+	}
+	p := &parser{s: name, pos: 2}
+	r, err := p.safeEncoding()
+	if err != nil {
+		return "", false
+	}
+	if p.pos < len(p.s) {
+		if p.s[p.pos] != '.' {
+			return "", false
+		}
+		// vendor specific suffix, for example .cold or .isra.0
+		r += " [clone " + p.s[p.pos:] + "]"
+	}
+	return r, true
+}
+
+// parser holds the state of the demangler.
+type parser struct {
+	s   string
+	pos int
+
+	// subs is the list of substitution candidates.
+	subs []cxxType
+	// tmpl holds the template arguments of the function being demangled,
+	// referenced by template parameters.
+	tmpl []cxxType
+}
+
+// cxxType is a demangled type. Function and array types are composite:
+// pointer, reference and cv-qualifiers applied to them are collected in
+// decl and printed between base and suffix, for example:
+//
+//	void (*)(int)
+type cxxType struct {
+	base      string
+	decl      string
+	suffix    string
+	composite bool
+}
+
+func plainType(s string) cxxType {
+	return cxxType{base: s}
+}
+
+func (t cxxType) String() string {
+	if !t.composite {
+		return t.base
+	}
+	if t.decl == "" {
+		return t.base + " " + t.suffix
+	}
+	return t.base + " (" + t.decl + ")" + t.suffix
+}
+
+// qualify applies the pointer, reference or cv-qualifier q to t.
+func (t cxxType) qualify(q string) cxxType {
+	if t.composite {
+		if q[0] == ' ' && t.decl == "" {
+			// cv-qualified function type
+			t.suffix += q
+			return t
+		}
+		t.decl += q
+		return t
+	}
+	t.base += q
+	return t
+}
+
+// bailout is used to unwind the parser when the input is malformed.
+type bailout struct{}
+
+func (p *parser) fail() {
+	panic(bailout{})
+}
+
+func (p *parser) safeEncoding() (r string, err error) {
+	defer func() {
+		if ierr := recover(); ierr != nil {
+			if _, ok := ierr.(bailout); !ok {
+				panic(ierr)
+			}
+			err = errUnsupported
+		}
+	}()
+	return p.encoding(), nil
+}
+
+func (p *parser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) peek2() string {
+	if p.pos+2 > len(p.s) {
+		return ""
+	}
+	return p.s[p.pos : p.pos+2]
+}
+
+func (p *parser) next() byte {
+	if p.pos >= len(p.s) {
+		p.fail()
+	}
+	c := p.s[p.pos]
+	p.pos++
+	return c
+}
+
+func (p *parser) expect(c byte) {
+	if p.next() != c {
+		p.fail()
+	}
+}
+
+func (p *parser) addSub(t cxxType) {
+	p.subs = append(p.subs, t)
+}
+
+// encoding parses:
+//
+//	<encoding> ::= <name> <bare-function-type>
+//	           ::= <name>
+func (p *parser) encoding() string {
+	n := p.name(true)
+	if p.pos >= len(p.s) || p.peek() == 'E' || p.peek() == '.' {
+		return n.name
+	}
+	if n.template && !n.ctorDtorConv {
+		// the return type of template functions is mangled but not printed
+		p.typ()
+	}
+	return n.name + p.bareFunctionType() + n.qualifiers
+}
+
+// bareFunctionType parses the parameter types of a function and returns
+// them formatted as a parameter list.
+func (p *parser) bareFunctionType() string {
+	var params []string
+	for p.pos < len(p.s) && p.peek() != 'E' && p.peek() != '.' {
+		t := p.typ()
+		params = append(params, t.String())
+	}
+	if len(params) == 0 {
+		p.fail()
+	}
+	if len(params) == 1 && params[0] == "void" {
+		return "()"
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
+// parsedName is the result of parsing a <name>.
+type parsedName struct {
+	name string
+	// template is true if the name ends with template arguments.
+	template bool
+	// ctorDtorConv is true if the name is a constructor, a destructor or a
+	// conversion operator.
+	ctorDtorConv bool
+	// qualifiers are the cv and ref qualifiers of a member function.
+	qualifiers string
+}
+
+// name parses:
+//
+//	<name> ::= <nested-name>
+//	       ::= <unscoped-name>
+//	       ::= <unscoped-template-name> <template-args>
+//	       ::= <local-name>
+//
+// If top is true the name is the name of the entity being demangled, the
+// template arguments it contains are the ones referenced by template
+// parameters.
+func (p *parser) name(top bool) parsedName {
+	switch p.peek() {
+	case 'N':
+		return p.nestedName(top)
+	case 'Z':
+		return p.localName()
+	}
+
+	var r parsedName
+	switch {
+	case p.peek2() == "St":
+		p.pos += 2
+		r.name, r.ctorDtorConv = p.unqualifiedName("")
+		r.name = "std::" + r.name
+	case p.peek() == 'S':
+		// substitutions are not substitution candidates themselves
+		t, ok := p.substitution()
+		if !ok || p.peek() != 'I' {
+			p.fail()
+		}
+		r.name = t.String()
+		r.name += p.templateArgs(top)
+		r.template = true
+		return r
+	default:
+		r.name, r.ctorDtorConv = p.unqualifiedName("")
+	}
+	if p.peek() == 'I' {
+		p.addSub(plainType(r.name))
+		args := p.templateArgs(top)
+		r.name += args
+		r.template = true
+	}
+	return r
+}
+
+// nestedName parses:
+//
+//	<nested-name> ::= N [<CV-qualifiers>] [<ref-qualifier>] <prefix> <unqualified-name> E
+//	              ::= N [<CV-qualifiers>] [<ref-qualifier>] <template-prefix> <template-args> E
+func (p *parser) nestedName(top bool) parsedName {
+	p.expect('N')
+	var r parsedName
+	r.qualifiers = p.cvQualifiers()
+	switch p.peek() {
+	case 'R':
+		p.pos++
+		r.qualifiers += " &"
+	case 'O':
+		p.pos++
+		r.qualifiers += " &&"
+	}
+
+	var cur, last string
+	for {
+		if p.peek() == 'E' {
+			p.pos++
+			break
+		}
+		r.template = false
+		switch {
+		case p.peek() == 'I':
+			if cur == "" {
+				p.fail()
+			}
+			cur += p.templateArgs(top)
+			r.template = true
+		case p.peek2() == "St":
+			p.pos += 2
+			cur = "std"
+			continue
+		case p.peek() == 'S':
+			t, ok := p.substitution()
+			if !ok {
+				p.fail()
+			}
+			cur = t.String()
+			if i := strings.LastIndex(cur, "::"); i >= 0 {
+				last = stripTemplateArgs(cur[i+2:])
+			} else {
+				last = stripTemplateArgs(cur)
+			}
+			continue
+		case p.peek() == 'T':
+			t := p.templateParam()
+			cur = t.String()
+			last = cur
+		default:
+			var name string
+			name, r.ctorDtorConv = p.unqualifiedName(last)
+			if !r.ctorDtorConv {
+				last = name
+			}
+			if cur != "" {
+				cur += "::"
+			}
+			cur += name
+		}
+		if p.peek() != 'E' {
+			p.addSub(plainType(cur))
+		}
+	}
+	if cur == "" {
+		p.fail()
+	}
+	r.name = cur
+	return r
+}
+
+// localName parses:
+//
+//	<local-name> ::= Z <encoding> E <entity name> [<discriminator>]
+//	             ::= Z <encoding> E s [<discriminator>]
+func (p *parser) localName() parsedName {
+	p.expect('Z')
+	tmpl := p.tmpl
+	enc := p.encoding()
+	p.tmpl = tmpl
+	p.expect('E')
+	var r parsedName
+	if p.peek() == 's' {
+		p.pos++
+		r.name = enc + "::string literal"
+	} else {
+		n := p.name(false)
+		r = n
+		r.name = enc + "::" + n.name
+	}
+	if p.peek() == '_' {
+		p.pos++
+		if p.peek() == '_' {
+			p.pos++
+			p.number()
+			p.expect('_')
+		} else {
+			p.next()
+		}
+	}
+	return r
+}
+
+// unqualifiedName parses:
+//
+//	<unqualified-name> ::= <operator-name> [<abi-tags>]
+//	                   ::= <ctor-dtor-name>
+//	                   ::= [L] <source-name> [<abi-tags>]
+//
+// The name of the class is needed to print constructors and destructors.
+func (p *parser) unqualifiedName(class string) (name string, ctorDtorConv bool) {
+	c := p.peek()
+	if c == 'L' {
+		// internal linkage
+		p.pos++
+		c = p.peek()
+	}
+	switch {
+	case c >= '0' && c <= '9':
+		name = p.sourceName()
+	case c == 'C' || (c == 'D' && p.pos+1 < len(p.s) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '5'):
+		if class == "" {
+			p.fail()
+		}
+		p.pos++
+		if c == 'C' && p.peek() == 'I' {
+			// inheriting constructor
+			p.pos++
+		}
+		p.next()
+		if c == 'D' {
+			name = "~" + class
+		} else {
+			name = class
+		}
+		return name, true
+	case c >= 'a' && c <= 'z':
+		name, ctorDtorConv = p.operatorName()
+	default:
+		p.fail()
+	}
+	for p.peek() == 'B' {
+		p.pos++
+		name += "[abi:" + p.sourceName() + "]"
+	}
+	return name, ctorDtorConv
+}
+
+// sourceName parses:
+//
+//	<source-name> ::= <positive length number> <identifier>
+func (p *parser) sourceName() string {
+	n := p.number()
+	if n <= 0 || n > len(p.s)-p.pos {
+		p.fail()
+	}
+	id := p.s[p.pos : p.pos+n]
+	p.pos += n
+	if strings.HasPrefix(id, "_GLOBAL_") && len(id) > len("_GLOBAL_")+1 && id[len("_GLOBAL_")+1] == 'N' {
+		return "(anonymous namespace)"
+	}
+	return id
+}
+
+// number parses a non negative decimal number.
+func (p *parser) number() int {
+	start := p.pos
+	n := 0
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		d := int(p.s[p.pos] - '0')
+		if n > (maxNumber-d)/10 {
+			p.fail()
+		}
+		n = n*10 + d
+		p.pos++
+	}
+	if p.pos == start {
+		p.fail()
+	}
+	return n
+}
+
+// seqID parses a base 36 number terminated by '_', as used by
+// substitutions and template parameters, the empty sequence is -1.
+func (p *parser) seqID() int {
+	n := -1
+	if p.peek() != '_' {
+		n = 0
+		for p.peek() != '_' {
+			c := p.next()
+			var d int
+			switch {
+			case c >= '0' && c <= '9':
+				d = int(c - '0')
+			case c >= 'A' && c <= 'Z':
+				d = int(c-'A') + 10
+			default:
+				p.fail()
+			}
+			if n > (maxNumber-d)/36 {
+				p.fail()
+			}
+			n = n*36 + d
+		}
+	}
+	p.expect('_')
+	return n
+}
+
+var operators = map[string]string{
+	"nw": "new", "na": "new[]", "dl": "delete", "da": "delete[]",
+	"ps": "+", "ng": "-", "ad": "&", "de": "*", "co": "~",
+	"pl": "+", "mi": "-", "ml": "*", "dv": "/", "rm": "%",
+	"an": "&", "or": "|", "eo": "^", "aS": "=",
+	"pL": "+=", "mI": "-=", "mL": "*=", "dV": "/=", "rM": "%=",
+	"aN": "&=", "oR": "|=", "eO": "^=",
+	"ls": "<<", "rs": ">>", "lS": "<<=", "rS": ">>=",
+	"eq": "==", "ne": "!=", "lt": "<", "gt": ">", "le": "<=", "ge": ">=", "ss": "<=>",
+	"nt": "!", "aa": "&&", "oo": "||", "pp": "++", "mm": "--",
+	"cm": ",", "pm": "->*", "pt": "->", "cl": "()", "ix": "[]", "qu": "?",
+}
+
+// operatorName parses:
+//
+//	<operator-name> ::= <two letter code>
+//	                ::= cv <type>
+//	                ::= li <source-name>
+func (p *parser) operatorName() (string, bool) {
+	code := p.peek2()
+	if code == "" {
+		p.fail()
+	}
+	p.pos += 2
+	switch code {
+	case "cv":
+		return "operator " + p.typ().String(), true
+	case "li":
+		return `operator"" ` + p.sourceName(), false
+	}
+	op, ok := operators[code]
+	if !ok {
+		p.fail()
+	}
+	if op[0] >= 'a' && op[0] <= 'z' {
+		return "operator " + op, false
+	}
+	return "operator" + op, false
+}
+
+// cvQualifiers parses:
+//
+//	<CV-qualifiers> ::= [r] [V] [K]
+func (p *parser) cvQualifiers() string {
+	var r string
+	if p.peek() == 'r' {
+		p.pos++
+		r += " restrict"
+	}
+	if p.peek() == 'V' {
+		p.pos++
+		r += " volatile"
+	}
+	if p.peek() == 'K' {
+		p.pos++
+		r += " const"
+	}
+	return r
+}
+
+// templateArgs parses:
+//
+//	<template-args> ::= I <template-arg>+ E
+func (p *parser) templateArgs(top bool) string {
+	p.expect('I')
+	var args []cxxType
+	for p.peek() != 'E' {
+		args = append(args, p.templateArg()...)
+	}
+	p.pos++
+	if top {
+		p.tmpl = args
+	}
+	strs := make([]string, len(args))
+	for i := range args {
+		strs[i] = args[i].String()
+	}
+	r := "<" + strings.Join(strs, ", ")
+	if strings.HasSuffix(r, ">") {
+		r += " "
+	}
+	return r + ">"
+}
+
+// templateArg parses:
+//
+//	<template-arg> ::= <type>
+//	               ::= L <literal> E
+//	               ::= J <template-arg>* E
+func (p *parser) templateArg() []cxxType {
+	switch p.peek() {
+	case 'L':
+		return []cxxType{plainType(p.literal())}
+	case 'J':
+		p.pos++
+		var r []cxxType
+		for p.peek() != 'E' {
+			r = append(r, p.templateArg()...)
+		}
+		p.pos++
+		return r
+	case 'X':
+		// expressions are not supported
+		p.fail()
+	}
+	return []cxxType{p.typ()}
+}
+
+// literal parses:
+//
+//	<expr-primary> ::= L <type> <value number> E
+//	               ::= L _Z <encoding> E
+func (p *parser) literal() string {
+	p.expect('L')
+	if p.peek2() == "_Z" {
+		p.pos += 2
+		tmpl := p.tmpl
+		r := p.encoding()
+		p.tmpl = tmpl
+		p.expect('E')
+		return r
+	}
+	t := p.typ()
+	neg := false
+	if p.peek() == 'n' {
+		p.pos++
+		neg = true
+	}
+	start := p.pos
+	for p.peek() != 'E' {
+		p.next()
+	}
+	val := p.s[start:p.pos]
+	p.pos++
+	switch t.String() {
+	case "bool":
+		switch val {
+		case "0":
+			return "false"
+		case "1":
+			return "true"
+		}
+	case "int":
+		if neg {
+			return "-" + val
+		}
+		return val
+	case "unsigned int":
+		return val + "u"
+	case "long":
+		if neg {
+			return "-" + val + "l"
+		}
+		return val + "l"
+	case "unsigned long":
+		return val + "ul"
+	}
+	if neg {
+		val = "-" + val
+	}
+	return "(" + t.String() + ")" + val
+}
+
+var builtinTypes = map[byte]string{
+	'v': "void", 'w': "wchar_t", 'b': "bool",
+	'c': "char", 'a': "signed char", 'h': "unsigned char",
+	's': "short", 't': "unsigned short",
+	'i': "int", 'j': "unsigned int",
+	'l': "long", 'm': "unsigned long",
+	'x': "long long", 'y': "unsigned long long",
+	'n': "__int128", 'o': "unsigned __int128",
+	'f': "float", 'd': "double", 'e': "long double", 'g': "__float128",
+	'z': "...",
+}
+
+var builtinDTypes = map[byte]string{
+	'd': "decimal64", 'e': "decimal128", 'f': "decimal32", 'h': "half",
+	'i': "char32_t", 's': "char16_t", 'u': "char8_t",
+	'a': "auto", 'c': "decltype(auto)", 'n': "decltype(nullptr)",
+}
+
+// typ parses a <type>.
+func (p *parser) typ() cxxType {
+	c := p.peek()
+	if name, ok := builtinTypes[c]; ok {
+		p.pos++
+		return plainType(name)
+	}
+
+	var t cxxType
+	switch c {
+	case 'u':
+		p.pos++
+		return plainType(p.sourceName())
+	case 'D':
+		if p.pos+1 >= len(p.s) {
+			p.fail()
+		}
+		c2 := p.s[p.pos+1]
+		if name, ok := builtinDTypes[c2]; ok {
+			p.pos += 2
+			return plainType(name)
+		}
+		if c2 != 'p' {
+			p.fail()
+		}
+		// pack expansion
+		p.pos += 2
+		t = p.typ()
+		t.base += "..."
+	case 'r', 'V', 'K':
+		q := p.cvQualifiers()
+		t = p.typ().qualify(q)
+	case 'P':
+		p.pos++
+		t = p.typ().qualify("*")
+	case 'R':
+		p.pos++
+		t = p.typ().qualify("&")
+	case 'O':
+		p.pos++
+		t = p.typ().qualify("&&")
+	case 'F':
+		p.pos++
+		if p.peek() == 'Y' {
+			p.pos++
+		}
+		ret := p.typ()
+		params := p.bareFunctionType()
+		switch p.peek() {
+		case 'R':
+			p.pos++
+			params += " &"
+		case 'O':
+			p.pos++
+			params += " &&"
+		}
+		p.expect('E')
+		t = cxxType{base: ret.String(), suffix: params, composite: true}
+	case 'A':
+		p.pos++
+		dim := ""
+		if p.peek() != '_' {
+			dim = p.s[p.pos : p.pos+digits(p.s[p.pos:])]
+			if dim == "" {
+				p.fail()
+			}
+			p.pos += len(dim)
+		}
+		p.expect('_')
+		elem := p.typ()
+		t = cxxType{base: elem.String(), suffix: " [" + dim + "]", composite: true}
+	case 'T':
+		t = p.templateParam()
+		p.addSub(t)
+		if p.peek() == 'I' {
+			t.base += p.templateArgs(false)
+		} else {
+			return t
+		}
+	case 'S':
+		if p.peek2() != "St" {
+			var ok bool
+			t, ok = p.substitution()
+			if !ok {
+				p.fail()
+			}
+			if p.peek() != 'I' {
+				return t
+			}
+			t = plainType(t.String() + p.templateArgs(false))
+			break
+		}
+		fallthrough
+	default:
+		n := p.name(false)
+		t = plainType(n.name)
+	}
+	p.addSub(t)
+	return t
+}
+
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// templateParam parses:
+//
+//	<template-param> ::= T_
+//	                 ::= T <parameter-2 non-negative number> _
+func (p *parser) templateParam() cxxType {
+	p.expect('T')
+	i := p.seqID() + 1
+	if i < 0 || i >= len(p.tmpl) {
+		p.fail()
+	}
+	return p.tmpl[i]
+}
+
+var stdSubstitutions = map[byte]string{
+	't': "std",
+	'a': "std::allocator",
+	'b': "std::basic_string",
+	's': "std::string",
+	'i': "std::istream",
+	'o': "std::ostream",
+	'd': "std::iostream",
+}
+
+// substitution parses:
+//
+//	<substitution> ::= S <seq-id> _
+//	               ::= S_
+//	               ::= St | Sa | Sb | Ss | Si | So | Sd
+func (p *parser) substitution() (cxxType, bool) {
+	p.expect('S')
+	c := p.peek()
+	if name, ok := stdSubstitutions[c]; ok {
+		p.pos++
+		return plainType(name), true
+	}
+	i := p.seqID() + 1
+	if i < 0 || i >= len(p.subs) {
+		return cxxType{}, false
+	}
+	return p.subs[i], true
+}
+
+// stripTemplateArgs removes the template arguments from the end of name.
+func stripTemplateArgs(name string) string {
+	if i := strings.Index(name, "<"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
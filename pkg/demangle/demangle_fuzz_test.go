@@ -0,0 +1,24 @@
+//go:build go1.18
+// +build go1.18
+
+package demangle
+
+import "testing"
+
+func FuzzDemangle(f *testing.F) {
+	for _, seed := range []string{
+		"_Z3foov",
+		"_ZN2ns7MyClass6methodEi",
+		"_Z1fRA10_i",
+		"_Z3maxIiET_S0_S0_",
+		"_ZNSt6vectorIiSaIiEE9push_backERKi",
+		"_ZS2000000000000_",
+		"_Z3foov.cold",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		// Demangle must not panic on malformed input.
+		Demangle(name)
+	})
+}
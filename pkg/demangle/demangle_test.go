@@ -0,0 +1,46 @@
+package demangle
+
+import "testing"
+
+func TestDemangle(t *testing.T) {
+	for _, tc := range []struct {
+		mangled, demangled string
+	}{
+		{"_Z3foov", "foo()"},
+		{"_Z3fooPKc", "foo(char const*)"},
+		{"_ZL3bari", "bar(int)"},
+		{"_ZN2ns7MyClass6methodEi", "ns::MyClass::method(int)"},
+		{"_ZNK7MyClass3getEv", "MyClass::get() const"},
+		{"_ZN7MyClassC2Ev", "MyClass::MyClass()"},
+		{"_ZN7MyClassD1Ev", "MyClass::~MyClass()"},
+		{"_ZN1AplERKS_", "A::operator+(A const&)"},
+		{"_ZN1AcvbEv", "A::operator bool()"},
+		{"_ZN12_GLOBAL__N_13fooEv", "(anonymous namespace)::foo()"},
+		{"_Z1fPFviE", "f(void (*)(int))"},
+		{"_Z1fRA10_i", "f(int (&) [10])"},
+		{"_Z3maxIiET_S0_S0_", "max<int>(int, int)"},
+		{"_ZNSt6vectorIiSaIiEE9push_backERKi", "std::vector<int, std::allocator<int> >::push_back(int const&)"},
+		{"_ZNSt7__cxx1112basic_stringIcSt11char_traitsIcESaIcEE6appendEPKc", "std::__cxx11::basic_string<char, std::char_traits<char>, std::allocator<char> >::append(char const*)"},
+		{"_Z3foov.cold", "foo() [clone .cold]"},
+	} {
+		got, ok := Demangle(tc.mangled)
+		if !ok {
+			t.Errorf("%s: could not demangle", tc.mangled)
+			continue
+		}
+		if got != tc.demangled {
+			t.Errorf("%s: got %q expected %q", tc.mangled, got, tc.demangled)
+		}
+	}
+
+	for _, name := range []string{
+		"main", "_Z", "_Zfoo", "_ZN3foo", "_ZTV7MyClass",
+		// malformed numbers and indices
+		"_ZS2000000000000_", "_ZSZZZZZZZZZZZZZZ_", "_Z3maxIiET2000000000000_",
+		"_Z99999999999999999999foo", "_Z9foo", "_Z1fS_", "_Z1fT_",
+	} {
+		if got, ok := Demangle(name); ok {
+			t.Errorf("%s: unexpected success %q", name, got)
+		}
+	}
+}
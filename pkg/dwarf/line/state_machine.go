@@ -17,6 +17,14 @@ type Location struct {
 	Delta   int
 }
 
+// Row is a row of the line number table.
+type Row struct {
+	Address uint64
+	File    string
+	Line    int
+	IsStmt  bool
+}
+
 type StateMachine struct {
 	dbl           *DebugLineInfo
 	file          string
@@ -375,6 +383,33 @@ func (lineInfo *DebugLineInfo) PrologueEndPC(start, end uint64) (pc uint64, file
 	}
 }
 
+// RowsBetween returns the rows of the line number table with an address in
+// the half open interval [start, end).
+func (lineInfo *DebugLineInfo) RowsBetween(start, end uint64) []Row {
+	if lineInfo == nil {
+		return nil
+	}
+
+	var rows []Row
+	sm := lineInfo.stateMachineForEntry(start)
+	for {
+		if sm.valid {
+			if sm.address >= end {
+				return rows
+			}
+			if sm.address >= start && !sm.endSeq {
+				rows = append(rows, Row{Address: sm.address, File: sm.file, Line: sm.line, IsStmt: sm.isStmt})
+			}
+		}
+		if err := sm.next(); err != nil {
+			if lineInfo.Logf != nil {
+				lineInfo.Logf("RowsBetween error: %v", err)
+			}
+			return rows
+		}
+	}
+}
+
 // FirstStmtForLine looks in the half open interval [start, end) for the
 // first PC address marked as stmt for the line at address 'start'.
 func (lineInfo *DebugLineInfo) FirstStmtForLine(start, end uint64) (pc uint64, file string, line int, ok bool) {
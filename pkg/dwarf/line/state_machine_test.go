@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/go-delve/delve/pkg/dwarf/util"
@@ -264,3 +265,104 @@ func TestMultipleSequences(t *testing.T) {
 		}
 	}
 }
+
+func TestRowsBetween(t *testing.T) {
+	const thefile = "thefile.go"
+
+	instr := bytes.NewBuffer(nil)
+	ptrSize := ptrSizeByRuntimeArch()
+
+	write_DW_LNE_set_address := func(addr uint64) {
+		instr.WriteByte(0)
+		util.EncodeULEB128(instr, 9) // 1 + ptr_size
+		instr.WriteByte(DW_LINE_set_address)
+		util.WriteUint(instr, binary.LittleEndian, ptrSize, addr)
+	}
+
+	write_DW_LNS_copy := func() {
+		instr.WriteByte(DW_LNS_copy)
+	}
+
+	write_DW_LNS_advance_pc := func(off uint64) {
+		instr.WriteByte(DW_LNS_advance_pc)
+		util.EncodeULEB128(instr, off)
+	}
+
+	write_DW_LNS_advance_line := func(off int64) {
+		instr.WriteByte(DW_LNS_advance_line)
+		util.EncodeSLEB128(instr, off)
+	}
+
+	write_DW_LNS_negate_stmt := func() {
+		instr.WriteByte(DW_LNS_negate_stmt)
+	}
+
+	write_DW_LNE_end_sequence := func() {
+		instr.WriteByte(0)
+		util.EncodeULEB128(instr, 1)
+		instr.WriteByte(DW_LINE_end_sequence)
+	}
+
+	write_DW_LNE_set_address(0x500000)
+	write_DW_LNS_advance_line(20)
+	write_DW_LNS_copy() // thefile.go:21 0x500000
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNS_advance_line(1)
+	write_DW_LNS_negate_stmt()
+	write_DW_LNS_copy() // thefile.go:22 0x500002 not a statement
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNS_advance_line(1)
+	write_DW_LNS_negate_stmt()
+	write_DW_LNS_copy() // thefile.go:23 0x500004
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNE_end_sequence() // thefile.go:23 ends the byte before 0x500006
+
+	write_DW_LNE_set_address(0x400000)
+	write_DW_LNS_copy() // thefile.go:1 0x400000
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNS_advance_line(1)
+	write_DW_LNS_copy() // thefile.go:2 0x400002
+	write_DW_LNS_advance_pc(0x2)
+	write_DW_LNE_end_sequence() // thefile.go:2 ends the byte before 0x400004
+
+	lines := &DebugLineInfo{
+		Prologue: &DebugLinePrologue{
+			UnitLength:     1,
+			Version:        2,
+			MinInstrLength: 1,
+			InitialIsStmt:  1,
+			LineBase:       -3,
+			LineRange:      12,
+			OpcodeBase:     13,
+			StdOpLengths:   []uint8{0, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 1},
+		},
+		IncludeDirs:  []string{},
+		FileNames:    []*FileEntry{&FileEntry{Path: thefile}},
+		Instructions: instr.Bytes(),
+		ptrSize:      ptrSize,
+
+		stateMachineCache: make(map[uint64]*StateMachine),
+		lastMachineCache:  make(map[uint64]*StateMachine),
+	}
+
+	for _, testCase := range []struct {
+		start, end uint64
+		tgt        string
+	}{
+		{0x500000, 0x500006, "[{0x500000 21 true} {0x500002 22 false} {0x500004 23 true}]"},
+		{0x500002, 0x500004, "[{0x500002 22 false}]"},
+		{0x400000, 0x400004, "[{0x400000 1 true} {0x400002 2 true}]"},
+	} {
+		rows := lines.RowsBetween(testCase.start, testCase.end)
+		out := make([]string, len(rows))
+		for i, row := range rows {
+			if row.File != thefile {
+				t.Errorf("RowsBetween(%#x, %#x): wrong file %q", testCase.start, testCase.end, row.File)
+			}
+			out[i] = fmt.Sprintf("{%#x %d %v}", row.Address, row.Line, row.IsStmt)
+		}
+		if s := "[" + strings.Join(out, " ") + "]"; s != testCase.tgt {
+			t.Errorf("RowsBetween(%#x, %#x): expected: %s got: %s", testCase.start, testCase.end, testCase.tgt, s)
+		}
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/go-delve/delve/pkg/demangle"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/service/api"
 )
@@ -115,6 +117,10 @@ func parseLocationSpecDefault(locStr, rest string) (LocationSpec, error) {
 		// On Windows, path may contain ":", so split only on last ":"
 		v = []string{strings.Join(v[0:len(v)-1], ":"), v[len(v)-1]}
 	}
+	if idx := strings.LastIndex(rest, ":"); idx > 0 && rest[idx-1] == ':' {
+		// C++ qualified name, without a line offset
+		v = []string{rest}
+	}
 
 	if len(v) == 1 {
 		n, err := strconv.ParseInt(v[0], 0, 64)
@@ -366,40 +372,7 @@ func (ale AmbiguousLocationError) Error() string {
 // This matches each other location spec that does not already have its own spec
 // implemented (such as regex, or addr).
 func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope *proc.EvalScope, locStr string, includeNonExecutableLines bool, substitutePathRules [][2]string) ([]api.Location, error) {
-	limit := maxFindLocationCandidates
-	var candidateFiles []string
-	for _, sourceFile := range scope.BinInfo.Sources {
-		substFile := sourceFile
-		if len(substitutePathRules) > 0 {
-			substFile = SubstitutePath(sourceFile, substitutePathRules)
-		}
-		if loc.FileMatch(substFile) || (len(processArgs) >= 1 && tryMatchRelativePathByProc(loc.Base, processArgs[0], substFile)) {
-			candidateFiles = append(candidateFiles, sourceFile)
-			if len(candidateFiles) >= limit {
-				break
-			}
-		}
-	}
-
-	limit -= len(candidateFiles)
-
-	var candidateFuncs []string
-	if loc.FuncBase != nil {
-		for _, f := range scope.BinInfo.Functions {
-			if !loc.FuncBase.Match(f, scope.BinInfo.PackageMap) {
-				continue
-			}
-			if loc.Base == f.Name {
-				// if an exact match for the function name is found use it
-				candidateFuncs = []string{f.Name}
-				break
-			}
-			candidateFuncs = append(candidateFuncs, f.Name)
-			if len(candidateFuncs) >= limit {
-				break
-			}
-		}
-	}
+	candidateFiles, candidateFuncs := loc.candidates(t.BinInfo(), processArgs, substitutePathRules, false)
 
 	if matching := len(candidateFiles) + len(candidateFuncs); matching == 0 {
 		// if no result was found this locations string could be an
@@ -438,6 +411,221 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 	return []api.Location{addressesToLocation(addrs)}, nil
 }
 
+// candidates returns the source files and the functions matched by loc.
+// Unless all is set at most maxFindLocationCandidates candidates are
+// returned and, if the name of a function matches loc exactly, it is the
+// only function returned.
+func (loc *NormalLocationSpec) candidates(bi *proc.BinaryInfo, processArgs []string, substitutePathRules [][2]string, all bool) (candidateFiles, candidateFuncs []string) {
+	limit := maxFindLocationCandidates
+	for _, sourceFile := range bi.Sources {
+		substFile := sourceFile
+		if len(substitutePathRules) > 0 {
+			substFile = SubstitutePath(sourceFile, substitutePathRules)
+		}
+		if loc.FileMatch(substFile) || (len(processArgs) >= 1 && tryMatchRelativePathByProc(loc.Base, processArgs[0], substFile)) {
+			candidateFiles = append(candidateFiles, sourceFile)
+			if !all && len(candidateFiles) >= limit {
+				break
+			}
+		}
+	}
+
+	limit -= len(candidateFiles)
+
+	cpp := isCPPLocation(loc.Base)
+	if loc.FuncBase != nil || cpp {
+		for _, f := range bi.Functions {
+			name, exact := f.Name, loc.Base == f.Name
+			if loc.FuncBase == nil || !loc.FuncBase.Match(f, bi.PackageMap) {
+				if !cpp {
+					continue
+				}
+				var match bool
+				match, exact = matchCPPFunction(loc.Base, &f)
+				if !match {
+					continue
+				}
+				// overloaded C++ functions have the same name, use the
+				// linkage name to identify them.
+				name = f.LinkageName
+			}
+			if exact && !all {
+				// if an exact match for the function name is found use it
+				candidateFuncs = []string{name}
+				break
+			}
+			candidateFuncs = append(candidateFuncs, name)
+			if !all && len(candidateFuncs) >= limit {
+				break
+			}
+		}
+	}
+	return candidateFiles, candidateFuncs
+}
+
+// isCPPLocation returns true if locStr could be the name of a C++
+// function: a qualified name, a name followed by its parameter list or a
+// mangled name.
+func isCPPLocation(locStr string) bool {
+	return strings.Contains(locStr, "::") || strings.Contains(locStr, "(") || strings.HasPrefix(locStr, "_Z")
+}
+
+// matchCPPFunction returns true if spec matches the C++ function fn. The
+// spec can be the mangled name of fn, or its demangled name optionally
+// prefixed by 'C.', with or without its parameter list and qualifying
+// namespaces and classes. The second return value is true if spec is the
+// mangled name or the complete demangled name of fn.
+// Spaces are ignored, parameter types must be written in the form produced
+// by the demangler, for example "char const*".
+func matchCPPFunction(spec string, fn *proc.Function) (match, exact bool) {
+	if fn.LinkageName == "" {
+		return false, false
+	}
+	if spec == fn.LinkageName {
+		return true, true
+	}
+	name, ok := demangle.Demangle(fn.LinkageName)
+	if !ok {
+		return false, false
+	}
+	spec = strings.Replace(strings.TrimPrefix(spec, "C."), " ", "", -1)
+	name = strings.Replace(name, " ", "", -1)
+	if spec == name {
+		return true, true
+	}
+	noParams := cppStripParams(name)
+	match = spec == noParams || strings.HasSuffix(name, "::"+spec) || strings.HasSuffix(noParams, "::"+spec)
+	return match, false
+}
+
+// cppStripParams removes the parameter list, and anything following it,
+// from the demangled name of a function.
+func cppStripParams(name string) string {
+	end := strings.LastIndex(name, ")")
+	depth := 0
+	for i := end; i >= 0; i-- {
+		switch name[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return name[:i]
+			}
+		}
+	}
+	return name
+}
+
+// FindRanked is like Find but, when more than one source file or function
+// matches loc, it returns one location for each of them instead of an
+// AmbiguousLocationError. The locations are sorted by rankCandidates.
+// Candidates that can not be resolved to an address are returned without
+// an address.
+func (loc *NormalLocationSpec) FindRanked(t *proc.Target, processArgs []string, scope *proc.EvalScope, locStr string, includeNonExecutableLines bool, substitutePathRules [][2]string) ([]api.Location, error) {
+	candidateFiles, candidateFuncs := loc.candidates(t.BinInfo(), processArgs, substitutePathRules, true)
+	if len(candidateFiles)+len(candidateFuncs) <= 1 {
+		return loc.Find(t, processArgs, scope, locStr, includeNonExecutableLines, substitutePathRules)
+	}
+
+	candidates := rankCandidates(loc, t.BinInfo(), scope, candidateFiles, candidateFuncs)
+	r := make([]api.Location, 0, len(candidates))
+	for _, c := range candidates {
+		var addrs []uint64
+		var err error
+		switch {
+		case c.fn != nil:
+			addrs, err = proc.FindFunctionLocation(t, c.name, loc.LineOffset)
+		case loc.LineOffset >= 0:
+			addrs, err = proc.FindFileLocation(t, c.name, loc.LineOffset)
+		}
+		if err != nil || len(addrs) == 0 {
+			l := api.Location{File: c.name}
+			if c.fn != nil {
+				l = api.Location{Function: api.ConvertFunction(c.fn)}
+			} else if loc.LineOffset >= 0 {
+				l.Line = loc.LineOffset
+			}
+			r = append(r, l)
+			continue
+		}
+		r = append(r, addressesToLocation(addrs))
+	}
+	return r, nil
+}
+
+type locationCandidate struct {
+	name     string         // path of the file or name of the function
+	fn       *proc.Function // nil for files
+	exact    bool
+	samePkg  bool
+	distance int
+}
+
+// rankCandidates sorts the files and functions matched by loc: exact
+// matches come first, followed by the candidates that belong to the
+// package of the current frame (or, for files, to its directory) and then
+// by the distance between their import path (or directory) and the one of
+// the current frame. Ties are broken by name, so that the order is always
+// the same.
+func rankCandidates(loc *NormalLocationSpec, bi *proc.BinaryInfo, scope *proc.EvalScope, files, funcs []string) []locationCandidate {
+	var curPkg, curDir string
+	if scope != nil && scope.Fn != nil {
+		curPkg = scope.Fn.PackageName()
+		curDir = path.Dir(filepath.ToSlash(scope.File))
+	}
+
+	r := make([]locationCandidate, 0, len(files)+len(funcs))
+	for _, file := range files {
+		c := locationCandidate{name: file, exact: file == loc.Base}
+		if curDir != "" {
+			dir := path.Dir(filepath.ToSlash(file))
+			c.samePkg = dir == curDir
+			c.distance = pathDistance(dir, curDir)
+		}
+		r = append(r, c)
+	}
+	for _, name := range funcs {
+		fn := bi.LookupFunc[name]
+		if fn == nil {
+			continue
+		}
+		c := locationCandidate{name: name, fn: fn, exact: name == loc.Base}
+		if curPkg != "" {
+			pkg := fn.PackageName()
+			c.samePkg = pkg == curPkg
+			c.distance = pathDistance(pkg, curPkg)
+		}
+		r = append(r, c)
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		a, b := &r[i], &r[j]
+		if a.exact != b.exact {
+			return a.exact
+		}
+		if a.samePkg != b.samePkg {
+			return a.samePkg
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		return a.name < b.name
+	})
+	return r
+}
+
+// pathDistance returns the number of path elements of a and b that are
+// not part of their common prefix.
+func pathDistance(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+	return len(as) - n + len(bs) - n
+}
+
 func crossPlatformPath(path string) string {
 	if runtime.GOOS == "windows" {
 		return strings.ToLower(path)
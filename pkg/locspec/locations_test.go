@@ -1,7 +1,10 @@
 package locspec
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
 )
 
 func parseLocationSpecNoError(t *testing.T, locstr string) LocationSpec {
@@ -66,3 +69,60 @@ func TestFunctionLocationParsing(t *testing.T) {
 	assertNormalLocationSpec(t, "github.com/go-delve/delve/pkg/proc.Process.Continue:10", NormalLocationSpec{"github.com/go-delve/delve/pkg/proc.Process.Continue", &FuncLocationSpec{PackageName: "github.com/go-delve/delve/pkg/proc", ReceiverName: "Process", BaseName: "Continue"}, 10})
 	assertNormalLocationSpec(t, "github.com/go-delve/delve/pkg/proc.Continue:10", NormalLocationSpec{"github.com/go-delve/delve/pkg/proc.Continue", &FuncLocationSpec{PackageName: "github.com/go-delve/delve/pkg/proc", BaseName: "Continue"}, 10})
 }
+
+func TestRankCandidates(t *testing.T) {
+	bi := &proc.BinaryInfo{LookupFunc: map[string]*proc.Function{}}
+	for _, name := range []string{"example.com/a/b.Foo", "example.com/a/b.(*T).Foo", "example.com/a/c.Foo", "example.com/x.Foo", "Foo"} {
+		bi.LookupFunc[name] = &proc.Function{Name: name}
+	}
+	scope := &proc.EvalScope{Location: proc.Location{File: "/src/a/c/c.go", Fn: &proc.Function{Name: "example.com/a/c.Bar"}}}
+
+	loc := parseLocationSpecNoError(t, "Foo").(*NormalLocationSpec)
+	funcs := []string{"example.com/x.Foo", "example.com/a/b.Foo", "Foo", "example.com/a/c.Foo", "example.com/a/b.(*T).Foo"}
+	files := []string{"/src/x/foo.go", "/src/a/c/foo.go"}
+
+	var got []string
+	for _, c := range rankCandidates(loc, bi, scope, files, funcs) {
+		got = append(got, c.name)
+	}
+	tgt := []string{
+		"Foo",                      // exact match
+		"/src/a/c/foo.go",          // same directory
+		"example.com/a/c.Foo",      // same package
+		"example.com/a/b.(*T).Foo", // distance 2, ties broken by name
+		"example.com/a/b.Foo",      // distance 2
+		"/src/x/foo.go",            // distance 3, ties broken by name
+		"example.com/x.Foo",        // distance 3
+	}
+	if !reflect.DeepEqual(got, tgt) {
+		t.Errorf("wrong order:\ngot:      %q\nexpected: %q", got, tgt)
+	}
+}
+
+func TestCPPFunctionLocations(t *testing.T) {
+	assertNormalLocationSpec(t, "ns::MyClass::method(int)", NormalLocationSpec{"ns::MyClass::method(int)", nil, -1})
+	assertNormalLocationSpec(t, "MyClass::method", NormalLocationSpec{"MyClass::method", nil, -1})
+	assertNormalLocationSpec(t, "MyClass::method(int):10", NormalLocationSpec{"MyClass::method(int)", nil, 10})
+
+	fn := &proc.Function{Name: "C.method", LinkageName: "_ZN2ns7MyClass6methodEPKc"}
+	for _, tc := range []struct {
+		spec         string
+		match, exact bool
+	}{
+		{"_ZN2ns7MyClass6methodEPKc", true, true},
+		{"ns::MyClass::method(char const*)", true, true},
+		{"C.ns::MyClass::method(char const *)", true, true},
+		{"ns::MyClass::method", true, false},
+		{"MyClass::method(char const*)", true, false},
+		{"MyClass::method", true, false},
+		{"method(char const*)", true, false},
+		{"Class::method", false, false},
+		{"MyClass::method(int)", false, false},
+		{"MyClass::other", false, false},
+	} {
+		match, exact := matchCPPFunction(tc.spec, fn)
+		if match != tc.match || exact != tc.exact {
+			t.Errorf("%q: got match=%v exact=%v, expected match=%v exact=%v", tc.spec, match, exact, tc.match, tc.exact)
+		}
+	}
+}
@@ -36,10 +36,28 @@ func arm64AsmDecode(asmInst *AsmInstruction, mem []byte, regs *op.DwarfRegisters
 	}
 
 	asmInst.DestLoc = resolveCallArgARM64(&inst, asmInst.Loc.PC, asmInst.AtPC, regs, memrw, bi)
+	asmInst.BranchTarget = branchTargetARM64(&inst, asmInst.Loc.PC)
 
 	return nil
 }
 
+// branchTargetARM64 returns the destination of inst if it is a direct
+// branch, 0 otherwise.
+func branchTargetARM64(inst *arm64asm.Inst, instAddr uint64) uint64 {
+	switch inst.Op {
+	case arm64asm.B, arm64asm.CBZ, arm64asm.CBNZ, arm64asm.TBZ, arm64asm.TBNZ:
+		// ok
+	default:
+		return 0
+	}
+	for _, arg := range inst.Args {
+		if rel, ok := arg.(arm64asm.PCRel); ok {
+			return uint64(int64(instAddr) + int64(rel))
+		}
+	}
+	return 0
+}
+
 func resolveCallArgARM64(inst *arm64asm.Inst, instAddr uint64, currentGoroutine bool, regs *op.DwarfRegisters, mem MemoryReadWriter, bininfo *BinaryInfo) *Location {
 	switch inst.Op {
 	case arm64asm.BL, arm64asm.BLR, arm64asm.B, arm64asm.BR:
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/parser"
 	"go/token"
 	"io"
 	"os"
@@ -283,6 +284,7 @@ type compileUnit struct {
 	lineInfo  *line.DebugLineInfo // debug_line segment associated with this compile unit
 	optimized bool                // this compile unit is optimized
 	producer  string              // producer attribute
+	pkgName   string              // package name of go compile units, if available
 
 	offset dwarf.Offset // offset of the entry describing the compile unit
 
@@ -315,6 +317,10 @@ type Function struct {
 
 	// InlinedCalls lists all inlined calls to this function
 	InlinedCalls []InlinedCall
+
+	// LinkageName is the mangled name of a C++ function, as it appears in
+	// the symbol table, or the empty string.
+	LinkageName string
 }
 
 // PackageName returns the package part of the symbol name,
@@ -375,6 +381,28 @@ func (fn *Function) PrologueEndPC() uint64 {
 	return pc
 }
 
+// LineTable returns the rows of the line number table that belong to fn.
+func (fn *Function) LineTable() []line.Row {
+	return fn.cu.lineInfo.RowsBetween(fn.Entry, fn.End)
+}
+
+// Wrapper returns true if fn is a wrapper generated by the compiler, for
+// example an ABI wrapper or the wrapper that allows a method with a value
+// receiver to be called through a pointer.
+func (fn *Function) Wrapper() bool {
+	if fn.cu.lineInfo != nil {
+		if file, _ := fn.cu.lineInfo.PCToLine(fn.Entry, fn.Entry); file == "<autogenerated>" {
+			return true
+		}
+	}
+	tree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		return false
+	}
+	trampoline, _ := tree.Val(dwarf.AttrTrampoline).(bool)
+	return trampoline
+}
+
 // From $GOROOT/src/runtime/traceback.go:597
 // exportedRuntime reports whether the function is an exported runtime function.
 // It is only for runtime functions, so ASCII A-Z is fine.
@@ -634,6 +662,31 @@ func (bi *BinaryInfo) PCToInlineFunc(pc uint64) *Function {
 	return bi.LookupFunc[fnname]
 }
 
+// PCToSymbol returns the name of the symbol containing the given PC
+// address and the offset of pc from its start. If pc belongs to a function
+// described by the debug information the symbol is the concrete function
+// containing pc, otherwise the symbol table is used. If no symbol contains
+// pc it returns an empty string.
+func (bi *BinaryInfo) PCToSymbol(pc uint64) (string, uint64) {
+	if fn := bi.PCToFunc(pc); fn != nil {
+		return fn.Name, pc - fn.Entry
+	}
+	var best *elf.Symbol
+	var bestAddr uint64
+	for addr, sym := range bi.SymNames {
+		if addr > pc || (addr != pc && pc-addr >= sym.Size) {
+			continue
+		}
+		if best == nil || addr > bestAddr {
+			best, bestAddr = sym, addr
+		}
+	}
+	if best == nil {
+		return "", 0
+	}
+	return best.Name, pc - bestAddr
+}
+
 // PCToImage returns the image containing the given PC address.
 func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 	fn := bi.PCToFunc(pc)
@@ -662,8 +715,10 @@ type Image struct {
 
 	compileUnits []*compileUnit // compileUnits is sorted by increasing DWARF offset
 
-	dwarfTreeCache      *simplelru.LRU
-	runtimeMallocgcTree *godwarf.Tree // patched version of runtime.mallocgc's DIE
+	dwarfTreeCache       *simplelru.LRU
+	dwarfTreeCacheHits   uint64
+	dwarfTreeCacheMisses uint64
+	runtimeMallocgcTree  *godwarf.Tree // patched version of runtime.mallocgc's DIE
 
 	// runtimeTypeToDIE maps between the offset of a runtime._type in
 	// runtime.moduledata.types and the offset of the DIE in debug_info. This
@@ -788,13 +843,50 @@ func (image *Image) LoadError() error {
 	return image.loadErr
 }
 
+// HasDebugInfo returns true if debug information was found for this image.
+func (image *Image) HasDebugInfo() bool {
+	return image.dwarf != nil
+}
+
+// ImageStats describes the debug information loaded for an image and the
+// state of the caches used to read it.
+type ImageStats struct {
+	CompileUnits          int    // number of compile units parsed
+	TypeCacheEntries      int    // number of types read from debug_info
+	DwarfTreeCacheEntries int    // number of DIE trees currently cached
+	DwarfTreeCacheHits    uint64 // lookups of DIE trees served by the cache
+	DwarfTreeCacheMisses  uint64 // lookups of DIE trees read from debug_info
+}
+
+// Stats returns statistics about the debug information loaded for image.
+func (image *Image) Stats() ImageStats {
+	r := ImageStats{
+		CompileUnits:         len(image.compileUnits),
+		TypeCacheEntries:     len(image.typeCache),
+		DwarfTreeCacheHits:   image.dwarfTreeCacheHits,
+		DwarfTreeCacheMisses: image.dwarfTreeCacheMisses,
+	}
+	if image.dwarfTreeCache != nil {
+		r.DwarfTreeCacheEntries = image.dwarfTreeCache.Len()
+	}
+	return r
+}
+
+// NumTypes returns the number of named types found in the debug
+// information of all images.
+func (bi *BinaryInfo) NumTypes() int {
+	return len(bi.types)
+}
+
 func (image *Image) getDwarfTree(off dwarf.Offset) (*godwarf.Tree, error) {
 	if image.runtimeMallocgcTree != nil && off == image.runtimeMallocgcTree.Offset {
 		return image.runtimeMallocgcTree, nil
 	}
 	if r, ok := image.dwarfTreeCache.Get(off); ok {
+		image.dwarfTreeCacheHits++
 		return r.(*godwarf.Tree), nil
 	}
+	image.dwarfTreeCacheMisses++
 	r, err := godwarf.LoadTree(off, image.dwarf, image.StaticBase)
 	if err != nil {
 		return nil, err
@@ -1577,6 +1669,51 @@ func (bi *BinaryInfo) findType(name string) (godwarf.Type, error) {
 	return godwarf.ReadType(image.dwarf, ref.imageIndex, ref.offset, image.typeCache)
 }
 
+// FindType returns the type named name. If no type has this exact name,
+// name is parsed as a type expression, package names in it are expanded
+// like they are in expressions and names without a package are looked up
+// in all packages. An error is returned if name is ambiguous.
+func (bi *BinaryInfo) FindType(name string) (godwarf.Type, error) {
+	if typ, err := bi.findType(name); err == nil {
+		return typ, nil
+	}
+	expr, err := parser.ParseExpr(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse type %s: %v", name, err)
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		var candidates []string
+		for typn := range bi.types {
+			if !strings.HasSuffix(typn, "."+e.Name) {
+				continue
+			}
+			pkg := typn[:len(typn)-len(e.Name)-1]
+			if packageName(typn) == pkg && !strings.ContainsAny(pkg, "[]*() ") {
+				candidates = append(candidates, typn)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			// not found, unless it is a predeclared type
+		case 1:
+			return bi.findType(candidates[0])
+		default:
+			sort.Strings(candidates)
+			return nil, fmt.Errorf("type name %s is ambiguous, could be: %s", name, strings.Join(candidates, ", "))
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && len(bi.PackageMap[x.Name]) > 1 {
+			return nil, fmt.Errorf("package name %s is ambiguous, could be: %s", x.Name, strings.Join(bi.PackageMap[x.Name], ", "))
+		}
+	}
+	typ, err := bi.findTypeExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("could not find type %s", name)
+	}
+	return typ, nil
+}
+
 func (bi *BinaryInfo) findTypeExpr(expr ast.Expr) (godwarf.Type, error) {
 	if lit, islit := expr.(*ast.BasicLit); islit && lit.Kind == token.STRING {
 		// Allow users to specify type names verbatim as quoted
@@ -1759,6 +1896,7 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 				}
 			}
 			gopkg, _ := entry.Val(godwarf.AttrGoPackageName).(string)
+			cu.pkgName = gopkg
 			if cu.isgo && gopkg != "" {
 				bi.PackageMap[gopkg] = append(bi.PackageMap[gopkg], escapePackagePath(strings.Replace(cu.name, "\\", "/", -1)))
 			}
@@ -1784,6 +1922,11 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 	for i := range bi.Functions {
 		bi.LookupFunc[bi.Functions[i].Name] = &bi.Functions[i]
 	}
+	for i := range bi.Functions {
+		if bi.Functions[i].LinkageName != "" {
+			bi.LookupFunc[bi.Functions[i].LinkageName] = &bi.Functions[i]
+		}
+	}
 
 	for _, cu := range image.compileUnits {
 		if cu.lineInfo != nil {
@@ -1947,6 +2090,7 @@ func (bi *BinaryInfo) addAbstractSubprogram(entry *dwarf.Entry, ctxt *loadDebugI
 	originIdx := ctxt.lookupAbstractOrigin(bi, entry.Offset)
 	fn := &bi.Functions[originIdx]
 	fn.Name = name
+	fn.LinkageName = subprogramEntryLinkageName(entry, cu)
 	fn.offset = entry.Offset
 	fn.cu = cu
 }
@@ -1995,6 +2139,7 @@ func (bi *BinaryInfo) addConcreteSubprogram(entry *dwarf.Entry, ctxt *loadDebugI
 	fn := &bi.Functions[originIdx]
 
 	fn.Name = name
+	fn.LinkageName = subprogramEntryLinkageName(entry, cu)
 	fn.Entry = lowpc
 	fn.End = highpc
 	fn.offset = entry.Offset
@@ -2007,6 +2152,13 @@ func (bi *BinaryInfo) addConcreteSubprogram(entry *dwarf.Entry, ctxt *loadDebugI
 
 func subprogramEntryName(entry *dwarf.Entry, cu *compileUnit) (string, bool) {
 	name, ok := entry.Val(dwarf.AttrName).(string)
+	if !ok && !cu.isgo {
+		// The definition of a C++ method, outside of its class, only refers
+		// to its declaration.
+		if decl := subprogramSpecification(entry, cu); decl != nil {
+			name, ok = decl.Val(dwarf.AttrName).(string)
+		}
+	}
 	if !ok {
 		return "", false
 	}
@@ -2016,6 +2168,48 @@ func subprogramEntryName(entry *dwarf.Entry, cu *compileUnit) (string, bool) {
 	return name, true
 }
 
+// attrMIPSLinkageName is the attribute used by old versions of gcc for the
+// linkage name.
+const attrMIPSLinkageName dwarf.Attr = 0x2007
+
+// subprogramEntryLinkageName returns the linkage name of a subprogram of a
+// C++ compile unit, or the empty string.
+func subprogramEntryLinkageName(entry *dwarf.Entry, cu *compileUnit) string {
+	if cu.isgo {
+		return ""
+	}
+	linkageName := func(entry *dwarf.Entry) string {
+		if name, ok := entry.Val(dwarf.AttrLinkageName).(string); ok {
+			return name
+		}
+		name, _ := entry.Val(attrMIPSLinkageName).(string)
+		return name
+	}
+	if name := linkageName(entry); name != "" {
+		return name
+	}
+	if decl := subprogramSpecification(entry, cu); decl != nil {
+		return linkageName(decl)
+	}
+	return ""
+}
+
+// subprogramSpecification returns the declaration referenced by the
+// DW_AT_specification attribute of entry, or nil.
+func subprogramSpecification(entry *dwarf.Entry, cu *compileUnit) *dwarf.Entry {
+	off, ok := entry.Val(dwarf.AttrSpecification).(dwarf.Offset)
+	if !ok {
+		return nil
+	}
+	rdr := cu.image.dwarf.Reader()
+	rdr.Seek(off)
+	decl, err := rdr.Next()
+	if err != nil || decl == nil {
+		return nil
+	}
+	return decl
+}
+
 func subprogramEntryRange(entry *dwarf.Entry, image *Image) (lowpc, highpc uint64, ok bool) {
 	ok = false
 	if ranges, _ := image.dwarf.Ranges(entry); len(ranges) >= 1 {
@@ -2185,6 +2379,7 @@ func (bi *BinaryInfo) symLookup(addr uint64) (string, uint64) {
 
 type PackageBuildInfo struct {
 	ImportPath    string
+	PackageName   string
 	DirectoryPath string
 	Files         map[string]struct{}
 }
@@ -2207,8 +2402,15 @@ func (bi *BinaryInfo) ListPackagesBuildInfo(includeFiles bool) []*PackageBuildIn
 				continue
 			}
 			dp := filepath.Dir(path)
+			pkgName := cu.pkgName
+			if pkgName == "" {
+				// Before Go 1.13 the package name isn't recorded in debug_info,
+				// assume it's the last element of the import path.
+				pkgName = ip[strings.LastIndex(ip, "/")+1:]
+			}
 			m[ip] = &PackageBuildInfo{
 				ImportPath:    ip,
+				PackageName:   pkgName,
 				DirectoryPath: dp,
 				Files:         make(map[string]struct{}),
 			}
@@ -8,6 +8,7 @@ import (
 	"go/parser"
 	"go/token"
 	"reflect"
+	"time"
 )
 
 const (
@@ -18,8 +19,12 @@ const (
 	// process dies because of a fatal runtime error.
 	FatalThrow = "runtime-fatal-throw"
 
+	// NextPanic is the name given to the breakpoint set by BreakOnNextPanic.
+	NextPanic = "next-panic"
+
 	unrecoveredPanicID = -1
 	fatalThrowID       = -2
+	nextPanicID        = -3
 )
 
 // Breakpoint represents a physical breakpoint. Stores information on the break
@@ -31,6 +36,10 @@ type Breakpoint struct {
 	File         string
 	Line         int
 
+	// OnReturn is true if the breakpoint was set on the return locations of
+	// FunctionName rather than on a line.
+	OnReturn bool
+
 	Addr         uint64 // Address breakpoint is set for.
 	OriginalData []byte // If software breakpoint, the data we replace with breakpoint instruction.
 	Name         string // User defined name of the breakpoint
@@ -53,10 +62,16 @@ type Breakpoint struct {
 	Goroutine     bool     // Retrieve goroutine information
 	Stacktrace    int      // Number of stack frames to retrieve
 	Variables     []string // Variables to evaluate
+	TraceMessage  string   // Message to format when the breakpoint is hit
 	LoadArgs      *LoadConfig
 	LoadLocals    *LoadConfig
 	HitCount      map[int]uint64 // Number of times a breakpoint has been reached in a certain goroutine
 	TotalHitCount uint64         // Number of times a breakpoint has been reached
+	LastHitTime   time.Time      // Last time the breakpoint was reached
+
+	// DumpGoroutinesOnce requests a dump of all goroutines the first time
+	// the breakpoint is hit.
+	DumpGoroutinesOnce bool
 
 	// DeferReturns: when kind == NextDeferBreakpoint this breakpoint
 	// will also check if the caller is runtime.gopanic or if the return
@@ -78,12 +93,59 @@ type Breakpoint struct {
 		Op  token.Token
 		Val int
 	}
+	// SampleRate: if greater than 1 the breakpoint will be triggered only
+	// once every SampleRate hits (counted after evaluating Cond and HitCond).
+	SampleRate int
+	// Temporary: if true the breakpoint should be cleared the first time it
+	// is triggered.
+	Temporary bool
+	// Assert: if not nil the breakpoint will be triggered only if evaluating
+	// Assert does not return true, i.e. when the invariant it describes does
+	// not hold (evaluated after Cond, HitCond and SampleRate).
+	Assert ast.Expr
+	// MinHitInterval: if greater than zero the breakpoint will not be
+	// triggered again until MinHitInterval has passed since the last time it
+	// was triggered (checked after all other conditions).
+	MinHitInterval time.Duration
+	// FirstPerGoroutine: if true the breakpoint will be triggered only the
+	// first time each goroutine hits it (evaluated after Cond, HitCond and
+	// SampleRate).
+	FirstPerGoroutine bool
+	// triggeredGoroutines contains the IDs of the goroutines that triggered
+	// the breakpoint, used for FirstPerGoroutine.
+	triggeredGoroutines map[int]bool
+	// logical is the state shared by all the physical breakpoints of the
+	// same logical breakpoint, it is nil if this is not a user breakpoint.
+	logical *logicalBreakpointState
+	// UserData is opaque data attached to the breakpoint by the client, it
+	// is not interpreted by the debugger.
+	UserData []byte
+	// panicGoroutines contains the IDs of the goroutines armed by
+	// BreakOnNextPanic, when kind includes NextPanicBreakpoint. The
+	// breakpoint is triggered the next time one of them reaches it,
+	// regardless of its conditions.
+	panicGoroutines map[int]bool
 
 	// ReturnInfo describes how to collect return variables when this
 	// breakpoint is hit as a return breakpoint.
 	returnInfo *returnBreakpointInfo
 }
 
+// logicalBreakpointState is the state of a logical breakpoint that must be
+// shared by all its physical breakpoints, so that a breakpoint set on
+// several addresses behaves as a single breakpoint.
+type logicalBreakpointState struct {
+	// sampledHitCount is the number of hits considered for SampleRate.
+	sampledHitCount uint64
+	// lastTriggered is the last time the breakpoint was triggered, used for
+	// MinHitInterval.
+	lastTriggered time.Time
+	// changedValues contains the values observed by the changed builtin the
+	// last time the conditions of the breakpoint were evaluated, indexed by
+	// the argument expression of the call to changed.
+	changedValues map[string]*Variable
+}
+
 // BreakpointKind determines the behavior of delve when the
 // breakpoint is reached.
 type BreakpointKind uint16
@@ -103,8 +165,23 @@ const (
 	// Continue will set a new breakpoint (of NextBreakpoint kind) on the
 	// destination of CALL, delete this breakpoint and then continue again
 	StepBreakpoint
+	// NextPanicBreakpoint is a breakpoint set by BreakOnNextPanic on
+	// runtime.gopanic, unlike the other internal breakpoints it is not
+	// cleared when the target stops, only once all the goroutines armed on
+	// it have panicked.
+	NextPanicBreakpoint
+	// DebuggerBreakpoint is a breakpoint set by the debugger for its own
+	// use, for example to follow goroutine switches while replaying a
+	// recording. Like user breakpoints it stops the target and it is not
+	// cleared when the target stops, but it is not reported as a user
+	// breakpoint and must be removed with ClearBreakpointKind.
+	DebuggerBreakpoint
 )
 
+// transientBreakpointKinds are the kinds of internal breakpoints that are
+// cleared every time the target stops.
+const transientBreakpointKinds = NextBreakpoint | NextDeferBreakpoint | StepBreakpoint
+
 // WatchType is the watchpoint type
 type WatchType uint8
 
@@ -178,12 +255,27 @@ func (bp *Breakpoint) CheckCondition(thread Thread) BreakpointState {
 			bpstate.HitCount[g.ID]++
 		}
 		bpstate.TotalHitCount++
+		bpstate.LastHitTime = time.Now()
 	}
 	bpstate.checkHitCond(thread)
+	bpstate.checkSampleRate()
+	bpstate.checkFirstPerGoroutine(thread)
+	bpstate.checkAssert(thread)
+	bpstate.checkMinHitInterval()
+	bpstate.recordFirstPerGoroutine(thread)
 	return bpstate
 }
 
 func (bpstate *BreakpointState) checkCond(thread Thread) {
+	if bpstate.Kind&NextPanicBreakpoint != 0 {
+		if g, _ := GetG(thread); g != nil && bpstate.panicGoroutines[g.ID] {
+			bpstate.Active = true
+			return
+		}
+		if bpstate.Kind == NextPanicBreakpoint {
+			return
+		}
+	}
 	if bpstate.Cond == nil && bpstate.internalCond == nil {
 		bpstate.Active = true
 		bpstate.Internal = bpstate.IsInternal()
@@ -202,7 +294,7 @@ func (bpstate *BreakpointState) checkCond(thread Thread) {
 	}
 	if bpstate.IsInternal() {
 		// Check internalCondition if this is also an internal breakpoint
-		bpstate.Active, bpstate.CondError = evalBreakpointCondition(thread, bpstate.internalCond)
+		bpstate.Active, bpstate.CondError = evalBreakpointCondition(thread, bpstate.internalCond, bpstate.Breakpoint)
 		bpstate.Active = bpstate.Active && nextDeferOk
 		if bpstate.Active || bpstate.CondError != nil {
 			bpstate.Internal = true
@@ -211,7 +303,7 @@ func (bpstate *BreakpointState) checkCond(thread Thread) {
 	}
 	if bpstate.IsUser() {
 		// Check normal condition if this is also a user breakpoint
-		bpstate.Active, bpstate.CondError = evalBreakpointCondition(thread, bpstate.Cond)
+		bpstate.Active, bpstate.CondError = evalBreakpointCondition(thread, bpstate.Cond, bpstate.Breakpoint)
 	}
 }
 
@@ -239,6 +331,95 @@ func (bpstate *BreakpointState) checkHitCond(thread Thread) {
 	}
 }
 
+// checkSampleRate deactivates bp unless this is one of the hits selected
+// by its sample rate.
+func (bpstate *BreakpointState) checkSampleRate() {
+	if bpstate.SampleRate <= 1 || !bpstate.Active || bpstate.Internal || bpstate.logical == nil {
+		return
+	}
+	bpstate.logical.sampledHitCount++
+	bpstate.Active = bpstate.logical.sampledHitCount%uint64(bpstate.SampleRate) == 0
+}
+
+// checkFirstPerGoroutine deactivates bp if it was already triggered by the
+// goroutine running on thread.
+func (bpstate *BreakpointState) checkFirstPerGoroutine(thread Thread) {
+	if !bpstate.FirstPerGoroutine || !bpstate.Active || bpstate.Internal {
+		return
+	}
+	g, err := GetG(thread)
+	if err != nil || g == nil {
+		return
+	}
+	if bpstate.triggeredGoroutines[g.ID] {
+		bpstate.Active = false
+	}
+}
+
+// recordFirstPerGoroutine records that bp was triggered by the goroutine
+// running on thread, it must be called after all other checks so that hits
+// deactivated by them do not count.
+func (bpstate *BreakpointState) recordFirstPerGoroutine(thread Thread) {
+	if !bpstate.FirstPerGoroutine || !bpstate.Active || bpstate.Internal {
+		return
+	}
+	g, err := GetG(thread)
+	if err != nil || g == nil {
+		return
+	}
+	if bpstate.triggeredGoroutines == nil {
+		bpstate.triggeredGoroutines = make(map[int]bool)
+	}
+	bpstate.triggeredGoroutines[g.ID] = true
+}
+
+// ResetFirstPerGoroutine forgets which goroutines triggered bp, so that
+// every goroutine will trigger it again once if FirstPerGoroutine is set.
+func (bp *Breakpoint) ResetFirstPerGoroutine() {
+	bp.triggeredGoroutines = nil
+}
+
+// ResetLogicalState forgets the hits counted for SampleRate, the last time
+// MinHitInterval was checked and the values recorded by the changed
+// builtin for the logical breakpoint of bp.
+func (bp *Breakpoint) ResetLogicalState() {
+	if bp.logical != nil {
+		*bp.logical = logicalBreakpointState{}
+	}
+}
+
+// checkAssert evaluates bp's assertion on thread, the breakpoint stays
+// active only if the assertion is false or can not be evaluated.
+func (bpstate *BreakpointState) checkAssert(thread Thread) {
+	if bpstate.Assert == nil || !bpstate.Active || bpstate.Internal {
+		return
+	}
+	ok, err := evalBreakpointCondition(thread, bpstate.Assert, bpstate.Breakpoint)
+	switch {
+	case err != nil:
+		bpstate.AssertError = fmt.Errorf("could not check assertion %s: %v", exprToString(bpstate.Assert), err)
+	case !ok:
+		bpstate.AssertError = fmt.Errorf("assertion failed: %s", exprToString(bpstate.Assert))
+	default:
+		bpstate.Active = false
+	}
+}
+
+// checkMinHitInterval deactivates bp if it was last triggered less than
+// MinHitInterval ago.
+func (bpstate *BreakpointState) checkMinHitInterval() {
+	if bpstate.MinHitInterval <= 0 || !bpstate.Active || bpstate.Internal || bpstate.logical == nil {
+		return
+	}
+	now := time.Now()
+	if !bpstate.logical.lastTriggered.IsZero() && now.Sub(bpstate.logical.lastTriggered) < bpstate.MinHitInterval {
+		bpstate.Active = false
+		bpstate.AssertError = nil
+		return
+	}
+	bpstate.logical.lastTriggered = now
+}
+
 func isPanicCall(frames []Stackframe) (bool, int) {
 	// In Go prior to 1.17 the call stack for a panic is:
 	//  0. deferred function call
@@ -275,7 +456,7 @@ func isDeferReturnCall(frames []Stackframe, deferReturns []uint64) (bool, uint64
 // User-set breakpoints can overlap with internal breakpoints, in that case
 // both IsUser and IsInternal will be true.
 func (bp *Breakpoint) IsInternal() bool {
-	return bp.Kind != UserBreakpoint
+	return bp.Kind&transientBreakpointKinds != 0
 }
 
 // IsUser returns true if bp is a user-set breakpoint.
@@ -285,7 +466,10 @@ func (bp *Breakpoint) IsUser() bool {
 	return bp.Kind&UserBreakpoint != 0
 }
 
-func evalBreakpointCondition(thread Thread, cond ast.Expr) (bool, error) {
+// evalBreakpointCondition evaluates cond on thread, bp is the breakpoint
+// the condition belongs to, it is used to store the values observed by the
+// changed builtin and can be nil.
+func evalBreakpointCondition(thread Thread, cond ast.Expr, bp *Breakpoint) (bool, error) {
 	if cond == nil {
 		return true, nil
 	}
@@ -296,6 +480,7 @@ func evalBreakpointCondition(thread Thread, cond ast.Expr) (bool, error) {
 			return true, err
 		}
 	}
+	scope.bp = bp
 	v, err := scope.evalAST(cond)
 	if err != nil {
 		return true, fmt.Errorf("error evaluating expression: %v", err)
@@ -324,6 +509,10 @@ func (nbp NoBreakpointError) Error() string {
 type BreakpointMap struct {
 	M map[uint64]*Breakpoint
 
+	// logical contains the state of each logical breakpoint, indexed by
+	// logical ID.
+	logical map[int]*logicalBreakpointState
+
 	breakpointIDCounter         int
 	internalBreakpointIDCounter int
 }
@@ -331,8 +520,36 @@ type BreakpointMap struct {
 // NewBreakpointMap creates a new BreakpointMap.
 func NewBreakpointMap() BreakpointMap {
 	return BreakpointMap{
-		M: make(map[uint64]*Breakpoint),
+		M:       make(map[uint64]*Breakpoint),
+		logical: make(map[int]*logicalBreakpointState),
+	}
+}
+
+// setLogicalID makes the user breakpoint bp one of the physical
+// breakpoints of the logical breakpoint id.
+func (bpmap *BreakpointMap) setLogicalID(bp *Breakpoint, id int) {
+	bpmap.releaseLogical(bp)
+	bp.LogicalID = id
+	if bpmap.logical[id] == nil {
+		bpmap.logical[id] = &logicalBreakpointState{}
+	}
+	bp.logical = bpmap.logical[id]
+}
+
+// releaseLogical removes bp from its logical breakpoint, the state of the
+// logical breakpoint is deleted when none of its physical breakpoints is
+// left.
+func (bpmap *BreakpointMap) releaseLogical(bp *Breakpoint) {
+	if bp.logical == nil {
+		return
 	}
+	bp.logical = nil
+	for _, other := range bpmap.M {
+		if other.logical != nil && other.LogicalID == bp.LogicalID {
+			return
+		}
+	}
+	delete(bpmap.logical, bp.LogicalID)
 }
 
 // SetBreakpoint sets a breakpoint at addr, and stores it in the process wide
@@ -393,14 +610,19 @@ func (t *Target) setBreakpointInternal(addr uint64, kind BreakpointKind, wtype W
 		// We can overlap one internal breakpoint with one user breakpoint, we
 		// need to support this otherwise a conditional breakpoint can mask a
 		// breakpoint set by next or step.
-		if (kind != UserBreakpoint && bp.Kind != UserBreakpoint) || (kind == UserBreakpoint && bp.IsUser()) {
+		if kind&bp.Kind != 0 || (kind&transientBreakpointKinds != 0 && bp.IsInternal()) {
 			return bp, BreakpointExistsError{bp.File, bp.Line, bp.Addr}
 		}
 		bp.Kind |= kind
-		if kind != UserBreakpoint {
-			bp.internalCond = cond
-		} else {
+		switch kind {
+		case UserBreakpoint:
+			bpmap.breakpointIDCounter++
+			bpmap.setLogicalID(bp, bpmap.breakpointIDCounter)
 			bp.Cond = cond
+		case NextPanicBreakpoint:
+			// armed goroutines are added by BreakOnNextPanic
+		default:
+			bp.internalCond = cond
 		}
 		return bp, nil
 	}
@@ -449,7 +671,7 @@ func (t *Target) setBreakpointInternal(addr uint64, kind BreakpointKind, wtype W
 		newBreakpoint.internalCond = cond
 	} else {
 		bpmap.breakpointIDCounter++
-		newBreakpoint.LogicalID = bpmap.breakpointIDCounter
+		bpmap.setLogicalID(newBreakpoint, bpmap.breakpointIDCounter)
 		newBreakpoint.Cond = cond
 	}
 
@@ -463,7 +685,7 @@ func (t *Target) SetBreakpointWithID(id int, addr uint64) (*Breakpoint, error) {
 	bpmap := t.Breakpoints()
 	bp, err := t.SetBreakpoint(addr, UserBreakpoint, nil)
 	if err == nil {
-		bp.LogicalID = id
+		bpmap.setLogicalID(bp, id)
 		bpmap.breakpointIDCounter--
 	}
 	return bp, err
@@ -482,7 +704,9 @@ func (t *Target) ClearBreakpoint(addr uint64) (*Breakpoint, error) {
 
 	bp.Kind &= ^UserBreakpoint
 	bp.Cond = nil
+	bp.Assert = nil
 	if bp.Kind != 0 {
+		bpmap.releaseLogical(bp)
 		return bp, nil
 	}
 
@@ -491,17 +715,67 @@ func (t *Target) ClearBreakpoint(addr uint64) (*Breakpoint, error) {
 	}
 
 	delete(bpmap.M, addr)
+	bpmap.releaseLogical(bp)
 
 	return bp, nil
 }
 
+// ClearBreakpointKind removes kind from the breakpoint at addr, the
+// breakpoint is deleted if no other kind remains.
+func (t *Target) ClearBreakpointKind(addr uint64, kind BreakpointKind) error {
+	bpmap := t.Breakpoints()
+	bp, ok := bpmap.M[addr]
+	if !ok {
+		return NoBreakpointError{Addr: addr}
+	}
+	bp.Kind &^= kind
+	if bp.Kind != 0 {
+		return nil
+	}
+	if err := t.proc.EraseBreakpoint(bp); err != nil {
+		return err
+	}
+	delete(bpmap.M, addr)
+	return nil
+}
+
+// SuspendBreakpoints removes all breakpoints, except keep, from the target
+// without changing their state and returns a function that puts them back.
+// While breakpoints are suspended they are not hit and their hit counts
+// and conditions are not evaluated.
+func (t *Target) SuspendBreakpoints(keep *Breakpoint) (resume func() error, err error) {
+	bpmap := t.Breakpoints()
+	suspended := []*Breakpoint{}
+	resume = func() error {
+		for _, bp := range suspended {
+			if err := t.proc.WriteBreakpoint(bp); err != nil {
+				return err
+			}
+			bpmap.M[bp.Addr] = bp
+		}
+		return nil
+	}
+	for addr, bp := range bpmap.M {
+		if bp == keep {
+			continue
+		}
+		if err := t.proc.EraseBreakpoint(bp); err != nil {
+			resume()
+			return nil, err
+		}
+		delete(bpmap.M, addr)
+		suspended = append(suspended, bp)
+	}
+	return resume, nil
+}
+
 // ClearInternalBreakpoints removes all internal breakpoints from the map,
 // calling clearBreakpoint on each one.
 func (t *Target) ClearInternalBreakpoints() error {
 	bpmap := t.Breakpoints()
 	threads := t.ThreadList()
 	for addr, bp := range bpmap.M {
-		bp.Kind = bp.Kind & UserBreakpoint
+		bp.Kind = bp.Kind &^ transientBreakpointKinds
 		bp.internalCond = nil
 		bp.returnInfo = nil
 		if bp.Kind != 0 {
@@ -517,6 +791,7 @@ func (t *Target) ClearInternalBreakpoints() error {
 		}
 		delete(bpmap.M, addr)
 	}
+	t.stepExcludedPkgs = nil
 	return nil
 }
 
@@ -552,6 +827,9 @@ type BreakpointState struct {
 	// CondError contains any error encountered while evaluating the
 	// breakpoint's condition.
 	CondError error
+	// AssertError describes why the breakpoint's assertion failed, it is
+	// nil if the breakpoint has no assertion or the assertion held.
+	AssertError error
 }
 
 // Clear zeros the struct.
@@ -560,6 +838,7 @@ func (bpstate *BreakpointState) Clear() {
 	bpstate.Active = false
 	bpstate.Internal = false
 	bpstate.CondError = nil
+	bpstate.AssertError = nil
 }
 
 func (bpstate *BreakpointState) String() string {
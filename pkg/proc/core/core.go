@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"go/constant"
 	"io"
 
 	"github.com/go-delve/delve/pkg/elfwriter"
@@ -157,6 +158,14 @@ type process struct {
 	Threads map[int]*thread
 	pid     int
 
+	// memoryMap is the memory map of the process, nil if the core file
+	// format does not describe it.
+	memoryMap []proc.MemoryMapEntry
+
+	// fatalSignal is the signal that caused the core dump, as recorded by
+	// the kernel.
+	fatalSignal int
+
 	entryPoint uint64
 
 	bi          *proc.BinaryInfo
@@ -221,12 +230,93 @@ func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.Target, e
 		return nil, ErrNoThreads
 	}
 
-	return proc.NewTarget(p, currentThread, proc.NewTargetConfig{
+	t, err := proc.NewTarget(p, currentThread, proc.NewTargetConfig{
 		Path:                exePath,
 		DebugInfoDirs:       debugInfoDirs,
 		DisableAsyncPreempt: false,
 		StopReason:          proc.StopAttached,
 		CanDump:             false})
+	if err != nil {
+		return nil, err
+	}
+
+	t.FatalSignal = p.fatalSignal
+	if th, sig := findCrashingThread(t); th != nil {
+		t.SwitchThread(th.ThreadID())
+		if sig != 0 {
+			t.FatalSignal = sig
+		}
+	}
+	return t, nil
+}
+
+// Linux signal numbers used by findCrashingThread.
+const (
+	_SIGQUIT = 0x3
+)
+
+// crashStackDepth is the maximum depth of the stack traces searched by
+// findCrashingThread.
+const crashStackDepth = 50
+
+// findCrashingThread returns the thread that crashed the Go program that
+// generated the core file and, if it is known, the signal that caused the
+// crash.
+//
+// The kernel writes the thread that received the fatal signal first, and
+// that is normally the crashing thread. However when a Go program crashes
+// with GOTRACEBACK=crash because of a signal the runtime relays SIGQUIT to
+// every other thread, so that they print their stack traces, and the
+// fatal signal is eventually raised by whichever thread prints last.
+// The thread that originally crashed is the one executing
+// runtime.fatalpanic or runtime.fatalthrow or, for signals, the one
+// executing runtime.sighandler for a signal other than SIGQUIT.
+//
+// Returns a nil thread if the core file was not produced by the Go
+// runtime's crash handler.
+func findCrashingThread(t *proc.Target) (proc.Thread, int) {
+	var sigThread proc.Thread
+	var sig int
+	for _, th := range t.ThreadList() {
+		frames, err := proc.ThreadStacktrace(th, crashStackDepth)
+		if err != nil {
+			continue
+		}
+		for i := range frames {
+			fn := frames[i].Current.Fn
+			if fn == nil {
+				continue
+			}
+			switch fn.Name {
+			case "runtime.fatalpanic", "runtime.fatalthrow":
+				return th, 0
+			case "runtime.sighandler":
+				if sigThread != nil {
+					continue
+				}
+				if n := sighandlerSignal(t, th, frames[i:]); n != 0 && n != _SIGQUIT {
+					sigThread, sig = th, n
+				}
+			}
+		}
+	}
+	return sigThread, sig
+}
+
+// sighandlerSignal returns the signal handled by the runtime.sighandler
+// frame at frames[0], or 0 if it can not be read.
+func sighandlerSignal(t *proc.Target, th proc.Thread, frames []proc.Stackframe) int {
+	g, _ := proc.GetG(th)
+	scope := proc.FrameToScope(t, t.BinInfo(), th.ProcessMemory(), g, frames...)
+	v, err := scope.EvalExpression("sig", proc.LoadConfig{})
+	if err != nil || v.Unreadable != nil || v.Value == nil {
+		return 0
+	}
+	n, ok := constant.Int64Val(v.Value)
+	if !ok {
+		return 0
+	}
+	return int(n)
 }
 
 // BinInfo will return the binary info.
@@ -465,9 +555,32 @@ func (p *process) FindThread(threadID int) (proc.Thread, bool) {
 }
 
 func (p *process) MemoryMap() ([]proc.MemoryMapEntry, error) {
-	return nil, proc.ErrMemoryMapNotSupported
+	if p.memoryMap == nil {
+		return nil, proc.ErrMemoryMapNotSupported
+	}
+	return p.memoryMap, nil
 }
 
 func (p *process) DumpProcessNotes(notes []elfwriter.Note, threadDone func()) (threadsDone bool, out []elfwriter.Note, err error) {
 	return false, notes, nil
 }
+
+// SetHeldThreads is not supported, core files can not be resumed.
+func (p *process) SetHeldThreads(tids []int) bool {
+	return false
+}
+
+// SetStopSignals is not supported, core files can not be resumed.
+func (p *process) SetStopSignals(sigs []int) bool {
+	return false
+}
+
+// PendingSignal is not supported, core files can not be resumed.
+func (p *process) PendingSignal(tid int) (int, string) {
+	return 0, ""
+}
+
+// SetPendingSignal is not supported, core files can not be resumed.
+func (p *process) SetPendingSignal(tid, sig int) bool {
+	return false
+}
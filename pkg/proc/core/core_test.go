@@ -312,6 +312,70 @@ func TestCore(t *testing.T) {
 	logRegisters(t, regs, p.BinInfo().Arch)
 }
 
+func TestCoreCrashingThread(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	if runtime.GOOS == "linux" && os.Getenv("CI") == "true" && buildMode == "pie" {
+		t.Skip("disabled on linux, Github Actions, with PIE buildmode")
+	}
+	p := withCoreFile(t, "panic", "")
+
+	const sigabrt = 0x6
+	if p.FatalSignal != sigabrt {
+		t.Errorf("wrong fatal signal %d, expected %d", p.FatalSignal, sigabrt)
+	}
+
+	g := p.SelectedGoroutine()
+	if g == nil {
+		t.Fatalf("no goroutine selected")
+	}
+	stack, err := g.Stacktrace(20, 0)
+	assertNoError(err, t, "Stacktrace()")
+	found := false
+	for _, frame := range stack {
+		if frame.Current.Fn != nil && frame.Current.Fn.Name == "main.main" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("selected goroutine %d is not the panicking goroutine", g.ID)
+	}
+}
+
+func TestCoreMemoryMap(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	if runtime.GOOS == "linux" && os.Getenv("CI") == "true" && buildMode == "pie" {
+		t.Skip("disabled on linux, Github Actions, with PIE buildmode")
+	}
+	p := withCoreFile(t, "panic", "")
+
+	mmap, err := p.MemoryMap()
+	assertNoError(err, t, "MemoryMap()")
+	if len(mmap) == 0 {
+		t.Fatal("empty memory map")
+	}
+
+	// The entry point of the executable must be in an executable mapping of
+	// the executable file.
+	entry := p.BinInfo().Images[0]
+	pc := p.BinInfo().LookupFunc["main.main"].Entry
+	found := false
+	for _, mme := range mmap {
+		if pc >= mme.Addr && pc < mme.Addr+mme.Size {
+			found = true
+			if !mme.Exec || mme.Filename != entry.Path {
+				t.Errorf("wrong mapping for main.main: %#v", mme)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("could not find mapping for main.main (%#x)", pc)
+	}
+}
+
 func TestCoreFpRegisters(t *testing.T) {
 	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
 		return
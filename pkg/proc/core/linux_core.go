@@ -57,6 +57,7 @@ func linuxThreadsFromNotes(p *process, notes []*note, machineType elf.Machine) p
 				p.Threads[int(t.Pid)] = &thread{lastThreadAMD, p, proc.CommonThread{}}
 				if currentThread == nil {
 					currentThread = p.Threads[int(t.Pid)]
+					p.fatalSignal = int(t.Cursig)
 				}
 			} else if machineType == _EM_AARCH64 {
 				t := note.Desc.(*linuxPrStatusARM64)
@@ -64,6 +65,7 @@ func linuxThreadsFromNotes(p *process, notes []*note, machineType elf.Machine) p
 				p.Threads[int(t.Pid)] = &thread{lastThreadARM, p, proc.CommonThread{}}
 				if currentThread == nil {
 					currentThread = p.Threads[int(t.Pid)]
+					p.fatalSignal = int(t.Cursig)
 				}
 			}
 		case _NT_FPREGSET:
@@ -131,6 +133,7 @@ func readLinuxOrPlatformIndependentCore(corePath, exePath string) (*process, pro
 	}
 
 	memory := buildMemory(coreFile, exeELF, exe, notes)
+	memoryMap := buildMemoryMap(coreFile, notes)
 
 	// TODO support 386
 	var bi *proc.BinaryInfo
@@ -155,6 +158,7 @@ func readLinuxOrPlatformIndependentCore(corePath, exePath string) (*process, pro
 
 	p := &process{
 		mem:         memory,
+		memoryMap:   memoryMap,
 		Threads:     map[int]*thread{},
 		entryPoint:  entryPoint,
 		bi:          bi,
@@ -299,7 +303,7 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 		// No good documentation reference, but the structure is
 		// simply a header, including entry count, followed by that
 		// many entries, and then the file name of each entry,
-		// null-delimited. Not reading the names here.
+		// null-delimited.
 		data := &linuxNTFile{}
 		if err := binary.Read(descReader, binary.LittleEndian, &data.linuxNTFileHdr); err != nil {
 			return nil, fmt.Errorf("reading NT_FILE header: %v", err)
@@ -311,6 +315,10 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 			}
 			data.entries = append(data.entries, entry)
 		}
+		names := strings.Split(string(desc[len(desc)-descReader.Len():]), "\x00")
+		if len(names) >= len(data.entries) {
+			data.filenames = names[:len(data.entries)]
+		}
 		note.Desc = data
 	case _NT_X86_XSTATE:
 		if machineType == _EM_X86_64 {
@@ -393,6 +401,42 @@ func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) proc.Me
 	return memory
 }
 
+// buildMemoryMap returns the memory map of the process from the PT_LOAD
+// segments of the core file, file names are taken from the NT_FILE note.
+func buildMemoryMap(core *elf.File, notes []*note) []proc.MemoryMapEntry {
+	var fileNote *linuxNTFile
+	for _, note := range notes {
+		if note.Type == _NT_FILE {
+			fileNote = note.Desc.(*linuxNTFile)
+		}
+	}
+
+	r := []proc.MemoryMapEntry{}
+	for _, prog := range core.Progs {
+		if prog.Type != elf.PT_LOAD {
+			continue
+		}
+		mme := proc.MemoryMapEntry{
+			Addr:  prog.Vaddr,
+			Size:  prog.Memsz,
+			Read:  prog.Flags&elf.PF_R != 0,
+			Write: prog.Flags&elf.PF_W != 0,
+			Exec:  prog.Flags&elf.PF_X != 0,
+		}
+		if fileNote != nil && fileNote.filenames != nil {
+			for i, entry := range fileNote.entries {
+				if prog.Vaddr >= entry.Start && prog.Vaddr < entry.End {
+					mme.Filename = fileNote.filenames[i]
+					mme.Offset = entry.FileOfs*fileNote.PageSize + (prog.Vaddr - entry.Start)
+					break
+				}
+			}
+		}
+		r = append(r, mme)
+	}
+	return r
+}
+
 func findEntryPoint(notes []*note, ptrSize int) uint64 {
 	for _, note := range notes {
 		if note.Type == _NT_AUXV {
@@ -456,7 +500,8 @@ type linuxSiginfo struct {
 // LinuxNTFile contains information on mapped files.
 type linuxNTFile struct {
 	linuxNTFileHdr
-	entries []*linuxNTFileEntry
+	entries   []*linuxNTFileEntry
+	filenames []string // file name of each entry, nil if they could not be read
 }
 
 // LinuxNTFileHdr is a header struct for NTFile.
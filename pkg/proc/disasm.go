@@ -14,6 +14,11 @@ type AsmInstruction struct {
 	Breakpoint bool
 	AtPC       bool
 
+	// BranchTarget is the destination of a direct (conditional or
+	// unconditional) jump instruction, if it is the address of another
+	// instruction of the disassembled range, 0 otherwise.
+	BranchTarget uint64
+
 	Size int
 	Kind AsmInstructionKind
 
@@ -173,6 +178,17 @@ func disassemble(memrw MemoryReadWriter, regs Registers, breakpoints *Breakpoint
 			break
 		}
 	}
+
+	// Only keep branch targets that are the start of an instruction in the range.
+	instPCs := make(map[uint64]bool, len(r))
+	for i := range r {
+		instPCs[r[i].Loc.PC] = true
+	}
+	for i := range r {
+		if r[i].BranchTarget != 0 && !instPCs[r[i].BranchTarget] {
+			r[i].BranchTarget = 0
+		}
+	}
 	return r, nil
 }
 
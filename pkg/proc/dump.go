@@ -50,6 +50,12 @@ type MemoryMapEntry struct {
 	Offset   uint64
 }
 
+// MemoryMap returns the memory map of the target process, returns
+// ErrMemoryMapNotSupported if the backend can not read it.
+func (t *Target) MemoryMap() ([]MemoryMapEntry, error) {
+	return t.proc.MemoryMap()
+}
+
 func (state *DumpState) setErr(err error) {
 	if err == nil {
 		return
@@ -54,6 +54,9 @@ type EvalScope struct {
 	// will have one assigned by looking at their position in the argument
 	// list.
 	trustArgOrder bool
+
+	// bp is the breakpoint whose condition is being evaluated, if any.
+	bp *Breakpoint
 }
 
 // ConvertEvalScope returns a new EvalScope in the context of the
@@ -163,6 +166,7 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 		// makes sure that the other goroutine won't wait forever if we make a mistake
 		defer close(scope.callCtx.continueRequest)
 	}
+	expr = rewriteFieldProjections(rewritePseudoVariables(expr))
 	t, err := parser.ParseExpr(expr)
 	if eqOff, isAs := isAssignment(err); scope.callCtx != nil && isAs {
 		lexpr := expr[:eqOff]
@@ -171,14 +175,23 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 		scope.callCtx.doReturn(nil, err)
 		return nil, err
 	}
+	var commaOk bool
+	if ta, isCommaOk := isCommaOk(expr, err); isCommaOk {
+		t, err, commaOk = ta, nil, true
+	}
 	if err != nil {
 		scope.callCtx.doReturn(nil, err)
 		return nil, err
 	}
 
-	ev, err := scope.evalToplevelTypeCast(t, cfg)
-	if ev == nil && err == nil {
-		ev, err = scope.evalAST(t)
+	var ev *Variable
+	if commaOk {
+		ev, err = scope.evalTypeAssertCommaOk(t.(*ast.TypeAssertExpr), cfg)
+	} else {
+		ev, err = scope.evalToplevelTypeCast(t, cfg)
+		if ev == nil && err == nil {
+			ev, err = scope.evalAST(t)
+		}
 	}
 	if err != nil {
 		scope.callCtx.doReturn(nil, err)
@@ -200,6 +213,27 @@ func isAssignment(err error) (int, bool) {
 	return 0, false
 }
 
+// isCommaOk returns the type assertion expression if expr, which failed
+// to parse with err, is the comma-ok form of a type assertion:
+//
+//	x.(T), ok
+func isCommaOk(expr string, err error) (*ast.TypeAssertExpr, bool) {
+	el, isScannerErr := err.(scanner.ErrorList)
+	if !isScannerErr || el[0].Msg != "expected 'EOF', found ','" {
+		return nil, false
+	}
+	off := el[0].Pos.Offset
+	if strings.TrimSpace(expr[off+1:]) != "ok" {
+		return nil, false
+	}
+	t, err := parser.ParseExpr(expr[:off])
+	if err != nil {
+		return nil, false
+	}
+	ta, isTypeAssert := t.(*ast.TypeAssertExpr)
+	return ta, isTypeAssert
+}
+
 // Locals returns all variables in 'scope'.
 func (scope *EvalScope) Locals() ([]*Variable, error) {
 	if scope.Fn == nil {
@@ -381,7 +415,7 @@ func (scope *EvalScope) EvalVariable(name string, cfg LoadConfig) (*Variable, er
 
 // SetVariable sets the value of the named variable
 func (scope *EvalScope) SetVariable(name, value string) error {
-	t, err := parser.ParseExpr(name)
+	t, err := parser.ParseExpr(rewritePseudoVariables(name))
 	if err != nil {
 		return err
 	}
@@ -399,11 +433,15 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 		return fmt.Errorf("Expression \"%s\" is unreadable: %v", name, xv.Unreadable)
 	}
 
-	t, err = parser.ParseExpr(value)
+	t, err = parser.ParseExpr(rewritePseudoVariables(value))
 	if err != nil {
 		return err
 	}
 
+	if lit, isLit := t.(*ast.CompositeLit); isLit {
+		return scope.setCompositeLit(xv, lit, value)
+	}
+
 	yv, err := scope.evalAST(t)
 	if err != nil {
 		return err
@@ -412,6 +450,204 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 	return scope.setValue(xv, yv, value)
 }
 
+// setCompositeLit writes the value of the composite literal lit to dstv.
+// Only structs, arrays and slices with enough capacity to hold all the
+// elements of the literal are supported, since no memory is allocated in
+// the target process.
+// Fields and elements not mentioned in the literal are zeroed. All
+// elements of the literal are evaluated before anything is written so that
+// an error will leave dstv unchanged.
+func (scope *EvalScope) setCompositeLit(dstv *Variable, lit *ast.CompositeLit, srcExpr string) error {
+	var writes []func() error
+	writes, err := scope.compositeLitWrites(dstv, lit, srcExpr, writes)
+	if err != nil {
+		return err
+	}
+	if dstv.Kind != reflect.Slice {
+		if err := dstv.writeZero(); err != nil {
+			return err
+		}
+	}
+	for _, write := range writes {
+		if err := write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compositeLitWrites appends to writes the list of memory writes needed to
+// assign lit to dstv.
+func (scope *EvalScope) compositeLitWrites(dstv *Variable, lit *ast.CompositeLit, srcExpr string, writes []func() error) ([]func() error, error) {
+	switch dstv.RealType.(type) {
+	case *godwarf.StructType, *godwarf.ArrayType, *godwarf.SliceType:
+		// ok
+	default:
+		return nil, fmt.Errorf("can not assign a composite literal to a variable of type %s", dstv.DwarfType.String())
+	}
+
+	if lit.Type != nil {
+		typ, err := scope.BinInfo.findTypeExpr(lit.Type)
+		if err != nil {
+			return nil, err
+		}
+		if typ.String() != dstv.DwarfType.String() {
+			return nil, fmt.Errorf("can not use %s literal as a %s value", typ.String(), dstv.DwarfType.String())
+		}
+	}
+
+	// elemWrites appends the writes needed to assign expr to elemv.
+	elemWrites := func(elemv *Variable, expr ast.Expr) error {
+		if sublit, isLit := expr.(*ast.CompositeLit); isLit {
+			var err error
+			writes, err = scope.compositeLitWrites(elemv, sublit, srcExpr, writes)
+			return err
+		}
+		srcv, err := scope.evalAST(expr)
+		if err != nil {
+			return err
+		}
+		srcv.loadValue(loadSingleValue)
+		if err := srcv.isType(elemv.RealType, elemv.Kind); err != nil {
+			return err
+		}
+		writes = append(writes, func() error { return scope.setValue(elemv, srcv, srcExpr) })
+		return nil
+	}
+
+	switch typ := dstv.RealType.(type) {
+	case *godwarf.StructType:
+		fields, exprs, err := structLitFields(typ, dstv.DwarfType.String(), lit)
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range fields {
+			fieldv, err := dstv.toField(typ.Field[i])
+			if err != nil {
+				return nil, err
+			}
+			if err := elemWrites(fieldv, exprs[k]); err != nil {
+				return nil, err
+			}
+		}
+		return writes, nil
+
+	default: // *godwarf.ArrayType or *godwarf.SliceType
+		n := int64(0)
+		idx := int64(0)
+		var idxs []int64
+		var exprs []ast.Expr
+		for _, elt := range lit.Elts {
+			expr := elt
+			if kv, keyed := elt.(*ast.KeyValueExpr); keyed {
+				keyv, err := scope.evalAST(kv.Key)
+				if err != nil {
+					return nil, err
+				}
+				if keyv.Kind != reflect.Int || keyv.Value == nil {
+					return nil, fmt.Errorf("index %s must be a constant integer", exprToString(kv.Key))
+				}
+				idx, _ = constant.Int64Val(keyv.Value)
+				expr = kv.Value
+			}
+			if idx < 0 {
+				return nil, fmt.Errorf("index %d out of bounds", idx)
+			}
+			idxs = append(idxs, idx)
+			exprs = append(exprs, expr)
+			idx++
+			if idx > n {
+				n = idx
+			}
+		}
+		limit := dstv.Len
+		if dstv.Kind == reflect.Slice {
+			limit = dstv.Cap
+		}
+		if n > limit {
+			if dstv.Kind == reflect.Slice {
+				return nil, fmt.Errorf("slice literal has %d elements but the capacity of %s is only %d", n, dstv.Name, limit)
+			}
+			return nil, fmt.Errorf("array index %d out of bounds [0:%d]", n-1, limit)
+		}
+		if dstv.Kind == reflect.Slice {
+			// temporarily extend the slice to the length of the literal so that
+			// its elements can be accessed, the header is written last.
+			slicev := *dstv
+			slicev.Len = n
+			slicev.loaded = false
+			dstv = &slicev
+			for i := int64(0); i < n; i++ {
+				elemv, err := dstv.sliceAccess(int(i))
+				if err != nil {
+					return nil, err
+				}
+				writes = append(writes, elemv.writeZero)
+			}
+		}
+		for i := range idxs {
+			elemv, err := dstv.sliceAccess(int(idxs[i]))
+			if err != nil {
+				return nil, err
+			}
+			if err := elemWrites(elemv, exprs[i]); err != nil {
+				return nil, err
+			}
+		}
+		if dstv.Kind == reflect.Slice {
+			writes = append(writes, func() error { return dstv.writeSlice(n, dstv.Cap, dstv.Base) })
+		}
+		return writes, nil
+	}
+}
+
+// structLitFields matches the elements of lit, a literal of the struct
+// type typ, to the fields of typ. It returns the indexes of the fields
+// mentioned in lit and the expressions assigned to them.
+func structLitFields(typ *godwarf.StructType, typeName string, lit *ast.CompositeLit) ([]int, []ast.Expr, error) {
+	if len(lit.Elts) == 0 {
+		return nil, nil, nil
+	}
+	fields := make([]int, 0, len(lit.Elts))
+	exprs := make([]ast.Expr, 0, len(lit.Elts))
+	if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); !keyed {
+		if len(lit.Elts) != len(typ.Field) {
+			return nil, nil, fmt.Errorf("wrong number of values in %s literal: %d (expected %d)", typeName, len(lit.Elts), len(typ.Field))
+		}
+		for i, elt := range lit.Elts {
+			if _, keyed := elt.(*ast.KeyValueExpr); keyed {
+				return nil, nil, errors.New("mixture of field:value and value elements in struct literal")
+			}
+			fields = append(fields, i)
+			exprs = append(exprs, elt)
+		}
+		return fields, exprs, nil
+	}
+	for _, elt := range lit.Elts {
+		kv, keyed := elt.(*ast.KeyValueExpr)
+		if !keyed {
+			return nil, nil, errors.New("mixture of field:value and value elements in struct literal")
+		}
+		key, isIdent := kv.Key.(*ast.Ident)
+		if !isIdent {
+			return nil, nil, fmt.Errorf("invalid field name %s in struct literal", exprToString(kv.Key))
+		}
+		found := false
+		for i, f := range typ.Field {
+			if f.Name == key.Name {
+				fields = append(fields, i)
+				exprs = append(exprs, kv.Value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("unknown field %s in struct literal of type %s", key.Name, typeName)
+		}
+	}
+	return fields, exprs, nil
+}
+
 // LocalVariables returns all local variables from the current function scope.
 func (scope *EvalScope) LocalVariables(cfg LoadConfig) ([]*Variable, error) {
 	vars, err := scope.Locals()
@@ -489,6 +725,30 @@ func (scope *EvalScope) PackageVariables(cfg LoadConfig) ([]*Variable, error) {
 	return vars, nil
 }
 
+// hasLocal returns true if name is the name of a local variable visible
+// in scope. Unlike Locals it only looks at the names of the variables in
+// the debug info, without reading their types or values.
+func (scope *EvalScope) hasLocal(name string) bool {
+	if scope.Fn == nil {
+		return false
+	}
+	dwarfTree, err := scope.image().getDwarfTree(scope.Fn.offset)
+	if err != nil {
+		return false
+	}
+	variablesFlags := reader.VariablesOnlyVisible
+	if scope.BinInfo.Producer() != "" && goversion.ProducerAfterOrEqual(scope.BinInfo.Producer(), 1, 15) {
+		variablesFlags |= reader.VariablesTrustDeclLine
+	}
+	for _, entry := range reader.Variables(dwarfTree, scope.PC, scope.Line, variablesFlags) {
+		n, _ := entry.Val(dwarf.AttrName).(string)
+		if strings.TrimPrefix(n, "&") == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (scope *EvalScope) findGlobal(pkgName, varName string) (*Variable, error) {
 	for _, pkgPath := range scope.BinInfo.PackageMap[pkgName] {
 		v, err := scope.findGlobalInternal(pkgPath + "." + varName)
@@ -500,6 +760,9 @@ func (scope *EvalScope) findGlobal(pkgName, varName string) (*Variable, error) {
 	if err != nil || v != nil {
 		return v, err
 	}
+	if len(scope.BinInfo.PackageMap[pkgName]) > 0 {
+		return nil, fmt.Errorf("package %s has no function, variable or constant named %s", pkgName, varName)
+	}
 	return nil, fmt.Errorf("could not find symbol value for %s.%s", pkgName, varName)
 }
 
@@ -553,6 +816,62 @@ func (scope *EvalScope) findGlobalInternal(name string) (*Variable, error) {
 	return nil, nil
 }
 
+// VisibleNames returns the sorted list of names that can be used as
+// identifiers in expressions evaluated in scope: the local variables and
+// arguments of the current function (including variables captured by
+// closures), the package level variables, functions and constants of the
+// package of the current function and the names of all packages.
+func (scope *EvalScope) VisibleNames() ([]string, error) {
+	names := make(map[string]struct{})
+
+	if scope.Fn != nil {
+		vars, err := scope.Locals()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars {
+			if v.Name != "" && v.Flags&VariableShadowed == 0 {
+				names[v.Name] = struct{}{}
+			}
+		}
+
+		prefix := scope.Fn.PackageName() + "."
+		addGlobal := func(fullName string) {
+			if !strings.HasPrefix(fullName, prefix) {
+				return
+			}
+			name := fullName[len(prefix):]
+			// skip methods, closures and instantiations of generic functions
+			if name == "" || strings.ContainsAny(name, ".()[]") {
+				return
+			}
+			names[name] = struct{}{}
+		}
+		for _, pkgvar := range scope.BinInfo.packageVars {
+			addGlobal(pkgvar.name)
+		}
+		for _, fn := range scope.BinInfo.Functions {
+			addGlobal(fn.Name)
+		}
+		for _, ctyp := range scope.BinInfo.consts {
+			for _, cval := range ctyp.values {
+				addGlobal(cval.fullName)
+			}
+		}
+	}
+
+	for pkgName := range scope.BinInfo.PackageMap {
+		names[pkgName] = struct{}{}
+	}
+
+	r := make([]string, 0, len(names))
+	for name := range names {
+		r = append(r, name)
+	}
+	sort.Strings(r)
+	return r, nil
+}
+
 // image returns the image containing the current function.
 func (scope *EvalScope) image() *Image {
 	return scope.BinInfo.funcToImage(scope.Fn)
@@ -715,6 +1034,9 @@ func (scope *EvalScope) evalAST(t ast.Expr) (*Variable, error) {
 		return scope.evalAST(node.X)
 
 	case *ast.SelectorExpr: // <expression>.<identifier>
+		if strings.HasPrefix(node.Sel.Name, fieldProjectionPrefix) {
+			return scope.evalFieldProjection(node)
+		}
 		// try to interpret the selector as a package variable
 		if maybePkg, ok := node.X.(*ast.Ident); ok {
 			if maybePkg.Name == "runtime" && node.Sel.Name == "curg" {
@@ -733,8 +1055,21 @@ func (scope *EvalScope) evalAST(t ast.Expr) (*Variable, error) {
 				return scope.g.variable.clone(), nil
 			} else if maybePkg.Name == "runtime" && node.Sel.Name == "frameoff" {
 				return newConstant(constant.MakeInt64(scope.frameOffset), scope.Mem), nil
-			} else if v, err := scope.findGlobal(maybePkg.Name, node.Sel.Name); err == nil {
-				return v, nil
+			} else if len(scope.BinInfo.PackageMap[maybePkg.Name]) == 0 || !scope.hasLocal(maybePkg.Name) {
+				// local variables shadow package names, as they do in Go
+				v, err := scope.findGlobal(maybePkg.Name, node.Sel.Name)
+				if err == nil {
+					return v, nil
+				}
+				if len(scope.BinInfo.PackageMap[maybePkg.Name]) > 0 {
+					// maybePkg is a package name, if it isn't also the name of a
+					// variable the error returned by findGlobal is more useful than
+					// the one returned by evalStructSelector.
+					if v, err2 := scope.evalStructSelector(node); err2 == nil {
+						return v, nil
+					}
+					return nil, err
+				}
 			}
 		}
 		// try to accept "package/path".varname syntax for package variables
@@ -955,11 +1290,70 @@ func (scope *EvalScope) evalBuiltinCall(node *ast.CallExpr) (*Variable, error) {
 		return callBuiltinWithArgs(imagBuiltin)
 	case "real":
 		return callBuiltinWithArgs(realBuiltin)
+	case pseudoVarPrefix + "hmap":
+		return callBuiltinWithArgs(hmapBuiltin)
+	case "changed":
+		return scope.changedBuiltin(node)
 	}
 
 	return nil, nil
 }
 
+// changedBuiltin implements the changed builtin, which can only be used in
+// breakpoint conditions. It returns true if the value of its argument is
+// different from the value it had the last time the conditions of the
+// breakpoint were evaluated, false the first time it is evaluated.
+// The value is loaded with loadFullValue and kept until the next
+// evaluation, for large expressions this has a memory cost.
+func (scope *EvalScope) changedBuiltin(node *ast.CallExpr) (*Variable, error) {
+	if len(node.Args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to changed: %d", len(node.Args))
+	}
+	if scope.bp == nil || scope.bp.logical == nil {
+		return nil, errors.New("changed can only be used in breakpoint conditions")
+	}
+	v, err := scope.evalAST(node.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	v.loadValue(loadFullValue)
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+
+	key := exprToString(node.Args[0])
+	logical := scope.bp.logical
+	prev, ok := logical.changedValues[key]
+	if logical.changedValues == nil {
+		logical.changedValues = make(map[string]*Variable)
+	}
+	logical.changedValues[key] = v
+	return newConstant(constant.MakeBool(ok && !variablesEqual(prev, v)), scope.Mem), nil
+}
+
+// variablesEqual returns true if the loaded values of a and b are the same.
+// Pointers are equal if they point to the same address.
+func variablesEqual(a, b *Variable) bool {
+	if a.Kind != b.Kind || a.Len != b.Len || a.Cap != b.Cap || len(a.Children) != len(b.Children) {
+		return false
+	}
+	if (a.RealType == nil) != (b.RealType == nil) || (a.RealType != nil && a.RealType.String() != b.RealType.String()) {
+		return false
+	}
+	if (a.Value == nil) != (b.Value == nil) || (a.Value != nil && !constant.Compare(a.Value, token.EQL, b.Value)) {
+		return false
+	}
+	for i := range a.Children {
+		if (a.Kind == reflect.Ptr || a.Kind == reflect.UnsafePointer) && a.Children[i].Addr != b.Children[i].Addr {
+			return false
+		}
+		if !variablesEqual(&a.Children[i], &b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func capBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("wrong number of arguments to cap: %d", len(args))
@@ -1122,6 +1516,200 @@ func realBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	return newConstant(constant.Real(arg.Value), arg.mem), nil
 }
 
+// hmapBuiltin implements $hmap(m), it returns the runtime header of map m
+// with the buckets and oldbuckets fields changed from pointers to the
+// first bucket into pointers to arrays of buckets, so that the buckets can
+// be indexed. Overflow buckets are reachable through the overflow field of
+// each bucket.
+func hmapBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to $hmap: %d", len(args))
+	}
+
+	arg := args[0]
+	if arg.Unreadable != nil {
+		return nil, arg.Unreadable
+	}
+	mt, ok := arg.RealType.(*godwarf.MapType)
+	if arg.Kind != reflect.Map || !ok {
+		return nil, fmt.Errorf("invalid argument %s (type %s) for $hmap", exprToString(nodeargs[0]), arg.TypeString())
+	}
+
+	sv := arg.clone()
+	sv.RealType = resolveTypedef(&mt.TypedefType)
+	sv = sv.maybeDereference()
+	if sv.Unreadable != nil {
+		return nil, sv.Unreadable
+	}
+	if sv.Addr == 0 {
+		return nil, fmt.Errorf("map %s is nil", exprToString(nodeargs[0]))
+	}
+	structType, ok := sv.RealType.(*godwarf.StructType)
+	if !ok {
+		return nil, errors.New("wrong real type for map")
+	}
+
+	var b, flags uint64
+	for _, f := range structType.Field {
+		if f.Name != "B" && f.Name != "flags" {
+			continue
+		}
+		field, err := sv.toField(f)
+		if err != nil {
+			return nil, err
+		}
+		if field.Unreadable != nil {
+			return nil, field.Unreadable
+		}
+		switch f.Name {
+		case "B":
+			b, err = field.asUint()
+		case "flags":
+			flags, err = field.asUint()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	numbuckets := uint64(1) << b
+	numoldbuckets := numbuckets
+	if flags&hashSameSizeGrow == 0 && b > 0 {
+		numoldbuckets = numbuckets >> 1
+	}
+
+	newStructType := &godwarf.StructType{}
+	*newStructType = *structType
+	newStructType.Field = make([]*godwarf.StructField, len(structType.Field))
+
+	for i := range structType.Field {
+		field := &godwarf.StructField{}
+		*field = *structType.Field[i]
+		if field.Name == "buckets" || field.Name == "oldbuckets" {
+			ptrType, ok := resolveTypedef(field.Type).(*godwarf.PtrType)
+			if !ok {
+				return nil, errMapBucketsNotStruct
+			}
+			n := numbuckets
+			if field.Name == "oldbuckets" {
+				n = numoldbuckets
+			}
+			field.Type = pointerTo(fakeArrayType(n, ptrType.Type), sv.bi.Arch)
+		}
+		newStructType.Field[i] = field
+	}
+
+	return newVariable("", sv.Addr, newStructType, sv.bi, sv.mem), nil
+}
+
+// pseudoVarPrefix replaces the '$' character at the start of the name of
+// a pseudo-variable, so that expressions like $GOOS can be parsed by
+// go/parser.
+const pseudoVarPrefix = "__delve_pseudovar_"
+
+// rewritePseudoVariables replaces every occurrence of '$' immediately
+// followed by an identifier, outside of string and character literals,
+// with pseudoVarPrefix.
+func rewritePseudoVariables(expr string) string {
+	if !strings.Contains(expr, "$") {
+		return expr
+	}
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expr))
+	var s scanner.Scanner
+	s.Init(file, []byte(expr), nil, 0)
+	var buf strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.ILLEGAL || lit != "$" {
+			continue
+		}
+		off := file.Offset(pos)
+		if off+1 >= len(expr) || !(expr[off+1] == '_' || (expr[off+1] >= 'a' && expr[off+1] <= 'z') || (expr[off+1] >= 'A' && expr[off+1] <= 'Z')) {
+			continue
+		}
+		buf.WriteString(expr[last:off])
+		buf.WriteString(pseudoVarPrefix)
+		last = off + 1
+	}
+	buf.WriteString(expr[last:])
+	return buf.String()
+}
+
+// fieldProjectionPrefix replaces the '#' character at the start of the
+// selector of a field projection, so that expressions like s.#Field can be
+// parsed by go/parser.
+const fieldProjectionPrefix = "__delve_fieldprojection_"
+
+// rewriteFieldProjections replaces every occurrence of '#' immediately
+// preceded by '.' and followed by an identifier, outside of string and
+// character literals, with fieldProjectionPrefix.
+func rewriteFieldProjections(expr string) string {
+	if !strings.Contains(expr, ".#") {
+		return expr
+	}
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expr))
+	var s scanner.Scanner
+	s.Init(file, []byte(expr), nil, 0)
+	var buf strings.Builder
+	last := 0
+	prevTok := token.ILLEGAL
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		isProjection := tok == token.ILLEGAL && lit == "#" && prevTok == token.PERIOD
+		prevTok = tok
+		if !isProjection {
+			continue
+		}
+		off := file.Offset(pos)
+		if off+1 >= len(expr) || !(expr[off+1] == '_' || (expr[off+1] >= 'a' && expr[off+1] <= 'z') || (expr[off+1] >= 'A' && expr[off+1] <= 'Z')) {
+			continue
+		}
+		buf.WriteString(expr[last:off])
+		buf.WriteString(fieldProjectionPrefix)
+		last = off + 1
+	}
+	buf.WriteString(expr[last:])
+	return buf.String()
+}
+
+// evalPseudoVariable evaluates the pseudo-variable $name.
+// Supported pseudo-variables are:
+//  $GOOS       operating system the target was built for
+//  $GOARCH     architecture the target was built for
+//  $goversion  version of the Go compiler used to build the target
+func (scope *EvalScope) evalPseudoVariable(name string) (*Variable, error) {
+	var val string
+	switch name {
+	case "GOOS":
+		val = scope.BinInfo.GOOS
+	case "GOARCH":
+		val = scope.BinInfo.Arch.Name
+	case "goversion":
+		producer := scope.BinInfo.Producer()
+		if producer == "" {
+			return nil, errors.New("could not determine Go version of target")
+		}
+		val = strings.TrimPrefix(producer, "Go cmd/compile ")
+		if i := strings.IndexAny(val, " ;"); i >= 0 {
+			val = val[:i]
+		}
+	default:
+		return nil, fmt.Errorf("unknown pseudo-variable $%s", name)
+	}
+	v := newConstant(constant.MakeString(val), scope.Mem)
+	v.Name = "$" + name
+	return v, nil
+}
+
 // Evaluates identifier expressions
 func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 	switch node.Name {
@@ -1131,6 +1719,10 @@ func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 		return nilVariable, nil
 	}
 
+	if strings.HasPrefix(node.Name, pseudoVarPrefix) {
+		return scope.evalPseudoVariable(node.Name[len(pseudoVarPrefix):])
+	}
+
 	vars, err := scope.Locals()
 	if err != nil {
 		return nil, err
@@ -1211,6 +1803,95 @@ func (scope *EvalScope) evalStructSelector(node *ast.SelectorExpr) (*Variable, e
 	return xv.structMember(node.Sel.Name)
 }
 
+// Evaluates expressions <subexpr>.#<field name> where subexpr is an array
+// or a slice of structs (or of pointers to structs), returns a slice
+// containing the value of the specified field for every element.
+// The returned slice is backed by memory that only exists inside the
+// debugger, assigning to its elements is not supported.
+func (scope *EvalScope) evalFieldProjection(node *ast.SelectorExpr) (*Variable, error) {
+	fieldName := node.Sel.Name[len(fieldProjectionPrefix):]
+	name := exprToString(node.X) + ".#" + fieldName
+	if scope.target == nil {
+		return nil, fmt.Errorf("can not evaluate %s: field projections not supported", name)
+	}
+	xv, err := scope.evalAST(node.X)
+	if err != nil {
+		return nil, err
+	}
+	if xv.Unreadable != nil {
+		return nil, xv.Unreadable
+	}
+	if xv.Kind != reflect.Slice && xv.Kind != reflect.Array {
+		return nil, fmt.Errorf("%s (type %s) is not an array or a slice", exprToString(node.X), xv.TypeString())
+	}
+
+	var fieldType godwarf.Type
+	pieces := make([]op.Piece, 0, xv.Len)
+	for i := 0; i < int(xv.Len); i++ {
+		elem, err := xv.sliceAccess(i)
+		if err != nil {
+			return nil, err
+		}
+		if elem.Kind == reflect.Ptr {
+			if elem = elem.maybeDereference(); elem.Unreadable != nil {
+				return nil, fmt.Errorf("element %d of %s: %v", i, exprToString(node.X), elem.Unreadable)
+			}
+			if elem.Addr == 0 {
+				return nil, fmt.Errorf("element %d of %s is nil", i, exprToString(node.X))
+			}
+		}
+		if elem.Kind != reflect.Struct {
+			return nil, fmt.Errorf("elements of %s (type %s) are not structs", exprToString(node.X), xv.TypeString())
+		}
+		field, err := elem.structMember(fieldName)
+		if err != nil {
+			return nil, fmt.Errorf("elements of %s (type %s) have no field %s", exprToString(node.X), xv.TypeString(), fieldName)
+		}
+		if field.Unreadable != nil {
+			return nil, field.Unreadable
+		}
+		if field.Addr == 0 {
+			return nil, fmt.Errorf("could not find the address of field %s of element %d of %s", fieldName, i, exprToString(node.X))
+		}
+		if fieldType == nil {
+			fieldType = field.DwarfType
+		}
+		pieces = append(pieces, op.Piece{Size: int(field.RealType.Size()), Kind: op.AddrPiece, Val: field.Addr})
+	}
+	mem := DereferenceMemory(xv.mem)
+	if fieldType == nil {
+		// empty array or slice, find the type of the field from the type of
+		// its elements.
+		typ := resolveTypedef(xv.fieldType)
+		if ptyp, isptr := typ.(*godwarf.PtrType); isptr {
+			typ = ptyp.Type
+		}
+		elem := xv.newVariable("", fakeAddressUnresolv, typ, mem)
+		if elem.Kind != reflect.Struct {
+			return nil, fmt.Errorf("elements of %s (type %s) are not structs", exprToString(node.X), xv.TypeString())
+		}
+		field, err := elem.structMember(fieldName)
+		if err != nil {
+			return nil, fmt.Errorf("elements of %s (type %s) have no field %s", exprToString(node.X), xv.TypeString(), fieldName)
+		}
+		fieldType = field.DwarfType
+	}
+
+	cmem, err := newCompositeMemory(mem, scope.BinInfo.Arch, op.DwarfRegisters{}, pieces)
+	if err != nil {
+		return nil, err
+	}
+	base := scope.target.registerFakeMemory(cmem)
+
+	r := newVariable(name, 0, fakeSliceType(fieldType), scope.BinInfo, &overlayMemory{cmem})
+	r.Len = xv.Len
+	r.Cap = xv.Len
+	r.Base = base
+	r.stride = fieldType.Size()
+	r.fieldType = fieldType
+	return r, nil
+}
+
 // Evaluates expressions <subexpr>.(<type>)
 func (scope *EvalScope) evalTypeAssert(node *ast.TypeAssertExpr) (*Variable, error) {
 	xv, err := scope.evalAST(node.X)
@@ -1228,7 +1909,7 @@ func (scope *EvalScope) evalTypeAssert(node *ast.TypeAssertExpr) (*Variable, err
 		return nil, xv.Children[0].Unreadable
 	}
 	if xv.Children[0].Addr == 0 {
-		return nil, fmt.Errorf("interface conversion: %s is nil, not %s", xv.DwarfType.String(), exprToString(node.Type))
+		return nil, &interfaceConversionErr{xv.DwarfType.String(), "nil", exprToString(node.Type), false}
 	}
 	// Accept .(data) as a type assertion that always succeeds, so that users
 	// can access the data field of an interface without actually having to
@@ -1238,8 +1919,11 @@ func (scope *EvalScope) evalTypeAssert(node *ast.TypeAssertExpr) (*Variable, err
 		if err != nil {
 			return nil, err
 		}
+		if _, isiface := resolveTypedef(typ).(*godwarf.InterfaceType); isiface {
+			return scope.typeAssertInterface(xv, typ)
+		}
 		if xv.Children[0].DwarfType.Common().Name != typ.Common().Name {
-			return nil, fmt.Errorf("interface conversion: %s is %s, not %s", xv.DwarfType.Common().Name, xv.Children[0].TypeString(), typ.Common().Name)
+			return nil, &interfaceConversionErr{xv.DwarfType.Common().Name, xv.Children[0].TypeString(), typ.Common().Name, false}
 		}
 	}
 	// loadInterface will set OnlyAddr for the data member since here we are
@@ -1250,6 +1934,181 @@ func (scope *EvalScope) evalTypeAssert(node *ast.TypeAssertExpr) (*Variable, err
 	return &xv.Children[0], nil
 }
 
+// typeAssertInterface evaluates the assertion of the non-nil interface
+// value xv to the interface type typ. The result is a value of type typ,
+// stored in fake memory, holding the same dynamic value as xv.
+func (scope *EvalScope) typeAssertInterface(xv *Variable, typ godwarf.Type) (*Variable, error) {
+	_type, data, _ := xv.readInterface()
+	if xv.Unreadable != nil {
+		return nil, xv.Unreadable
+	}
+	ptrSize := scope.BinInfo.Arch.PtrSize()
+	typeAddr := _type.maybeDereference().Addr
+	dataWord, err := readUintRaw(data.mem, data.Addr, int64(ptrSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// The first word of an empty interface is its dynamic type, the first
+	// word of a non-empty interface is an itab.
+	firstWord := typeAddr
+	ityp := resolveTypedef(&resolveTypedef(typ).(*godwarf.InterfaceType).TypedefType).(*godwarf.StructType)
+	if len(ityp.Field) > 0 && ityp.Field[0].Name == "tab" {
+		interAddr, _, found, err := dwarfToRuntimeType(scope.BinInfo, scope.Mem, typ)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("could not find runtime type of %s", typ.Common().Name)
+		}
+		itab, implements, found, err := scope.findItab(interAddr, typeAddr)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			// The runtime computes itabs lazily, the first time a type is
+			// converted to an interface, we can not do this without calling
+			// into the target.
+			return nil, fmt.Errorf("could not determine whether %s implements %s", xv.Children[0].TypeString(), typ.Common().Name)
+		}
+		if !implements {
+			return nil, &interfaceConversionErr{xv.DwarfType.Common().Name, xv.Children[0].TypeString(), typ.Common().Name, true}
+		}
+		firstWord = itab
+	}
+
+	cmem, err := newCompositeMemory(DereferenceMemory(xv.mem), scope.BinInfo.Arch, op.DwarfRegisters{}, []op.Piece{
+		{Size: ptrSize, Kind: op.ImmPiece, Val: firstWord},
+		{Size: ptrSize, Kind: op.ImmPiece, Val: dataWord},
+	})
+	if err != nil {
+		return nil, err
+	}
+	addr := scope.target.registerFakeMemory(cmem)
+	return newVariable("", addr, typ, scope.BinInfo, &overlayMemory{cmem}), nil
+}
+
+// findItab searches runtime.itabTable for the itab of the interface type
+// described by the runtime type at interAddr and the concrete type
+// described by the runtime type at typeAddr. The table contains both the
+// itabs generated by the linker and the ones created by the runtime when
+// a type assertion is executed, including the ones recording that the
+// type does not implement the interface, which have a zero fun[0].
+func (scope *EvalScope) findItab(interAddr, typeAddr uint64) (itab uint64, implements, found bool, err error) {
+	itabTable, err := scope.findGlobal("runtime", "itabTable")
+	if err != nil {
+		return 0, false, false, err
+	}
+	itabTable = itabTable.maybeDereference()
+	sizev := itabTable.loadFieldNamed("size")
+	if sizev == nil {
+		return 0, false, false, errors.New("could not read runtime.itabTable")
+	}
+	size, _ := constant.Uint64Val(sizev.Value)
+	entries, err := itabTable.structMember("entries")
+	if err != nil {
+		return 0, false, false, err
+	}
+	entriesTyp, isarr := entries.RealType.(*godwarf.ArrayType)
+	if !isarr {
+		return 0, false, false, errors.New("could not read runtime.itabTable")
+	}
+	itabTyp := resolveTypedef(entriesTyp.Type).(*godwarf.PtrType).Type
+
+	ptrSize := int64(scope.BinInfo.Arch.PtrSize())
+	for i := uint64(0); i < size; i++ {
+		p, err := readUintRaw(entries.mem, entries.Addr+i*uint64(ptrSize), ptrSize)
+		if err != nil {
+			return 0, false, false, err
+		}
+		if p == 0 {
+			continue
+		}
+		itabv := newVariable("", p, itabTyp, scope.BinInfo, entries.mem)
+		inter, err := itabv.structMember("inter")
+		if err != nil {
+			return 0, false, false, err
+		}
+		_type, err := itabv.structMember("_type")
+		if err != nil {
+			return 0, false, false, err
+		}
+		if inter.maybeDereference().Addr != interAddr || _type.maybeDereference().Addr != typeAddr {
+			continue
+		}
+		fun, err := itabv.structMember("fun")
+		if err != nil {
+			return 0, false, false, err
+		}
+		fun0, err := readUintRaw(fun.mem, fun.Addr, ptrSize)
+		if err != nil {
+			return 0, false, false, err
+		}
+		return p, fun0 != 0, true, nil
+	}
+	return 0, false, false, nil
+}
+
+// interfaceConversionErr is returned when a type assertion fails because
+// the interface does not contain a value of the asserted type or, if the
+// asserted type is an interface, because the dynamic type of the value
+// does not implement it.
+type interfaceConversionErr struct {
+	iface, dynamic, asserted string
+	toInterface              bool
+}
+
+func (err *interfaceConversionErr) Error() string {
+	if err.toInterface {
+		return fmt.Sprintf("interface conversion: %s is not %s", err.dynamic, err.asserted)
+	}
+	return fmt.Sprintf("interface conversion: %s is %s, not %s", err.iface, err.dynamic, err.asserted)
+}
+
+// evalTypeAssertCommaOk evaluates the comma-ok form of the type assertion
+// node. The result is a struct with two fields: value, the result of the
+// assertion, and ok, a boolean reporting whether the assertion succeeded.
+// When the assertion fails value is unreadable, and the reason is stored
+// in its Unreadable field, since the zero value of the asserted type can
+// not be created.
+func (scope *EvalScope) evalTypeAssertCommaOk(node *ast.TypeAssertExpr, cfg LoadConfig) (*Variable, error) {
+	v, err := scope.evalTypeAssert(node)
+	if err != nil {
+		if _, isConvErr := err.(*interfaceConversionErr); !isConvErr {
+			return nil, err
+		}
+		typ, _ := scope.BinInfo.findTypeExpr(node.Type)
+		v = newVariable("", 0, typ, scope.BinInfo, scope.Mem)
+		v.loaded = true
+		v.Unreadable = err
+	} else {
+		v.loadValue(cfg)
+	}
+	v.Name = "value"
+
+	ok := newConstant(constant.MakeBool(err == nil), scope.Mem)
+	ok.Name = "ok"
+
+	r := newVariable("", 0, nil, scope.BinInfo, nil)
+	r.loaded = true
+	r.Kind = reflect.Struct
+	if v.DwarfType != nil {
+		booltyp, err := scope.BinInfo.findType("bool")
+		if err != nil {
+			return nil, err
+		}
+		r.DwarfType = &godwarf.StructType{
+			CommonType: godwarf.CommonType{Name: fmt.Sprintf("struct { value %s; ok bool }", v.DwarfType.String())},
+			Kind:       "struct",
+			Field:      []*godwarf.StructField{{Name: "value", Type: v.DwarfType}, {Name: "ok", Type: booltyp}},
+		}
+		r.RealType = r.DwarfType
+	}
+	r.Children = []Variable{*v, *ok}
+	r.Len = int64(len(r.Children))
+	return r, nil
+}
+
 // Evaluates expressions <subexpr>[<subexpr>] (subscript access to arrays, slices and maps)
 func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 	xev, err := scope.evalAST(node.X)
@@ -1264,6 +2123,10 @@ func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 		xev = xev.maybeDereference()
 	}
 
+	if lit, isLit := node.Index.(*ast.CompositeLit); isLit && xev.Kind == reflect.Map {
+		return scope.mapAccessLit(xev, lit)
+	}
+
 	idxev, err := scope.evalAST(node.Index)
 	if err != nil {
 		return nil, err
@@ -1300,6 +2163,9 @@ func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 		if idxev.Unreadable != nil {
 			return nil, idxev.Unreadable
 		}
+		if err := xev.checkMapKey(idxev); err != nil {
+			return nil, fmt.Errorf("can not use %s (type %s) as key of %s: %v", exprToString(node.Index), idxev.TypeString(), xev.TypeString(), err)
+		}
 		return xev.mapAccess(idxev)
 	default:
 		return nil, cantindex
@@ -1960,6 +2826,215 @@ func (v *Variable) mapAccess(idx *Variable) (*Variable, error) {
 	return nil, fmt.Errorf("key not found")
 }
 
+// checkMapKey returns an error if idx can not be used as a key of the map
+// v.
+func (v *Variable) checkMapKey(idx *Variable) error {
+	mt, ok := v.RealType.(*godwarf.MapType)
+	if !ok {
+		return nil
+	}
+	keyv := v.newVariable("", 0, mt.KeyType, v.mem)
+	return idx.isType(keyv.RealType, keyv.Kind)
+}
+
+// mapKeyLit is a struct literal used to index a map, with its elements
+// already evaluated. Fields not mentioned in the literal have neither a
+// value nor a sub-literal and must be zero.
+type mapKeyLit struct {
+	vals []*Variable
+	lits []*mapKeyLit
+}
+
+// mapAccessLit returns the value associated in the map v with the key
+// described by the struct literal lit.
+func (scope *EvalScope) mapAccessLit(v *Variable, lit *ast.CompositeLit) (*Variable, error) {
+	mt, ok := v.RealType.(*godwarf.MapType)
+	if !ok {
+		return nil, fmt.Errorf("can not index %s with a composite literal", v.TypeString())
+	}
+	keylit, err := scope.evalMapKeyLit(v.newVariable("", 0, mt.KeyType, v.mem), lit)
+	if err != nil {
+		return nil, fmt.Errorf("can not use %s as key of %s: %v", exprToString(lit), v.TypeString(), err)
+	}
+
+	it := v.mapIterator()
+	if it == nil {
+		return nil, fmt.Errorf("can not access unreadable map: %v", v.Unreadable)
+	}
+	for it.next() {
+		key := it.key()
+		key.loadValue(loadFullValue)
+		if key.Unreadable != nil {
+			return nil, fmt.Errorf("can not access unreadable map: %v", key.Unreadable)
+		}
+		eql, err := keylit.match(key)
+		if err != nil {
+			return nil, err
+		}
+		if eql {
+			return it.value(), nil
+		}
+	}
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	return nil, fmt.Errorf("key not found")
+}
+
+// evalMapKeyLit evaluates the elements of lit, a literal of the type of
+// keyv.
+func (scope *EvalScope) evalMapKeyLit(keyv *Variable, lit *ast.CompositeLit) (*mapKeyLit, error) {
+	if lit.Type != nil {
+		typ, err := scope.BinInfo.findTypeExpr(lit.Type)
+		if err != nil {
+			return nil, err
+		}
+		if typ.String() != keyv.DwarfType.String() {
+			return nil, fmt.Errorf("can not use %s literal as a %s value", typ.String(), keyv.DwarfType.String())
+		}
+	}
+	typ, isstruct := keyv.RealType.(*godwarf.StructType)
+	if !isstruct {
+		return nil, fmt.Errorf("composite literals of type %s are not supported as map keys", keyv.DwarfType.String())
+	}
+	fields, exprs, err := structLitFields(typ, keyv.DwarfType.String(), lit)
+	if err != nil {
+		return nil, err
+	}
+	keylit := &mapKeyLit{vals: make([]*Variable, len(typ.Field)), lits: make([]*mapKeyLit, len(typ.Field))}
+	for k, i := range fields {
+		fieldv := keyv.newVariable("", 0, typ.Field[i].Type, keyv.mem)
+		if sublit, isLit := exprs[k].(*ast.CompositeLit); isLit {
+			keylit.lits[i], err = scope.evalMapKeyLit(fieldv, sublit)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		val, err := scope.evalAST(exprs[k])
+		if err != nil {
+			return nil, err
+		}
+		val.loadValue(loadFullValue)
+		if val.Unreadable != nil {
+			return nil, val.Unreadable
+		}
+		if err := val.isType(fieldv.RealType, fieldv.Kind); err != nil {
+			return nil, err
+		}
+		keylit.vals[i] = val
+	}
+	return keylit, nil
+}
+
+// match returns true if the struct key is equal to the literal k.
+func (k *mapKeyLit) match(key *Variable) (bool, error) {
+	if len(key.Children) != len(k.vals) || int64(len(key.Children)) != key.Len {
+		return false, fmt.Errorf("structure too deep for comparison")
+	}
+	for i := range key.Children {
+		fieldv := &key.Children[i]
+		var eql bool
+		var err error
+		switch {
+		case k.lits[i] != nil:
+			eql, err = k.lits[i].match(fieldv)
+		case k.vals[i] != nil:
+			eql, err = compareOp(token.EQL, fieldv, k.vals[i])
+		default:
+			eql = fieldv.isZero()
+		}
+		if err != nil || !eql {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isZero returns true if v, which must be loaded, holds the zero value of
+// its type.
+func (v *Variable) isZero() bool {
+	switch v.Kind {
+	case reflect.String:
+		return v.Len == 0
+	case reflect.Struct, reflect.Array:
+		for i := range v.Children {
+			if !v.Children[i].isZero() {
+				return false
+			}
+		}
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+		return v.isNil()
+	}
+	if v.Value == nil {
+		return false
+	}
+	switch v.Value.Kind() {
+	case constant.Bool:
+		return !constant.BoolVal(v.Value)
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Compare(v.Value, token.EQL, constant.MakeInt64(0))
+	}
+	return false
+}
+
+// readBytesChunkSize is the maximum number of bytes read from the target's
+// memory at once by ReadBytes.
+const readBytesChunkSize = 1024 * 1024
+
+// ReadBytes returns the full contents of v, which must be a string or a
+// slice or array with single byte elements, regardless of the load
+// configuration used to evaluate it. The contents are read in chunks of
+// at most readBytesChunkSize bytes, an error is returned if v is longer
+// than limit bytes.
+func (v *Variable) ReadBytes(limit int) ([]byte, error) {
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	notBytesErr := fmt.Errorf("%s (type %s) is not a string or a slice or array of bytes", v.Name, v.TypeString())
+	switch v.Kind {
+	case reflect.String:
+		if v.Flags&(VariableCPtr|VariableCPURegister) != 0 {
+			return nil, notBytesErr
+		}
+		if v.Base == 0 && v.Value != nil {
+			// string constant
+			return []byte(constant.StringVal(v.Value)), nil
+		}
+	case reflect.Slice, reflect.Array:
+		if v.fieldType == nil || v.fieldType.Size() != 1 {
+			return nil, notBytesErr
+		}
+		switch resolveTypedef(v.fieldType).(type) {
+		case *godwarf.UintType, *godwarf.IntType, *godwarf.UcharType, *godwarf.CharType:
+			// ok
+		default:
+			return nil, notBytesErr
+		}
+	default:
+		return nil, notBytesErr
+	}
+	if v.Len > int64(limit) {
+		return nil, fmt.Errorf("%s is %d bytes long, more than the limit of %d bytes", v.Name, v.Len, limit)
+	}
+	if v.Len <= 0 {
+		return []byte{}, nil
+	}
+	mem := DereferenceMemory(v.mem)
+	buf := make([]byte, v.Len)
+	for off := 0; off < len(buf); off += readBytesChunkSize {
+		end := off + readBytesChunkSize
+		if end > len(buf) {
+			end = len(buf)
+		}
+		if _, err := mem.ReadMemory(buf[off:end], v.Base+uint64(off)); err != nil {
+			return nil, fmt.Errorf("could not read %s at %#x: %v", v.Name, v.Base+uint64(off), err)
+		}
+	}
+	return buf, nil
+}
+
 // LoadResliced returns a new array, slice or map that starts at index start and contains
 // up to cfg.MaxArrayValues children.
 func (v *Variable) LoadResliced(start int, cfg LoadConfig) (newV *Variable, err error) {
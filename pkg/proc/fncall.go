@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/constant"
+	"go/parser"
 	"go/token"
 	"reflect"
 	"sort"
@@ -64,6 +65,10 @@ var (
 	errFuncCallNotAllowedStrAlloc = errors.New("literal string can not be allocated because function calls are not allowed without using 'call'")
 )
 
+// ErrFunctionCallAborted is returned by the evaluation of a function call
+// that was unwound by AbortFunctionCall.
+var ErrFunctionCallAborted = errors.New("function call aborted")
+
 type functionCallState struct {
 	// savedRegs contains the saved registers
 	savedRegs Registers
@@ -132,6 +137,9 @@ type callInjection struct {
 	continueCompleted chan<- *G
 	continueRequest   <-chan continueRequest
 	startThreadID     int
+	// aborted is set by AbortFunctionCall, the return values of the call
+	// are discarded and evaluation fails with ErrFunctionCallAborted.
+	aborted bool
 }
 
 func (callCtx *callContext) doContinue() *G {
@@ -204,6 +212,115 @@ func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig
 	return finishEvalExpressionWithCalls(t, g, contReq, ok)
 }
 
+// MethodCallExpr returns an expression, suitable for
+// EvalExpressionWithCalls, that calls method on the value of receiverExpr
+// passing args as arguments.
+// The receiver is evaluated in scope, without function calls, to check
+// that it has the method and that the method can be called on it: value
+// methods can not be called through a nil pointer and pointer methods can
+// only be called on addressable values. Like in Go, pointer methods can be
+// called on addressable values that aren't pointers.
+// Methods that assign to the entries of their receiver can not be called
+// on a nil map.
+func MethodCallExpr(scope *EvalScope, receiverExpr, method string, args []string) (string, error) {
+	if id, err := parser.ParseExpr(method); err != nil || id == nil {
+		return "", fmt.Errorf("invalid method name %q", method)
+	} else if _, isident := id.(*ast.Ident); !isident {
+		return "", fmt.Errorf("invalid method name %q", method)
+	}
+	for _, arg := range args {
+		if _, err := parser.ParseExpr(arg); err != nil {
+			return "", fmt.Errorf("invalid argument %q: %v", arg, err)
+		}
+	}
+
+	xv, err := scope.EvalExpression(receiverExpr, LoadConfig{})
+	if err != nil {
+		return "", err
+	}
+	if xv.Unreadable != nil {
+		return "", xv.Unreadable
+	}
+	if _, isiface := xv.RealType.(*godwarf.InterfaceType); isiface {
+		if _, _, isnil := xv.readInterface(); isnil {
+			return "", fmt.Errorf("can not call method %s on %s: nil interface", method, receiverExpr)
+		}
+	}
+
+	fnvar, err := xv.findMethod(method)
+	if err != nil {
+		return "", err
+	}
+	if fnvar == nil || fnvar.Kind != reflect.Func || len(fnvar.Children) != 1 {
+		return "", fmt.Errorf("%s (type %s) has no method %s", receiverExpr, xv.TypeString(), method)
+	}
+	if fnvar.Unreadable != nil {
+		return "", fnvar.Unreadable
+	}
+
+	recv := &fnvar.Children[0]
+	if recv.Unreadable != nil {
+		return "", recv.Unreadable
+	}
+	if recv.Kind == reflect.Ptr {
+		if recv.Addr == 0 && len(recv.Children) == 1 {
+			// pointer method on a value, the value must be addressable
+			if v := recv.Children[0]; v.Addr == 0 || v.Flags&(VariableFakeAddress|VariableCPURegister) != 0 {
+				return "", fmt.Errorf("can not call pointer method %s on %s: receiver is not addressable", method, receiverExpr)
+			}
+		}
+	} else if recv.Addr == 0 && recv.Value == nil {
+		return "", fmt.Errorf("can not call value method %s on %s: nil pointer dereference", method, receiverExpr)
+	}
+
+	mapv := recv
+	if recv.Kind == reflect.Ptr && len(recv.Children) == 1 {
+		mapv = &recv.Children[0]
+	}
+	if isNilMap(mapv) {
+		writes, err := writesMap(scope, scope.BinInfo.PCToFunc(uint64(fnvar.Base)))
+		if err != nil {
+			return "", err
+		}
+		if writes {
+			return "", fmt.Errorf("can not call method %s on %s: assignment to entry in nil map", method, receiverExpr)
+		}
+	}
+
+	return fmt.Sprintf("(%s).%s(%s)", receiverExpr, method, strings.Join(args, ", ")), nil
+}
+
+// isNilMap returns true if v is a nil map.
+func isNilMap(v *Variable) bool {
+	mt, ok := v.RealType.(*godwarf.MapType)
+	if !ok || v.Kind != reflect.Map {
+		return false
+	}
+	sv := v.clone()
+	sv.RealType = resolveTypedef(&mt.TypedefType)
+	sv = sv.maybeDereference()
+	return sv.Unreadable == nil && sv.Addr == 0
+}
+
+// writesMap returns true if fn assigns to a map entry, i.e. if it calls
+// runtime.mapassign directly. Calling it with a nil map receiver would
+// panic.
+func writesMap(scope *EvalScope, fn *Function) (bool, error) {
+	if fn == nil {
+		return false, nil
+	}
+	text, err := disassemble(scope.Mem, nil, scope.target.Breakpoints(), scope.BinInfo, fn.Entry, fn.End, false)
+	if err != nil {
+		return false, err
+	}
+	for _, instr := range text {
+		if instr.IsCall() && instr.DestLoc != nil && instr.DestLoc.Fn != nil && strings.HasPrefix(instr.DestLoc.Fn.Name, "runtime.mapassign") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func finishEvalExpressionWithCalls(t *Target, g *G, contReq continueRequest, ok bool) error {
 	fncallLog("stashing return values for %d in thread=%d", g.ID, g.Thread.ThreadID())
 	g.Thread.Common().CallReturn = true
@@ -397,6 +514,51 @@ func (err fncallPanicErr) Error() string {
 	return "panic calling a function"
 }
 
+// maxPanicErrorChain is the maximum number of wrapped errors returned by
+// panicErrorChain.
+const maxPanicErrorChain = 16
+
+// panicErrorChain returns the chain of errors wrapped by the panic value v.
+// Since the Unwrap methods can not be called while the panic is being
+// handled, for each error the first field of type error of its concrete
+// value is followed. This matches the implementation of Unwrap for
+// the error types of the standard library (*fmt.wrapError, *os.PathError,
+// *os.SyscallError, *net.OpError, *url.Error, etc).
+func panicErrorChain(v *Variable, cfg LoadConfig) []Variable {
+	var chain []Variable
+	for len(chain) < maxPanicErrorChain {
+		if v.Kind != reflect.Interface || len(v.Children) == 0 {
+			break
+		}
+		data := &v.Children[0]
+		if data.Kind == reflect.Ptr {
+			data = data.maybeDereference()
+		}
+		typ, isstruct := data.RealType.(*godwarf.StructType)
+		if !isstruct || data.Addr == 0 || data.Unreadable != nil {
+			break
+		}
+		var next *Variable
+		for _, field := range typ.Field {
+			if field.Type.String() == "error" {
+				next, _ = data.toField(field)
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		if _, _, isnil := next.readInterface(); isnil || next.Unreadable != nil {
+			break
+		}
+		next.Name = "~unwrapped"
+		next.loadValue(cfg)
+		chain = append(chain, *next)
+		v = next
+	}
+	return chain
+}
+
 func fncallLog(fmtstr string, args ...interface{}) {
 	logflags.FnCallLogger().Infof(fmtstr, args...)
 }
@@ -461,7 +623,7 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 	if fnvar.Kind != reflect.Func {
 		return fmt.Errorf("expression %q is not a function", exprToString(fncall.expr.Fun))
 	}
-	fnvar.loadValue(LoadConfig{false, 0, 0, 0, 0, 0})
+	fnvar.loadValue(LoadConfig{false, 0, 0, 0, 0, 0, 0, false})
 	if fnvar.Unreadable != nil {
 		return fnvar.Unreadable
 	}
@@ -875,6 +1037,12 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState, thread Thread
 		if fncall.panicvar != nil || fncall.lateCallFailure {
 			break
 		}
+		if callinj := p.fncallForG[callScope.g.ID]; callinj != nil && callinj.aborted {
+			// the called function was unwound by AbortFunctionCall, there are no
+			// return values to read.
+			fncall.err = ErrFunctionCallAborted
+			break
+		}
 		retScope, err := ThreadScope(p, thread)
 		if err != nil {
 			fncall.err = fmt.Errorf("could not get return values: %v", err)
@@ -914,6 +1082,7 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState, thread Thread
 			break
 		}
 		fncall.panicvar.Name = "~panic"
+		fncall.panicvar.Children = append(fncall.panicvar.Children, panicErrorChain(fncall.panicvar, callScope.callCtx.retLoadCfg)...)
 
 	default:
 		// Got an unknown protocol register value, this is probably bad but the safest thing
@@ -1096,6 +1265,111 @@ func findCallInjectionStateForThread(t *Target, thread Thread) (*G, *callInjecti
 	return nil, nil, notfound()
 }
 
+// FunctionCallGoroutines returns the IDs of the goroutines involved in an
+// injected function call that is in progress, in increasing order.
+// This includes both the goroutine where the call was started and, for Go
+// 1.15 and later, the goroutine executing the injected call.
+func (t *Target) FunctionCallGoroutines() []int {
+	r := []int{}
+	for goid, callinj := range t.fncallForG {
+		if callinj != nil && callinj.continueCompleted != nil {
+			r = append(r, goid)
+		}
+	}
+	sort.Ints(r)
+	return r
+}
+
+// abortFunctionCallStackDepth is the maximum number of frames searched by
+// AbortFunctionCall for the frame of the called function.
+const abortFunctionCallStackDepth = 1000
+
+// AbortFunctionCall unwinds the injected function call in progress on
+// goroutine g, as if the called function had returned immediately, so that
+// the function call protocol completes the next time the target is
+// resumed. The evaluation that started the call then fails with an error.
+// The call can only be unwound while the called function is executing on
+// a thread, outside of the runtime: a call blocked waiting for something
+// (for example a channel operation) can not be aborted. Deferred calls of
+// the unwound frames are discarded without running them.
+func (t *Target) AbortFunctionCall(g *G) error {
+	callinj := t.fncallForG[g.ID]
+	if callinj == nil || callinj.continueCompleted == nil {
+		return fmt.Errorf("no function call in progress on goroutine %d", g.ID)
+	}
+	// In Go 1.15 and later the called function runs on a different goroutine,
+	// which is also registered in fncallForG once the call has started.
+	for _, thread := range t.ThreadList() {
+		threadg, _ := GetG(thread)
+		if threadg == nil || t.fncallForG[threadg.ID] != callinj {
+			continue
+		}
+		if err := unwindInjectedCall(threadg); err != nil {
+			return err
+		}
+		callinj.aborted = true
+		return nil
+	}
+	return fmt.Errorf("can not abort function call on goroutine %d: the called function is not running", g.ID)
+}
+
+// unwindInjectedCall moves the thread of g to the return address of the
+// innermost frame called by the runtime function that dispatches injected
+// calls and pops the deferred calls of the unwound frames.
+func unwindInjectedCall(g *G) error {
+	frames, err := g.Stacktrace(abortFunctionCallStackDepth, 0)
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(frames); i++ {
+		if fn := frames[i].Current.Fn; fn == nil || fn.PackageName() == "runtime" {
+			// the runtime could be holding locks or be in the middle of updating
+			// the state of the goroutine.
+			return fmt.Errorf("can not abort function call on goroutine %d: the called function is executing runtime code", g.ID)
+		}
+		if frames[i].Inlined || !IsDebugCallFunction(frames[i+1].Current.Fn) {
+			continue
+		}
+		cfa := uint64(frames[i].Regs.CFA)
+
+		top := g.Defer()
+		d := top
+		for d != nil && d.Unreadable == nil && d.SP < cfa {
+			d = d.Next()
+		}
+		if d != nil && d.Unreadable != nil {
+			return fmt.Errorf("can not abort function call: %v", d.Unreadable)
+		}
+		if d != top {
+			dvar, _ := g.variable.structMember("_defer")
+			newtop := uint64(0)
+			if d != nil {
+				newtop = d.variable.Addr
+			}
+			if err := writePointer(g.variable.bi, g.variable.mem, dvar.Addr, newtop); err != nil {
+				return err
+			}
+		}
+
+		if err := setSP(g.Thread, cfa); err != nil {
+			return err
+		}
+		if err := setPC(g.Thread, frames[i+1].Current.PC); err != nil {
+			return err
+		}
+		g.Thread.Breakpoint().Clear()
+		return nil
+	}
+	return fmt.Errorf("can not abort function call on goroutine %d: frame of the called function not found", g.ID)
+}
+
+// IsDebugCallFunction returns true if fn is one of the runtime functions
+// used to inject function calls (runtime.debugCallV1, runtime.debugCallV2,
+// runtime.debugCallWrap, etc).
+func IsDebugCallFunction(fn *Function) bool {
+	return fn != nil && (strings.HasPrefix(fn.Name, debugCallFunctionNamePrefix1) || strings.HasPrefix(fn.Name, debugCallFunctionNamePrefix2))
+}
+
 // debugCallFunction searches for the debug call function in the binary and
 // uses this search to detect the debug call version.
 // Returns the debug call function and its version as an integer (the lowest
@@ -173,8 +173,9 @@ type gdbThread struct {
 	regs              gdbRegisters
 	CurrentBreakpoint proc.BreakpointState
 	p                 *gdbProcess
-	sig               uint8 // signal received by thread after last stop
-	setbp             bool  // thread was stopped because of a breakpoint
+	sig               uint8  // signal received by thread after last stop
+	setbp             bool   // thread was stopped because of a breakpoint
+	watchAddr         uint64 // if > 0 this is the watchpoint address
 	common            proc.CommonThread
 }
 
@@ -411,10 +412,12 @@ func commandLogger(binary string, arguments ...string) *exec.Cmd {
 // ErrUnsupportedOS is returned when trying to use the lldb backend on Windows.
 var ErrUnsupportedOS = errors.New("lldb backend not supported on Windows")
 
-func getLdEnvVars() []string {
+func getLdEnvVars(environ []string) []string {
 	var result []string
 
-	environ := os.Environ()
+	if environ == nil {
+		environ = os.Environ()
+	}
 	for i := 0; i < len(environ); i++ {
 		if strings.HasPrefix(environ[i], "LD_") ||
 			strings.HasPrefix(environ[i], "DYLD_") {
@@ -428,7 +431,9 @@ func getLdEnvVars() []string {
 // LLDBLaunch starts an instance of lldb-server and connects to it, asking
 // it to launch the specified target program with the specified arguments
 // (cmd) on the specified directory wd.
-func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+// If env is not nil it is used as the environment of the new process,
+// otherwise the environment of the current process is inherited.
+func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string, env []string) (*proc.Target, error) {
 	if runtime.GOOS == "windows" {
 		return nil, ErrUnsupportedOS
 	}
@@ -452,7 +457,7 @@ func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs [
 		if err != nil {
 			return nil, err
 		}
-		ldEnvVars := getLdEnvVars()
+		ldEnvVars := getLdEnvVars(env)
 		args := make([]string, 0, len(cmd)+4+len(ldEnvVars))
 		args = append(args, ldEnvVars...)
 
@@ -524,7 +529,9 @@ func LLDBLaunch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs [
 	}
 
 	if runtime.GOOS == "darwin" {
-		process.Env = proc.DisableAsyncPreemptEnv()
+		process.Env = proc.DisableAsyncPreemptEnv(env)
+	} else {
+		process.Env = env
 	}
 
 	if err = process.Start(); err != nil {
@@ -809,10 +816,9 @@ func (p *gdbProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 	var atstart bool
 continueLoop:
 	for {
-		var err error
-		var sig uint8
 		tu.Reset()
-		threadID, sig, err = p.conn.resume(p.threads, &tu)
+		sp, err := p.conn.resume(p.threads, &tu)
+		threadID = sp.threadID
 		if err != nil {
 			if _, exited := err.(proc.ErrProcessExited); exited {
 				p.exited = true
@@ -829,7 +835,8 @@ continueLoop:
 		if trapthread != nil && !p.threadStopInfo {
 			// For stubs that do not support qThreadStopInfo we manually set the
 			// reason the thread returned by resume() stopped.
-			trapthread.sig = sig
+			trapthread.sig = sp.sig
+			trapthread.watchAddr = sp.watchAddr
 		}
 
 		var shouldStop bool
@@ -1062,7 +1069,7 @@ func (p *gdbProcess) Restart(pos string) (proc.Thread, error) {
 
 	// for some reason we have to send a vCont;c after a vRun to make rr behave
 	// properly, because that's what gdb does.
-	_, _, err = p.conn.resume(nil, nil)
+	_, err = p.conn.resume(nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1074,8 +1081,8 @@ func (p *gdbProcess) Restart(pos string) (proc.Thread, error) {
 	p.clearThreadSignals()
 	p.clearThreadRegisters()
 
-	for addr := range p.breakpoints.M {
-		p.conn.setBreakpoint(addr, p.breakpointKind)
+	for _, bp := range p.breakpoints.M {
+		p.WriteBreakpoint(bp)
 	}
 
 	return p.currentThread, p.setCurrentBreakpoints()
@@ -1211,14 +1218,28 @@ func (p *gdbProcess) FindBreakpoint(pc uint64) (*proc.Breakpoint, bool) {
 	return nil, false
 }
 
+// findWatchpoint returns the watchpoint covering addr, stubs can report
+// any address inside the watched memory.
+func (p *gdbProcess) findWatchpoint(addr uint64) *proc.Breakpoint {
+	for _, bp := range p.breakpoints.M {
+		if bp.WatchType != 0 && addr >= bp.Addr && addr < bp.Addr+uint64(bp.WatchType.Size()) {
+			return bp
+		}
+	}
+	return nil
+}
+
 func (p *gdbProcess) WriteBreakpoint(bp *proc.Breakpoint) error {
 	if bp.WatchType != 0 {
-		return errors.New("hardware breakpoints not supported")
+		return p.conn.setWatchpoint(bp.Addr, bp.WatchType)
 	}
 	return p.conn.setBreakpoint(bp.Addr, p.breakpointKind)
 }
 
 func (p *gdbProcess) EraseBreakpoint(bp *proc.Breakpoint) error {
+	if bp.WatchType != 0 {
+		return p.conn.clearWatchpoint(bp.Addr, bp.WatchType)
+	}
 	return p.conn.clearBreakpoint(bp.Addr, p.breakpointKind)
 }
 
@@ -1311,7 +1332,7 @@ func (p *gdbProcess) updateThreadList(tu *threadUpdater) error {
 
 	for _, th := range p.threads {
 		if p.threadStopInfo {
-			sig, reason, err := p.conn.threadStopInfo(th.strID)
+			sp, err := p.conn.threadStopInfo(th.strID)
 			if err != nil {
 				if isProtocolErrorUnsupported(err) {
 					p.threadStopInfo = false
@@ -1319,10 +1340,12 @@ func (p *gdbProcess) updateThreadList(tu *threadUpdater) error {
 				}
 				return err
 			}
-			th.setbp = (reason == "breakpoint" || (reason == "" && sig == breakpointSignal))
-			th.sig = sig
+			th.setbp = (sp.reason == "breakpoint" || (sp.reason == "" && sp.sig == breakpointSignal) || sp.watchAddr > 0)
+			th.sig = sp.sig
+			th.watchAddr = sp.watchAddr
 		} else {
 			th.sig = 0
+			th.watchAddr = 0
 		}
 	}
 
@@ -1439,7 +1462,7 @@ func (t *gdbThread) Common() *proc.CommonThread {
 // StepInstruction will step exactly 1 CPU instruction.
 func (t *gdbThread) StepInstruction() error {
 	pc := t.regs.PC()
-	if _, atbp := t.p.breakpoints.M[pc]; atbp {
+	if bp, atbp := t.p.breakpoints.M[pc]; atbp && bp.WatchType == 0 {
 		err := t.p.conn.clearBreakpoint(pc, t.p.breakpointKind)
 		if err != nil {
 			return err
@@ -1530,6 +1553,26 @@ func (p *gdbProcess) DumpProcessNotes(notes []elfwriter.Note, threadDone func())
 	return false, notes, nil
 }
 
+// SetHeldThreads is not supported, the target is always resumed as a whole.
+func (p *gdbProcess) SetHeldThreads(tids []int) bool {
+	return false
+}
+
+// SetStopSignals is not supported, signals are handled by the stub.
+func (p *gdbProcess) SetStopSignals(sigs []int) bool {
+	return false
+}
+
+// PendingSignal is not supported, signals are handled by the stub.
+func (p *gdbProcess) PendingSignal(tid int) (int, string) {
+	return 0, ""
+}
+
+// SetPendingSignal is not supported, signals are handled by the stub.
+func (p *gdbProcess) SetPendingSignal(tid, sig int) bool {
+	return false
+}
+
 func (regs *gdbRegisters) init(regsInfo []gdbRegisterInfo, arch *proc.Arch, regnames *gdbRegnames) {
 	regs.arch = arch
 	regs.regnames = regnames
@@ -1664,8 +1707,8 @@ func (t *gdbThread) reloadGAtPC() error {
 	// around by clearing and re-setting the breakpoint in a specific sequence
 	// with the memory writes.
 	// Additionally all breakpoints in [pc, pc+len(movinstr)] need to be removed
-	for addr := range t.p.breakpoints.M {
-		if addr >= pc && addr <= pc+uint64(len(movinstr)) {
+	for addr, bp := range t.p.breakpoints.M {
+		if bp.WatchType == 0 && addr >= pc && addr <= pc+uint64(len(movinstr)) {
 			err := t.p.conn.clearBreakpoint(addr, t.p.breakpointKind)
 			if err != nil {
 				return err
@@ -1774,6 +1817,7 @@ func (t *gdbThread) reloadGAlloc() error {
 
 func (t *gdbThread) clearBreakpointState() {
 	t.setbp = false
+	t.watchAddr = 0
 	t.CurrentBreakpoint.Clear()
 }
 
@@ -1781,7 +1825,16 @@ func (t *gdbThread) clearBreakpointState() {
 func (t *gdbThread) SetCurrentBreakpoint(adjustPC bool) error {
 	// adjustPC is ignored, it is the stub's responsibiility to set the PC
 	// address correctly after hitting a breakpoint.
+	watchAddr := t.watchAddr
 	t.clearBreakpointState()
+	if watchAddr > 0 {
+		bp := t.p.findWatchpoint(watchAddr)
+		if bp == nil {
+			return fmt.Errorf("could not find watchpoint at address %#x", watchAddr)
+		}
+		t.CurrentBreakpoint = bp.CheckCondition(t)
+		return nil
+	}
 	regs, err := t.Registers()
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"debug/macho"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -420,6 +421,39 @@ func (conn *gdbConn) clearBreakpoint(addr uint64, kind int) error {
 	return err
 }
 
+// setWatchpoint executes a 'Z' (insert breakpoint) command of type '2',
+// '3' or '4', depending on wtype, with the size of the watched memory as
+// kind.
+func (conn *gdbConn) setWatchpoint(addr uint64, wtype proc.WatchType) error {
+	conn.outbuf.Reset()
+	fmt.Fprintf(&conn.outbuf, "$Z%d,%x,%d", watchpointPacketType(wtype), addr, wtype.Size())
+	_, err := conn.exec(conn.outbuf.Bytes(), "set watchpoint")
+	return err
+}
+
+// clearWatchpoint executes a 'z' (remove breakpoint) command of type '2',
+// '3' or '4', depending on wtype.
+func (conn *gdbConn) clearWatchpoint(addr uint64, wtype proc.WatchType) error {
+	conn.outbuf.Reset()
+	fmt.Fprintf(&conn.outbuf, "$z%d,%x,%d", watchpointPacketType(wtype), addr, wtype.Size())
+	_, err := conn.exec(conn.outbuf.Bytes(), "clear watchpoint")
+	return err
+}
+
+// watchpointPacketType returns the type argument of the 'Z' and 'z'
+// commands for a watchpoint of type wtype: '2' for write watchpoints, '3'
+// for read watchpoints and '4' for access watchpoints.
+func watchpointPacketType(wtype proc.WatchType) int {
+	switch {
+	case wtype.Read() && wtype.Write():
+		return 4
+	case wtype.Read():
+		return 3
+	default:
+		return 2
+	}
+}
+
 // kill executes a 'k' (kill) command.
 func (conn *gdbConn) kill() error {
 	resp, err := conn.exec([]byte{'$', 'k'}, "kill")
@@ -537,7 +571,7 @@ func (conn *gdbConn) writeRegister(threadID string, regnum int, data []byte) err
 // resume each thread. If a thread has sig == 0 the 'c' action will be used,
 // otherwise the 'C' action will be used and the value of sig will be passed
 // to it.
-func (conn *gdbConn) resume(threads map[int]*gdbThread, tu *threadUpdater) (string, uint8, error) {
+func (conn *gdbConn) resume(threads map[int]*gdbThread, tu *threadUpdater) (stopPacket, error) {
 	if conn.direction == proc.Forward {
 		conn.outbuf.Reset()
 		fmt.Fprintf(&conn.outbuf, "$vCont")
@@ -549,7 +583,7 @@ func (conn *gdbConn) resume(threads map[int]*gdbThread, tu *threadUpdater) (stri
 		fmt.Fprintf(&conn.outbuf, ";c")
 	} else {
 		if err := conn.selectThread('c', "p-1.-1", "resume"); err != nil {
-			return "", 0, err
+			return stopPacket{}, err
 		}
 		conn.outbuf.Reset()
 		fmt.Fprint(&conn.outbuf, "$bc")
@@ -557,7 +591,7 @@ func (conn *gdbConn) resume(threads map[int]*gdbThread, tu *threadUpdater) (stri
 	conn.manualStopMutex.Lock()
 	if err := conn.send(conn.outbuf.Bytes()); err != nil {
 		conn.manualStopMutex.Unlock()
-		return "", 0, err
+		return stopPacket{}, err
 	}
 	conn.running = true
 	conn.manualStopMutex.Unlock()
@@ -584,7 +618,7 @@ func (conn *gdbConn) step(threadID string, tu *threadUpdater, ignoreFaultSignal
 		if err := conn.send(conn.outbuf.Bytes()); err != nil {
 			return err
 		}
-		_, _, err := conn.waitForvContStop("singlestep", threadID, tu)
+		_, err := conn.waitForvContStop("singlestep", threadID, tu)
 		return err
 	}
 	var sig uint8 = 0
@@ -601,11 +635,11 @@ func (conn *gdbConn) step(threadID string, tu *threadUpdater, ignoreFaultSignal
 		if tu != nil {
 			tu.Reset()
 		}
-		var err error
-		_, sig, err = conn.waitForvContStop("singlestep", threadID, tu)
+		sp, err := conn.waitForvContStop("singlestep", threadID, tu)
 		if err != nil {
 			return err
 		}
+		sig = sp.sig
 		switch sig {
 		case faultSignal:
 			if ignoreFaultSignal { // we attempting to read the TLS, a fault here should be ignored
@@ -626,7 +660,7 @@ func (conn *gdbConn) step(threadID string, tu *threadUpdater, ignoreFaultSignal
 
 var errThreadBlocked = errors.New("thread blocked")
 
-func (conn *gdbConn) waitForvContStop(context string, threadID string, tu *threadUpdater) (string, uint8, error) {
+func (conn *gdbConn) waitForvContStop(context string, threadID string, tu *threadUpdater) (stopPacket, error) {
 	count := 0
 	failed := false
 	for {
@@ -647,22 +681,23 @@ func (conn *gdbConn) waitForvContStop(context string, threadID string, tu *threa
 			}
 			count++
 		} else if failed {
-			return "", 0, errThreadBlocked
+			return stopPacket{}, errThreadBlocked
 		} else if err != nil {
-			return "", 0, err
+			return stopPacket{}, err
 		} else {
 			repeat, sp, err := conn.parseStopPacket(resp, threadID, tu)
 			if !repeat {
-				return sp.threadID, sp.sig, err
+				return sp, err
 			}
 		}
 	}
 }
 
 type stopPacket struct {
-	threadID string
-	sig      uint8
-	reason   string
+	threadID  string
+	sig       uint8
+	reason    string
+	watchAddr uint64 // address of the watchpoint that was hit, if any
 }
 
 // executes 'vCont' (continue/step) command
@@ -683,6 +718,7 @@ func (conn *gdbConn) parseStopPacket(resp []byte, threadID string, tu *threadUpd
 			conn.log.Debugf("full stop packet: %s", string(resp))
 		}
 
+		var description []byte
 		buf := resp[3:]
 		for buf != nil {
 			colon := bytes.Index(buf, []byte{':'})
@@ -712,6 +748,21 @@ func (conn *gdbConn) parseStopPacket(resp []byte, threadID string, tu *threadUpd
 				}
 			case "reason":
 				sp.reason = string(value)
+			case "watch", "rwatch", "awatch", "watch_addr":
+				// watch_addr is sent by newer versions of debugserver
+				sp.watchAddr, err = strconv.ParseUint(string(value), 16, 64)
+				if err != nil {
+					return false, stopPacket{}, fmt.Errorf("malformed stop packet: %s (wrong watch address)", string(resp))
+				}
+			case "description":
+				description = value
+			}
+		}
+
+		if sp.reason == "watchpoint" && sp.watchAddr == 0 && description != nil {
+			sp.watchAddr, err = debugserverWatchAddr(description)
+			if err != nil {
+				return false, stopPacket{}, fmt.Errorf("malformed stop packet: %s (wrong watchpoint description)", string(resp))
 			}
 		}
 
@@ -747,6 +798,22 @@ func (conn *gdbConn) parseStopPacket(resp []byte, threadID string, tu *threadUpd
 	}
 }
 
+// debugserverWatchAddr returns the address of the watchpoint that was hit
+// from the description sent by debugserver in stop packets with reason
+// 'watchpoint': an hex encoded string of space separated decimal numbers,
+// the first one is the address of the watchpoint.
+func debugserverWatchAddr(description []byte) (uint64, error) {
+	buf, err := hex.DecodeString(string(description))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(buf))
+	if len(fields) == 0 {
+		return 0, errors.New("empty watchpoint description")
+	}
+	return strconv.ParseUint(fields[0], 10, 64)
+}
+
 const ctrlC = 0x03 // the ASCII character for ^C
 
 // executes a ctrl-C on the line
@@ -967,18 +1034,15 @@ func (conn *gdbConn) allocMemory(sz uint64) (uint64, error) {
 
 // threadStopInfo executes a 'qThreadStopInfo' and returns the reason the
 // thread stopped.
-func (conn *gdbConn) threadStopInfo(threadID string) (sig uint8, reason string, err error) {
+func (conn *gdbConn) threadStopInfo(threadID string) (stopPacket, error) {
 	conn.outbuf.Reset()
 	fmt.Fprintf(&conn.outbuf, "$qThreadStopInfo%s", threadID)
 	resp, err := conn.exec(conn.outbuf.Bytes(), "thread stop info")
 	if err != nil {
-		return 0, "", err
+		return stopPacket{}, err
 	}
 	_, sp, err := conn.parseStopPacket(resp, "", nil)
-	if err != nil {
-		return 0, "", err
-	}
-	return sp.sig, sp.reason, nil
+	return sp, err
 }
 
 // restart executes a 'vRun' command.
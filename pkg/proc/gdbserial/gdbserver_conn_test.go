@@ -0,0 +1,63 @@
+package gdbserial
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+func TestParseStopPacketWatchpoint(t *testing.T) {
+	description := hex.EncodeToString([]byte("3221225488 0 3221225492"))
+	for _, tc := range []struct {
+		resp      string
+		watchAddr uint64
+	}{
+		// gdbserver and rr
+		{"T05thread:p1.1;watch:c0000010;", 0xc0000010},
+		{"T05thread:p1.1;rwatch:c0000010;", 0xc0000010},
+		{"T05thread:p1.1;awatch:c0000010;", 0xc0000010},
+		// debugserver
+		{"T05thread:1a2b;reason:watchpoint;description:" + description + ";", 3221225488},
+		{"T05thread:1a2b;reason:watchpoint;watch_addr:c0000010;description:" + description + ";", 0xc0000010},
+		// not a watchpoint
+		{"T05thread:1a2b;reason:breakpoint;", 0},
+		{"T05thread:p1.1;", 0},
+	} {
+		conn := &gdbConn{}
+		_, sp, err := conn.parseStopPacket([]byte(tc.resp), "", nil)
+		if err != nil {
+			t.Errorf("%s: %v", tc.resp, err)
+			continue
+		}
+		if sp.watchAddr != tc.watchAddr {
+			t.Errorf("%s: wrong watchpoint address %#x, expected %#x", tc.resp, sp.watchAddr, tc.watchAddr)
+		}
+	}
+
+	for _, resp := range []string{
+		"T05thread:p1.1;watch:zzz;",
+		"T05thread:1a2b;reason:watchpoint;description:zz;",
+		"T05thread:1a2b;reason:watchpoint;description:" + hex.EncodeToString([]byte("addr")) + ";",
+	} {
+		conn := &gdbConn{}
+		if _, _, err := conn.parseStopPacket([]byte(resp), "", nil); err == nil {
+			t.Errorf("%s: expected error", resp)
+		}
+	}
+}
+
+func TestWatchpointPacketType(t *testing.T) {
+	for _, tc := range []struct {
+		wtype proc.WatchType
+		tgt   int
+	}{
+		{proc.WatchWrite, 2},
+		{proc.WatchRead, 3},
+		{proc.WatchRead | proc.WatchWrite, 4},
+	} {
+		if got := watchpointPacketType(tc.wtype); got != tc.tgt {
+			t.Errorf("%#x: got %d expected %d", tc.wtype, got, tc.tgt)
+		}
+	}
+}
@@ -21,7 +21,9 @@ import (
 // program. Returns a run function which will actually record the program, a
 // stop function which will prematurely terminate the recording of the
 // program.
-func RecordAsync(cmd []string, wd string, quiet bool, redirects [3]string) (run func() (string, error), stop func() error, err error) {
+// If env is not nil it is used as the environment of the recorded program,
+// otherwise the environment of the current process is inherited.
+func RecordAsync(cmd []string, wd string, quiet bool, redirects [3]string, env []string) (run func() (string, error), stop func() error, err error) {
 	if err := checkRRAvailabe(); err != nil {
 		return nil, nil, err
 	}
@@ -42,6 +44,7 @@ func RecordAsync(cmd []string, wd string, quiet bool, redirects [3]string) (run
 	}
 	rrcmd.ExtraFiles = []*os.File{wfd}
 	rrcmd.Dir = wd
+	rrcmd.Env = env
 
 	tracedirChan := make(chan string)
 	go func() {
@@ -114,7 +117,7 @@ func openRedirects(redirects [3]string, quiet bool) (stdin, stdout, stderr *os.F
 // Record uses rr to record the execution of the specified program and
 // returns the trace directory's path.
 func Record(cmd []string, wd string, quiet bool, redirects [3]string) (tracedir string, err error) {
-	run, _, err := RecordAsync(cmd, wd, quiet, redirects)
+	run, _, err := RecordAsync(cmd, wd, quiet, redirects, nil)
 	if err != nil {
 		return "", err
 	}
@@ -181,6 +181,28 @@ func TestReverseBreakpointCounts(t *testing.T) {
 	})
 }
 
+func TestWatchpointRecording(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestRecording("databpeasy", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.main")
+		assertNoError(p.Continue(), t, "Continue()")
+
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope")
+		bp, err := p.SetWatchpoint(scope, "globalvar1", proc.WatchWrite, nil)
+		assertNoError(err, t, "SetWatchpoint")
+
+		assertNoError(p.Continue(), t, "Continue()")
+		_, loc := getPosition(p, t)
+		if loc.Line != 17 {
+			t.Fatalf("wrong stop location %s:%d", loc.File, loc.Line)
+		}
+		if curbp := p.CurrentThread().Breakpoint().Breakpoint; curbp != bp {
+			t.Fatalf("wrong current breakpoint %#v", curbp)
+		}
+	})
+}
+
 func getPosition(p *proc.Target, t *testing.T) (when string, loc *proc.Location) {
 	var err error
 	when, err = p.When()
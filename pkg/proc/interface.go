@@ -47,6 +47,29 @@ type ProcessInternal interface {
 	DumpProcessNotes(notes []elfwriter.Note, threadDone func()) (bool, []elfwriter.Note, error)
 	// MemoryMap returns the memory map of the target process. This method must be implemented if CanDump is true.
 	MemoryMap() ([]MemoryMapEntry, error)
+
+	// SetHeldThreads sets the threads that ContinueOnce will leave stopped
+	// when it resumes the target, replacing the previous set.
+	// Implementing this method is optional, backends that can not resume
+	// only some of the threads of the target return false.
+	SetHeldThreads(tids []int) bool
+
+	// SetStopSignals sets the signals that stop ContinueOnce, with
+	// StopSignal, when they are received by a thread of the target,
+	// replacing the previous set. The thread that received the signal is
+	// returned as the trap thread and the signal is delivered to it when the
+	// target is resumed.
+	// Implementing this method is optional, backends that can not intercept
+	// signals return false.
+	SetStopSignals(sigs []int) bool
+	// PendingSignal returns the number and the name of the signal that will
+	// be delivered to thread tid when the target is resumed, sig is 0 if
+	// there is none.
+	PendingSignal(tid int) (sig int, name string)
+	// SetPendingSignal changes the signal that will be delivered to thread
+	// tid when the target is resumed, 0 means that no signal is delivered.
+	// Backends that do not implement SetStopSignals return false.
+	SetPendingSignal(tid, sig int) bool
 }
 
 // RecordingManipulation is an interface for manipulating process recordings.
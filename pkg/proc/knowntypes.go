@@ -0,0 +1,320 @@
+package proc
+
+import (
+	"go/constant"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// maxHashTrieMapDepth is the maximum depth of the trie used by sync.Map in
+// Go 1.24 and later, 64 bits of hash consumed 4 bits at a time.
+const maxHashTrieMapDepth = 16
+
+// loadKnownType replaces the children of v, a struct variable whose fields
+// have just been loaded, with a decoded representation of its internal
+// state, if v has one of the types of the sync package known to the
+// debugger:
+//
+//	sync.WaitGroup	the children are replaced by counter and waiters
+//	sync.Once	the children are replaced by done
+//	sync.Map	v is turned into a map[interface {}]interface {} of its entries
+//
+// If the internal state can not be decoded v is left unchanged.
+func (v *Variable) loadKnownType(recurseLevel int, cfg LoadConfig) {
+	if v.Unreadable != nil || v.Addr == 0 {
+		return
+	}
+	switch v.RealType.String() {
+	case "sync.WaitGroup":
+		v.loadWaitGroup()
+	case "sync.Once":
+		v.loadOnce()
+	case "sync.Map":
+		v.loadSyncMap(recurseLevel, cfg)
+	}
+}
+
+// loadWaitGroup decodes the state word of the sync.WaitGroup v.
+func (v *Variable) loadWaitGroup() {
+	var statep uint64
+	if addr, _, ok := fieldAddr(v.RealType, v.Addr, "state"); ok {
+		// Go 1.20 and later
+		statep = addr
+	} else if addr, _, ok := fieldAddr(v.RealType, v.Addr, "state1"); ok {
+		// Go 1.19 and earlier, the state is stored in the 64bit aligned half of
+		// state1 (and state2).
+		statep = addr
+		if statep%8 != 0 {
+			statep += 4
+		}
+	} else {
+		return
+	}
+	state, err := readUintRaw(v.mem, statep, 8)
+	if err != nil {
+		return
+	}
+	counter := newConstant(constant.MakeInt64(int64(int32(state>>32))), v.mem)
+	counter.Name = "counter"
+	waiters := newConstant(constant.MakeUint64(uint64(uint32(state))), v.mem)
+	waiters.Name = "waiters"
+	v.Children = []Variable{*counter, *waiters}
+	v.Len = int64(len(v.Children))
+}
+
+// loadOnce decodes the done flag of the sync.Once v.
+func (v *Variable) loadOnce() {
+	addr, typ, ok := fieldAddr(v.RealType, v.Addr, "done")
+	if !ok {
+		return
+	}
+	// done is either an uint32, a sync/atomic.Uint32 or a sync/atomic.Bool,
+	// all of which store their value at offset 0.
+	n, err := readUintRaw(v.mem, addr, typ.Size())
+	if err != nil {
+		return
+	}
+	done := newConstant(constant.MakeBool(n != 0), v.mem)
+	done.Name = "done"
+	v.Children = []Variable{*done}
+	v.Len = int64(len(v.Children))
+}
+
+// loadSyncMap turns the sync.Map v into a map of its entries.
+func (v *Variable) loadSyncMap(recurseLevel int, cfg LoadConfig) {
+	var children []Variable
+	var n int64
+	var ok bool
+	if m, err := v.structMember("m"); err == nil && strings.Contains(m.RealType.String(), "HashTrieMap[") {
+		children, n, ok = m.loadHashTrieMap(recurseLevel, cfg)
+	} else {
+		children, n, ok = v.loadReadDirtyMap(recurseLevel, cfg)
+	}
+	if !ok {
+		return
+	}
+	anyType, err := v.bi.findType("interface {}")
+	if err != nil {
+		return
+	}
+	// The real type of v becomes map[interface {}]interface {}, so that the
+	// kind of v matches its real type, but there is no runtime map header
+	// behind it: operations that need one, like indexing, fail.
+	v.RealType = &godwarf.MapType{
+		TypedefType: godwarf.TypedefType{
+			CommonType: godwarf.CommonType{ByteSize: v.RealType.Size(), Name: "map[interface {}]interface {}", ReflectKind: reflect.Map},
+			Type:       &godwarf.VoidType{},
+		},
+		KeyType:  anyType,
+		ElemType: anyType,
+	}
+	v.Kind = reflect.Map
+	v.Base = v.Addr // a sync.Map is never nil
+	v.Children = children
+	v.Len = n
+}
+
+// loadReadDirtyMap loads the entries of the sync.Map v as implemented up
+// to Go 1.23, with a read-only map and a dirty map.
+func (v *Variable) loadReadDirtyMap(recurseLevel int, cfg LoadConfig) ([]Variable, int64, bool) {
+	mem := DereferenceMemory(v.mem)
+	ptrSize := int64(v.bi.Arch.PtrSize())
+
+	var expunged uint64
+	if ev, err := globalScope(v.bi, v.bi.Images[0], mem).EvalExpression("sync.expunged", loadSingleValue); err == nil && len(ev.Children) == 1 {
+		expunged = ev.Children[0].Addr
+	}
+
+	readv, err := v.structMember("read")
+	if err != nil {
+		return nil, 0, false
+	}
+	readv, err = readv.structMember("v")
+	if err != nil {
+		return nil, 0, false
+	}
+	var ro *Variable
+	switch readv.Kind {
+	case reflect.Interface:
+		// Go 1.19 and earlier, read is an atomic.Value containing a readOnly.
+		readv.loadInterface(0, false, LoadConfig{})
+		if readv.Unreadable != nil || len(readv.Children) == 0 {
+			return nil, 0, false
+		}
+		ro = &readv.Children[0]
+	case reflect.UnsafePointer:
+		// Go 1.20 and later, read is an atomic.Pointer[readOnly].
+		typ, err := v.bi.findType("sync.readOnly")
+		if err != nil {
+			return nil, 0, false
+		}
+		addr, err := readUintRaw(v.mem, readv.Addr, ptrSize)
+		if err != nil {
+			return nil, 0, false
+		}
+		ro = v.newVariable("", addr, typ, mem)
+	default:
+		return nil, 0, false
+	}
+
+	var mapv *Variable
+	if ro.Addr != 0 && ro.Kind == reflect.Struct {
+		if amended := ro.loadFieldNamed("amended"); amended != nil && !constant.BoolVal(amended.Value) {
+			mapv, _ = ro.structMember("m")
+		}
+	}
+	if mapv == nil {
+		// the dirty map, when it isn't nil, contains all entries of the
+		// read-only map that have not been deleted.
+		mapv, err = v.structMember("dirty")
+		if err != nil {
+			return nil, 0, false
+		}
+	}
+
+	it := mapv.mapIterator()
+	if it == nil {
+		return nil, 0, mapv.Unreadable == nil
+	}
+	it.maxNumBuckets = uint64(cfg.MaxMapBuckets)
+
+	var children []Variable
+	n := int64(0)
+	for it.next() {
+		key := it.key()
+		entry, err := readUintRaw(mem, it.value().Addr, ptrSize)
+		if err != nil || entry == 0 {
+			continue
+		}
+		// the field p of sync.entry, an unsafe.Pointer or an
+		// atomic.Pointer[any], is stored at offset 0.
+		p, err := readUintRaw(mem, entry, ptrSize)
+		if err != nil || p == 0 || p == expunged {
+			continue
+		}
+		n++
+		if len(children)/2 >= cfg.MaxArrayValues || recurseLevel > cfg.MaxVariableRecurse {
+			continue
+		}
+		val := v.newVariable("", p, key.DwarfType, mem)
+		key.loadValueInternal(recurseLevel+1, elementLoadConfig(key.DwarfType, cfg))
+		val.loadValueInternal(recurseLevel+1, elementLoadConfig(val.DwarfType, cfg))
+		children = append(children, *key, *val)
+	}
+	return children, n, true
+}
+
+// loadHashTrieMap loads the entries of v, the internal/sync.HashTrieMap
+// used to implement sync.Map in Go 1.24 and later.
+func (v *Variable) loadHashTrieMap(recurseLevel int, cfg LoadConfig) ([]Variable, int64, bool) {
+	mem := DereferenceMemory(v.mem)
+	ptrSize := int64(v.bi.Arch.PtrSize())
+
+	name := v.RealType.String()
+	i := strings.Index(name, "HashTrieMap[")
+	pkg, typeArgs := name[:i], name[i+len("HashTrieMap"):]
+	indirectType, err := v.bi.findType(pkg + "indirect" + typeArgs)
+	if err != nil {
+		return nil, 0, false
+	}
+	entryType, err := v.bi.findType(pkg + "entry" + typeArgs)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	rootAddr, _, ok := fieldAddr(v.RealType, v.Addr, "root")
+	if !ok {
+		return nil, 0, false
+	}
+	childrenAddr, _, ok := fieldAddr(indirectType, 0, "children")
+	if !ok {
+		return nil, 0, false
+	}
+	// Both indirect and entry embed, as their first field, a node struct
+	// whose only field is isEntry.
+	const isEntryAddr = 0
+	overflowAddr, _, ok := fieldAddr(entryType, 0, "overflow")
+	if !ok {
+		return nil, 0, false
+	}
+	keyAddr, keyType, ok := fieldAddr(entryType, 0, "key")
+	if !ok {
+		return nil, 0, false
+	}
+	valueAddr, valueType, ok := fieldAddr(entryType, 0, "value")
+	if !ok {
+		return nil, 0, false
+	}
+
+	var children []Variable
+	n := int64(0)
+
+	var visit func(indirect uint64, depth int) bool
+	visit = func(indirect uint64, depth int) bool {
+		if depth > maxHashTrieMapDepth {
+			return false
+		}
+		for i := 0; i < 16; i++ {
+			node, err := readUintRaw(mem, indirect+childrenAddr+uint64(int64(i)*ptrSize), ptrSize)
+			if err != nil {
+				return false
+			}
+			if node == 0 {
+				continue
+			}
+			isEntry, err := readUintRaw(mem, node+isEntryAddr, 1)
+			if err != nil {
+				return false
+			}
+			if isEntry == 0 {
+				if !visit(node, depth+1) {
+					return false
+				}
+				continue
+			}
+			for entry := node; entry != 0; {
+				n++
+				if len(children)/2 < cfg.MaxArrayValues && recurseLevel <= cfg.MaxVariableRecurse {
+					key := v.newVariable("", entry+keyAddr, keyType, mem)
+					val := v.newVariable("", entry+valueAddr, valueType, mem)
+					key.loadValueInternal(recurseLevel+1, elementLoadConfig(keyType, cfg))
+					val.loadValueInternal(recurseLevel+1, elementLoadConfig(valueType, cfg))
+					children = append(children, *key, *val)
+				}
+				entry, err = readUintRaw(mem, entry+overflowAddr, ptrSize)
+				if err != nil {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	root, err := readUintRaw(mem, rootAddr, ptrSize)
+	if err != nil {
+		return nil, 0, false
+	}
+	if root != 0 && !visit(root, 0) {
+		return nil, 0, false
+	}
+	return children, n, true
+}
+
+// fieldAddr returns the address and the type of field name of a struct of
+// type typ stored at addr. Fields of type sync/atomic.Pointer[T] and
+// similar atomic types store their value at offset 0 so their address can
+// be used to read the value directly.
+func fieldAddr(typ godwarf.Type, addr uint64, name string) (uint64, godwarf.Type, bool) {
+	st, ok := resolveTypedef(typ).(*godwarf.StructType)
+	if !ok {
+		return 0, nil, false
+	}
+	for _, field := range st.Field {
+		if field.Name == name {
+			return addr + uint64(field.ByteOffset), field.Type, true
+		}
+	}
+	return 0, nil, false
+}
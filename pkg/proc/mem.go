@@ -180,6 +180,34 @@ func (mem *compositeMemory) WriteMemory(addr uint64, data []byte) (int, error) {
 	return len(data), nil
 }
 
+// overlayMemory reads the address range of a compositeMemory from the
+// compositeMemory itself and every other address from the memory of the
+// target process.
+// It is used as the backing memory of slices whose elements only exist
+// inside the debugger, unlike compositeMemory it is not replaced by
+// DereferenceMemory so that the elements of the slice can be reached.
+type overlayMemory struct {
+	cmem *compositeMemory
+}
+
+func (mem *overlayMemory) contains(addr uint64, size int) bool {
+	return addr >= mem.cmem.base && addr+uint64(size) <= mem.cmem.base+uint64(len(mem.cmem.data))
+}
+
+func (mem *overlayMemory) ReadMemory(data []byte, addr uint64) (int, error) {
+	if mem.contains(addr, len(data)) {
+		return mem.cmem.ReadMemory(data, addr)
+	}
+	return mem.cmem.realmem.ReadMemory(data, addr)
+}
+
+func (mem *overlayMemory) WriteMemory(addr uint64, data []byte) (int, error) {
+	if mem.contains(addr, len(data)) {
+		return 0, errors.New("can not write to the elements of a field projection")
+	}
+	return mem.cmem.realmem.WriteMemory(addr, data)
+}
+
 // DereferenceMemory returns a MemoryReadWriter that can read and write the
 // memory pointed to by pointers in this memory.
 // Normally mem and mem.Dereference are the same object, they are different
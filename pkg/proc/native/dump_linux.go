@@ -55,7 +55,10 @@ smapsLinesLoop:
 			}
 		}
 		if strings.HasPrefix(dev, "00:") {
-			filename = ""
+			// Keep the names of special regions, like [heap] and [stack].
+			if !strings.HasPrefix(filename, "[") {
+				filename = ""
+			}
 			offset = 0
 		}
 
@@ -13,7 +13,7 @@ import (
 var ErrNativeBackendDisabled = errors.New("native backend disabled during compilation")
 
 // Launch returns ErrNativeBackendDisabled.
-func Launch(_ []string, _ string, _ proc.LaunchFlags, _ []string, _ string, _ [3]string) (*proc.Target, error) {
+func Launch(_ []string, _ string, _ proc.LaunchFlags, _ []string, _ string, _ [3]string, _ []string) (*proc.Target, error) {
 	return nil, ErrNativeBackendDisabled
 }
 
@@ -56,6 +56,26 @@ func (dbp *nativeProcess) resume() error {
 	panic(ErrNativeBackendDisabled)
 }
 
+// SetHeldThreads is not supported on this platform.
+func (dbp *nativeProcess) SetHeldThreads(tids []int) bool {
+	panic(ErrNativeBackendDisabled)
+}
+
+// SetStopSignals is not supported on this platform.
+func (dbp *nativeProcess) SetStopSignals(sigs []int) bool {
+	panic(ErrNativeBackendDisabled)
+}
+
+// PendingSignal is not supported on this platform.
+func (dbp *nativeProcess) PendingSignal(tid int) (int, string) {
+	panic(ErrNativeBackendDisabled)
+}
+
+// SetPendingSignal is not supported on this platform.
+func (dbp *nativeProcess) SetPendingSignal(tid, sig int) bool {
+	panic(ErrNativeBackendDisabled)
+}
+
 func (dbp *nativeProcess) trapWait(pid int) (*nativeThread, error) {
 	panic(ErrNativeBackendDisabled)
 }
@@ -253,6 +253,7 @@ func (dbp *nativeProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 
 		for _, th := range dbp.threads {
 			th.CurrentBreakpoint.Clear()
+			th.signaled = false
 		}
 
 		if dbp.resumeChan != nil {
@@ -270,6 +271,9 @@ func (dbp *nativeProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 		}
 		if trapthread != nil {
 			dbp.memthread = trapthread
+			if trapthread.signaled {
+				return trapthread, proc.StopSignal, nil
+			}
 			return trapthread, proc.StopUnknown, nil
 		}
 	}
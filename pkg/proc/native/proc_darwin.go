@@ -38,7 +38,11 @@ type osProcessDetails struct {
 // custom fork/exec process in order to take advantage of
 // PT_SIGEXC on Darwin which will turn Unix signals into
 // Mach exceptions.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, _ [3]string) (*proc.Target, error) {
+// Changing the environment of the target, with env, is not supported.
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, _ [3]string, env []string) (*proc.Target, error) {
+	if len(env) > 0 {
+		return nil, errors.New("the native backend does not support setting the environment of the target on macOS, use the lldb backend")
+	}
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -409,6 +413,26 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 	return err
 }
 
+// SetHeldThreads is not supported on this platform.
+func (dbp *nativeProcess) SetHeldThreads(tids []int) bool {
+	return false
+}
+
+// SetStopSignals is not supported on this platform.
+func (dbp *nativeProcess) SetStopSignals(sigs []int) bool {
+	return false
+}
+
+// PendingSignal is not supported on this platform.
+func (dbp *nativeProcess) PendingSignal(tid int) (int, string) {
+	return 0, ""
+}
+
+// SetPendingSignal is not supported on this platform.
+func (dbp *nativeProcess) SetPendingSignal(tid, sig int) bool {
+	return false
+}
+
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
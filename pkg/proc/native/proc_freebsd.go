@@ -42,7 +42,7 @@ type osProcessDetails struct {
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string, env []string) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -74,7 +74,7 @@ func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []str
 		process.Stdout = stdout
 		process.Stderr = stderr
 		process.SysProcAttr = &syscall.SysProcAttr{Ptrace: true, Setpgid: true, Foreground: foreground}
-		process.Env = proc.DisableAsyncPreemptEnv()
+		process.Env = proc.DisableAsyncPreemptEnv(env)
 		if foreground {
 			signal.Ignore(syscall.SIGTTOU, syscall.SIGTTIN)
 		}
@@ -328,6 +328,26 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 	return err
 }
 
+// SetHeldThreads is not supported on this platform.
+func (dbp *nativeProcess) SetHeldThreads(tids []int) bool {
+	return false
+}
+
+// SetStopSignals is not supported on this platform.
+func (dbp *nativeProcess) SetStopSignals(sigs []int) bool {
+	return false
+}
+
+// PendingSignal is not supported on this platform.
+func (dbp *nativeProcess) PendingSignal(tid int) (int, string) {
+	return 0, ""
+}
+
+// SetPendingSignal is not supported on this platform.
+func (dbp *nativeProcess) SetPendingSignal(tid, sig int) bool {
+	return false
+}
+
 // Used by ContinueOnce
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
@@ -45,6 +45,9 @@ const (
 // process details.
 type osProcessDetails struct {
 	comm string
+
+	heldThreads map[int]bool // threads that resume will not resume
+	stopSignals map[int]bool // signals that stop the target instead of being delivered
 }
 
 // Launch creates and begins debugging a new process. First entry in
@@ -52,7 +55,9 @@ type osProcessDetails struct {
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string) (*proc.Target, error) {
+// If env is not nil it is used as the environment of the new process,
+// otherwise the environment of the current process is inherited.
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []string, tty string, redirects [3]string, env []string) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -92,6 +97,7 @@ func Launch(cmd []string, wd string, flags proc.LaunchFlags, debugInfoDirs []str
 		process.Stdin = stdin
 		process.Stdout = stdout
 		process.Stderr = stderr
+		process.Env = env
 		process.SysProcAttr = &syscall.SysProcAttr{
 			Ptrace:     true,
 			Setpgid:    true,
@@ -405,6 +411,23 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 			return th, nil
 		}
 
+		if sig := int(status.StopSignal()); dbp.os.stopSignals[sig] {
+			// The signal is delivered when the thread is resumed, unless it is
+			// changed with SetPendingSignal.
+			th.os.delayedSignal = sig
+			th.signaled = true
+			if halt && th.os.running {
+				// We sent this thread a STOP signal, resume it so that we can
+				// observe it.
+				if err := th.resumeWithSig(0); err != nil && err != sys.ESRCH {
+					return nil, err
+				}
+				continue
+			}
+			th.os.running = false
+			return th, nil
+		}
+
 		// TODO(dp) alert user about unexpected signals here.
 		if halt && !th.os.running {
 			// We are trying to stop the process, queue this signal to be delivered
@@ -501,9 +524,57 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 	return err
 }
 
+// SetHeldThreads sets the threads that will be left stopped by resume.
+func (dbp *nativeProcess) SetHeldThreads(tids []int) bool {
+	dbp.os.heldThreads = make(map[int]bool, len(tids))
+	for _, tid := range tids {
+		dbp.os.heldThreads[tid] = true
+	}
+	return true
+}
+
+// SetStopSignals sets the signals that stop the target instead of being
+// delivered to it.
+func (dbp *nativeProcess) SetStopSignals(sigs []int) bool {
+	dbp.os.stopSignals = make(map[int]bool, len(sigs))
+	for _, sig := range sigs {
+		dbp.os.stopSignals[sig] = true
+	}
+	return true
+}
+
+// PendingSignal returns the signal that will be delivered to thread tid
+// when it is resumed.
+func (dbp *nativeProcess) PendingSignal(tid int) (int, string) {
+	th := dbp.threads[tid]
+	if th == nil || th.os.delayedSignal == 0 {
+		return 0, ""
+	}
+	return th.os.delayedSignal, sys.SignalName(sys.Signal(th.os.delayedSignal))
+}
+
+// SetPendingSignal changes the signal that will be delivered to thread tid
+// when it is resumed.
+func (dbp *nativeProcess) SetPendingSignal(tid, sig int) bool {
+	th := dbp.threads[tid]
+	if th == nil {
+		return false
+	}
+	th.os.delayedSignal = sig
+	return true
+}
+
 func (dbp *nativeProcess) resume() error {
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
+		if dbp.os.heldThreads[thread.ID] {
+			// the breakpoint a held thread is stopped at was already reported,
+			// it is kept so that the thread steps over it once it is released.
+			bp := thread.CurrentBreakpoint.Breakpoint
+			thread.CurrentBreakpoint.Clear()
+			thread.CurrentBreakpoint.Breakpoint = bp
+			continue
+		}
 		if thread.CurrentBreakpoint.Breakpoint != nil {
 			if err := thread.StepInstruction(); err != nil {
 				return err
@@ -511,8 +582,11 @@ func (dbp *nativeProcess) resume() error {
 			thread.CurrentBreakpoint.Clear()
 		}
 	}
-	// everything is resumed
+	// everything, except held threads, is resumed
 	for _, thread := range dbp.threads {
+		if dbp.os.heldThreads[thread.ID] {
+			continue
+		}
 		if err := thread.resume(); err != nil && err != sys.ESRCH {
 			return err
 		}
@@ -529,7 +603,7 @@ func (dbp *nativeProcess) stop(trapthread *nativeThread) (*nativeThread, error)
 	for _, th := range dbp.threads {
 		th.os.setbp = false
 	}
-	trapthread.os.setbp = true
+	trapthread.os.setbp = !trapthread.signaled
 
 	// check if any other thread simultaneously received a SIGTRAP
 	for {
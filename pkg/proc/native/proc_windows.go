@@ -21,13 +21,13 @@ type osProcessDetails struct {
 }
 
 // Launch creates and begins debugging a new process.
-func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, redirects [3]string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, flags proc.LaunchFlags, _ []string, _ string, redirects [3]string, env []string) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
 	}
 
-	env := proc.DisableAsyncPreemptEnv()
+	env = proc.DisableAsyncPreemptEnv(env)
 
 	stdin, stdout, stderr, closefn, err := openRedirects(redirects, true)
 	if err != nil {
@@ -390,6 +390,26 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 	return err
 }
 
+// SetHeldThreads is not supported on this platform.
+func (dbp *nativeProcess) SetHeldThreads(tids []int) bool {
+	return false
+}
+
+// SetStopSignals is not supported on this platform.
+func (dbp *nativeProcess) SetStopSignals(sigs []int) bool {
+	return false
+}
+
+// PendingSignal is not supported on this platform.
+func (dbp *nativeProcess) PendingSignal(tid int) (int, string) {
+	return 0, ""
+}
+
+// SetPendingSignal is not supported on this platform.
+func (dbp *nativeProcess) SetPendingSignal(tid, sig int) bool {
+	return false
+}
+
 func (dbp *nativeProcess) resume() error {
 	for _, thread := range dbp.threads {
 		if thread.CurrentBreakpoint.Breakpoint != nil {
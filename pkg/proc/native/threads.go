@@ -18,6 +18,7 @@ type nativeThread struct {
 
 	dbp            *nativeProcess
 	singleStepping bool
+	signaled       bool // the thread was stopped by one of the signals set with SetStopSignals
 	os             *osSpecificDetails
 	common         proc.CommonThread
 }
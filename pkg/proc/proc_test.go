@@ -35,7 +35,7 @@ import (
 	"github.com/go-delve/delve/service/api"
 )
 
-var normalLoadConfig = proc.LoadConfig{true, 1, 64, 64, -1, 0}
+var normalLoadConfig = proc.LoadConfig{true, 1, 64, 64, -1, 0, 0, false}
 var testBackend, buildMode string
 
 func init() {
@@ -101,9 +101,9 @@ func withTestProcessArgs(name string, t testing.TB, wd string, args []string, bu
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{}, nil)
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{}, nil)
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
@@ -1477,6 +1477,73 @@ func TestBreakpointCounts(t *testing.T) {
 	})
 }
 
+func TestBreakpointFirstPerGoroutine(t *testing.T) {
+	skipOn(t, "broken", "freebsd")
+	protest.AllowRecording(t)
+	withTestProcess("bpcountstest", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFileBreakpoint(p, t, fixture.Source, 12)
+		bp.FirstPerGoroutine = true
+
+		seen := map[int]bool{}
+		for {
+			if err := p.Continue(); err != nil {
+				if _, exited := err.(proc.ErrProcessExited); exited {
+					break
+				}
+				assertNoError(err, t, "Continue()")
+			}
+			g, err := proc.GetG(p.CurrentThread())
+			assertNoError(err, t, "GetG()")
+			if seen[g.ID] {
+				t.Fatalf("stopped twice on goroutine %d", g.ID)
+			}
+			seen[g.ID] = true
+		}
+
+		if len(seen) != 2 {
+			t.Fatalf("wrong number of stops %d (expected 2)", len(seen))
+		}
+		if bp.TotalHitCount != 200 {
+			t.Fatalf("wrong TotalHitCount %d (expected 200)", bp.TotalHitCount)
+		}
+	})
+}
+
+func TestBreakpointFirstPerGoroutineAssert(t *testing.T) {
+	// Hits deactivated by other checks do not count as the first hit of a
+	// goroutine.
+	skipOn(t, "broken", "freebsd")
+	protest.AllowRecording(t)
+	withTestProcess("bpcountstest", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFileBreakpoint(p, t, fixture.Source, 12)
+		bp.FirstPerGoroutine = true
+		bp.Assert = &ast.BinaryExpr{
+			Op: token.LSS,
+			X:  &ast.Ident{Name: "i"},
+			Y:  &ast.BasicLit{Kind: token.INT, Value: "50"},
+		}
+
+		stops := 0
+		for {
+			if err := p.Continue(); err != nil {
+				if _, exited := err.(proc.ErrProcessExited); exited {
+					break
+				}
+				assertNoError(err, t, "Continue()")
+			}
+			stops++
+			ivar := evalVariable(p, t, "i")
+			if i, _ := constant.Int64Val(ivar.Value); i != 50 {
+				t.Fatalf("stopped on wrong iteration %d (expected 50)", i)
+			}
+		}
+
+		if stops != 2 {
+			t.Fatalf("wrong number of stops %d (expected 2)", stops)
+		}
+	})
+}
+
 func BenchmarkArray(b *testing.B) {
 	// each bencharr struct is 128 bytes, bencharr is 64 elements long
 	b.SetBytes(int64(64 * 128))
@@ -1787,6 +1854,169 @@ func TestHitCondBreakpointREM(t *testing.T) {
 	})
 }
 
+func TestBreakpointSampleRate(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFileBreakpoint(p, t, fixture.Source, 7)
+		bp.SampleRate = 3
+
+		for it := 3; it <= 10; it += 3 {
+			assertNoError(p.Continue(), t, "Continue()")
+			ivar := evalVariable(p, t, "i")
+
+			i, _ := constant.Int64Val(ivar.Value)
+			if int(i) != it {
+				t.Fatalf("Stopped on wrong hitcount %d (expected %d)\n", i, it)
+			}
+		}
+
+		err := p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("Unexpected error on Continue(): %v", err)
+		}
+	})
+}
+
+func TestBreakpointSampleRateLogical(t *testing.T) {
+	// SampleRate counts the hits of all the physical breakpoints of a
+	// logical breakpoint together.
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp1 := setFileBreakpoint(p, t, fixture.Source, 6)
+		addrs, err := proc.FindFileLocation(p, fixture.Source, 7)
+		assertNoError(err, t, "FindFileLocation()")
+		bp2, err := p.SetBreakpointWithID(bp1.LogicalID, addrs[0])
+		assertNoError(err, t, "SetBreakpointWithID()")
+		bp1.SampleRate = 2
+		bp2.SampleRate = 2
+
+		// lines 6 and 7 are hit alternately, only the hits on line 7 are
+		// selected
+		for it := int64(1); it <= 3; it++ {
+			assertNoError(p.Continue(), t, "Continue()")
+			loc, err := p.CurrentThread().Location()
+			assertNoError(err, t, "Location()")
+			ivar := evalVariable(p, t, "i")
+			if i, _ := constant.Int64Val(ivar.Value); loc.Line != 7 || i != it {
+				t.Fatalf("Stopped at line %d with i = %d (expected line 7 with i = %d)", loc.Line, i, it)
+			}
+		}
+	})
+}
+
+func TestBreakpointMinHitInterval(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFileBreakpoint(p, t, fixture.Source, 7)
+		bp.MinHitInterval = time.Hour
+
+		assertNoError(p.Continue(), t, "Continue()")
+		ivar := evalVariable(p, t, "i")
+		if i, _ := constant.Int64Val(ivar.Value); i != 1 {
+			t.Fatalf("Stopped on wrong iteration %d (expected 1)", i)
+		}
+
+		// all the following hits are within MinHitInterval of the first one
+		err := p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("Unexpected error on Continue(): %v", err)
+		}
+		if bp.TotalHitCount != 11 {
+			t.Fatalf("wrong hit count %d (expected 11)", bp.TotalHitCount)
+		}
+	})
+}
+
+func TestBreakpointMinHitIntervalLogical(t *testing.T) {
+	// MinHitInterval applies to all the physical breakpoints of a logical
+	// breakpoint together.
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp1 := setFileBreakpoint(p, t, fixture.Source, 6)
+		addrs, err := proc.FindFileLocation(p, fixture.Source, 7)
+		assertNoError(err, t, "FindFileLocation()")
+		bp2, err := p.SetBreakpointWithID(bp1.LogicalID, addrs[0])
+		assertNoError(err, t, "SetBreakpointWithID()")
+		bp1.MinHitInterval = time.Hour
+		bp2.MinHitInterval = time.Hour
+
+		assertNoError(p.Continue(), t, "Continue()")
+		if loc, _ := p.CurrentThread().Location(); loc == nil || loc.Line != 6 {
+			t.Fatalf("Stopped at wrong location %v (expected line 6)", loc)
+		}
+
+		err = p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("Unexpected error on Continue(): %v", err)
+		}
+	})
+}
+
+func TestBreakpointConditionChanged(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp := setFileBreakpoint(p, t, fixture.Source, 7)
+		bp.Cond = &ast.CallExpr{
+			Fun: &ast.Ident{Name: "changed"},
+			Args: []ast.Expr{&ast.BinaryExpr{
+				Op: token.QUO,
+				X:  &ast.Ident{Name: "i"},
+				Y:  &ast.BasicLit{Kind: token.INT, Value: "4"},
+			}},
+		}
+
+		// i/4 changes when i is 4 and 8, the first hit only records its value
+		for _, tgt := range []int64{4, 8} {
+			assertNoError(p.Continue(), t, "Continue()")
+			ivar := evalVariable(p, t, "i")
+			if i, _ := constant.Int64Val(ivar.Value); i != tgt {
+				t.Fatalf("Stopped on wrong iteration %d (expected %d)", i, tgt)
+			}
+		}
+
+		scope, err := proc.GoroutineScope(p, p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		_, err = scope.EvalExpression("changed(i)", normalLoadConfig)
+		if err == nil {
+			t.Fatal("changed evaluated outside of a breakpoint condition")
+		}
+
+		err = p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("Unexpected error on Continue(): %v", err)
+		}
+	})
+}
+
+func TestBreakpointConditionChangedLogical(t *testing.T) {
+	// the values recorded by changed are shared by all the physical
+	// breakpoints of a logical breakpoint.
+	protest.AllowRecording(t)
+	withTestProcess("break", t, func(p *proc.Target, fixture protest.Fixture) {
+		bp1 := setFileBreakpoint(p, t, fixture.Source, 6)
+		addrs, err := proc.FindFileLocation(p, fixture.Source, 7)
+		assertNoError(err, t, "FindFileLocation()")
+		bp2, err := p.SetBreakpointWithID(bp1.LogicalID, addrs[0])
+		assertNoError(err, t, "SetBreakpointWithID()")
+		for _, bp := range []*proc.Breakpoint{bp1, bp2} {
+			bp.Cond = &ast.CallExpr{
+				Fun:  &ast.Ident{Name: "changed"},
+				Args: []ast.Expr{&ast.Ident{Name: "i"}},
+			}
+		}
+
+		// i changes between every hit on line 6 and the following hit on
+		// line 7, the first hit only records its value
+		assertNoError(p.Continue(), t, "Continue()")
+		loc, err := p.CurrentThread().Location()
+		assertNoError(err, t, "Location()")
+		ivar := evalVariable(p, t, "i")
+		if i, _ := constant.Int64Val(ivar.Value); loc.Line != 7 || i != 1 {
+			t.Fatalf("Stopped at line %d with i = %d (expected line 7 with i = 1)", loc.Line, i)
+		}
+	})
+}
+
 func TestIssue356(t *testing.T) {
 	// slice with a typedef does not get printed correctly
 	protest.AllowRecording(t)
@@ -2162,9 +2392,9 @@ func TestUnsupportedArch(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch([]string{outfile}, ".", 0, []string{}, "", [3]string{})
+		p, err = native.Launch([]string{outfile}, ".", 0, []string{}, "", [3]string{}, nil)
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", 0, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", 0, []string{}, "", [3]string{}, nil)
 	default:
 		t.Skip("test not valid for this backend")
 	}
@@ -2743,7 +2973,7 @@ func BenchmarkTrace(b *testing.B) {
 			assertNoError(p.Continue(), b, "Continue()")
 			s, err := proc.GoroutineScope(p, p.CurrentThread())
 			assertNoError(err, b, "Scope()")
-			_, err = s.FunctionArguments(proc.LoadConfig{false, 0, 64, 0, 3, 0})
+			_, err = s.FunctionArguments(proc.LoadConfig{false, 0, 64, 0, 3, 0, 0, false})
 			assertNoError(err, b, "FunctionArguments()")
 		}
 		b.StopTimer()
@@ -4457,6 +4687,40 @@ func TestCallConcurrent(t *testing.T) {
 	})
 }
 
+func TestAbortFunctionCall(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestProcess("fncallblock", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		g := p.SelectedGoroutine()
+
+		if err := p.AbortFunctionCall(g); err == nil {
+			t.Fatal("AbortFunctionCall succeeded without a function call in progress")
+		}
+
+		stop := time.AfterFunc(500*time.Millisecond, func() { p.RequestManualStop() })
+		assertNoError(proc.EvalExpressionWithCalls(p, g, "spin()", normalLoadConfig, true), t, "EvalExpressionWithCalls(spin())")
+		stop.Stop()
+		if len(p.FunctionCallGoroutines()) == 0 {
+			t.Fatal("spin() returned")
+		}
+
+		assertNoError(p.AbortFunctionCall(g), t, "AbortFunctionCall()")
+		if err := p.Continue(); err != proc.ErrFunctionCallAborted {
+			t.Fatalf("wrong error after aborting the call: %v", err)
+		}
+		if gids := p.FunctionCallGoroutines(); len(gids) != 0 {
+			t.Fatalf("function call still in progress on %v", gids)
+		}
+
+		g = p.SelectedGoroutine()
+		assertNoError(proc.EvalExpressionWithCalls(p, g, "quick()", normalLoadConfig, true), t, "EvalExpressionWithCalls(quick())")
+		retvals := p.CurrentThread().Common().ReturnValues(normalLoadConfig)
+		if len(retvals) != 1 || constant.Compare(retvals[0].Value, token.NEQ, constant.MakeInt64(42)) {
+			t.Fatalf("wrong return values after aborting a call: %v", retvals)
+		}
+	})
+}
+
 func TestPluginStepping(t *testing.T) {
 	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
 
@@ -5259,8 +5523,8 @@ func TestWatchpointsBasic(t *testing.T) {
 	skipOn(t, "not implemented", "darwin")
 	skipOn(t, "not implemented", "386")
 	skipOn(t, "not implemented", "arm64")
-	skipOn(t, "not implemented", "rr")
 
+	protest.AllowRecording(t)
 	withTestProcess("databpeasy", t, func(p *proc.Target, fixture protest.Fixture) {
 		setFunctionBreakpoint(p, t, "main.main")
 		assertNoError(p.Continue(), t, "Continue 0")
@@ -5305,7 +5569,6 @@ func TestWatchpointCounts(t *testing.T) {
 	skipOn(t, "not implemented", "darwin")
 	skipOn(t, "not implemented", "386")
 	skipOn(t, "not implemented", "arm64")
-	skipOn(t, "not implemented", "rr")
 
 	protest.AllowRecording(t)
 	withTestProcess("databpcountstest", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -1,6 +1,8 @@
 package proc
 
 import (
+	"bytes"
+	"go/parser"
 	"testing"
 )
 
@@ -96,3 +98,118 @@ func TestConvertInt(t *testing.T) {
 		}
 	}
 }
+
+func TestRewritePseudoVariables(t *testing.T) {
+	for _, tc := range []struct{ in, tgt string }{
+		{"a + b", "a + b"},
+		{"$GOOS", pseudoVarPrefix + "GOOS"},
+		{"$GOOS == \"linux\" && $GOARCH != \"$GOOS\"", pseudoVarPrefix + "GOOS == \"linux\" && " + pseudoVarPrefix + "GOARCH != \"$GOOS\""},
+		{"'$' == c", "'$' == c"},
+		{"$ + 1", "$ + 1"},
+	} {
+		if out := rewritePseudoVariables(tc.in); out != tc.tgt {
+			t.Errorf("rewritePseudoVariables(%q) = %q, expected %q", tc.in, out, tc.tgt)
+		}
+	}
+}
+
+func TestRewriteFieldProjections(t *testing.T) {
+	for _, tc := range []struct{ in, tgt string }{
+		{"a.b", "a.b"},
+		{"a.#b", "a." + fieldProjectionPrefix + "b"},
+		{"len(a.#b) + c.d.#e[1]", "len(a." + fieldProjectionPrefix + "b) + c.d." + fieldProjectionPrefix + "e[1]"},
+		{"a + \".#b\"", "a + \".#b\""},
+		{"a.# b", "a.# b"},
+	} {
+		if out := rewriteFieldProjections(tc.in); out != tc.tgt {
+			t.Errorf("rewriteFieldProjections(%q) = %q, expected %q", tc.in, out, tc.tgt)
+		}
+	}
+}
+
+func TestIsCommaOk(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		tgt string
+	}{
+		{"x.(*main.T), ok", "x.(*main.T)"},
+		{"a.b.(int) ,ok ", "a.b.(int)"},
+		{"x.(*main.T), found", ""},
+		{"x, ok", ""},
+		{"x.(*main.T), ok, ok", ""},
+	} {
+		_, err := parser.ParseExpr(tc.in)
+		ta, isCommaOk := isCommaOk(tc.in, err)
+		out := ""
+		if isCommaOk {
+			out = exprToString(ta)
+		}
+		if out != tc.tgt {
+			t.Errorf("isCommaOk(%q) = %q, expected %q", tc.in, out, tc.tgt)
+		}
+	}
+}
+
+func TestParseRegisterName(t *testing.T) {
+	for _, tc := range []struct {
+		name, regname, lanetyp string
+		lane                   int
+		err                    bool
+	}{
+		{"RSP", "rsp", "", 0, false},
+		{"__RAX", "rax", "", 0, false},
+		{"XMM0.float32[1]", "xmm0", "float32", 1, false},
+		{"XMM3.UINT8[15]", "xmm3", "uint8", 15, false},
+		{"XMM0.float32", "", "", 0, true},
+		{"XMM0.float32[-1]", "", "", 0, true},
+		{"XMM0.float32[x]", "", "", 0, true},
+	} {
+		regname, lanetyp, lane, err := parseRegisterName(tc.name)
+		if (err != nil) != tc.err {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if regname != tc.regname || lanetyp != tc.lanetyp || lane != tc.lane {
+			t.Errorf("%s: got %q %q %d, expected %q %q %d", tc.name, regname, lanetyp, lane, tc.regname, tc.lanetyp, tc.lane)
+		}
+	}
+}
+
+func TestSetRegisterLane(t *testing.T) {
+	buf := make([]byte, 16)
+	if err := setRegisterLane(buf, "float32", 1, "1.5"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setRegisterLane(buf, "int8", 15, "-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setRegisterLane(buf, "uint16", 0, "0x1234"); err != nil {
+		t.Fatal(err)
+	}
+	tgt := []byte{0x34, 0x12, 0, 0, 0, 0, 0xc0, 0x3f, 0, 0, 0, 0, 0, 0, 0, 0xff}
+	if !bytes.Equal(buf, tgt) {
+		t.Errorf("got %x, expected %x", buf, tgt)
+	}
+	if err := setRegisterLane(buf, "float64", 2, "1"); err == nil {
+		t.Errorf("no error for out of range lane")
+	}
+	if err := setRegisterLane(buf, "int8", 0, "200"); err == nil {
+		t.Errorf("no error for out of range value")
+	}
+}
+
+func TestParseRegisterBytes(t *testing.T) {
+	buf, err := parseRegisterBytes("0x102", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tgt := []byte{0x02, 0x01, 0, 0}; !bytes.Equal(buf, tgt) {
+		t.Errorf("got %x, expected %x", buf, tgt)
+	}
+	if _, err := parseRegisterBytes("0x0102030405", 4); err == nil {
+		t.Errorf("no error for value too large")
+	}
+	if _, err := parseRegisterBytes("0xzz", 4); err == nil {
+		t.Errorf("no error for invalid digits")
+	}
+}
@@ -0,0 +1,125 @@
+package proc
+
+import (
+	"encoding/binary"
+)
+
+// referencesStacktraceDepth is the maximum depth of the stacktraces used
+// to find the frame containing a reference.
+const referencesStacktraceDepth = 1000
+
+// Reference is a word of memory, on the stack of a goroutine, containing
+// the address searched by FindReferences.
+type Reference struct {
+	Goroutine *G
+	// Addr is the address of the word containing the reference.
+	Addr uint64
+	// Frame is the index, in the stacktrace of the goroutine, of the frame
+	// whose stack contains Addr, -1 if it could not be determined.
+	Frame int
+	// Location is the location of the frame containing Addr.
+	Location Location
+	// Variable is the local variable or argument of the frame that
+	// contains Addr, nil if Addr doesn't belong to a variable, or belongs
+	// to a variable that isn't described by the debug information.
+	Variable *Variable
+	// Offset is the offset of Addr from the start of Variable.
+	Offset uint64
+}
+
+// FindReferences scans the stacks of all goroutines for pointer-aligned
+// words equal to addr and returns up to maxResults of them (all of them if
+// maxResults is zero or negative).
+// The scan is conservative: any word equal to addr is reported, whether it
+// is a pointer or not, and pointers held only in registers or in the heap
+// are not found.
+func FindReferences(t *Target, addr uint64, maxResults int) ([]Reference, error) {
+	gs, _, err := GoroutinesInfo(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	ptrSize := uint64(t.BinInfo().Arch.PtrSize())
+	mem := t.Memory()
+
+	var r []Reference
+	for _, g := range gs {
+		lo, hi := g.stack.lo, g.stack.hi
+		if hi <= lo {
+			continue
+		}
+		sp := g.SP
+		if g.Thread != nil {
+			if regs, err := g.Thread.Registers(); err == nil {
+				sp = regs.SP()
+			}
+		}
+		if sp >= lo && sp < hi {
+			lo = sp
+		}
+		lo = (lo + ptrSize - 1) &^ (ptrSize - 1)
+		if hi <= lo {
+			continue
+		}
+
+		buf := make([]byte, hi-lo)
+		if _, err := mem.ReadMemory(buf, lo); err != nil {
+			continue
+		}
+
+		var frames []Stackframe
+		for off := uint64(0); off+ptrSize <= uint64(len(buf)); off += ptrSize {
+			var v uint64
+			if ptrSize == 4 {
+				v = uint64(binary.LittleEndian.Uint32(buf[off:]))
+			} else {
+				v = binary.LittleEndian.Uint64(buf[off:])
+			}
+			if v != addr {
+				continue
+			}
+			if frames == nil {
+				frames, _ = g.Stacktrace(referencesStacktraceDepth, 0)
+			}
+			r = append(r, findReferenceFrame(t, g, frames, lo+off))
+			if maxResults > 0 && len(r) >= maxResults {
+				return r, nil
+			}
+		}
+	}
+	return r, nil
+}
+
+// findReferenceFrame returns a Reference for the word at addr, on the stack
+// of g, attributing it to the frame and variable that contain it.
+func findReferenceFrame(t *Target, g *G, frames []Stackframe, addr uint64) Reference {
+	ref := Reference{Goroutine: g, Addr: addr, Frame: -1}
+	for i := range frames {
+		if frames[i].SystemStack || addr < frames[i].Regs.SP() || addr >= uint64(frames[i].Regs.CFA) {
+			continue
+		}
+		if ref.Frame < 0 {
+			ref.Frame = i
+			ref.Location = frames[i].Call
+		}
+		// Inlined calls share the stack frame of the function that contains
+		// them, look for the variable in all of them.
+		scope := FrameToScope(t, t.BinInfo(), t.Memory(), g, frames[i:]...)
+		vars, err := scope.Locals()
+		if err != nil {
+			continue
+		}
+		for _, v := range vars {
+			if v.Flags&VariableFakeAddress != 0 || v.RealType == nil {
+				continue
+			}
+			if addr >= v.Addr && addr < v.Addr+uint64(v.RealType.Size()) {
+				ref.Frame = i
+				ref.Location = frames[i].Call
+				ref.Variable = v
+				ref.Offset = addr - v.Addr
+				return ref
+			}
+		}
+	}
+	return ref
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
@@ -124,3 +125,173 @@ func (descr flagRegisterDescr) Describe(reg uint64, bitsize int) string {
 	}
 	return fmt.Sprintf("%#0*x\t[%s]", bitsize/4, reg, strings.Join(r, " "))
 }
+
+// SetRegister changes the value of the register called name of thread.
+//
+// The name of the register is case insensitive and can be followed by a
+// lane selector, using the same syntax used for CPU registers in
+// expressions, for example XMM0.float32[1] is the second float32 lane of
+// XMM0. On AMD64 the name YMMn can be used to write both halves of the
+// corresponding YMM register.
+//
+// The value of a lane is parsed according to the lane type. The value of a
+// register of 64 bits or less is parsed as an integer, hexadecimal if it is
+// prefixed by 0x. Larger registers are set from a string of hexadecimal
+// digits, most significant byte first, in the format used by the regs
+// command.
+func SetRegister(thread Thread, name, value string) error {
+	regname, lanetyp, lane, err := parseRegisterName(name)
+	if err != nil {
+		return err
+	}
+	sz := 0
+	if strings.HasPrefix(regname, "ymm") {
+		regname = "x" + regname[1:]
+		sz = 32
+	}
+	arch := thread.BinInfo().Arch
+	regnum, ok := arch.RegisterNameToDwarf(regname)
+	if !ok {
+		return fmt.Errorf("unknown register %s", name)
+	}
+
+	regs, err := thread.Registers()
+	if err != nil {
+		return err
+	}
+	cur := arch.RegistersToDwarfRegisters(0, regs).Reg(uint64(regnum))
+	if cur == nil {
+		return fmt.Errorf("register %s not available", name)
+	}
+	if sz == 0 {
+		sz = len(cur.Bytes)
+	}
+	if len(cur.Bytes) < sz {
+		return fmt.Errorf("register %s not available", name)
+	}
+
+	var reg *op.DwarfRegister
+	switch {
+	case lanetyp != "":
+		buf := make([]byte, sz)
+		copy(buf, cur.Bytes)
+		if err := setRegisterLane(buf, lanetyp, lane, value); err != nil {
+			return fmt.Errorf("can not set %s: %v", name, err)
+		}
+		reg = op.DwarfRegisterFromBytes(buf)
+	case sz > 8:
+		buf, err := parseRegisterBytes(value, sz)
+		if err != nil {
+			return fmt.Errorf("can not set %s: %v", name, err)
+		}
+		reg = op.DwarfRegisterFromBytes(buf)
+	default:
+		n, err := parseRegisterUint(value, 64)
+		if err != nil {
+			return fmt.Errorf("can not set %s: %v", name, err)
+		}
+		reg = op.DwarfRegisterFromUint64(n)
+	}
+	return thread.SetReg(uint64(regnum), reg)
+}
+
+// parseRegisterName splits a register name of the form REGNAME or
+// REGNAME.type[lane] into its components, REGNAME is returned in lower
+// case and without any leading underscores.
+func parseRegisterName(name string) (regname, lanetyp string, lane int, err error) {
+	regname = strings.ToLower(strings.TrimLeft(name, "_"))
+	dot := strings.Index(regname, ".")
+	if dot < 0 {
+		return regname, "", 0, nil
+	}
+	regname, lanetyp = regname[:dot], regname[dot+1:]
+	open := strings.Index(lanetyp, "[")
+	if open < 0 || !strings.HasSuffix(lanetyp, "]") {
+		return "", "", 0, fmt.Errorf("malformed register lane %q, expected REGNAME.type[index]", name)
+	}
+	lane, err = strconv.Atoi(lanetyp[open+1 : len(lanetyp)-1])
+	if err != nil || lane < 0 {
+		return "", "", 0, fmt.Errorf("malformed register lane %q, expected REGNAME.type[index]", name)
+	}
+	return regname, lanetyp[:open], lane, nil
+}
+
+// setRegisterLane parses value as a lanetyp value and writes it in the
+// lane-th lane of buf.
+func setRegisterLane(buf []byte, lanetyp string, lane int, value string) error {
+	var n uint64
+	var bits int
+	var err error
+	switch lanetyp {
+	case "int8", "int16", "int32", "int64":
+		bits, _ = strconv.Atoi(lanetyp[len("int"):])
+		var x int64
+		x, err = strconv.ParseInt(value, 0, bits)
+		n = uint64(x)
+	case "uint8", "uint16", "uint32", "uint64":
+		bits, _ = strconv.Atoi(lanetyp[len("uint"):])
+		n, err = parseRegisterUint(value, bits)
+	case "float32":
+		bits = 32
+		var f float64
+		f, err = strconv.ParseFloat(value, 32)
+		n = uint64(math.Float32bits(float32(f)))
+	case "float64":
+		bits = 64
+		var f float64
+		f, err = strconv.ParseFloat(value, 64)
+		n = math.Float64bits(f)
+	default:
+		return fmt.Errorf("unknown lane type %q", lanetyp)
+	}
+	if err != nil {
+		return err
+	}
+	sz := bits / 8
+	if (lane+1)*sz > len(buf) {
+		return fmt.Errorf("lane %d out of range, register has %d %s lanes", lane, len(buf)/sz, lanetyp)
+	}
+	for i := 0; i < sz; i++ {
+		buf[lane*sz+i] = byte(n >> uint(8*i))
+	}
+	return nil
+}
+
+// parseRegisterBytes parses value, a string of at most sz*2 hexadecimal
+// digits optionally prefixed by 0x, as a little endian register of sz
+// bytes.
+func parseRegisterBytes(value string, sz int) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.ToLower(value), "0x")
+	if len(digits) == 0 || len(digits) > sz*2 {
+		return nil, fmt.Errorf("expected at most %d hexadecimal digits", sz*2)
+	}
+	if len(digits)%2 != 0 {
+		digits = "0" + digits
+	}
+	buf := make([]byte, sz)
+	for i := 0; i < len(digits)/2; i++ {
+		b, err := strconv.ParseUint(digits[len(digits)-2*i-2:len(digits)-2*i], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hexadecimal digits %q", value)
+		}
+		buf[i] = byte(b)
+	}
+	return buf, nil
+}
+
+// parseRegisterUint parses value as an unsigned integer of the specified
+// size, negative numbers are accepted and converted to their two's
+// complement representation.
+func parseRegisterUint(value string, bits int) (uint64, error) {
+	if strings.HasPrefix(value, "-") {
+		n, err := strconv.ParseInt(value, 0, bits)
+		if err != nil {
+			return 0, err
+		}
+		if bits < 64 {
+			return uint64(n) & (1<<uint(bits) - 1), nil
+		}
+		return uint64(n), nil
+	}
+	return strconv.ParseUint(value, 0, bits)
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"go/constant"
+	"sort"
 
 	"github.com/go-delve/delve/pkg/dwarf/frame"
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
 )
@@ -48,6 +50,9 @@ type Stackframe struct {
 	Inlined bool
 	// Bottom is true if this is the bottom of the stack
 	Bottom bool
+	// Panicking is true if this frame is being unwound by a panic, i.e. it
+	// is a direct or indirect caller of runtime.gopanic.
+	Panicking bool
 
 	// lastpc is a memory address guaranteed to belong to the last instruction
 	// executed in this stack frame.
@@ -315,9 +320,22 @@ func (it *stackIterator) stacktrace(depth int) ([]Stackframe, error) {
 		}
 		frames = append(frames, Stackframe{Err: err})
 	}
+	markPanickingFrames(frames)
 	return frames, nil
 }
 
+// markPanickingFrames sets the Panicking flag of all frames that are
+// callers of runtime.gopanic.
+func markPanickingFrames(frames []Stackframe) {
+	panicking := false
+	for i := range frames {
+		frames[i].Panicking = panicking
+		if fn := frames[i].Current.Fn; fn != nil && fn.Name == "runtime.gopanic" {
+			panicking = true
+		}
+	}
+}
+
 func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe) []Stackframe {
 	if frame.Call.Fn == nil {
 		return append(frames, frame)
@@ -574,7 +592,7 @@ func (g *G) readDefers(frames []Stackframe) {
 }
 
 func (d *Defer) load() {
-	d.variable.loadValue(LoadConfig{false, 1, 0, 0, -1, 0})
+	d.variable.loadValue(LoadConfig{false, 1, 0, 0, -1, 0, 0, false})
 	if d.variable.Unreadable != nil {
 		d.Unreadable = d.variable.Unreadable
 		return
@@ -665,6 +683,94 @@ func (d *Defer) EvalScope(t *Target, thread Thread) (*EvalScope, error) {
 	return scope, nil
 }
 
+// Wrapped returns true if the deferred call goes through a wrapper
+// generated by the compiler (Go 1.17 and later), in that case the values of
+// its arguments are captured by the wrapper.
+func (d *Defer) Wrapped(p *Target) bool {
+	fn := p.BinInfo().PCToFunc(d.DwrapPC)
+	return fn != nil && fn.isDeferWrapper()
+}
+
+// WrappedArgs returns the arguments of a deferred call that goes through a
+// defer wrapper (see Wrapped), read from the variables captured by the
+// closure of the wrapper.
+// Since Go 1.23 the debug information of the wrapper describes the offset
+// of each captured variable inside the closure, the wrapper captures the
+// arguments of the deferred function in order, receiver first. Arguments
+// that are not captured, for example constants that the compiler moved
+// inside the wrapper, can not be read: if the wrapper does not capture all
+// the arguments of the deferred function an error is returned.
+// Indirect deferred calls, through a function value or an interface, are
+// not supported.
+func (d *Defer) WrappedArgs(p *Target, cfg LoadConfig) ([]*Variable, error) {
+	bi := p.BinInfo()
+	wrapper := bi.PCToFunc(d.DwrapPC)
+	_, _, fn := d.DeferredFunc(p)
+	if wrapper == nil || fn == nil || fn.isDeferWrapper() {
+		return nil, errors.New("arguments of indirect deferred calls can not be read")
+	}
+
+	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		return nil, fmt.Errorf("DWARF read error: %v", err)
+	}
+	names := []string{}
+	for _, entry := range reader.Variables(dwarfTree, fn.Entry, int(^uint(0)>>1), reader.VariablesSkipInlinedSubroutines) {
+		if entry.Tag != dwarf.TagFormalParameter {
+			continue
+		}
+		if isret, _ := entry.Val(dwarf.AttrVarParam).(bool); isret {
+			continue
+		}
+		name, _ := entry.Val(dwarf.AttrName).(string)
+		names = append(names, name)
+	}
+
+	wrapperTree, err := wrapper.cu.image.getDwarfTree(wrapper.offset)
+	if err != nil {
+		return nil, fmt.Errorf("DWARF read error: %v", err)
+	}
+	captured := []*godwarf.Tree{}
+	for _, entry := range reader.Variables(wrapperTree, wrapper.Entry, int(^uint(0)>>1), reader.VariablesSkipInlinedSubroutines) {
+		if _, ok := entry.Val(godwarf.AttrGoClosureOffset).(int64); ok {
+			captured = append(captured, entry.Tree)
+		}
+	}
+	if len(captured) != len(names) {
+		return nil, errors.New("arguments not captured by the defer wrapper")
+	}
+	sort.Slice(captured, func(i, j int) bool {
+		return captured[i].Val(godwarf.AttrGoClosureOffset).(int64) < captured[j].Val(godwarf.AttrGoClosureOffset).(int64)
+	})
+
+	fnfield := d.variable.fieldVariable("fn")
+	if fnfield == nil {
+		return nil, errors.New("could not find closure of defer wrapper")
+	}
+	closureAddr, err := readUintRaw(d.variable.mem, fnfield.Addr, int64(bi.Arch.PtrSize()))
+	if err != nil {
+		return nil, err
+	}
+	if closureAddr == 0 {
+		return nil, errors.New("nil closure for defer wrapper")
+	}
+	mem := DereferenceMemory(d.variable.mem)
+
+	r := make([]*Variable, 0, len(captured))
+	for i, entry := range captured {
+		_, typ, err := readVarEntry(entry, wrapper.cu.image)
+		if err != nil {
+			return nil, err
+		}
+		off := entry.Val(godwarf.AttrGoClosureOffset).(int64)
+		v := newVariable(names[i], closureAddr+uint64(off), typ, bi, mem)
+		v.Flags |= VariableArgument
+		r = append(r, v)
+	}
+	loadValues(r, cfg)
+	return r, nil
+}
+
 // DeferredFunc returns the deferred function, on Go 1.17 and later unwraps
 // any defer wrapper.
 func (d *Defer) DeferredFunc(p *Target) (file string, line int, fn *Function) {
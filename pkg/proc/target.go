@@ -23,6 +23,15 @@ var (
 
 	// ErrProcessDetached indicates that we detached from the target process.
 	ErrProcessDetached = errors.New("detached from the process")
+
+	// ErrParkingUnsupported is returned by ParkGoroutine when the backend
+	// can not leave some threads stopped while resuming the others.
+	ErrParkingUnsupported = errors.New("parking goroutines is not supported by this backend")
+
+	// ErrSignalsUnsupported is returned by SetStopSignals and
+	// SetPendingSignal when the backend can not intercept the signals
+	// received by the target.
+	ErrSignalsUnsupported = errors.New("intercepting signals is not supported by this backend")
 )
 
 type LaunchFlags uint8
@@ -46,6 +55,10 @@ type Target struct {
 	// CanDump is true if core dumping is supported.
 	CanDump bool
 
+	// FatalSignal is the signal that terminated the process, it is only set
+	// for core files.
+	FatalSignal int
+
 	// currentThread is the thread that will be used by next/step/stepout and to evaluate variables if no goroutine is selected.
 	currentThread Thread
 
@@ -56,6 +69,28 @@ type Target struct {
 	// fncallForG stores a mapping of current active function calls.
 	fncallForG map[int]*callInjection
 
+	// parkedGoroutines is the set of goroutines that Continue will not let
+	// run, see ParkGoroutine.
+	parkedGoroutines map[int]bool
+
+	// syntheticLabels maps goroutine IDs to the labels attached to them by
+	// SetGoroutineLabels.
+	syntheticLabels map[int]map[string]string
+
+	// stepExcludedPkgs is the list of packages that the current step
+	// operation will not step into, see StepExcluding.
+	stepExcludedPkgs []string
+
+	// skipTracepoints is true while ContinueSkippingTracepoints is running.
+	skipTracepoints bool
+
+	// runningThreads are the IDs of the threads that were running a
+	// goroutine when the target was stopped by a manual stop request.
+	runningThreads []int
+
+	// stopSignals are the signals set with SetStopSignals.
+	stopSignals []int
+
 	asyncPreemptChanged bool  // runtime/debug.asyncpreemptoff was changed
 	asyncPreemptOff     int64 // cached value of runtime/debug.asyncpreemptoff
 
@@ -115,6 +150,8 @@ func (sr StopReason) String() string {
 		return "call returned"
 	case StopWatchpoint:
 		return "watchpoint"
+	case StopSignal:
+		return "signal"
 	default:
 		return ""
 	}
@@ -131,6 +168,7 @@ const (
 	StopNextFinished                   // The next/step/stepout command terminated
 	StopCallReturned                   // An injected call completed
 	StopWatchpoint                     // The target process hit one or more watchpoints
+	StopSignal                         // A thread of the target process received one of the signals set with SetStopSignals
 )
 
 // NewTargetConfig contains the configuration for a new Target object,
@@ -142,10 +180,14 @@ type NewTargetConfig struct {
 	CanDump             bool       // Can create core dumps (must implement ProcessInternal.MemoryMap)
 }
 
-// DisableAsyncPreemptEnv returns a process environment (like os.Environ)
-// where asyncpreemptoff is set to 1.
-func DisableAsyncPreemptEnv() []string {
-	env := os.Environ()
+// DisableAsyncPreemptEnv returns a copy of the process environment env
+// (os.Environ if env is nil) where asyncpreemptoff is set to 1.
+func DisableAsyncPreemptEnv(env []string) []string {
+	if env == nil {
+		env = os.Environ()
+	} else {
+		env = append([]string(nil), env...)
+	}
 	for i := range env {
 		if strings.HasPrefix(env[i], "GODEBUG=") {
 			// Go 1.14 asynchronous preemption mechanism is incompatible with
@@ -262,6 +304,8 @@ func (t *Target) Restart(from string) error {
 	}
 	t.currentThread = currentThread
 	t.selectedGoroutine, _ = GetG(t.CurrentThread())
+	// goroutine IDs do not identify the same goroutines after a restart.
+	t.parkedGoroutines = nil
 	if from != "" {
 		t.StopReason = StopManual
 	} else {
@@ -290,6 +334,166 @@ func (p *Target) SwitchGoroutine(g *G) error {
 	return nil
 }
 
+// ParkGoroutine prevents goroutine gid from running when the target is
+// resumed, while the rest of the program continues normally. The backend
+// enforces this by leaving stopped the thread the goroutine is running on,
+// therefore a goroutine is only held while it is running on a thread: a
+// goroutine that is blocked or waiting to be scheduled when the target is
+// resumed will run until the target stops again.
+// A parked goroutine still runs while it is the selected goroutine of a
+// step operation or a function call.
+// Note that holding a thread will eventually block the whole program if
+// the runtime needs to stop the world (for example to run the garbage
+// collector).
+func (t *Target) ParkGoroutine(gid int) error {
+	if !t.proc.SetHeldThreads(nil) {
+		return ErrParkingUnsupported
+	}
+	g, err := FindGoroutine(t, gid)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return fmt.Errorf("unknown goroutine %d", gid)
+	}
+	if t.parkedGoroutines == nil {
+		t.parkedGoroutines = make(map[int]bool)
+	}
+	t.parkedGoroutines[gid] = true
+	return nil
+}
+
+// UnparkGoroutine lets goroutine gid run again, undoing ParkGoroutine.
+func (t *Target) UnparkGoroutine(gid int) error {
+	if !t.parkedGoroutines[gid] {
+		return fmt.Errorf("goroutine %d is not parked", gid)
+	}
+	delete(t.parkedGoroutines, gid)
+	return nil
+}
+
+// RunningThreads returns the IDs of the threads that were running a
+// goroutine, outside of system calls, when the target was stopped by the
+// last manual stop request (see RequestManualStop), in increasing order.
+// It returns nil if the target was resumed since.
+func (t *Target) RunningThreads() []int {
+	return t.runningThreads
+}
+
+// SetStopSignals sets the signals that stop the target, with StopSignal,
+// when they are received by one of its threads, replacing the previous
+// set. The signal is delivered to the thread when the target is resumed,
+// unless it is changed with SetPendingSignal.
+func (t *Target) SetStopSignals(sigs []int) error {
+	if !t.proc.SetStopSignals(sigs) {
+		return ErrSignalsUnsupported
+	}
+	t.stopSignals = append([]int(nil), sigs...)
+	return nil
+}
+
+// StopSignals returns the signals set with SetStopSignals.
+func (t *Target) StopSignals() []int {
+	return t.stopSignals
+}
+
+// PendingSignal returns the number and the name of the signal that will be
+// delivered to thread tid when the target is resumed, sig is 0 if there is
+// none.
+func (t *Target) PendingSignal(tid int) (sig int, name string) {
+	return t.proc.PendingSignal(tid)
+}
+
+// SetPendingSignal changes the signal that will be delivered to thread tid
+// when the target is resumed, 0 means that no signal is delivered.
+func (t *Target) SetPendingSignal(tid, sig int) error {
+	if _, ok := t.FindThread(tid); !ok {
+		return fmt.Errorf("unknown thread %d", tid)
+	}
+	if !t.proc.SetPendingSignal(tid, sig) {
+		return ErrSignalsUnsupported
+	}
+	return nil
+}
+
+// ParkedGoroutines returns the IDs of the parked goroutines, in increasing
+// order.
+func (t *Target) ParkedGoroutines() []int {
+	r := make([]int, 0, len(t.parkedGoroutines))
+	for gid := range t.parkedGoroutines {
+		r = append(r, gid)
+	}
+	sort.Ints(r)
+	return r
+}
+
+// SetGoroutineLabels attaches labels to goroutine gid, replacing the ones
+// attached by a previous call. The labels are only visible through
+// GoroutineLabels and are not written to the memory of the target. Passing
+// an empty map removes them.
+func (t *Target) SetGoroutineLabels(gid int, labels map[string]string) error {
+	g, err := FindGoroutine(t, gid)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return fmt.Errorf("unknown goroutine %d", gid)
+	}
+	if len(labels) == 0 {
+		delete(t.syntheticLabels, gid)
+		return nil
+	}
+	if t.syntheticLabels == nil {
+		t.syntheticLabels = make(map[int]map[string]string)
+	}
+	t.syntheticLabels[gid] = make(map[string]string, len(labels))
+	for k, v := range labels {
+		t.syntheticLabels[gid][k] = v
+	}
+	return nil
+}
+
+// GoroutineLabels returns the pprof labels of g, combined with the labels
+// attached to it by SetGoroutineLabels, which take precedence.
+func (t *Target) GoroutineLabels(g *G) map[string]string {
+	synthetic := t.syntheticLabels[g.ID]
+	if len(synthetic) == 0 {
+		return g.Labels()
+	}
+	labels := make(map[string]string)
+	for k, v := range g.Labels() {
+		labels[k] = v
+	}
+	for k, v := range synthetic {
+		labels[k] = v
+	}
+	return labels
+}
+
+// heldThreads returns the threads that are running a parked goroutine and
+// must be left stopped by ContinueOnce.
+func (t *Target) heldThreads() []int {
+	if len(t.parkedGoroutines) == 0 {
+		return nil
+	}
+	stepping := t.Breakpoints().HasInternalBreakpoints()
+	var r []int
+	for _, th := range t.ThreadList() {
+		g, _ := GetG(th)
+		if g == nil || !t.parkedGoroutines[g.ID] {
+			continue
+		}
+		if t.fncallForG[g.ID] != nil {
+			continue
+		}
+		if stepping && t.selectedGoroutine != nil && t.selectedGoroutine.ID == g.ID {
+			continue
+		}
+		r = append(r, th.ThreadID())
+	}
+	return r
+}
+
 // SwitchThread will change the selected and active thread.
 func (p *Target) SwitchThread(tid int) error {
 	if ok, err := p.Valid(); !ok {
@@ -384,6 +588,66 @@ func (t *Target) createFatalThrowBreakpoint() {
 	}
 }
 
+// BreakOnNextPanic arms a breakpoint on runtime.gopanic that stops the
+// target the next time goroutine gid panics and returns the IDs of all the
+// armed goroutines, in increasing order. Each goroutine is disarmed when it
+// panics, the breakpoint is cleared once no goroutine is left armed.
+// The breakpoint coexists with a user breakpoint at the same address, the
+// armed goroutines stop on it regardless of the condition of the user
+// breakpoint.
+func (t *Target) BreakOnNextPanic(gid int) ([]int, error) {
+	panicpcs, err := FindFunctionLocation(t.Process, "runtime.gopanic", 0)
+	if err != nil {
+		return nil, err
+	}
+	bp := t.Breakpoints().M[panicpcs[0]]
+	if bp == nil || bp.Kind&NextPanicBreakpoint == 0 {
+		bp, err = t.SetBreakpoint(panicpcs[0], NextPanicBreakpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		if bp.Kind == NextPanicBreakpoint {
+			bp.LogicalID = nextPanicID
+			bp.Name = NextPanic
+		}
+		bp.panicGoroutines = make(map[int]bool)
+	}
+	bp.panicGoroutines[gid] = true
+	r := make([]int, 0, len(bp.panicGoroutines))
+	for armed := range bp.panicGoroutines {
+		r = append(r, armed)
+	}
+	sort.Ints(r)
+	return r, nil
+}
+
+// disarmNextPanic disarms the goroutine running on thread if it was armed
+// on bp by BreakOnNextPanic, clearing the NextPanicBreakpoint kind of bp
+// once no goroutine is left armed.
+func (t *Target) disarmNextPanic(thread Thread, bp *Breakpoint) error {
+	if bp.Kind&NextPanicBreakpoint == 0 {
+		return nil
+	}
+	g, _ := GetG(thread)
+	if g == nil || !bp.panicGoroutines[g.ID] {
+		return nil
+	}
+	delete(bp.panicGoroutines, g.ID)
+	if len(bp.panicGoroutines) > 0 {
+		return nil
+	}
+	bp.Kind &^= NextPanicBreakpoint
+	bp.panicGoroutines = nil
+	if bp.Kind != 0 {
+		return nil
+	}
+	if err := t.proc.EraseBreakpoint(bp); err != nil {
+		return err
+	}
+	delete(t.Breakpoints().M, bp.Addr)
+	return nil
+}
+
 // CurrentThread returns the currently selected thread which will be used
 // for next/step/stepout and for reading variables, unless a goroutine is
 // selected.
@@ -471,7 +735,7 @@ func (t *Target) dwrapUnwrap(fn *Function) *Function {
 	if fn == nil {
 		return nil
 	}
-	if !strings.Contains(fn.Name, "·dwrap·") {
+	if !fn.isDeferWrapper() {
 		return fn
 	}
 	if unwrap := t.BinInfo().dwrapUnwrapCache[fn.Entry]; unwrap != nil {
@@ -489,3 +753,10 @@ func (t *Target) dwrapUnwrap(fn *Function) *Function {
 	}
 	return fn
 }
+
+// isDeferWrapper returns true if fn is a wrapper generated by the compiler
+// for a deferred call, named "·dwrap·N" in Go 1.17 to 1.20 and
+// "deferwrapN" in later versions.
+func (fn *Function) isDeferWrapper() bool {
+	return strings.Contains(fn.Name, "·dwrap·") || strings.Contains(fn.Name, ".deferwrap")
+}
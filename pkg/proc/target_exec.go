@@ -8,10 +8,13 @@ import (
 	"go/ast"
 	"go/token"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/go-delve/delve/pkg/astutil"
+	"github.com/go-delve/delve/pkg/dwarf/frame"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
+	"github.com/go-delve/delve/pkg/goversion"
 )
 
 const maxSkipAutogeneratedWrappers = 5 // maximum recursion depth for skipAutogeneratedWrappers
@@ -55,21 +58,21 @@ func (dbp *Target) Continue() error {
 		thread.Common().returnValues = nil
 	}
 	dbp.CheckAndClearManualStopRequest()
+	dbp.runningThreads = nil
 	defer func() {
 		// Make sure we clear internal breakpoints if we simultaneously receive a
 		// manual stop request and hit a breakpoint.
 		if dbp.CheckAndClearManualStopRequest() {
-			dbp.StopReason = StopManual
-			dbp.ClearInternalBreakpoints()
+			dbp.manualStop()
 		}
 	}()
 	for {
 		if dbp.CheckAndClearManualStopRequest() {
-			dbp.StopReason = StopManual
-			dbp.ClearInternalBreakpoints()
+			dbp.manualStop()
 			return nil
 		}
 		dbp.ClearCaches()
+		dbp.proc.SetHeldThreads(dbp.heldThreads())
 		trapthread, stopReason, err := dbp.proc.ContinueOnce()
 		dbp.StopReason = stopReason
 		if err != nil {
@@ -108,6 +111,15 @@ func (dbp *Target) Continue() error {
 			return callErr
 		}
 
+		if stopReason == StopSignal {
+			// Report the thread that received the signal even if other threads
+			// simultaneously stopped at a breakpoint.
+			if err := dbp.SwitchThread(trapthread.ThreadID()); err != nil {
+				return err
+			}
+			return conditionErrors(threads)
+		}
+
 		curthread := dbp.CurrentThread()
 		curbp := curthread.Breakpoint()
 
@@ -190,6 +202,9 @@ func (dbp *Target) Continue() error {
 				dbp.StopReason = StopNextFinished
 				return conditionErrors(threads)
 			}
+		case dbp.skipBreakpoint(curbp):
+			// tracepoint skipped by ContinueSkippingTracepoints, no other thread
+			// stopped on a breakpoint, repeat
 		case curbp.Active:
 			onNextGoroutine, err := onNextGoroutine(curthread, dbp.Breakpoints())
 			if err != nil {
@@ -204,6 +219,9 @@ func (dbp *Target) Continue() error {
 			if curbp.Name == UnrecoveredPanic {
 				dbp.ClearInternalBreakpoints()
 			}
+			if err := dbp.disarmNextPanic(curthread, curbp.Breakpoint); err != nil {
+				return err
+			}
 			dbp.StopReason = StopBreakpoint
 			if curbp.Breakpoint.WatchType != 0 {
 				dbp.StopReason = StopWatchpoint
@@ -221,6 +239,37 @@ func (dbp *Target) Continue() error {
 	}
 }
 
+// manualStop records that the target was stopped by a manual stop request
+// and which threads were running a goroutine at that moment.
+func (dbp *Target) manualStop() {
+	dbp.StopReason = StopManual
+	dbp.ClearInternalBreakpoints()
+	dbp.runningThreads = dbp.runningThreads[:0]
+	for _, th := range dbp.ThreadList() {
+		if g, _ := GetG(th); g != nil && g.Status != Gsyscall {
+			dbp.runningThreads = append(dbp.runningThreads, th.ThreadID())
+		}
+	}
+	sort.Ints(dbp.runningThreads)
+}
+
+// ContinueSkippingTracepoints is like Continue but user breakpoints with
+// the Tracepoint or TraceReturn flags set do not stop the target, their
+// hit counts are still updated.
+func (dbp *Target) ContinueSkippingTracepoints() error {
+	dbp.skipTracepoints = true
+	defer func() {
+		dbp.skipTracepoints = false
+	}()
+	return dbp.Continue()
+}
+
+// skipBreakpoint returns true if bpstate is an active tracepoint that
+// should not stop the target because of ContinueSkippingTracepoints.
+func (dbp *Target) skipBreakpoint(bpstate *BreakpointState) bool {
+	return dbp.skipTracepoints && bpstate.Breakpoint != nil && bpstate.Active && !bpstate.Internal && (bpstate.Tracepoint || bpstate.TraceReturn)
+}
+
 func conditionErrors(threads []Thread) error {
 	var condErr error
 	for _, th := range threads {
@@ -245,11 +294,11 @@ func pickCurrentThread(dbp *Target, trapthread Thread, threads []Thread) error {
 			return dbp.SwitchThread(th.ThreadID())
 		}
 	}
-	if bp := trapthread.Breakpoint(); bp.Active {
+	if bp := trapthread.Breakpoint(); bp.Active && !dbp.skipBreakpoint(bp) {
 		return dbp.SwitchThread(trapthread.ThreadID())
 	}
 	for _, th := range threads {
-		if bp := th.Breakpoint(); bp.Active {
+		if bp := th.Breakpoint(); bp.Active && !dbp.skipBreakpoint(bp) {
 			return dbp.SwitchThread(th.ThreadID())
 		}
 	}
@@ -305,12 +354,22 @@ func stepInstructionOut(dbp *Target, curthread Thread, fnname1, fnname2 string)
 // Step will continue until another source line is reached.
 // Will step into functions.
 func (dbp *Target) Step() (err error) {
+	return dbp.StepExcluding(nil)
+}
+
+// StepExcluding is like Step but it will step over, instead of into, calls
+// to functions that belong to one of the packages in excludedPkgs.
+// Each entry of excludedPkgs is either the import path of a package or a
+// prefix followed by "/*", matching all packages below that prefix (for
+// example "internal/*").
+func (dbp *Target) StepExcluding(excludedPkgs []string) (err error) {
 	if _, err := dbp.Valid(); err != nil {
 		return err
 	}
 	if dbp.Breakpoints().HasInternalBreakpoints() {
 		return fmt.Errorf("next while nexting")
 	}
+	dbp.stepExcludedPkgs = excludedPkgs
 
 	if err = next(dbp, true, false); err != nil {
 		_ = dbp.ClearInternalBreakpoints()
@@ -413,6 +472,294 @@ func (dbp *Target) StepOut() error {
 	return dbp.Continue()
 }
 
+// StepOutToUserCode steps out of the current function, and then out of its
+// callers, until the selected goroutine returns to a function that does not
+// belong to the standard library or there are no more callers to return
+// to. It stops early if the target stops for any other reason, for example
+// because a breakpoint was hit.
+func (dbp *Target) StepOutToUserCode() error {
+	for {
+		if err := dbp.StepOut(); err != nil {
+			return err
+		}
+		if dbp.StopReason != StopNextFinished {
+			return nil
+		}
+		topframe, _, err := topframe(dbp.SelectedGoroutine(), dbp.CurrentThread())
+		if err != nil {
+			return err
+		}
+		if topframe.Ret == 0 || !isStdlibFn(dbp.BinInfo(), topframe.Call.Fn) {
+			return nil
+		}
+	}
+}
+
+// StepToLine continues until the selected goroutine reaches the specified
+// line, in the function of the current frame, or until that function
+// returns. If file is the empty string the file of the current frame is
+// used.
+func (dbp *Target) StepToLine(file string, line int) error {
+	if _, err := dbp.Valid(); err != nil {
+		return err
+	}
+	if dbp.GetDirection() == Backward {
+		return errors.New("can not step to a line backward")
+	}
+	if dbp.Breakpoints().HasInternalBreakpoints() {
+		return fmt.Errorf("next while nexting")
+	}
+
+	selg := dbp.SelectedGoroutine()
+	curthread := dbp.CurrentThread()
+
+	topframe, retframe, err := topframe(selg, curthread)
+	if err != nil {
+		return err
+	}
+	if topframe.Inlined {
+		return errors.New("can not step to a line from an inlined call")
+	}
+	fn := topframe.Current.Fn
+	if fn == nil {
+		return &ErrNoSourceForPC{topframe.Current.PC}
+	}
+	if file == "" {
+		file = topframe.Current.File
+	}
+
+	pcs, err := dbp.BinInfo().LineToPC(file, line)
+	if err != nil {
+		return err
+	}
+	pcs = removePCsOutside(pcs, fn.Entry, fn.End)
+	if len(pcs) == 0 {
+		return fmt.Errorf("%s:%d is not in function %s", file, line, fn.Name)
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			dbp.ClearInternalBreakpoints()
+		}
+	}()
+
+	sameGCond := sameGoroutineCondition(selg)
+	sameFrameCond := astutil.And(sameGCond, frameoffCondition(&topframe))
+	for _, pc := range pcs {
+		if _, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(pc, NextBreakpoint, sameFrameCond)); err != nil {
+			return err
+		}
+	}
+
+	// Stop if the function returns, or panics, before reaching the line.
+	if _, err := setDeferBreakpoint(dbp, nil, topframe, sameGCond, false); err != nil {
+		return err
+	}
+	if topframe.Ret != 0 {
+		topframe, retframe := skipAutogeneratedWrappersOut(selg, curthread, &topframe, &retframe)
+		retFrameCond := astutil.And(sameGCond, frameoffCondition(retframe))
+		bp, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(retframe.Current.PC, NextBreakpoint, retFrameCond))
+		if err != nil {
+			return err
+		}
+		if bp != nil {
+			configureReturnBreakpoint(dbp.BinInfo(), bp, topframe, retFrameCond)
+		}
+	}
+
+	if bp := curthread.Breakpoint(); bp.Breakpoint == nil {
+		curthread.SetCurrentBreakpoint(false)
+	}
+
+	success = true
+	return dbp.Continue()
+}
+
+// ContinueToFunctions resumes execution until any goroutine enters one of
+// the functions in fns, or the target stops for any other reason, and
+// returns the name of the function that was entered. The internal
+// breakpoints used to do this are always removed before returning.
+func (dbp *Target) ContinueToFunctions(fns []string) (reached string, err error) {
+	if _, err := dbp.Valid(); err != nil {
+		return "", err
+	}
+	if dbp.GetDirection() == Backward {
+		return "", errors.New("can not continue to a function backward")
+	}
+	if dbp.Breakpoints().HasInternalBreakpoints() {
+		return "", fmt.Errorf("next while nexting")
+	}
+	if len(fns) == 0 {
+		return "", errors.New("no functions specified")
+	}
+
+	defer func() {
+		if valid, _ := dbp.Valid(); valid {
+			dbp.ClearInternalBreakpoints()
+		}
+	}()
+
+	pcToFn := make(map[uint64]string)
+	for _, fn := range fns {
+		pcs, err := FindFunctionLocation(dbp, fn, 0)
+		if err != nil {
+			return "", err
+		}
+		for _, pc := range pcs {
+			if _, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(pc, NextBreakpoint, nil)); err != nil {
+				return "", err
+			}
+			pcToFn[pc] = fn
+		}
+	}
+
+	curthread := dbp.CurrentThread()
+	if bp := curthread.Breakpoint(); bp.Breakpoint == nil {
+		curthread.SetCurrentBreakpoint(false)
+	}
+
+	if err := dbp.Continue(); err != nil {
+		return "", err
+	}
+	if dbp.StopReason == StopNextFinished {
+		if regs, err := dbp.CurrentThread().Registers(); err == nil {
+			reached = pcToFn[regs.PC()]
+		}
+	}
+	return reached, nil
+}
+
+// SetNextStatement moves the program counter of goroutine gid to the first
+// instruction of file:line, so that it is the next statement executed when
+// the target is resumed. The destination must be inside the function of the
+// topmost frame of the goroutine and past its prologue. The jump is refused
+// if the stack frame has a different layout at the destination or if it
+// would skip the initialization of a variable visible there.
+// If file is the empty string the file of the current frame is used.
+func (dbp *Target) SetNextStatement(gid int, file string, line int) error {
+	if _, err := dbp.Valid(); err != nil {
+		return err
+	}
+	if recorded, _ := dbp.Recorded(); recorded {
+		return errors.New("can not set the next statement of a recording")
+	}
+	if dbp.Breakpoints().HasInternalBreakpoints() {
+		return errors.New("can not set the next statement while nexting")
+	}
+
+	g, err := FindGoroutine(dbp, gid)
+	if err != nil {
+		return err
+	}
+	thread := dbp.CurrentThread()
+	if g != nil {
+		if g.Thread == nil {
+			return fmt.Errorf("goroutine %d is not running on a thread", g.ID)
+		}
+		thread = g.Thread
+	}
+
+	topframe, _, err := topframe(g, thread)
+	if err != nil {
+		return err
+	}
+	if topframe.Inlined {
+		return errors.New("can not set the next statement inside an inlined call")
+	}
+	fn := topframe.Current.Fn
+	if fn == nil {
+		return &ErrNoSourceForPC{topframe.Current.PC}
+	}
+	if file == "" {
+		file = topframe.Current.File
+	}
+
+	bi := dbp.BinInfo()
+	pcs, err := bi.LineToPC(file, line)
+	if err != nil {
+		return err
+	}
+	pcs = removePCsOutside(pcs, fn.Entry, fn.End)
+	pcs, err = removeInlinedCalls(pcs, topframe)
+	if err != nil {
+		return err
+	}
+	if len(pcs) == 0 {
+		return fmt.Errorf("%s:%d is not in function %s", file, line, fn.Name)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	pc := pcs[0]
+
+	if afterPrologue, err := FirstPCAfterPrologue(dbp, fn, false); err == nil && pc < afterPrologue {
+		return fmt.Errorf("can not jump to %s:%d, it is inside the prologue of %s", file, line, fn.Name)
+	}
+	if !sameCFARule(cfaRuleForPC(bi, topframe.Current.PC), cfaRuleForPC(bi, pc)) {
+		return fmt.Errorf("can not jump to %s:%d, the stack frame of %s has a different size there", file, line, fn.Name)
+	}
+	skipped, err := skippedVariables(fn, topframe.Current.PC, topframe.Current.Line, pc, line)
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		return fmt.Errorf("can not jump to %s:%d, it would skip the initialization of %s", file, line, strings.Join(skipped, ", "))
+	}
+
+	if err := setPC(thread, pc); err != nil {
+		return err
+	}
+	if err := thread.SetCurrentBreakpoint(false); err != nil {
+		return err
+	}
+	dbp.ClearCaches()
+	if thread.ThreadID() == dbp.CurrentThread().ThreadID() {
+		dbp.selectedGoroutine, _ = GetG(thread)
+	}
+	return nil
+}
+
+// cfaRuleForPC returns the rule used to compute the canonical frame address
+// at pc.
+func cfaRuleForPC(bi *BinaryInfo, pc uint64) frame.DWRule {
+	fde, err := bi.frameEntries.FDEForPC(pc)
+	if err != nil {
+		return bi.Arch.fixFrameUnwindContext(nil, pc, bi).CFA
+	}
+	return bi.Arch.fixFrameUnwindContext(fde.EstablishFrame(pc), pc, bi).CFA
+}
+
+func sameCFARule(a, b frame.DWRule) bool {
+	return a.Rule == b.Rule && a.Reg == b.Reg && a.Offset == b.Offset && bytes.Equal(a.Expression, b.Expression)
+}
+
+// skippedVariables returns the names of the variables of fn that are visible
+// at dstpc:dstline but not at curpc:curline, i.e. the variables whose
+// initialization would be skipped by jumping from curpc to dstpc.
+func skippedVariables(fn *Function, curpc uint64, curline int, dstpc uint64, dstline int) ([]string, error) {
+	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		return nil, err
+	}
+	flags := reader.VariablesOnlyVisible | reader.VariablesSkipInlinedSubroutines
+	if producer := fn.cu.producer; producer != "" && goversion.ProducerAfterOrEqual(producer, 1, 15) {
+		flags |= reader.VariablesTrustDeclLine
+	}
+	visible := make(map[dwarf.Offset]bool)
+	for _, v := range reader.Variables(dwarfTree, curpc, curline, flags) {
+		visible[v.Offset] = true
+	}
+	var skipped []string
+	for _, v := range reader.Variables(dwarfTree, dstpc, dstline, flags) {
+		if visible[v.Offset] || v.Tag == dwarf.TagFormalParameter {
+			continue
+		}
+		if name, _ := v.Val(dwarf.AttrName).(string); name != "" && name[0] != '.' && name[0] != '~' {
+			skipped = append(skipped, name)
+		}
+	}
+	return skipped, nil
+}
+
 // StepInstruction will continue the current thread for exactly
 // one instruction. This method affects only the thread
 // associated with the selected goroutine. All other
@@ -737,6 +1084,16 @@ func removeInlinedCalls(pcs []uint64, topframe Stackframe) ([]uint64, error) {
 	return pcs, nil
 }
 
+func removePCsOutside(pcs []uint64, start, end uint64) []uint64 {
+	out := pcs[:0]
+	for _, pc := range pcs {
+		if pc >= start && pc < end {
+			out = append(out, pc)
+		}
+	}
+	return out
+}
+
 func removePCsBetween(pcs []uint64, start, end uint64) []uint64 {
 	out := pcs[:0]
 	for _, pc := range pcs {
@@ -772,10 +1129,6 @@ func setStepIntoBreakpoint(dbp *Target, curfn *Function, text []AsmInstruction,
 		return nil
 	}
 
-	//TODO(aarzilli): if we want to let users hide functions
-	// or entire packages from being stepped into with 'step'
-	// those extra checks should be done here.
-
 	pc := instr.DestLoc.PC
 
 	// Skip InhibitStepInto functions for different arch.
@@ -785,6 +1138,11 @@ func setStepIntoBreakpoint(dbp *Target, curfn *Function, text []AsmInstruction,
 
 	fn, pc = skipAutogeneratedWrappersIn(dbp, fn, pc)
 
+	// Skip functions in packages excluded by StepExcluding
+	if fn != nil && stepExcluded(fn, dbp.stepExcludedPkgs) {
+		return nil
+	}
+
 	// We want to skip the function prologue but we should only do it if the
 	// destination address of the CALL instruction is the entry point of the
 	// function.
@@ -803,6 +1161,22 @@ func setStepIntoBreakpoint(dbp *Target, curfn *Function, text []AsmInstruction,
 	return nil
 }
 
+// stepExcluded returns true if fn belongs to one of the packages in
+// excludedPkgs, see StepExcluding.
+func stepExcluded(fn *Function, excludedPkgs []string) bool {
+	pkg := fn.PackageName()
+	for _, excluded := range excludedPkgs {
+		if strings.HasSuffix(excluded, "/*") {
+			if strings.HasPrefix(pkg, excluded[:len(excluded)-1]) {
+				return true
+			}
+		} else if pkg == excluded {
+			return true
+		}
+	}
+	return false
+}
+
 func allowDuplicateBreakpoint(bp *Breakpoint, err error) (*Breakpoint, error) {
 	if err != nil {
 		if _, isexists := err.(BreakpointExistsError); isexists {
@@ -1053,7 +1427,7 @@ type onNextGoroutineWalker struct {
 
 func (w *onNextGoroutineWalker) Visit(n ast.Node) ast.Visitor {
 	if binx, isbin := n.(*ast.BinaryExpr); isbin && binx.Op == token.EQL && exprToString(binx.X) == "runtime.curg.goid" {
-		w.ret, w.err = evalBreakpointCondition(w.thread, n.(ast.Expr))
+		w.ret, w.err = evalBreakpointCondition(w.thread, n.(ast.Expr), nil)
 		return nil
 	}
 	return w
@@ -422,7 +422,7 @@ func nameOfInterfaceRuntimeType(mds []moduleData, _type *Variable, kind, tflag i
 	buf.WriteString("interface {")
 
 	methods, _ := _type.structMember(interfacetypeFieldMhdr)
-	methods.loadArrayValues(0, LoadConfig{false, 1, 0, 4096, -1, 0})
+	methods.loadArrayValues(0, LoadConfig{false, 1, 0, 4096, -1, 0, 0, false})
 	if methods.Unreadable != nil {
 		return "", nil
 	}
@@ -483,7 +483,7 @@ func nameOfStructRuntimeType(mds []moduleData, _type *Variable, kind, tflag int6
 	buf.WriteString("struct {")
 
 	fields, _ := _type.structMember("fields")
-	fields.loadArrayValues(0, LoadConfig{false, 2, 0, 4096, -1, 0})
+	fields.loadArrayValues(0, LoadConfig{false, 2, 0, 4096, -1, 0, 0, false})
 	if fields.Unreadable != nil {
 		return "", fields.Unreadable
 	}
@@ -656,3 +656,41 @@ func dwarfToRuntimeType(bi *BinaryInfo, mem MemoryReadWriter, typ godwarf.Type)
 	typeKind, _ = constant.Uint64Val(kindv.Value)
 	return typeAddr, typeKind, true, nil
 }
+
+// TypeAlign returns the alignment, in bytes, of values of type typ on a
+// target whose pointers are ptrSize bytes long.
+func TypeAlign(typ godwarf.Type, ptrSize int) int64 {
+	var sz int64
+	switch t := resolveTypedef(typ).(type) {
+	case *godwarf.ArrayType:
+		return TypeAlign(t.Type, ptrSize)
+	case *godwarf.StringType:
+		return TypeAlign(&t.StructType, ptrSize)
+	case *godwarf.SliceType:
+		return TypeAlign(&t.StructType, ptrSize)
+	case *godwarf.StructType:
+		align := int64(1)
+		for _, field := range t.Field {
+			if a := TypeAlign(field.Type, ptrSize); a > align {
+				align = a
+			}
+		}
+		return align
+	case *godwarf.InterfaceType, *godwarf.MapType, *godwarf.ChanType, *godwarf.FuncType, *godwarf.PtrType:
+		return int64(ptrSize)
+	case *godwarf.ComplexType:
+		// complex numbers are aligned like their real part
+		sz = t.ByteSize / 2
+	case nil:
+		return 1
+	default:
+		sz = t.Size()
+	}
+	switch {
+	case sz <= 0:
+		return 1
+	case sz > int64(ptrSize):
+		return int64(ptrSize)
+	}
+	return sz
+}
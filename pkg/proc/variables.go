@@ -29,6 +29,7 @@ const (
 	hashTophashEmptyOne  = 1 // used by map reading code, indicates an empty cell in Go 1.12 and later
 	hashMinTopHashGo111  = 4 // used by map reading code, indicates minimum value of tophash that isn't empty or evacuated, in Go1.11
 	hashMinTopHashGo112  = 5 // used by map reading code, indicates minimum value of tophash that isn't empty or evacuated, in Go1.12
+	hashSameSizeGrow     = 8 // used by map reading code, flag of hmap.flags set when the map is growing to a new bucket array of the same size
 
 	maxFramePrefetchSize = 1 * 1024 * 1024 // Maximum prefetch size for a stack frame
 
@@ -120,6 +121,22 @@ type Variable struct {
 
 	Children []Variable
 
+	// The value was only partially loaded because of the limits of the
+	// LoadConfig: LenTruncated is set for strings truncated by MaxStringLen,
+	// CapTruncated when Children was truncated by MaxArrayValues (or
+	// MaxStructFields) and RecurseTruncated when Children was not loaded
+	// because MaxVariableRecurse was reached.
+	LenTruncated     bool
+	CapTruncated     bool
+	RecurseTruncated bool
+
+	// For channels ChanBuffer contains the buffered elements, in the order
+	// they will be received, ChanSendWaiters and ChanRecvWaiters the number
+	// of goroutines blocked sending to and receiving from the channel.
+	ChanBuffer      []Variable
+	ChanSendWaiters int
+	ChanRecvWaiters int
+
 	loaded     bool
 	Unreadable error
 
@@ -168,11 +185,22 @@ type LoadConfig struct {
 	// sparse map is in scope, but evaluating a single variable will still work
 	// correctly, even if the variable in question is a very sparse map.
 	MaxMapBuckets int
+
+	// MaxElementBytes is the maximum number of bytes read from each element
+	// of an array, a slice or a map, 0 will read elements entirely.
+	// Struct fields that end past the limit are not loaded, strings and
+	// arrays contained in the element are truncated so that they fit.
+	MaxElementBytes int
+
+	// PrettyKnownTypes requests that the internal state of some types of
+	// the sync package (sync.Map, sync.WaitGroup and sync.Once) is decoded
+	// and shown in place of their fields.
+	PrettyKnownTypes bool
 }
 
-var loadSingleValue = LoadConfig{false, 0, 64, 0, 0, 0}
-var loadFullValue = LoadConfig{true, 1, 64, 64, -1, 0}
-var loadFullValueLongerStrings = LoadConfig{true, 1, 1024 * 1024, 64, -1, 0}
+var loadSingleValue = LoadConfig{false, 0, 64, 0, 0, 0, 0, false}
+var loadFullValue = LoadConfig{true, 1, 64, 64, -1, 0, 0, false}
+var loadFullValueLongerStrings = LoadConfig{true, 1, 1024 * 1024, 64, -1, 0, 0, false}
 
 // G status, from: src/runtime/runtime2.go
 const (
@@ -386,7 +414,7 @@ func FindGoroutine(dbp *Target, gid int) (*G, error) {
 	}
 
 	if gid == 0 {
-		return nil, fmt.Errorf("unknown goroutine %d", gid)
+		return nil, ErrUnknownGoroutine{gid}
 	}
 
 	if g := dbp.gcache.partialGCache[gid]; g != nil {
@@ -421,7 +449,7 @@ func FindGoroutine(dbp *Target, gid int) (*G, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("unknown goroutine %d", gid)
+	return nil, ErrUnknownGoroutine{gid}
 }
 
 func getGVariable(thread Thread) (*Variable, error) {
@@ -490,16 +518,59 @@ func (g *G) UserCurrent() Location {
 	}
 	for count := 0; it.Next() && count < maxGoroutineUserCurrentDepth; count++ {
 		frame := it.Frame()
-		if frame.Call.Fn != nil {
-			name := frame.Call.Fn.Name
-			if strings.Contains(name, ".") && (!strings.HasPrefix(name, "runtime.") || frame.Call.Fn.exportedRuntime()) {
-				return frame.Call
-			}
+		if isUserCodeFn(frame.Call.Fn) {
+			return frame.Call
 		}
 	}
 	return g.CurrentLoc
 }
 
+// InUserCode returns true if the location returned by UserCurrent is in
+// user code, i.e. if g is not executing exclusively runtime code.
+func (g *G) InUserCode() bool {
+	return isUserCodeFn(g.UserCurrent().Fn)
+}
+
+// isUserCodeFn returns true if fn is not an internal function of the
+// runtime.
+func isUserCodeFn(fn *Function) bool {
+	if fn == nil {
+		return false
+	}
+	return strings.Contains(fn.Name, ".") && (!strings.HasPrefix(fn.Name, "runtime.") || fn.exportedRuntime())
+}
+
+// isStdlibFn returns true if fn belongs to the standard library, including
+// the runtime. The source files of the standard library are found in the
+// GOROOT used to build the executable, which is derived from the location
+// of runtime.main, if that isn't possible (for example because the
+// executable was built with -trimpath) standard library packages are
+// recognized by the first element of their path not containing a dot.
+func isStdlibFn(bi *BinaryInfo, fn *Function) bool {
+	if fn == nil {
+		return false
+	}
+	const runtimeMainFile = "/src/runtime/proc.go"
+	if rtmain := bi.LookupFunc["runtime.main"]; rtmain != nil {
+		if rtfile, _, _ := bi.PCToLine(rtmain.Entry); strings.HasSuffix(rtfile, runtimeMainFile) {
+			gorootSrc := rtfile[:len(rtfile)-len(runtimeMainFile)] + "/src/"
+			file, _, _ := bi.PCToLine(fn.Entry)
+			return strings.HasPrefix(file, gorootSrc)
+		}
+	}
+	pkg := fn.PackageName()
+	if i := strings.Index(pkg, "/"); i >= 0 {
+		pkg = pkg[:i]
+	}
+	return pkg != "" && pkg != "main" && !strings.Contains(pkg, ".")
+}
+
+// StackBounds returns the bounds of the stack of the goroutine, the
+// runtime copies the stack to a new location when it grows or shrinks it.
+func (g *G) StackBounds() (lo, hi uint64) {
+	return g.stack.lo, g.stack.hi
+}
+
 // Go returns the location of the 'go' statement
 // that spawned this goroutine.
 func (g *G) Go() Location {
@@ -818,6 +889,16 @@ func (ng ErrNoGoroutine) Error() string {
 	return fmt.Sprintf("no G executing on thread %d", ng.tid)
 }
 
+// ErrUnknownGoroutine is returned by FindGoroutine when no goroutine with
+// the requested ID exists.
+type ErrUnknownGoroutine struct {
+	gid int
+}
+
+func (err ErrUnknownGoroutine) Error() string {
+	return fmt.Sprintf("unknown goroutine %d", err.gid)
+}
+
 var ErrUnreadableG = errors.New("could not read G struct")
 
 func (v *Variable) parseG() (*G, error) {
@@ -996,6 +1077,39 @@ func Ancestors(p Process, g *G, n int) ([]Ancestor, error) {
 	return r, nil
 }
 
+// ErrCreatorNotRecorded is returned by CreatorID when the runtime of the
+// target process does not record which goroutine created a goroutine.
+var ErrCreatorNotRecorded = errors.New("the creator of goroutines is not recorded, use Go 1.21 or later or set GODEBUG=tracebackancestors=N")
+
+// CreatorID returns the ID of the goroutine that created g, or 0 if g was
+// not created by a go statement.
+// Starting with Go 1.21 the runtime records the creator of every
+// goroutine, for older versions of Go the creator is only known when the
+// target is run with GODEBUG=tracebackancestors=N.
+func CreatorID(p Process, g *G) (int, error) {
+	if g.variable == nil {
+		return 0, ErrCreatorNotRecorded
+	}
+	if pv := g.variable.loadFieldNamed("parentGoid"); pv != nil {
+		n, _ := constant.Int64Val(pv.Value)
+		return int(n), nil
+	}
+	ancestors, err := Ancestors(p, g, 1)
+	if err != nil {
+		if err == errTracebackAncestorsDisabled {
+			return 0, ErrCreatorNotRecorded
+		}
+		return 0, err
+	}
+	if len(ancestors) == 0 {
+		return 0, nil
+	}
+	if ancestors[0].Unreadable != nil {
+		return 0, ancestors[0].Unreadable
+	}
+	return int(ancestors[0].ID), nil
+}
+
 // Stack returns the stack trace of ancestor 'a' as saved by the runtime.
 func (a *Ancestor) Stack(n int) ([]Stackframe, error) {
 	if a.Unreadable != nil {
@@ -1234,6 +1348,9 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 		v.Children = sv.Children
 		v.Len = sv.Len
 		v.Base = sv.Addr
+		if sv.Unreadable == nil && sv.Addr != 0 && recurseLevel <= cfg.MaxVariableRecurse {
+			v.loadChanBuffer(sv, recurseLevel, cfg)
+		}
 
 	case reflect.Map:
 		if recurseLevel <= cfg.MaxVariableRecurse {
@@ -1241,6 +1358,7 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 		} else {
 			// loads length so that the client knows that the map isn't empty
 			v.mapIterator()
+			v.RecurseTruncated = v.Len > 0
 		}
 
 	case reflect.String:
@@ -1270,6 +1388,7 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 			val, v.Unreadable = readStringValue(DereferenceMemory(v.mem), v.Base, v.Len, cfg)
 		}
 		v.Value = constant.MakeString(val)
+		v.LenTruncated = v.Unreadable == nil && v.Flags&VariableCPURegister == 0 && int64(len(val)) < v.Len
 
 	case reflect.Slice, reflect.Array:
 		v.loadArrayValues(recurseLevel, cfg)
@@ -1284,6 +1403,7 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 			v.Children = make([]Variable, 0, len(t.Field))
 			for i, field := range t.Field {
 				if cfg.MaxStructFields >= 0 && len(v.Children) >= cfg.MaxStructFields {
+					v.CapTruncated = true
 					break
 				}
 				f, _ := v.toField(field)
@@ -1291,6 +1411,11 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 				v.Children[i].Name = field.Name
 				v.Children[i].loadValueInternal(recurseLevel+1, cfg)
 			}
+			if cfg.PrettyKnownTypes {
+				v.loadKnownType(recurseLevel, cfg)
+			}
+		} else {
+			v.RecurseTruncated = v.Len > 0
 		}
 
 	case reflect.Interface:
@@ -1556,6 +1681,93 @@ func (v *Variable) loadChanInfo() {
 	}
 }
 
+// maxChanWaiters is the maximum number of goroutines counted in the wait
+// queues of a channel, it protects against loops in corrupted queues.
+const maxChanWaiters = 1 << 16
+
+// loadChanBuffer loads the elements buffered in the channel backed by
+// hchan, starting from recvx and wrapping around the circular buffer, and
+// counts the goroutines waiting in its send and receive queues.
+func (v *Variable) loadChanBuffer(hchan *Variable, recurseLevel int, cfg LoadConfig) {
+	chanType, ok := v.RealType.(*godwarf.ChanType)
+	if !ok {
+		return
+	}
+	structType, ok := hchan.RealType.(*godwarf.StructType)
+	if !ok {
+		return
+	}
+
+	var qcount, dataqsiz, recvx, buf uint64
+	for _, field := range structType.Field {
+		fv, err := hchan.toField(field)
+		if err != nil {
+			return
+		}
+		switch field.Name {
+		case "qcount":
+			qcount, err = readUintRaw(fv.mem, fv.Addr, field.Type.Size())
+		case "dataqsiz":
+			dataqsiz, err = readUintRaw(fv.mem, fv.Addr, field.Type.Size())
+		case "recvx":
+			recvx, err = readUintRaw(fv.mem, fv.Addr, field.Type.Size())
+		case "buf":
+			buf, err = readUintRaw(fv.mem, fv.Addr, int64(v.bi.Arch.PtrSize()))
+		case "sendq":
+			v.ChanSendWaiters = waitqLen(fv)
+		case "recvq":
+			v.ChanRecvWaiters = waitqLen(fv)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if qcount > dataqsiz || buf == 0 {
+		return
+	}
+
+	count := qcount
+	if count > uint64(cfg.MaxArrayValues) {
+		count = uint64(cfg.MaxArrayValues)
+	}
+	stride := uint64(chanType.ElemType.Size())
+	errcount := 0
+	for i := uint64(0); i < count; i++ {
+		idx := (recvx + i) % dataqsiz
+		elemvar := v.newVariable("", buf+idx*stride, chanType.ElemType, hchan.mem)
+		elemvar.loadValueInternal(recurseLevel+1, elementLoadConfig(chanType.ElemType, cfg))
+		if elemvar.Unreadable != nil {
+			errcount++
+		}
+		v.ChanBuffer = append(v.ChanBuffer, *elemvar)
+		if errcount > maxErrCount {
+			break
+		}
+	}
+}
+
+// waitqLen returns the number of sudogs in the runtime.waitq q.
+func waitqLen(q *Variable) int {
+	p, err := q.structMember("first")
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for n < maxChanWaiters {
+		sudog := p.maybeDereference()
+		if sudog.Unreadable != nil || sudog.Addr == 0 {
+			break
+		}
+		n++
+		p, err = sudog.structMember("next")
+		if err != nil {
+			break
+		}
+	}
+	return n
+}
+
 func (v *Variable) loadArrayValues(recurseLevel int, cfg LoadConfig) {
 	if v.Unreadable != nil {
 		return
@@ -1569,6 +1781,7 @@ func (v *Variable) loadArrayValues(recurseLevel int, cfg LoadConfig) {
 	// Cap number of elements
 	if count > int64(cfg.MaxArrayValues) {
 		count = int64(cfg.MaxArrayValues)
+		v.CapTruncated = true
 	}
 
 	if v.stride < maxArrayStridePrefetch {
@@ -1584,7 +1797,7 @@ func (v *Variable) loadArrayValues(recurseLevel int, cfg LoadConfig) {
 
 	for i := int64(0); i < count; i++ {
 		fieldvar := v.newVariable("", uint64(int64(v.Base)+(i*v.stride)), v.fieldType, mem)
-		fieldvar.loadValueInternal(recurseLevel+1, cfg)
+		fieldvar.loadValueInternal(recurseLevel+1, elementLoadConfig(v.fieldType, cfg))
 
 		if fieldvar.Unreadable != nil {
 			errcount++
@@ -1597,6 +1810,38 @@ func (v *Variable) loadArrayValues(recurseLevel int, cfg LoadConfig) {
 	}
 }
 
+// elementLoadConfig returns the configuration used to load an element of
+// type typ of an array, a slice or a map, limiting the number of bytes
+// loaded to cfg.MaxElementBytes.
+func elementLoadConfig(typ godwarf.Type, cfg LoadConfig) LoadConfig {
+	if cfg.MaxElementBytes <= 0 || typ == nil || (typ.Size() <= int64(cfg.MaxElementBytes) && cfg.MaxStringLen <= cfg.MaxElementBytes) {
+		return cfg
+	}
+	if cfg.MaxStringLen > cfg.MaxElementBytes {
+		cfg.MaxStringLen = cfg.MaxElementBytes
+	}
+	switch t := resolveTypedef(typ).(type) {
+	case *godwarf.StructType:
+		n := 0
+		for _, field := range t.Field {
+			if field.ByteOffset+field.Type.Size() > int64(cfg.MaxElementBytes) {
+				break
+			}
+			n++
+		}
+		if cfg.MaxStructFields < 0 || n < cfg.MaxStructFields {
+			cfg.MaxStructFields = n
+		}
+	case *godwarf.ArrayType:
+		if sz := t.Type.Size(); sz > 0 {
+			if n := int64(cfg.MaxElementBytes) / sz; n < int64(cfg.MaxArrayValues) {
+				cfg.MaxArrayValues = int(n)
+			}
+		}
+	}
+	return cfg
+}
+
 func (v *Variable) readComplex(size int64) {
 	var fs int64
 	switch size {
@@ -1615,6 +1860,15 @@ func (v *Variable) readComplex(size int64) {
 	imagvar := v.newVariable("imaginary", v.Addr+uint64(fs), ftyp, v.mem)
 	realvar.loadValue(loadSingleValue)
 	imagvar.loadValue(loadSingleValue)
+	if realvar.FloatSpecial != 0 || imagvar.FloatSpecial != 0 {
+		// infinities and NaNs can not be represented by constant.Value, the
+		// parts are kept so that they can still be printed.
+		v.Children = []Variable{*realvar, *imagvar}
+		v.FloatSpecial = realvar.FloatSpecial
+		if v.FloatSpecial == 0 {
+			v.FloatSpecial = imagvar.FloatSpecial
+		}
+	}
 	v.Value = constant.BinaryOp(realvar.Value, token.ADD, constant.MakeImag(imagvar.Value))
 }
 
@@ -1838,7 +2092,11 @@ func (v *Variable) loadMap(recurseLevel int, cfg LoadConfig) {
 	}
 	it.maxNumBuckets = uint64(cfg.MaxMapBuckets)
 
-	if v.Len == 0 || int64(v.mapSkip) >= v.Len || cfg.MaxArrayValues == 0 {
+	if v.Len == 0 || int64(v.mapSkip) >= v.Len {
+		return
+	}
+	if cfg.MaxArrayValues == 0 {
+		v.CapTruncated = true
 		return
 	}
 
@@ -1859,8 +2117,8 @@ func (v *Variable) loadMap(recurseLevel int, cfg LoadConfig) {
 		} else {
 			val = v.newVariable("", it.values.Addr, it.values.fieldType, DereferenceMemory(v.mem))
 		}
-		key.loadValueInternal(recurseLevel+1, cfg)
-		val.loadValueInternal(recurseLevel+1, cfg)
+		key.loadValueInternal(recurseLevel+1, elementLoadConfig(key.DwarfType, cfg))
+		val.loadValueInternal(recurseLevel+1, elementLoadConfig(val.DwarfType, cfg))
 		if key.Unreadable != nil || val.Unreadable != nil {
 			errcount++
 		}
@@ -1873,6 +2131,7 @@ func (v *Variable) loadMap(recurseLevel int, cfg LoadConfig) {
 			break
 		}
 	}
+	v.CapTruncated = errcount <= maxErrCount && int64(v.mapSkip+count) < v.Len
 }
 
 type mapIterator struct {
@@ -2218,6 +2477,7 @@ func (v *Variable) loadInterface(recurseLevel int, loadData bool, cfg LoadConfig
 		v.Children[0].loadValueInternal(recurseLevel, cfg)
 	} else {
 		v.Children[0].OnlyAddr = true
+		v.RecurseTruncated = loadData
 	}
 }
 
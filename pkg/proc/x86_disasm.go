@@ -37,9 +37,26 @@ func x86AsmDecode(asmInst *AsmInstruction, mem []byte, regs *op.DwarfRegisters,
 	}
 
 	asmInst.DestLoc = resolveCallArgX86(&inst, asmInst.Loc.PC, asmInst.AtPC, regs, memrw, bi)
+	asmInst.BranchTarget = branchTargetX86(&inst)
 	return nil
 }
 
+// branchTargetX86 returns the destination of inst if it is a direct jump,
+// 0 otherwise.
+func branchTargetX86(inst *x86asm.Inst) uint64 {
+	switch inst.Op {
+	case x86asm.JMP, x86asm.JA, x86asm.JAE, x86asm.JB, x86asm.JBE, x86asm.JCXZ, x86asm.JE, x86asm.JECXZ, x86asm.JG, x86asm.JGE, x86asm.JL, x86asm.JLE, x86asm.JNE, x86asm.JNO, x86asm.JNP, x86asm.JNS, x86asm.JO, x86asm.JP, x86asm.JRCXZ, x86asm.JS, x86asm.LOOP, x86asm.LOOPE, x86asm.LOOPNE:
+		// ok
+	default:
+		return 0
+	}
+	// PC relative arguments were converted to immediates by patchPCRelX86
+	if imm, ok := inst.Args[0].(x86asm.Imm); ok {
+		return uint64(imm)
+	}
+	return 0
+}
+
 // converts PC relative arguments to absolute addresses
 func patchPCRelX86(pc uint64, inst *x86asm.Inst) {
 	for i := range inst.Args {
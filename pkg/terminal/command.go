@@ -266,11 +266,21 @@ To only display user (or runtime) goroutines, use:
 	goroutines -with user
 	goroutines -without user
 
+To only display goroutines whose user location is (or is not) in user code, excluding goroutines that are only executing runtime code, use:
+
+	goroutines -with usercode
+	goroutines -without usercode
+
+To only display goroutines with (or without) the specified scheduling status, one of idle, runnable, running, syscall, waiting, dead or copystack, use:
+
+	goroutines -with status <status>
+	goroutines -without status <status>
+
 GROUPING
 
-	goroutines -group (userloc|curloc|goloc|startloc|running|user)
+	goroutines -group (userloc|curloc|goloc|startloc|running|user|usercode|status)
 
-Groups goroutines by the given location, running status or user classification, up to 5 goroutines per group will be displayed as well as the total number of goroutines in the group.
+Groups goroutines by the given location, running status, user classification or scheduling status, up to 5 goroutines per group will be displayed as well as the total number of goroutines in the group.
 
 	goroutines -group label key
 
@@ -435,6 +445,12 @@ Supported commands: print, stack and goroutine)`},
 
 Specifies that the breakpoint, tracepoint or watchpoint should break only if the boolean expression is true.
 
+The builtin changed(expr) can be used in the boolean expression to break only when the value of expr is different from the one it had the last time the condition was evaluated, for example:
+
+	condition 1 changed(x)
+
+See also: "help expr".
+
 With the -hitcount option a condition on the breakpoint hit count can be set, the following operators are supported
 
 	condition -hitcount bp > n
@@ -932,6 +948,10 @@ func readGoroutinesFilterKind(args []string, i int) (api.GoroutineField, error)
 		return api.GoroutineRunning, nil
 	case "user":
 		return api.GoroutineUser, nil
+	case "status":
+		return api.GoroutineStatus, nil
+	case "usercode":
+		return api.GoroutineUserCode, nil
 	default:
 		return api.GoroutineFieldNone, fmt.Errorf("unrecognized argument to %s %s", args[i-1], args[i])
 	}
@@ -946,7 +966,7 @@ func readGoroutinesFilter(args []string, pi *int) (*api.ListGoroutinesFilter, er
 	}
 	*pi++
 	switch r.Kind {
-	case api.GoroutineRunning, api.GoroutineUser:
+	case api.GoroutineRunning, api.GoroutineUser, api.GoroutineUserCode:
 		return r, nil
 	}
 	if *pi+1 >= len(args) {
@@ -1376,6 +1396,17 @@ func parseOneRedirect(w []string, redirs *[3]string) ([]string, bool, error) {
 	return w, false, nil
 }
 
+// printOutput writes what the target wrote to stdout and stderr, reported
+// in state when the debugger streams the output of the target.
+func printOutput(state *api.DebuggerState) {
+	if len(state.Stdout) > 0 {
+		os.Stdout.Write(state.Stdout)
+	}
+	if len(state.Stderr) > 0 {
+		os.Stderr.Write(state.Stderr)
+	}
+}
+
 func printcontextNoState(t *Term) {
 	state, _ := t.client.GetState()
 	if state == nil || state.CurrentThread == nil {
@@ -1418,10 +1449,16 @@ func (c *Commands) cont(t *Term, ctx callContext, args string) error {
 	stateChan := t.client.Continue()
 	var state *api.DebuggerState
 	for state = range stateChan {
+		printOutput(state)
 		if state.Err != nil {
 			printcontextNoState(t)
 			return state.Err
 		}
+		if state.OutputInProgress {
+			// the target was only stopped to report its output, the client
+			// resumes it.
+			continue
+		}
 		printcontext(t, state)
 	}
 	printfile(t, state.CurrentThread.File, state.CurrentThread.Line, true)
@@ -1441,6 +1478,7 @@ func continueUntilCompleteNext(t *Term, state *api.DebuggerState, op string, sho
 		stateChan := t.client.DirectionCongruentContinue()
 		var state *api.DebuggerState
 		for state = range stateChan {
+			printOutput(state)
 			if state.Err != nil {
 				printcontextNoState(t)
 				return state.Err
@@ -2478,6 +2516,9 @@ func libraries(t *Term, ctx callContext, args string) error {
 	d := digits(len(libs))
 	for i := range libs {
 		fmt.Printf("%"+strconv.Itoa(d)+"d. %#x %s\n", i, libs[i].Address, libs[i].Path)
+		if libs[i].LoadError != "" {
+			fmt.Printf("    Load error: %s\n", libs[i].LoadError)
+		}
 	}
 	return nil
 }
@@ -2632,6 +2673,10 @@ func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 	bp := th.Breakpoint
 	bpi := th.BreakpointInfo
 
+	if bpi.AssertError != "" {
+		fmt.Printf("\t%s\n", bpi.AssertError)
+	}
+
 	if bp.TraceReturn {
 		return
 	}
@@ -2645,6 +2690,11 @@ func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 		fmt.Println()
 	}
 
+	if bpi.TraceMessage != "" {
+		tracepointnl()
+		fmt.Printf("\t%s\n", bpi.TraceMessage)
+	}
+
 	if bpi.Goroutine != nil {
 		tracepointnl()
 		writeGoroutineLong(t, os.Stdout, bpi.Goroutine, "\t")
@@ -12,6 +12,46 @@ import (
 func (env *Env) starlarkPredeclare() starlark.StringDict {
 	r := starlark.StringDict{}
 
+	r["add_watch"] = starlark.NewBuiltin("add_watch", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.AddWatchIn
+		var rpcRet rpc2.AddWatchOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("AddWatch", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["amend_breakpoint"] = starlark.NewBuiltin("amend_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -100,6 +140,159 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["break_on_next_panic"] = starlark.NewBuiltin("break_on_next_panic", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.BreakOnNextPanicIn
+		var rpcRet rpc2.BreakOnNextPanicOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.GoroutineID, "GoroutineID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "GoroutineID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineID, "GoroutineID")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("BreakOnNextPanic", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["breakpoint_hit_stats"] = starlark.NewBuiltin("breakpoint_hit_stats", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.BreakpointHitStatsIn
+		var rpcRet rpc2.BreakpointHitStatsOut
+		err := env.ctx.Client().CallAPI("BreakpointHitStats", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["breakpoints_sharing_address"] = starlark.NewBuiltin("breakpoints_sharing_address", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.BreakpointsSharingAddressIn
+		var rpcRet rpc2.BreakpointsSharingAddressOut
+		err := env.ctx.Client().CallAPI("BreakpointsSharingAddress", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["build_info"] = starlark.NewBuiltin("build_info", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.BuildInfoIn
+		var rpcRet rpc2.BuildInfoOut
+		err := env.ctx.Client().CallAPI("BuildInfo", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["call_injection_stack"] = starlark.NewBuiltin("call_injection_stack", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.CallInjectionStackIn
+		var rpcRet rpc2.CallInjectionStackOut
+		err := env.ctx.Client().CallAPI("CallInjectionStack", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["call_method"] = starlark.NewBuiltin("call_method", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.CallMethodIn
+		var rpcRet rpc2.CallMethodOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Receiver, "Receiver")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Method, "Method")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.Args, "Args")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 4 && args[4] != starlark.None {
+			err := unmarshalStarlarkValue(args[4], &rpcArgs.UnsafeCall, "UnsafeCall")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 5 && args[5] != starlark.None {
+			err := unmarshalStarlarkValue(args[5], &rpcArgs.ReturnInfoLoadConfig, "ReturnInfoLoadConfig")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.ReturnInfoLoadConfig = &cfg
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Receiver":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Receiver, "Receiver")
+			case "Method":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Method, "Method")
+			case "Args":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Args, "Args")
+			case "UnsafeCall":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.UnsafeCall, "UnsafeCall")
+			case "ReturnInfoLoadConfig":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ReturnInfoLoadConfig, "ReturnInfoLoadConfig")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("CallMethod", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["cancel_next"] = starlark.NewBuiltin("cancel_next", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -142,6 +335,36 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["children"] = starlark.NewBuiltin("children", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ChildrenIn
+		var rpcRet rpc2.ChildrenOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.GoroutineID, "GoroutineID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "GoroutineID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineID, "GoroutineID")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("Children", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["clear_breakpoint"] = starlark.NewBuiltin("clear_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -255,6 +478,54 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
+		if len(args) > 6 && args[6] != starlark.None {
+			err := unmarshalStarlarkValue(args[6], &rpcArgs.CallTimeout, "CallTimeout")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 7 && args[7] != starlark.None {
+			err := unmarshalStarlarkValue(args[7], &rpcArgs.Count, "Count")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 8 && args[8] != starlark.None {
+			err := unmarshalStarlarkValue(args[8], &rpcArgs.StepExcludingPackages, "StepExcludingPackages")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 9 && args[9] != starlark.None {
+			err := unmarshalStarlarkValue(args[9], &rpcArgs.SkipTracepoints, "SkipTracepoints")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 10 && args[10] != starlark.None {
+			err := unmarshalStarlarkValue(args[10], &rpcArgs.File, "File")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 11 && args[11] != starlark.None {
+			err := unmarshalStarlarkValue(args[11], &rpcArgs.Line, "Line")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 12 && args[12] != starlark.None {
+			err := unmarshalStarlarkValue(args[12], &rpcArgs.Functions, "Functions")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 13 && args[13] != starlark.None {
+			err := unmarshalStarlarkValue(args[13], &rpcArgs.Signal, "Signal")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
@@ -270,6 +541,22 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
 			case "UnsafeCall":
 				err = unmarshalStarlarkValue(kv[1], &rpcArgs.UnsafeCall, "UnsafeCall")
+			case "CallTimeout":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.CallTimeout, "CallTimeout")
+			case "Count":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Count, "Count")
+			case "StepExcludingPackages":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.StepExcludingPackages, "StepExcludingPackages")
+			case "SkipTracepoints":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.SkipTracepoints, "SkipTracepoints")
+			case "File":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.File, "File")
+			case "Line":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Line, "Line")
+			case "Functions":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Functions, "Functions")
+			case "Signal":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Signal, "Signal")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -313,6 +600,44 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["create_breakpoints_in_package"] = starlark.NewBuiltin("create_breakpoints_in_package", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.CreateBreakpointsInPackageIn
+		var rpcRet rpc2.CreateBreakpointsInPackageOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Package, "Package")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.EntryOnly, "EntryOnly")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Package":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Package, "Package")
+			case "EntryOnly":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.EntryOnly, "EntryOnly")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("CreateBreakpointsInPackage", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["create_watchpoint"] = starlark.NewBuiltin("create_watchpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -361,23 +686,86 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["detach"] = starlark.NewBuiltin("detach", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["debugger_stats"] = starlark.NewBuiltin("debugger_stats", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.DetachIn
-		var rpcRet rpc2.DetachOut
+		var rpcArgs rpc2.DebuggerStatsIn
+		var rpcRet rpc2.DebuggerStatsOut
+		err := env.ctx.Client().CallAPI("DebuggerStats", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["deferred_calls"] = starlark.NewBuiltin("deferred_calls", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.DeferredCallsIn
+		var rpcRet rpc2.DeferredCallsOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Kill, "Kill")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("DeferredCalls", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["detach"] = starlark.NewBuiltin("detach", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.DetachIn
+		var rpcRet rpc2.DetachOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Kill, "Kill")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Signal, "Signal")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
 			case "Kill":
 				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Kill, "Kill")
+			case "Signal":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Signal, "Signal")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -391,6 +779,36 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["disable_breakpoint"] = starlark.NewBuiltin("disable_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.DisableBreakpointIn
+		var rpcRet rpc2.DisableBreakpointOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Id":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("DisableBreakpoint", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["disassemble"] = starlark.NewBuiltin("disassemble", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -447,6 +865,54 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["disassemble_function"] = starlark.NewBuiltin("disassemble_function", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.DisassembleFunctionIn
+		var rpcRet rpc2.DisassembleFunctionOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.FunctionName, "FunctionName")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Flavour, "Flavour")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "FunctionName":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.FunctionName, "FunctionName")
+			case "Flavour":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Flavour, "Flavour")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("DisassembleFunction", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["dump_cancel"] = starlark.NewBuiltin("dump_cancel", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -519,6 +985,36 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["enable_breakpoint"] = starlark.NewBuiltin("enable_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.EnableBreakpointIn
+		var rpcRet rpc2.EnableBreakpointOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Id":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("EnableBreakpoint", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	r["eval"] = starlark.NewBuiltin("eval", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
@@ -570,31 +1066,34 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["examine_memory"] = starlark.NewBuiltin("examine_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["eval_across_goroutines"] = starlark.NewBuiltin("eval_across_goroutines", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ExamineMemoryIn
-		var rpcRet rpc2.ExaminedMemoryOut
+		var rpcArgs rpc2.EvalAcrossGoroutinesIn
+		var rpcRet rpc2.EvalAcrossGoroutinesOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Address, "Address")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Expr, "Expr")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
 		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Length, "Length")
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
 		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Address":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Address, "Address")
-			case "Length":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Length, "Length")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -602,18 +1101,18 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ExamineMemory", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("EvalAcrossGoroutines", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["find_location"] = starlark.NewBuiltin("find_location", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["eval_to_j_s_o_n"] = starlark.NewBuiltin("eval_to_j_s_o_n", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.FindLocationIn
-		var rpcRet rpc2.FindLocationOut
+		var rpcArgs rpc2.EvalToJSONIn
+		var rpcRet rpc2.EvalToJSONOut
 		if len(args) > 0 && args[0] != starlark.None {
 			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
 			if err != nil {
@@ -623,34 +1122,29 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 			rpcArgs.Scope = env.ctx.Scope()
 		}
 		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Loc, "Loc")
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
 		if len(args) > 2 && args[2] != starlark.None {
-			err := unmarshalStarlarkValue(args[2], &rpcArgs.IncludeNonExecutableLines, "IncludeNonExecutableLines")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
-			}
-		}
-		if len(args) > 3 && args[3] != starlark.None {
-			err := unmarshalStarlarkValue(args[3], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Cfg, "Cfg")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
 		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
 			case "Scope":
 				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
-			case "Loc":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Loc, "Loc")
-			case "IncludeNonExecutableLines":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.IncludeNonExecutableLines, "IncludeNonExecutableLines")
-			case "SubstitutePathRules":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -658,20 +1152,26 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("FindLocation", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("EvalToJSON", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["function_return_locations"] = starlark.NewBuiltin("function_return_locations", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["examine_memory"] = starlark.NewBuiltin("examine_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.FunctionReturnLocationsIn
-		var rpcRet rpc2.FunctionReturnLocationsOut
+		var rpcArgs rpc2.ExamineMemoryIn
+		var rpcRet rpc2.ExaminedMemoryOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.FnName, "FnName")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Address, "Address")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Length, "Length")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -679,8 +1179,10 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "FnName":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.FnName, "FnName")
+			case "Address":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Address, "Address")
+			case "Length":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Length, "Length")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -688,26 +1190,26 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("FunctionReturnLocations", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("ExamineMemory", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["get_breakpoint"] = starlark.NewBuiltin("get_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["export_chrome_trace"] = starlark.NewBuiltin("export_chrome_trace", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.GetBreakpointIn
-		var rpcRet rpc2.GetBreakpointOut
+		var rpcArgs rpc2.ExportChromeTraceIn
+		var rpcRet rpc2.ExportChromeTraceOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.FromEvent, "FromEvent")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
 		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Name, "Name")
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.ToEvent, "ToEvent")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -715,10 +1217,10 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Id":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
-			case "Name":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Name, "Name")
+			case "FromEvent":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.FromEvent, "FromEvent")
+			case "ToEvent":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ToEvent, "ToEvent")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -726,131 +1228,63 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("GetBreakpoint", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("ExportChromeTrace", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["get_thread"] = starlark.NewBuiltin("get_thread", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["find_location"] = starlark.NewBuiltin("find_location", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.GetThreadIn
-		var rpcRet rpc2.GetThreadOut
+		var rpcArgs rpc2.FindLocationIn
+		var rpcRet rpc2.FindLocationOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
 		}
-		for _, kv := range kwargs {
-			var err error
-			switch kv[0].(starlark.String) {
-			case "Id":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
-			default:
-				err = fmt.Errorf("unknown argument %q", kv[0])
-			}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Loc, "Loc")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("GetThread", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.IncludeNonExecutableLines, "IncludeNonExecutableLines")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
 		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["is_multiclient"] = starlark.NewBuiltin("is_multiclient", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
 		}
-		var rpcArgs rpc2.IsMulticlientIn
-		var rpcRet rpc2.IsMulticlientOut
-		err := env.ctx.Client().CallAPI("IsMulticlient", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
-		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["last_modified"] = starlark.NewBuiltin("last_modified", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
-		}
-		var rpcArgs rpc2.LastModifiedIn
-		var rpcRet rpc2.LastModifiedOut
-		err := env.ctx.Client().CallAPI("LastModified", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
-		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["breakpoints"] = starlark.NewBuiltin("breakpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
-		}
-		var rpcArgs rpc2.ListBreakpointsIn
-		var rpcRet rpc2.ListBreakpointsOut
-		err := env.ctx.Client().CallAPI("ListBreakpoints", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
-		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["checkpoints"] = starlark.NewBuiltin("checkpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
-		}
-		var rpcArgs rpc2.ListCheckpointsIn
-		var rpcRet rpc2.ListCheckpointsOut
-		err := env.ctx.Client().CallAPI("ListCheckpoints", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
-		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["dynamic_libraries"] = starlark.NewBuiltin("dynamic_libraries", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
-		}
-		var rpcArgs rpc2.ListDynamicLibrariesIn
-		var rpcRet rpc2.ListDynamicLibrariesOut
-		err := env.ctx.Client().CallAPI("ListDynamicLibraries", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
-		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["function_args"] = starlark.NewBuiltin("function_args", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
-		}
-		var rpcArgs rpc2.ListFunctionArgsIn
-		var rpcRet rpc2.ListFunctionArgsOut
-		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
-			}
-		} else {
-			rpcArgs.Scope = env.ctx.Scope()
-		}
-		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+		if len(args) > 4 && args[4] != starlark.None {
+			err := unmarshalStarlarkValue(args[4], &rpcArgs.RankedCandidates, "RankedCandidates")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		} else {
-			rpcArgs.Cfg = env.ctx.LoadConfig()
 		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
 			case "Scope":
 				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
-			case "Cfg":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			case "Loc":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Loc, "Loc")
+			case "IncludeNonExecutableLines":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.IncludeNonExecutableLines, "IncludeNonExecutableLines")
+			case "SubstitutePathRules":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
+			case "RankedCandidates":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.RankedCandidates, "RankedCandidates")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -858,20 +1292,20 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListFunctionArgs", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("FindLocation", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["functions"] = starlark.NewBuiltin("functions", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["find_type"] = starlark.NewBuiltin("find_type", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListFunctionsIn
-		var rpcRet rpc2.ListFunctionsOut
+		var rpcArgs rpc2.FindTypeIn
+		var rpcRet rpc2.FindTypeOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Name, "Name")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -879,8 +1313,8 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Filter":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			case "Name":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Name, "Name")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -888,38 +1322,50 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListFunctions", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("FindType", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["goroutines"] = starlark.NewBuiltin("goroutines", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["function_line_entries"] = starlark.NewBuiltin("function_line_entries", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListGoroutinesIn
-		var rpcRet rpc2.ListGoroutinesOut
+		var rpcArgs rpc2.FunctionLineEntriesIn
+		var rpcRet rpc2.FunctionLineEntriesOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Start, "Start")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.FnName, "FnName")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Count, "Count")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "FnName":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.FnName, "FnName")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
-		}
-		if len(args) > 2 && args[2] != starlark.None {
-			err := unmarshalStarlarkValue(args[2], &rpcArgs.Filters, "Filters")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		if len(args) > 3 && args[3] != starlark.None {
-			err := unmarshalStarlarkValue(args[3], &rpcArgs.GoroutineGroupingOptions, "GoroutineGroupingOptions")
+		err := env.ctx.Client().CallAPI("FunctionLineEntries", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["function_return_locations"] = starlark.NewBuiltin("function_return_locations", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.FunctionReturnLocationsIn
+		var rpcRet rpc2.FunctionReturnLocationsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.FnName, "FnName")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -927,14 +1373,8 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Start":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Start, "Start")
-			case "Count":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Count, "Count")
-			case "Filters":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filters, "Filters")
-			case "GoroutineGroupingOptions":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineGroupingOptions, "GoroutineGroupingOptions")
+			case "FnName":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.FnName, "FnName")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -942,41 +1382,37 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListGoroutines", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("FunctionReturnLocations", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["local_vars"] = starlark.NewBuiltin("local_vars", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["get_breakpoint"] = starlark.NewBuiltin("get_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListLocalVarsIn
-		var rpcRet rpc2.ListLocalVarsOut
+		var rpcArgs rpc2.GetBreakpointIn
+		var rpcRet rpc2.GetBreakpointOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		} else {
-			rpcArgs.Scope = env.ctx.Scope()
 		}
 		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Name, "Name")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		} else {
-			rpcArgs.Cfg = env.ctx.LoadConfig()
 		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Scope":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
-			case "Cfg":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			case "Id":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
+			case "Name":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Name, "Name")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -984,39 +1420,29 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListLocalVars", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("GetBreakpoint", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["package_vars"] = starlark.NewBuiltin("package_vars", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["get_goroutine_dump"] = starlark.NewBuiltin("get_goroutine_dump", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListPackageVarsIn
-		var rpcRet rpc2.ListPackageVarsOut
+		var rpcArgs rpc2.GetGoroutineDumpIn
+		var rpcRet rpc2.GetGoroutineDumpOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
-			}
-		}
-		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		} else {
-			rpcArgs.Cfg = env.ctx.LoadConfig()
 		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Filter":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
-			case "Cfg":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			case "Id":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1024,20 +1450,20 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListPackageVars", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("GetGoroutineDump", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["packages_build_info"] = starlark.NewBuiltin("packages_build_info", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["get_thread"] = starlark.NewBuiltin("get_thread", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListPackagesBuildInfoIn
-		var rpcRet rpc2.ListPackagesBuildInfoOut
+		var rpcArgs rpc2.GetThreadIn
+		var rpcRet rpc2.GetThreadOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.IncludeFiles, "IncludeFiles")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Id, "Id")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -1045,8 +1471,8 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "IncludeFiles":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.IncludeFiles, "IncludeFiles")
+			case "Id":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Id, "Id")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1054,48 +1480,29 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListPackagesBuildInfo", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("GetThread", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["registers"] = starlark.NewBuiltin("registers", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["goroutine"] = starlark.NewBuiltin("goroutine", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListRegistersIn
-		var rpcRet rpc2.ListRegistersOut
+		var rpcArgs rpc2.GoroutineIn
+		var rpcRet rpc2.GoroutineOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.ThreadID, "ThreadID")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
-			}
-		}
-		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.IncludeFp, "IncludeFp")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
-			}
-		}
-		if len(args) > 2 && args[2] != starlark.None {
-			err := unmarshalStarlarkValue(args[2], &rpcArgs.Scope, "Scope")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.ID, "ID")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		} else {
-			scope := env.ctx.Scope()
-			rpcArgs.Scope = &scope
 		}
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "ThreadID":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ThreadID, "ThreadID")
-			case "IncludeFp":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.IncludeFp, "IncludeFp")
-			case "Scope":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "ID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ID, "ID")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1103,20 +1510,20 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListRegisters", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("Goroutine", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["sources"] = starlark.NewBuiltin("sources", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["goroutine_stacktrace_diff"] = starlark.NewBuiltin("goroutine_stacktrace_diff", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListSourcesIn
-		var rpcRet rpc2.ListSourcesOut
+		var rpcArgs rpc2.GoroutineStacktraceDiffIn
+		var rpcRet rpc2.GoroutineStacktraceDiffOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Prev, "Prev")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -1124,8 +1531,8 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Filter":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			case "Prev":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Prev, "Prev")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1133,32 +1540,20 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListSources", &rpcArgs, &rpcRet)
-		if err != nil {
-			return starlark.None, err
-		}
-		return env.interfaceToStarlarkValue(rpcRet), nil
-	})
-	r["threads"] = starlark.NewBuiltin("threads", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		if err := isCancelled(thread); err != nil {
-			return starlark.None, decorateError(thread, err)
-		}
-		var rpcArgs rpc2.ListThreadsIn
-		var rpcRet rpc2.ListThreadsOut
-		err := env.ctx.Client().CallAPI("ListThreads", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("GoroutineStacktraceDiff", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["types"] = starlark.NewBuiltin("types", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["inlined_packages"] = starlark.NewBuiltin("inlined_packages", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ListTypesIn
-		var rpcRet rpc2.ListTypesOut
+		var rpcArgs rpc2.InlinedPackagesIn
+		var rpcRet rpc2.InlinedPackagesOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.FnName, "FnName")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -1166,8 +1561,8 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Filter":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			case "FnName":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.FnName, "FnName")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1175,7 +1570,636 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("ListTypes", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("InlinedPackages", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["is_multiclient"] = starlark.NewBuiltin("is_multiclient", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.IsMulticlientIn
+		var rpcRet rpc2.IsMulticlientOut
+		err := env.ctx.Client().CallAPI("IsMulticlient", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["last_modified"] = starlark.NewBuiltin("last_modified", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.LastModifiedIn
+		var rpcRet rpc2.LastModifiedOut
+		err := env.ctx.Client().CallAPI("LastModified", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["last_operation_stats"] = starlark.NewBuiltin("last_operation_stats", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.LastOperationStatsIn
+		var rpcRet rpc2.LastOperationStatsOut
+		err := env.ctx.Client().CallAPI("LastOperationStats", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["breakpoints"] = starlark.NewBuiltin("breakpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListBreakpointsIn
+		var rpcRet rpc2.ListBreakpointsOut
+		err := env.ctx.Client().CallAPI("ListBreakpoints", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["checkpoints"] = starlark.NewBuiltin("checkpoints", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListCheckpointsIn
+		var rpcRet rpc2.ListCheckpointsOut
+		err := env.ctx.Client().CallAPI("ListCheckpoints", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["dynamic_libraries"] = starlark.NewBuiltin("dynamic_libraries", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListDynamicLibrariesIn
+		var rpcRet rpc2.ListDynamicLibrariesOut
+		err := env.ctx.Client().CallAPI("ListDynamicLibraries", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["eval_aliases"] = starlark.NewBuiltin("eval_aliases", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListEvalAliasesIn
+		var rpcRet rpc2.ListEvalAliasesOut
+		err := env.ctx.Client().CallAPI("ListEvalAliases", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["function_args"] = starlark.NewBuiltin("function_args", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListFunctionArgsIn
+		var rpcRet rpc2.ListFunctionArgsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Cfg = env.ctx.LoadConfig()
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListFunctionArgs", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["functions"] = starlark.NewBuiltin("functions", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListFunctionsIn
+		var rpcRet rpc2.ListFunctionsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListFunctions", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["goroutines"] = starlark.NewBuiltin("goroutines", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListGoroutinesIn
+		var rpcRet rpc2.ListGoroutinesOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Start, "Start")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Count, "Count")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Filters, "Filters")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.GoroutineGroupingOptions, "GoroutineGroupingOptions")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Start":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Start, "Start")
+			case "Count":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Count, "Count")
+			case "Filters":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filters, "Filters")
+			case "GoroutineGroupingOptions":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineGroupingOptions, "GoroutineGroupingOptions")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListGoroutines", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["local_vars"] = starlark.NewBuiltin("local_vars", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListLocalVarsIn
+		var rpcRet rpc2.ListLocalVarsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Cfg = env.ctx.LoadConfig()
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListLocalVars", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["package_vars"] = starlark.NewBuiltin("package_vars", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListPackageVarsIn
+		var rpcRet rpc2.ListPackageVarsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Cfg = env.ctx.LoadConfig()
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListPackageVars", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["packages_build_info"] = starlark.NewBuiltin("packages_build_info", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListPackagesBuildInfoIn
+		var rpcRet rpc2.ListPackagesBuildInfoOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.IncludeFiles, "IncludeFiles")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "IncludeFiles":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.IncludeFiles, "IncludeFiles")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListPackagesBuildInfo", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["registers"] = starlark.NewBuiltin("registers", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListRegistersIn
+		var rpcRet rpc2.ListRegistersOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.ThreadID, "ThreadID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.IncludeFp, "IncludeFp")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			scope := env.ctx.Scope()
+			rpcArgs.Scope = &scope
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "ThreadID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ThreadID, "ThreadID")
+			case "IncludeFp":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.IncludeFp, "IncludeFp")
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListRegisters", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["sources"] = starlark.NewBuiltin("sources", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListSourcesIn
+		var rpcRet rpc2.ListSourcesOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListSources", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["sources_grouped"] = starlark.NewBuiltin("sources_grouped", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListSourcesGroupedIn
+		var rpcRet rpc2.ListSourcesGroupedOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListSourcesGrouped", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["threads"] = starlark.NewBuiltin("threads", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListThreadsIn
+		var rpcRet rpc2.ListThreadsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListThreads", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["types"] = starlark.NewBuiltin("types", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ListTypesIn
+		var rpcRet rpc2.ListTypesOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Filter, "Filter")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Filter":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Filter, "Filter")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ListTypes", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["mutex_owner"] = starlark.NewBuiltin("mutex_owner", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.MutexOwnerIn
+		var rpcRet rpc2.MutexOwnerOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("MutexOwner", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["park_goroutine"] = starlark.NewBuiltin("park_goroutine", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ParkGoroutineIn
+		var rpcRet rpc2.ParkGoroutineOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.GoroutineID, "GoroutineID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "GoroutineID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineID, "GoroutineID")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ParkGoroutine", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["preview_breakpoint"] = starlark.NewBuiltin("preview_breakpoint", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.PreviewBreakpointIn
+		var rpcRet rpc2.PreviewBreakpointOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Loc, "Loc")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Loc":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Loc, "Loc")
+			case "SubstitutePathRules":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("PreviewBreakpoint", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["process_memory_maps"] = starlark.NewBuiltin("process_memory_maps", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ProcessMemoryMapsIn
+		var rpcRet rpc2.ProcessMemoryMapsOut
+		err := env.ctx.Client().CallAPI("ProcessMemoryMaps", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
@@ -1185,64 +2209,547 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.ProcessPidIn
-		var rpcRet rpc2.ProcessPidOut
-		err := env.ctx.Client().CallAPI("ProcessPid", &rpcArgs, &rpcRet)
+		var rpcArgs rpc2.ProcessPidIn
+		var rpcRet rpc2.ProcessPidOut
+		err := env.ctx.Client().CallAPI("ProcessPid", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["read_bytes"] = starlark.NewBuiltin("read_bytes", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ReadBytesIn
+		var rpcRet rpc2.ReadBytesOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Limit, "Limit")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "Limit":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Limit, "Limit")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ReadBytes", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["read_memory"] = starlark.NewBuiltin("read_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ReadMemoryIn
+		var rpcRet rpc2.ReadMemoryOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Addr, "Addr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Count, "Count")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Addr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Addr, "Addr")
+			case "Count":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Count, "Count")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ReadMemory", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["recorded"] = starlark.NewBuiltin("recorded", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.RecordedIn
+		var rpcRet rpc2.RecordedOut
+		err := env.ctx.Client().CallAPI("Recorded", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["references_to"] = starlark.NewBuiltin("references_to", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ReferencesToIn
+		var rpcRet rpc2.ReferencesToOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Addr, "Addr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.MaxResults, "MaxResults")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Addr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Addr, "Addr")
+			case "MaxResults":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.MaxResults, "MaxResults")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ReferencesTo", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["remove_watch"] = starlark.NewBuiltin("remove_watch", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.RemoveWatchIn
+		var rpcRet rpc2.RemoveWatchOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.ID, "ID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "ID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ID, "ID")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("RemoveWatch", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["resolve_address"] = starlark.NewBuiltin("resolve_address", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ResolveAddressIn
+		var rpcRet rpc2.ResolveAddressOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.PC, "PC")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "PC":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.PC, "PC")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ResolveAddress", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["recorded"] = starlark.NewBuiltin("recorded", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["restart"] = starlark.NewBuiltin("restart", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.RestartIn
+		var rpcRet rpc2.RestartOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Position, "Position")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.ResetArgs, "ResetArgs")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.NewArgs, "NewArgs")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.Rerecord, "Rerecord")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 4 && args[4] != starlark.None {
+			err := unmarshalStarlarkValue(args[4], &rpcArgs.Rebuild, "Rebuild")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 5 && args[5] != starlark.None {
+			err := unmarshalStarlarkValue(args[5], &rpcArgs.NewRedirects, "NewRedirects")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 6 && args[6] != starlark.None {
+			err := unmarshalStarlarkValue(args[6], &rpcArgs.NewEnv, "NewEnv")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Position":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Position, "Position")
+			case "ResetArgs":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ResetArgs, "ResetArgs")
+			case "NewArgs":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.NewArgs, "NewArgs")
+			case "Rerecord":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Rerecord, "Rerecord")
+			case "Rebuild":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Rebuild, "Rebuild")
+			case "NewRedirects":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.NewRedirects, "NewRedirects")
+			case "NewEnv":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.NewEnv, "NewEnv")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("Restart", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["set_expr"] = starlark.NewBuiltin("set_expr", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.SetIn
+		var rpcRet rpc2.SetOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Symbol, "Symbol")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Value, "Value")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Symbol":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Symbol, "Symbol")
+			case "Value":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Value, "Value")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("Set", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["set_default_load_config"] = starlark.NewBuiltin("set_default_load_config", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.SetDefaultLoadConfigIn
+		var rpcRet rpc2.SetDefaultLoadConfigOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("SetDefaultLoadConfig", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["set_eval_alias"] = starlark.NewBuiltin("set_eval_alias", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.SetEvalAliasIn
+		var rpcRet rpc2.SetEvalAliasOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Name, "Name")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Name":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Name, "Name")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("SetEvalAlias", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["set_goroutine_labels"] = starlark.NewBuiltin("set_goroutine_labels", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.SetGoroutineLabelsIn
+		var rpcRet rpc2.SetGoroutineLabelsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.GoroutineID, "GoroutineID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Labels, "Labels")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "GoroutineID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineID, "GoroutineID")
+			case "Labels":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Labels, "Labels")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("SetGoroutineLabels", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["set_next_statement"] = starlark.NewBuiltin("set_next_statement", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.RecordedIn
-		var rpcRet rpc2.RecordedOut
-		err := env.ctx.Client().CallAPI("Recorded", &rpcArgs, &rpcRet)
+		var rpcArgs rpc2.SetNextStatementIn
+		var rpcRet rpc2.SetNextStatementOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.File, "File")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Line, "Line")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "File":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.File, "File")
+			case "Line":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Line, "Line")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("SetNextStatement", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["restart"] = starlark.NewBuiltin("restart", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["set_register"] = starlark.NewBuiltin("set_register", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.RestartIn
-		var rpcRet rpc2.RestartOut
+		var rpcArgs rpc2.SetRegisterIn
+		var rpcRet rpc2.SetRegisterOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Position, "Position")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.ThreadID, "ThreadID")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
 		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.ResetArgs, "ResetArgs")
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Name, "Name")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
 		if len(args) > 2 && args[2] != starlark.None {
-			err := unmarshalStarlarkValue(args[2], &rpcArgs.NewArgs, "NewArgs")
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.Value, "Value")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		if len(args) > 3 && args[3] != starlark.None {
-			err := unmarshalStarlarkValue(args[3], &rpcArgs.Rerecord, "Rerecord")
-			if err != nil {
-				return starlark.None, decorateError(thread, err)
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "ThreadID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ThreadID, "ThreadID")
+			case "Name":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Name, "Name")
+			case "Value":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Value, "Value")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
-		}
-		if len(args) > 4 && args[4] != starlark.None {
-			err := unmarshalStarlarkValue(args[4], &rpcArgs.Rebuild, "Rebuild")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		if len(args) > 5 && args[5] != starlark.None {
-			err := unmarshalStarlarkValue(args[5], &rpcArgs.NewRedirects, "NewRedirects")
+		err := env.ctx.Client().CallAPI("SetRegister", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["set_stop_signals"] = starlark.NewBuiltin("set_stop_signals", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.SetStopSignalsIn
+		var rpcRet rpc2.SetStopSignalsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Signals, "Signals")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -1250,18 +2757,8 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Position":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Position, "Position")
-			case "ResetArgs":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.ResetArgs, "ResetArgs")
-			case "NewArgs":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.NewArgs, "NewArgs")
-			case "Rerecord":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Rerecord, "Rerecord")
-			case "Rebuild":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Rebuild, "Rebuild")
-			case "NewRedirects":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.NewRedirects, "NewRedirects")
+			case "Signals":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Signals, "Signals")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1269,34 +2766,38 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("Restart", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("SetStopSignals", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
-	r["set_expr"] = starlark.NewBuiltin("set_expr", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r["source_lines"] = starlark.NewBuiltin("source_lines", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, decorateError(thread, err)
 		}
-		var rpcArgs rpc2.SetIn
-		var rpcRet rpc2.SetOut
+		var rpcArgs rpc2.SourceLinesIn
+		var rpcRet rpc2.SourceLinesOut
 		if len(args) > 0 && args[0] != starlark.None {
-			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.File, "File")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
-		} else {
-			rpcArgs.Scope = env.ctx.Scope()
 		}
 		if len(args) > 1 && args[1] != starlark.None {
-			err := unmarshalStarlarkValue(args[1], &rpcArgs.Symbol, "Symbol")
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Start, "Start")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
 		if len(args) > 2 && args[2] != starlark.None {
-			err := unmarshalStarlarkValue(args[2], &rpcArgs.Value, "Value")
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.End, "End")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
 			if err != nil {
 				return starlark.None, decorateError(thread, err)
 			}
@@ -1304,12 +2805,14 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		for _, kv := range kwargs {
 			var err error
 			switch kv[0].(starlark.String) {
-			case "Scope":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
-			case "Symbol":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Symbol, "Symbol")
-			case "Value":
-				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Value, "Value")
+			case "File":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.File, "File")
+			case "Start":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Start, "Start")
+			case "End":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.End, "End")
+			case "SubstitutePathRules":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.SubstitutePathRules, "SubstitutePathRules")
 			default:
 				err = fmt.Errorf("unknown argument %q", kv[0])
 			}
@@ -1317,7 +2820,7 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 				return starlark.None, decorateError(thread, err)
 			}
 		}
-		err := env.ctx.Client().CallAPI("Set", &rpcArgs, &rpcRet)
+		err := env.ctx.Client().CallAPI("SourceLines", &rpcArgs, &rpcRet)
 		if err != nil {
 			return starlark.None, err
 		}
@@ -1461,5 +2964,204 @@ func (env *Env) starlarkPredeclare() starlark.StringDict {
 		}
 		return env.interfaceToStarlarkValue(rpcRet), nil
 	})
+	r["unpark_goroutine"] = starlark.NewBuiltin("unpark_goroutine", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.UnparkGoroutineIn
+		var rpcRet rpc2.UnparkGoroutineOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.GoroutineID, "GoroutineID")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "GoroutineID":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.GoroutineID, "GoroutineID")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("UnparkGoroutine", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["value_history"] = starlark.NewBuiltin("value_history", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.ValueHistoryIn
+		var rpcRet rpc2.ValueHistoryOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 2 && args[2] != starlark.None {
+			err := unmarshalStarlarkValue(args[2], &rpcArgs.MaxSamples, "MaxSamples")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 3 && args[3] != starlark.None {
+			err := unmarshalStarlarkValue(args[3], &rpcArgs.Cfg, "Cfg")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			cfg := env.ctx.LoadConfig()
+			rpcArgs.Cfg = &cfg
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			case "MaxSamples":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.MaxSamples, "MaxSamples")
+			case "Cfg":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Cfg, "Cfg")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("ValueHistory", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["visible_names"] = starlark.NewBuiltin("visible_names", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.VisibleNamesIn
+		var rpcRet rpc2.VisibleNamesOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("VisibleNames", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["what_is"] = starlark.NewBuiltin("what_is", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.WhatIsIn
+		var rpcRet rpc2.WhatIsOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Scope, "Scope")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		} else {
+			rpcArgs.Scope = env.ctx.Scope()
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Expr, "Expr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Scope":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Scope, "Scope")
+			case "Expr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Expr, "Expr")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("WhatIs", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
+	r["write_memory"] = starlark.NewBuiltin("write_memory", func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := isCancelled(thread); err != nil {
+			return starlark.None, decorateError(thread, err)
+		}
+		var rpcArgs rpc2.WriteMemoryIn
+		var rpcRet rpc2.WriteMemoryOut
+		if len(args) > 0 && args[0] != starlark.None {
+			err := unmarshalStarlarkValue(args[0], &rpcArgs.Addr, "Addr")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		if len(args) > 1 && args[1] != starlark.None {
+			err := unmarshalStarlarkValue(args[1], &rpcArgs.Data, "Data")
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		for _, kv := range kwargs {
+			var err error
+			switch kv[0].(starlark.String) {
+			case "Addr":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Addr, "Addr")
+			case "Data":
+				err = unmarshalStarlarkValue(kv[1], &rpcArgs.Data, "Data")
+			default:
+				err = fmt.Errorf("unknown argument %q", kv[0])
+			}
+			if err != nil {
+				return starlark.None, decorateError(thread, err)
+			}
+		}
+		err := env.ctx.Client().CallAPI("WriteMemory", &rpcArgs, &rpcRet)
+		if err != nil {
+			return starlark.None, err
+		}
+		return env.interfaceToStarlarkValue(rpcRet), nil
+	})
 	return r
 }
@@ -20,23 +20,31 @@ import (
 // an api.Breakpoint.
 func ConvertBreakpoint(bp *proc.Breakpoint) *Breakpoint {
 	b := &Breakpoint{
-		Name:          bp.Name,
-		ID:            bp.LogicalID,
-		FunctionName:  bp.FunctionName,
-		File:          bp.File,
-		Line:          bp.Line,
-		Addr:          bp.Addr,
-		Tracepoint:    bp.Tracepoint,
-		TraceReturn:   bp.TraceReturn,
-		Stacktrace:    bp.Stacktrace,
-		Goroutine:     bp.Goroutine,
-		Variables:     bp.Variables,
-		LoadArgs:      LoadConfigFromProc(bp.LoadArgs),
-		LoadLocals:    LoadConfigFromProc(bp.LoadLocals),
-		WatchExpr:     bp.WatchExpr,
-		WatchType:     WatchType(bp.WatchType),
-		TotalHitCount: bp.TotalHitCount,
-		Addrs:         []uint64{bp.Addr},
+		Name:               bp.Name,
+		ID:                 bp.LogicalID,
+		FunctionName:       bp.FunctionName,
+		OnReturn:           bp.OnReturn,
+		File:               bp.File,
+		Line:               bp.Line,
+		Addr:               bp.Addr,
+		Tracepoint:         bp.Tracepoint,
+		TraceReturn:        bp.TraceReturn,
+		Stacktrace:         bp.Stacktrace,
+		Goroutine:          bp.Goroutine,
+		Variables:          bp.Variables,
+		TraceMessage:       bp.TraceMessage,
+		DumpGoroutinesOnce: bp.DumpGoroutinesOnce,
+		LoadArgs:           LoadConfigFromProc(bp.LoadArgs),
+		LoadLocals:         LoadConfigFromProc(bp.LoadLocals),
+		WatchExpr:          bp.WatchExpr,
+		WatchType:          WatchType(bp.WatchType),
+		TotalHitCount:      bp.TotalHitCount,
+		SampleRate:         bp.SampleRate,
+		MinHitInterval:     bp.MinHitInterval,
+		FirstPerGoroutine:  bp.FirstPerGoroutine,
+		Temporary:          bp.Temporary,
+		Addrs:              []uint64{bp.Addr},
+		UserData:           bp.UserData,
 	}
 
 	b.HitCount = map[string]uint64{}
@@ -47,6 +55,11 @@ func ConvertBreakpoint(bp *proc.Breakpoint) *Breakpoint {
 	var buf bytes.Buffer
 	printer.Fprint(&buf, token.NewFileSet(), bp.Cond)
 	b.Cond = buf.String()
+	if bp.Assert != nil {
+		buf.Reset()
+		printer.Fprint(&buf, token.NewFileSet(), bp.Assert)
+		b.Assert = buf.String()
+	}
 	if bp.HitCond != nil {
 		b.HitCond = fmt.Sprintf("%s %d", bp.HitCond.Op.String(), bp.HitCond.Val)
 	}
@@ -101,7 +114,8 @@ func ConvertThread(th proc.Thread) *Thread {
 		bp = ConvertBreakpoint(b.Breakpoint)
 	}
 
-	if g, _ := proc.GetG(th); g != nil {
+	g, _ := proc.GetG(th)
+	if g != nil {
 		gid = g.ID
 	}
 
@@ -116,6 +130,41 @@ func ConvertThread(th proc.Thread) *Thread {
 	}
 }
 
+// ConvertThreadState returns the state of thread th running goroutine g,
+// g is nil for threads that are not running a goroutine.
+func ConvertThreadState(th proc.Thread, g *proc.G) ThreadState {
+	switch {
+	case g == nil && threadParked(th):
+		return ThreadBlocked
+	case g == nil:
+		return ThreadIdle
+	case g.Status == proc.Gsyscall:
+		return ThreadSyscall
+	default:
+		return ThreadRunning
+	}
+}
+
+// threadParkedStacktraceDepth is the depth of the stacktrace searched by
+// threadParked.
+const threadParkedStacktraceDepth = 10
+
+// threadParked returns true if th is parked by the Go runtime, waiting for
+// a goroutine to run or, for the sysmon thread, sleeping.
+func threadParked(th proc.Thread) bool {
+	frames, _ := proc.ThreadStacktrace(th, threadParkedStacktraceDepth)
+	for _, frame := range frames {
+		if frame.Call.Fn == nil {
+			continue
+		}
+		switch frame.Call.Fn.Name {
+		case "runtime.notesleep", "runtime.notetsleep", "runtime.usleep":
+			return true
+		}
+	}
+	return false
+}
+
 // ConvertThreads converts a slice of proc.Thread into a slice of api.Thread.
 func ConvertThreads(threads []proc.Thread) []*Thread {
 	r := make([]*Thread, len(threads))
@@ -125,6 +174,94 @@ func ConvertThreads(threads []proc.Thread) []*Thread {
 	return r
 }
 
+// ConvertTypeDefinition converts typ to an api.TypeDefinition, the
+// methods of the type are not filled in.
+func ConvertTypeDefinition(typ godwarf.Type) *TypeDefinition {
+	r := &TypeDefinition{
+		Name: PrettyTypeName(typ),
+		Kind: typ.Common().ReflectKind,
+		Size: typ.Size(),
+	}
+
+	// the kind is not recorded in the debug info of all types
+	setKind := func(kind reflect.Kind) {
+		if r.Kind == reflect.Invalid {
+			r.Kind = kind
+		}
+	}
+
+	var styp *godwarf.StructType
+	for {
+		switch t := typ.(type) {
+		case *godwarf.QualType:
+			typ = t.Type
+			continue
+		case *godwarf.StructType:
+			styp = t
+			setKind(reflect.Struct)
+		case *godwarf.StringType:
+			styp = &t.StructType
+			setKind(reflect.String)
+		case *godwarf.SliceType:
+			styp = &t.StructType
+			r.Elem = PrettyTypeName(t.ElemType)
+			setKind(reflect.Slice)
+		case *godwarf.InterfaceType:
+			styp, _ = resolveTypedef(t.Type).(*godwarf.StructType)
+			setKind(reflect.Interface)
+		case *godwarf.PtrType:
+			r.Elem = PrettyTypeName(t.Type)
+			setKind(reflect.Ptr)
+		case *godwarf.ArrayType:
+			r.Elem = PrettyTypeName(t.Type)
+			r.Len = t.Count
+			setKind(reflect.Array)
+		case *godwarf.MapType:
+			r.Key = PrettyTypeName(t.KeyType)
+			r.Elem = PrettyTypeName(t.ElemType)
+			setKind(reflect.Map)
+		case *godwarf.ChanType:
+			r.Elem = PrettyTypeName(t.ElemType)
+			setKind(reflect.Chan)
+		case *godwarf.FuncType:
+			setKind(reflect.Func)
+		case *godwarf.TypedefType:
+			typ = t.Type
+			continue
+		}
+		break
+	}
+
+	if styp != nil {
+		r.Fields = make([]TypeField, len(styp.Field))
+		for i, f := range styp.Field {
+			r.Fields[i] = TypeField{
+				Name:      f.Name,
+				Type:      PrettyTypeName(f.Type),
+				Offset:    f.ByteOffset,
+				Size:      f.Type.Size(),
+				Embedded:  f.Embedded,
+				BitOffset: f.BitOffset,
+				BitSize:   f.BitSize,
+			}
+		}
+	}
+	return r
+}
+
+func resolveTypedef(typ godwarf.Type) godwarf.Type {
+	for {
+		switch t := typ.(type) {
+		case *godwarf.TypedefType:
+			typ = t.Type
+		case *godwarf.QualType:
+			typ = t.Type
+		default:
+			return typ
+		}
+	}
+}
+
 func PrettyTypeName(typ godwarf.Type) string {
 	if typ == nil {
 		return ""
@@ -152,6 +289,39 @@ func convertFloatValue(v *proc.Variable, sz int) string {
 	return strconv.FormatFloat(f, 'f', -1, sz)
 }
 
+// convertComplexParts returns the real and imaginary parts of the complex
+// number v, sz is their size in bits.
+func convertComplexParts(v *proc.Variable, sz int) []Variable {
+	kind := reflect.Float64
+	if sz == 32 {
+		kind = reflect.Float32
+	}
+	parts := []Variable{{Name: "real", Kind: kind}, {Name: "imaginary", Kind: kind}}
+	switch {
+	case len(v.Children) == 2:
+		// one of the parts is an infinity or NaN, which can not be
+		// represented by v.Value
+		parts[0].Value = convertFloatValue(&v.Children[0], sz)
+		parts[1].Value = convertFloatValue(&v.Children[1], sz)
+	case v.Value != nil:
+		real, _ := constant.Float64Val(constant.Real(v.Value))
+		parts[0].Value = strconv.FormatFloat(real, 'f', -1, sz)
+		imag, _ := constant.Float64Val(constant.Imag(v.Value))
+		parts[1].Value = strconv.FormatFloat(imag, 'f', -1, sz)
+	default:
+		parts[0].Value = "nil"
+		parts[1].Value = "nil"
+	}
+	return parts
+}
+
+// convertComplexValue formats the value of v as a Go complex literal, sz is
+// the size in bits of its real and imaginary parts.
+func convertComplexValue(v *proc.Variable, sz int) string {
+	parts := convertComplexParts(v, sz)
+	return formatComplex(parts[0].Value, parts[1].Value)
+}
+
 // ConvertVar converts from proc.Variable to api.Variable.
 func ConvertVar(v *proc.Variable) *Variable {
 	r := Variable{
@@ -166,6 +336,20 @@ func ConvertVar(v *proc.Variable) *Variable {
 
 		LocationExpr: v.LocationExpr.String(),
 		DeclLine:     v.DeclLine,
+
+		LenTruncated:     v.LenTruncated,
+		CapTruncated:     v.CapTruncated,
+		RecurseTruncated: v.RecurseTruncated,
+
+		ChanSendWaiters: v.ChanSendWaiters,
+		ChanRecvWaiters: v.ChanRecvWaiters,
+	}
+
+	if len(v.ChanBuffer) > 0 {
+		r.ChanBuffer = make([]Variable, len(v.ChanBuffer))
+		for i := range v.ChanBuffer {
+			r.ChanBuffer[i] = *ConvertVar(&v.ChanBuffer[i])
+		}
 	}
 
 	r.Type = PrettyTypeName(v.DwarfType)
@@ -179,47 +363,13 @@ func ConvertVar(v *proc.Variable) *Variable {
 
 	switch v.Kind {
 	case reflect.Complex64:
-		r.Children = make([]Variable, 2)
+		r.Children = convertComplexParts(v, 32)
 		r.Len = 2
 
-		r.Children[0].Name = "real"
-		r.Children[0].Kind = reflect.Float32
-
-		r.Children[1].Name = "imaginary"
-		r.Children[1].Kind = reflect.Float32
-
-		if v.Value != nil {
-			real, _ := constant.Float64Val(constant.Real(v.Value))
-			r.Children[0].Value = strconv.FormatFloat(real, 'f', -1, 32)
-
-			imag, _ := constant.Float64Val(constant.Imag(v.Value))
-			r.Children[1].Value = strconv.FormatFloat(imag, 'f', -1, 32)
-		} else {
-			r.Children[0].Value = "nil"
-			r.Children[1].Value = "nil"
-		}
-
 	case reflect.Complex128:
-		r.Children = make([]Variable, 2)
+		r.Children = convertComplexParts(v, 64)
 		r.Len = 2
 
-		r.Children[0].Name = "real"
-		r.Children[0].Kind = reflect.Float64
-
-		r.Children[1].Name = "imaginary"
-		r.Children[1].Kind = reflect.Float64
-
-		if v.Value != nil {
-			real, _ := constant.Float64Val(constant.Real(v.Value))
-			r.Children[0].Value = strconv.FormatFloat(real, 'f', -1, 64)
-
-			imag, _ := constant.Float64Val(constant.Imag(v.Value))
-			r.Children[1].Value = strconv.FormatFloat(imag, 'f', -1, 64)
-		} else {
-			r.Children[0].Value = "nil"
-			r.Children[1].Value = "nil"
-		}
-
 	default:
 		r.Children = make([]Variable, len(v.Children))
 
@@ -240,6 +390,10 @@ func VariableValueAsString(v *proc.Variable) string {
 		return convertFloatValue(v, 32)
 	case reflect.Float64:
 		return convertFloatValue(v, 64)
+	case reflect.Complex64:
+		return convertComplexValue(v, 32)
+	case reflect.Complex128:
+		return convertComplexValue(v, 64)
 	case reflect.String, reflect.Func:
 		return constant.StringVal(v.Value)
 	default:
@@ -302,7 +456,7 @@ func ConvertGoroutine(tgt *proc.Target, g *proc.G) *Goroutine {
 		ThreadID:       tid,
 		WaitSince:      g.WaitSince,
 		WaitReason:     g.WaitReason,
-		Labels:         g.Labels(),
+		Labels:         tgt.GoroutineLabels(g),
 		Status:         g.Status,
 	}
 }
@@ -334,12 +488,13 @@ func ConvertAsmInstruction(inst proc.AsmInstruction, text string) AsmInstruction
 		destloc = &r
 	}
 	return AsmInstruction{
-		Loc:        ConvertLocation(inst.Loc),
-		DestLoc:    destloc,
-		Text:       text,
-		Bytes:      inst.Bytes,
-		Breakpoint: inst.Breakpoint,
-		AtPC:       inst.AtPC,
+		Loc:          ConvertLocation(inst.Loc),
+		DestLoc:      destloc,
+		BranchTarget: inst.BranchTarget,
+		Text:         text,
+		Bytes:        inst.Bytes,
+		Breakpoint:   inst.Breakpoint,
+		AtPC:         inst.AtPC,
 	}
 }
 
@@ -354,6 +509,8 @@ func LoadConfigToProc(cfg *LoadConfig) *proc.LoadConfig {
 		MaxStringLen:       cfg.MaxStringLen,
 		MaxArrayValues:     cfg.MaxArrayValues,
 		MaxStructFields:    cfg.MaxStructFields,
+		MaxElementBytes:    cfg.MaxElementBytes,
+		PrettyKnownTypes:   cfg.PrettyKnownTypes,
 		MaxMapBuckets:      0, // MaxMapBuckets is set internally by pkg/proc, read its documentation for an explanation.
 	}
 }
@@ -369,6 +526,8 @@ func LoadConfigFromProc(cfg *proc.LoadConfig) *LoadConfig {
 		MaxStringLen:       cfg.MaxStringLen,
 		MaxArrayValues:     cfg.MaxArrayValues,
 		MaxStructFields:    cfg.MaxStructFields,
+		MaxElementBytes:    cfg.MaxElementBytes,
+		PrettyKnownTypes:   cfg.PrettyKnownTypes,
 	}
 }
 
@@ -423,8 +582,40 @@ func ConvertRegisters(in *op.DwarfRegisters, dwarfRegisterToString func(int, *op
 	return
 }
 
+// ConvertStopReason converts a proc.StopReason into a StopReason.
+func ConvertStopReason(sr proc.StopReason) StopReason {
+	switch sr {
+	case proc.StopLaunched:
+		return StopLaunched
+	case proc.StopAttached:
+		return StopAttached
+	case proc.StopExited:
+		return StopExited
+	case proc.StopBreakpoint:
+		return StopBreakpoint
+	case proc.StopHardcodedBreakpoint:
+		return StopHardcodedBreakpoint
+	case proc.StopManual:
+		return StopManual
+	case proc.StopNextFinished:
+		return StopNextFinished
+	case proc.StopCallReturned:
+		return StopCallReturned
+	case proc.StopWatchpoint:
+		return StopWatchpoint
+	case proc.StopSignal:
+		return StopSignal
+	default:
+		return StopUnknown
+	}
+}
+
 func ConvertImage(image *proc.Image) Image {
-	return Image{Path: image.Path, Address: image.StaticBase}
+	r := Image{Path: image.Path, Address: image.StaticBase, DebugInfo: image.HasDebugInfo()}
+	if err := image.LoadError(); err != nil {
+		r.LoadError = err.Error()
+	}
+	return r
 }
 
 func ConvertDumpState(dumpState *proc.DumpState) *DumpState {
@@ -443,3 +634,21 @@ func ConvertDumpState(dumpState *proc.DumpState) *DumpState {
 	}
 	return r
 }
+
+// ConvertMemoryMap converts a slice of proc.MemoryMapEntry to a slice of
+// MemoryMap.
+func ConvertMemoryMap(mmap []proc.MemoryMapEntry) []MemoryMap {
+	r := make([]MemoryMap, len(mmap))
+	for i, mme := range mmap {
+		r[i] = MemoryMap{
+			Addr:     mme.Addr,
+			Size:     mme.Size,
+			Read:     mme.Read,
+			Write:    mme.Write,
+			Exec:     mme.Exec,
+			Filename: mme.Filename,
+			Offset:   mme.Offset,
+		}
+	}
+	return r
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// JSONValue is the node of the tree produced by Variable.JSON.
+type JSONValue struct {
+	// Type is the Go type of the value.
+	Type string `json:"type"`
+	// Kind is the kind of the value, as returned by reflect.Kind.String.
+	Kind string `json:"kind"`
+	// Addr is the address of the value, omitted for values that do not
+	// reside in memory.
+	Addr string `json:"addr,omitempty"`
+	// Value is set for booleans (as a JSON boolean), integers and finite
+	// floating point numbers (as a JSON number), strings, complex numbers,
+	// non-finite floating point numbers and functions (as a JSON string).
+	Value interface{} `json:"value,omitempty"`
+	// Nil is set for nil pointers, interfaces, slices, maps, channels and
+	// functions.
+	Nil bool `json:"nil,omitempty"`
+	// Len and Cap are the length and capacity of strings, arrays, slices,
+	// maps and channels.
+	Len *int64 `json:"len,omitempty"`
+	Cap *int64 `json:"cap,omitempty"`
+	// Fields contains the fields of structs and channels.
+	Fields []JSONField `json:"fields,omitempty"`
+	// Elements contains the elements of arrays and slices.
+	Elements []*JSONValue `json:"elements,omitempty"`
+	// Entries contains the entries of maps.
+	Entries []JSONMapEntry `json:"entries,omitempty"`
+	// Target is the value a pointer points to, or the value contained in an
+	// interface.
+	Target *JSONValue `json:"target,omitempty"`
+	// Ref is set instead of Target for pointers to one of the values that
+	// enclose them, it is the address of the value pointed to.
+	Ref string `json:"ref,omitempty"`
+	// Truncated is set if the value was not loaded completely, because of
+	// the limits of the LoadConfig used to evaluate it.
+	Truncated bool `json:"truncated,omitempty"`
+	// Unreadable is the error encountered reading the value.
+	Unreadable string `json:"unreadable,omitempty"`
+}
+
+// JSONField is a struct field in a JSONValue.
+type JSONField struct {
+	Name  string     `json:"name"`
+	Value *JSONValue `json:"value"`
+}
+
+// JSONMapEntry is a map entry in a JSONValue.
+type JSONMapEntry struct {
+	Key   *JSONValue `json:"key"`
+	Value *JSONValue `json:"value"`
+}
+
+// JSON returns a JSON representation of v, see JSONValue. Pointers to one
+// of the values enclosing them are represented using JSONValue.Ref.
+func (v *Variable) JSON() (json.RawMessage, error) {
+	return json.Marshal(v.jsonValue(map[jsonValueKey]bool{}))
+}
+
+type jsonValueKey struct {
+	addr uint64
+	typ  string
+}
+
+func (v *Variable) jsonValue(enclosing map[jsonValueKey]bool) *JSONValue {
+	r := &JSONValue{Type: v.Type, Kind: v.Kind.String()}
+	if v.Addr != 0 {
+		r.Addr = fmt.Sprintf("%#x", v.Addr)
+	}
+	if v.Unreadable != "" {
+		r.Unreadable = v.Unreadable
+		return r
+	}
+	r.Truncated = v.LenTruncated || v.CapTruncated || v.RecurseTruncated
+
+	if v.Addr != 0 {
+		k := jsonValueKey{v.Addr, v.Type}
+		if !enclosing[k] {
+			enclosing[k] = true
+			defer delete(enclosing, k)
+		}
+	}
+
+	children := func() []*JSONValue {
+		r := make([]*JSONValue, len(v.Children))
+		for i := range v.Children {
+			r[i] = v.Children[i].jsonValue(enclosing)
+		}
+		return r
+	}
+
+	switch v.Kind {
+	case reflect.Bool:
+		r.Value = v.Value == "true"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Value != "" {
+			r.Value = json.Number(v.Value)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(v.Value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			r.Value = json.Number(v.Value)
+		} else if v.Value != "" {
+			r.Value = v.Value
+		}
+	case reflect.Complex64, reflect.Complex128:
+		r.Value = v.Value
+	case reflect.String:
+		r.Value = v.Value
+		r.Len = &v.Len
+		r.Truncated = r.Truncated || int64(len(v.Value)) < v.Len
+	case reflect.Func:
+		if v.Value == "" {
+			r.Nil = true
+		} else {
+			r.Value = v.Value
+		}
+	case reflect.Array:
+		r.Len = &v.Len
+		r.Elements = children()
+		r.Truncated = r.Truncated || int64(len(v.Children)) < v.Len
+	case reflect.Slice:
+		if v.Base == 0 {
+			r.Nil = true
+			break
+		}
+		r.Len, r.Cap = &v.Len, &v.Cap
+		r.Elements = children()
+		r.Truncated = r.Truncated || int64(len(v.Children)) < v.Len
+	case reflect.Map:
+		if v.Base == 0 && len(v.Children) == 0 {
+			r.Nil = true
+			break
+		}
+		r.Len = &v.Len
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			r.Entries = append(r.Entries, JSONMapEntry{Key: v.Children[i].jsonValue(enclosing), Value: v.Children[i+1].jsonValue(enclosing)})
+		}
+		r.Truncated = r.Truncated || int64(len(v.Children)/2) < v.Len
+	case reflect.Chan:
+		if len(v.Children) == 0 {
+			r.Nil = true
+			break
+		}
+		r.Len, r.Cap = &v.Len, &v.Cap
+		v.jsonFields(r, enclosing)
+	case reflect.Struct:
+		v.jsonFields(r, enclosing)
+	case reflect.Ptr, reflect.UnsafePointer:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			r.Nil = true
+			break
+		}
+		target := &v.Children[0]
+		switch {
+		case enclosing[jsonValueKey{target.Addr, target.Type}]:
+			r.Ref = fmt.Sprintf("%#x", target.Addr)
+		case v.Kind == reflect.UnsafePointer || target.OnlyAddr:
+			r.Value = fmt.Sprintf("%#x", target.Addr)
+			r.Truncated = r.Truncated || v.Kind == reflect.Ptr
+		default:
+			r.Target = target.jsonValue(enclosing)
+		}
+	case reflect.Interface:
+		if v.Addr == 0 || len(v.Children) == 0 || (v.Children[0].Kind == reflect.Invalid && v.Children[0].Addr == 0) {
+			r.Nil = true
+			break
+		}
+		r.Target = v.Children[0].jsonValue(enclosing)
+	default:
+		if v.Value != "" {
+			r.Value = v.Value
+		}
+	}
+	return r
+}
+
+func (v *Variable) jsonFields(r *JSONValue, enclosing map[jsonValueKey]bool) {
+	for i := range v.Children {
+		r.Fields = append(r.Fields, JSONField{Name: v.Children[i].Name, Value: v.Children[i].jsonValue(enclosing)})
+	}
+}
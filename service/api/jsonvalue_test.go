@@ -0,0 +1,50 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestVariableJSON(t *testing.T) {
+	node := Variable{Name: "n", Addr: 0x1000, Type: "main.Node", Kind: reflect.Struct, Len: 3}
+	node.Children = []Variable{
+		{Name: "val", Addr: 0x1000, Type: "int", Kind: reflect.Int, Value: "42"},
+		{Name: "name", Addr: 0x1008, Type: "string", Kind: reflect.String, Value: "abc", Len: 10},
+		{Name: "next", Addr: 0x1018, Type: "*main.Node", Kind: reflect.Ptr, Children: []Variable{{Addr: 0x1000, Type: "main.Node", Kind: reflect.Struct}}},
+	}
+
+	buf, err := node.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r JSONValue
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.UseNumber()
+	if err := d.Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Type != "main.Node" || r.Kind != "struct" || r.Addr != "0x1000" || len(r.Fields) != 3 {
+		t.Fatalf("wrong struct %s", buf)
+	}
+	if f := r.Fields[0]; f.Name != "val" || f.Value.Value != json.Number("42") {
+		t.Errorf("wrong int field %#v", f.Value)
+	}
+	if f := r.Fields[1]; f.Value.Value != "abc" || !f.Value.Truncated || f.Value.Len == nil || *f.Value.Len != 10 {
+		t.Errorf("wrong string field %#v", f.Value)
+	}
+	if f := r.Fields[2]; f.Value.Ref != "0x1000" || f.Value.Target != nil {
+		t.Errorf("cyclic pointer not rendered as reference %#v", f.Value)
+	}
+
+	nilptr := Variable{Addr: 0x2000, Type: "*int", Kind: reflect.Ptr, Children: []Variable{{Type: "int", Kind: reflect.Int}}}
+	buf, err = nilptr.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != `{"type":"*int","kind":"ptr","addr":"0x2000","nil":true}` {
+		t.Errorf("wrong nil pointer %s", buf)
+	}
+}
@@ -39,6 +39,44 @@ func (v *Variable) MultilineString(indent, fmtstr string) string {
 	return buf.String()
 }
 
+// FormatIntegers rewrites the values of v, and of its children, that are
+// integers in the specified base, using the syntax of Go integer literals
+// (0x prefix for base 16, 0 prefix for base 8). Only bases 8, 10 and 16
+// are supported, values are left unchanged for other bases.
+func (v *Variable) FormatIntegers(base int) {
+	if base != 8 && base != 16 {
+		return
+	}
+	switch v.Kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(v.Value, 0, 64); err == nil {
+			if n < 0 {
+				v.Value = "-" + formatUintRadix(uint64(-n), base)
+			} else {
+				v.Value = formatUintRadix(uint64(n), base)
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, err := strconv.ParseUint(v.Value, 0, 64); err == nil {
+			v.Value = formatUintRadix(n, base)
+		}
+	}
+	for i := range v.Children {
+		v.Children[i].FormatIntegers(base)
+	}
+}
+
+func formatUintRadix(n uint64, base int) string {
+	switch {
+	case n == 0:
+		return "0"
+	case base == 8:
+		return "0" + strconv.FormatUint(n, 8)
+	default:
+		return "0x" + strconv.FormatUint(n, 16)
+	}
+}
+
 func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, indent, fmtstr string) {
 	if v.Unreadable != "" {
 		fmt.Fprintf(buf, "(unreadable %s)", v.Unreadable)
@@ -161,7 +199,7 @@ func (v *Variable) writeBasicType(buf io.Writer, fmtstr string) {
 			buf.Write([]byte(v.Value))
 			return
 		}
-		n, _ := strconv.ParseInt(v.Value, 10, 64)
+		n, _ := strconv.ParseInt(v.Value, 0, 64)
 		fmt.Fprintf(buf, fmtstr, n)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
@@ -169,7 +207,7 @@ func (v *Variable) writeBasicType(buf io.Writer, fmtstr string) {
 			buf.Write([]byte(v.Value))
 			return
 		}
-		n, _ := strconv.ParseUint(v.Value, 10, 64)
+		n, _ := strconv.ParseUint(v.Value, 0, 64)
 		fmt.Fprintf(buf, fmtstr, n)
 
 	case reflect.Float32, reflect.Float64:
@@ -182,13 +220,17 @@ func (v *Variable) writeBasicType(buf io.Writer, fmtstr string) {
 
 	case reflect.Complex64, reflect.Complex128:
 		if fmtstr == "" {
-			fmt.Fprintf(buf, "(%s + %si)", v.Children[0].Value, v.Children[1].Value)
+			buf.Write([]byte(formatComplex(v.Children[0].Value, v.Children[1].Value)))
 			return
 		}
+		// the format is applied to both the real and the imaginary part
 		real, _ := strconv.ParseFloat(v.Children[0].Value, 64)
 		imag, _ := strconv.ParseFloat(v.Children[1].Value, 64)
-		var x complex128 = complex(real, imag)
-		fmt.Fprintf(buf, fmtstr, x)
+		if v.Kind == reflect.Complex64 {
+			fmt.Fprintf(buf, fmtstr, complex(float32(real), float32(imag)))
+		} else {
+			fmt.Fprintf(buf, fmtstr, complex(real, imag))
+		}
 
 	case reflect.String:
 		if fmtstr == "" {
@@ -203,6 +245,16 @@ func (v *Variable) writeBasicType(buf io.Writer, fmtstr string) {
 	}
 }
 
+// formatComplex returns the Go literal for the complex number with real
+// part re and imaginary part im, both already formatted as floating point
+// numbers, for example "(1+2i)".
+func formatComplex(re, im string) string {
+	if im == "" || (im[0] != '-' && im[0] != '+') {
+		im = "+" + im
+	}
+	return "(" + re + im + "i)"
+}
+
 func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s len: %d, cap: %d, ", v.Type, v.Len, v.Cap)
@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -57,3 +58,73 @@ func Test_byteArrayToUInt64(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatIntegers(t *testing.T) {
+	v := Variable{
+		Kind: reflect.Struct,
+		Type: "main.T",
+		Len:  5,
+		Children: []Variable{
+			{Name: "a", Kind: reflect.Int, Type: "int", Value: "255"},
+			{Name: "b", Kind: reflect.Int64, Type: "int64", Value: "-8"},
+			{Name: "c", Kind: reflect.Uint8, Type: "uint8", Value: "0"},
+			{Name: "d", Kind: reflect.Float64, Type: "float64", Value: "1.5"},
+			{Name: "e", Kind: reflect.Uint64, Type: "uint64", Value: "18446744073709551615"},
+		},
+	}
+
+	for _, tc := range []struct {
+		base int
+		tgt  string
+	}{
+		{16, "main.T {a: 0xff, b: -0x8, c: 0, d: 1.5, e: 0xffffffffffffffff}"},
+		{8, "main.T {a: 0377, b: -010, c: 0, d: 1.5, e: 01777777777777777777777}"},
+		{10, "main.T {a: 255, b: -8, c: 0, d: 1.5, e: 18446744073709551615}"},
+	} {
+		v2 := v
+		v2.Children = append([]Variable(nil), v.Children...)
+		v2.FormatIntegers(tc.base)
+		if out := v2.SinglelineString(); out != tc.tgt {
+			t.Errorf("base %d: got %q expected %q", tc.base, out, tc.tgt)
+		}
+		if out := v2.Children[0].SinglelineStringFormatted("%d"); out != "255" {
+			t.Errorf("base %d: formatted value of a: %q", tc.base, out)
+		}
+	}
+}
+
+func TestComplexFormatting(t *testing.T) {
+	mkcomplex := func(kind reflect.Kind, re, im string) Variable {
+		partKind := reflect.Float64
+		if kind == reflect.Complex64 {
+			partKind = reflect.Float32
+		}
+		return Variable{
+			Kind:  kind,
+			Value: formatComplex(re, im),
+			Len:   2,
+			Children: []Variable{
+				{Name: "real", Kind: partKind, Value: re},
+				{Name: "imaginary", Kind: partKind, Value: im},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		v      Variable
+		fmtstr string
+		tgt    string
+	}{
+		{mkcomplex(reflect.Complex128, "1", "2"), "", "(1+2i)"},
+		{mkcomplex(reflect.Complex128, "-1.5", "-2.25"), "", "(-1.5-2.25i)"},
+		{mkcomplex(reflect.Complex128, "0", "+Inf"), "", "(0+Infi)"},
+		{mkcomplex(reflect.Complex128, "1", "NaN"), "", "(1+NaNi)"},
+		{mkcomplex(reflect.Complex128, "1", "2"), "%.2f", "(1.00+2.00i)"},
+		{mkcomplex(reflect.Complex128, "1.25", "-2"), "%e", "(1.250000e+00-2.000000e+00i)"},
+		{mkcomplex(reflect.Complex64, "0.1", "0.2"), "%v", "(0.1+0.2i)"},
+	} {
+		if out := tc.v.SinglelineStringFormatted(tc.fmtstr); out != tc.tgt {
+			t.Errorf("%v %q: got %q expected %q", tc.v.Children, tc.fmtstr, out, tc.tgt)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 	"unicode"
 
 	"github.com/go-delve/delve/pkg/proc"
@@ -44,6 +46,42 @@ type DebuggerState struct {
 	ExitStatus int  `json:"exitStatus"`
 	// When contains a description of the current position in a recording
 	When string
+	// StopReason describes why the target process stopped.
+	StopReason StopReason `json:"stopReason,omitempty"`
+	// FatalSignal is the signal that terminated the process, only set when
+	// examining a core file.
+	FatalSignal int `json:"fatalSignal,omitempty"`
+	// ParkedGoroutines contains the IDs of the goroutines that are prevented
+	// from running when the target is continued.
+	ParkedGoroutines []int `json:"parkedGoroutines,omitempty"`
+	// Watches contains the expressions registered with AddWatch, evaluated
+	// when the state was returned.
+	Watches []Watch `json:"watches,omitempty"`
+	// RunningThreads is only set in the state returned by the Halt command,
+	// it contains the IDs of the threads that were running a goroutine,
+	// outside of system calls, when the target was stopped.
+	RunningThreads []int `json:"runningThreads,omitempty"`
+	// StackCopy is set by reverse execution commands if the stack of the
+	// selected goroutine was copied to a different location, to grow or
+	// shrink it, between the start and the end of the command.
+	StackCopy *StackCopy `json:"stackCopy,omitempty"`
+	// ReachedFunction is set by ContinueToFunctions to the name of the
+	// function that was entered.
+	ReachedFunction string `json:"reachedFunction,omitempty"`
+	// Signal is set when the target was stopped by a thread receiving one
+	// of the signals set with SetStopSignals.
+	Signal *Signal `json:"signal,omitempty"`
+	// Stdout and Stderr contain what the target wrote to its standard output
+	// and standard error since the previous state was returned, they are
+	// only filled by commands that resume the target, when the debugger was
+	// started with output streaming enabled.
+	Stdout []byte `json:"stdout,omitempty"`
+	Stderr []byte `json:"stderr,omitempty"`
+	// OutputInProgress indicates that a continue operation was interrupted
+	// only to report the output in Stdout and Stderr and is waiting to
+	// complete, only Pid, Stdout and Stderr are set. Clients should report
+	// the output and execute continue again.
+	OutputInProgress bool `json:"outputInProgress,omitempty"`
 	// Filled by RPCClient.Continue, indicates an error
 	Err error `json:"-"`
 }
@@ -66,24 +104,68 @@ type Breakpoint struct {
 	// FunctionName is the name of the function at the current breakpoint, and
 	// may not always be available.
 	FunctionName string `json:"functionName,omitempty"`
+	// OnReturn, when creating a breakpoint with FunctionName, sets the
+	// breakpoint on every return location of the function (its RET
+	// instructions and calls to runtime.deferreturn) instead of its entry
+	// point. Line is ignored. It is reported for breakpoints created this
+	// way and they are set again on the return locations on restart.
+	OnReturn bool `json:"onReturn,omitempty"`
+	// FallbackLocations is a prioritized list of location expressions (using
+	// the same syntax accepted by FindLocation) that will be tried, in order,
+	// if the location specified by File/Line or FunctionName can not be
+	// resolved.
+	FallbackLocations []string `json:"fallbackLocations,omitempty"`
 
 	// Breakpoint condition
 	Cond string
 	// Breakpoint hit count condition.
 	// Supported hit count conditions are "NUMBER" and "OP NUMBER".
 	HitCond string
+	// SampleRate, if greater than 1, makes the breakpoint stop (or trace)
+	// only once every SampleRate hits. Hits are counted after Cond and
+	// HitCond are evaluated.
+	SampleRate int `json:"sampleRate,omitempty"`
+	// MinHitInterval, if greater than zero, makes the breakpoint stop (or
+	// trace) at most once every MinHitInterval, hits happening sooner than
+	// that after the last stop are skipped silently. It is applied after
+	// all other conditions, skipped hits are still counted in TotalHitCount.
+	MinHitInterval time.Duration `json:"minHitInterval,omitempty"`
+	// FirstPerGoroutine, if true, makes the breakpoint stop (or trace) only
+	// the first time each goroutine hits it, later hits by the same
+	// goroutine are skipped silently. It is applied after Cond, HitCond and
+	// SampleRate, the set of goroutines is cleared on restart.
+	FirstPerGoroutine bool `json:"firstPerGoroutine,omitempty"`
 
 	// Tracepoint flag, signifying this is a tracepoint.
 	Tracepoint bool `json:"continue"`
 	// TraceReturn flag signifying this is a breakpoint set at a return
 	// statement in a traced function.
 	TraceReturn bool `json:"traceReturn"`
+	// Temporary breakpoints are cleared automatically the first time they
+	// are hit, before the stopped state is returned. Temporary breakpoints
+	// are not recreated on restart.
+	Temporary bool `json:"temporary,omitempty"`
+	// Assert is an expression describing an invariant that should hold
+	// every time the breakpoint is hit: the breakpoint only stops the target
+	// if Assert evaluates to false, or can not be evaluated.
+	Assert string `json:"assert,omitempty"`
 	// retrieve goroutine information
 	Goroutine bool `json:"goroutine"`
 	// number of stack frames to retrieve
 	Stacktrace int `json:"stacktrace"`
 	// expressions to evaluate
 	Variables []string `json:"variables,omitempty"`
+	// TraceMessage is a message formatted every time the breakpoint is
+	// hit, it is a format string followed by the expressions used as its
+	// operands, for example: "handling %d from %s", id, name
+	// The verbs of the format string are the same as the verbs of the fmt
+	// package. The formatted message is returned in
+	// BreakpointInfo.TraceMessage.
+	TraceMessage string `json:"traceMessage,omitempty"`
+	// DumpGoroutinesOnce requests that the first time the breakpoint is hit
+	// the stacktraces of all goroutines are recorded, the dump can be
+	// retrieved later with GetGoroutineDump.
+	DumpGoroutinesOnce bool `json:"dumpGoroutinesOnce,omitempty"`
 	// LoadArgs requests loading function arguments when the breakpoint is hit
 	LoadArgs *LoadConfig
 	// LoadLocals requests loading function locals when the breakpoint is hit
@@ -99,6 +181,10 @@ type Breakpoint struct {
 	TotalHitCount uint64 `json:"totalHitCount"`
 	// Disabled flag, signifying the state of the breakpoint
 	Disabled bool `json:"disabled"`
+	// UserData is arbitrary JSON attached to the breakpoint by the client.
+	// It is stored and returned unchanged, including after a restart, and
+	// is never interpreted by Delve.
+	UserData json.RawMessage `json:"userData,omitempty"`
 }
 
 // ValidBreakpointName returns an error if
@@ -127,6 +213,66 @@ const (
 	WatchWrite
 )
 
+// StopReason describes the reason why the target process is stopped.
+type StopReason string
+
+const (
+	StopUnknown             StopReason = "unknown"
+	StopLaunched            StopReason = "launched"            // The process was just launched
+	StopAttached            StopReason = "attached"            // The debugger stopped the process after attaching
+	StopExited              StopReason = "exited"              // The target process terminated
+	StopBreakpoint          StopReason = "breakpoint"          // The target process hit one or more software breakpoints
+	StopHardcodedBreakpoint StopReason = "hardcodedBreakpoint" // The target process hit a hardcoded breakpoint (for example runtime.Breakpoint())
+	StopWatchpoint          StopReason = "watchpoint"          // The target process hit one or more watchpoints
+	StopManual              StopReason = "manual"              // A manual stop was requested (Halt)
+	StopCallReturned        StopReason = "callReturned"        // An injected call completed
+	StopNextFinished        StopReason = "nextFinished"        // A next, step or stepout command terminated
+	StopNext                StopReason = "next"                // A next command terminated
+	StopStep                StopReason = "step"                // A step command terminated
+	StopStepOut             StopReason = "stepOut"             // A stepout command terminated
+	StopStepInstruction     StopReason = "stepInstruction"     // A single instruction was executed
+	StopFunctionReached     StopReason = "functionReached"     // A continueToFunctions command terminated
+	StopSignal              StopReason = "signal"              // A thread received one of the signals set with SetStopSignals
+)
+
+// Signal describes a signal received by a thread of the target, that will
+// be delivered to it when the target is resumed unless it is continued with
+// ContinueWithSignal.
+type Signal struct {
+	ThreadID int    `json:"threadID"`
+	Number   int    `json:"number"`
+	Name     string `json:"name"`
+}
+
+// StackCopy describes a copy of the stack of a goroutine, made by the Go
+// runtime when the stack grows (morestack) or shrinks (during garbage
+// collection). The addresses of stack variables and the frame offsets of
+// the goroutine are not comparable across a copy.
+type StackCopy struct {
+	GoroutineID int `json:"goroutineID"`
+	// OldLo and OldHi are the bounds of the stack when the command started,
+	// NewLo and NewHi its bounds when the command ended.
+	OldLo uint64 `json:"oldLo"`
+	OldHi uint64 `json:"oldHi"`
+	NewLo uint64 `json:"newLo"`
+	NewHi uint64 `json:"newHi"`
+}
+
+// BreakpointStat contains the hit counters of a breakpoint.
+type BreakpointStat struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+	// TotalHits is the number of times the breakpoint was reached, on any
+	// of its addresses.
+	TotalHits uint64 `json:"totalHits"`
+	// DistinctGoroutines is the number of different goroutines that reached
+	// the breakpoint.
+	DistinctGoroutines int `json:"distinctGoroutines"`
+	// LastHitTime is the last time the breakpoint was reached, it is the
+	// zero time if it was never reached.
+	LastHitTime time.Time `json:"lastHitTime"`
+}
+
 // Thread is a thread within the debugged process.
 type Thread struct {
 	// ID is a unique identifier for the thread.
@@ -152,6 +298,40 @@ type Thread struct {
 	ReturnValues []Variable
 	// CallReturn is true if ReturnValues are the return values of an injected call.
 	CallReturn bool
+
+	// State describes what the thread is doing, it is only filled by
+	// ListThreads.
+	State ThreadState `json:"state,omitempty"`
+}
+
+// ThreadState describes what a thread is doing.
+type ThreadState string
+
+const (
+	ThreadRunning ThreadState = "running" // The thread is running a goroutine
+	ThreadSyscall ThreadState = "syscall" // The goroutine running on the thread is in a system call or cgo call
+	ThreadBlocked ThreadState = "blocked" // The thread is not running any goroutine and is parked waiting for work
+	ThreadIdle    ThreadState = "idle"    // The thread is not running any goroutine and is not parked (i.e. it is executing the scheduler or C code)
+)
+
+// ThreadFilter selects the threads returned by ListThreads, the zero value
+// selects all threads.
+type ThreadFilter struct {
+	// State, if not empty, selects the threads in the specified state.
+	State ThreadState
+	// InScheduler selects the threads executing the scheduler of the Go
+	// runtime.
+	InScheduler bool
+}
+
+// LineEntry is a row of the line number table of a function.
+type LineEntry struct {
+	PC   uint64 `json:"pc"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// IsStmt is true if PC is a recommended breakpoint location for Line,
+	// i.e. it is a statement boundary.
+	IsStmt bool `json:"isStmt"`
 }
 
 // Location holds program location information.
@@ -165,6 +345,18 @@ type Location struct {
 	Line     int       `json:"line"`
 	Function *Function `json:"function,omitempty"`
 	PCs      []uint64  `json:"pcs,omitempty"`
+	// Inlined is true if PC belongs to a call that was inlined in Function.
+	// Only set by PreviewBreakpoint and, for stack frames of inlined calls,
+	// by Stacktrace.
+	Inlined bool `json:"inlined,omitempty"`
+	// Wrapper is true if Function is a wrapper generated by the compiler.
+	// Only set by PreviewBreakpoint.
+	Wrapper bool `json:"wrapper,omitempty"`
+	// Symbol is the name of the symbol containing PC and SymbolOffset the
+	// offset of PC from the start of the symbol.
+	// Only set by ResolveAddress.
+	Symbol       string `json:"symbol,omitempty"`
+	SymbolOffset uint64 `json:"symbolOffset,omitempty"`
 }
 
 // Stackframe describes one frame in a stack trace.
@@ -176,7 +368,21 @@ type Stackframe struct {
 	FrameOffset        int64
 	FramePointerOffset int64
 
+	// Defers are the functions deferred by this frame that have not run
+	// yet, they are only read when StacktraceReadDefers is passed to
+	// Stacktrace. The number of pending defers of a frame is len(Defers).
 	Defers []Defer
+	// Panicking is true if this frame is being unwound by a panic, i.e. it
+	// called runtime.gopanic directly or indirectly. The deferred calls
+	// of a panicking frame will run during the unwind, unless the panic is
+	// recovered.
+	Panicking bool `json:"panicking,omitempty"`
+	// InlineParentPC is set for frames of inlined calls (Location.Inlined is
+	// true) and is the entry point of the function of the physical frame
+	// that contains the inlined call. Consecutive inlined frames with the
+	// same InlineParentPC, followed by the physical frame itself, describe a
+	// chain of calls inlined into the same physical frame.
+	InlineParentPC uint64 `json:"inlineParentPC,omitempty"`
 
 	Bottom bool `json:"Bottom,omitempty"` // Bottom is true if this is the bottom frame of the stack
 
@@ -191,6 +397,41 @@ type Defer struct {
 	Unreadable  string
 }
 
+// DeferredCall is a deferred call pending on a goroutine.
+type DeferredCall struct {
+	Defer
+	// Frame is the index of the stack frame that deferred the call and
+	// DeferredCall its index (starting at 1) among the deferred calls of the
+	// frame, they can be used in EvalScope to evaluate expressions in the
+	// context of the deferred call.
+	Frame        int `json:"frame"`
+	DeferredCall int `json:"deferredCall"`
+	// Args contains the values of the arguments of the deferred call.
+	Args []Variable `json:"args,omitempty"`
+	// ArgsUnreadable is set if the arguments of the deferred call could not
+	// be read.
+	ArgsUnreadable string `json:"argsUnreadable,omitempty"`
+}
+
+// Reference describes a word of memory, on the stack of a goroutine, that
+// contains the address passed to ReferencesTo.
+type Reference struct {
+	GoroutineID int `json:"goroutineID"`
+	// Addr is the address of the memory containing the reference.
+	Addr uint64 `json:"addr"`
+	// Frame is the index of the stack frame containing the reference, as
+	// returned by Stacktrace, -1 if it could not be determined.
+	Frame int `json:"frame"`
+	// Location is the location of the frame containing the reference.
+	Location Location `json:"location"`
+	// Variable is the name of the local variable or argument that contains
+	// the reference and Offset the position of the reference inside it.
+	// Variable is empty if the reference could not be attributed to a
+	// variable.
+	Variable string `json:"variable,omitempty"`
+	Offset   uint64 `json:"offset,omitempty"`
+}
+
 // Var will return the variable described by 'name' within
 // this stack frame.
 func (frame *Stackframe) Var(name string) *Variable {
@@ -207,6 +448,49 @@ func (frame *Stackframe) Var(name string) *Variable {
 	return nil
 }
 
+// TypeDefinition describes the memory layout and the methods of a type.
+type TypeDefinition struct {
+	Name string       `json:"name"`
+	Kind reflect.Kind `json:"kind"`
+	// Size is the size of values of the type, in bytes.
+	Size int64 `json:"size"`
+	// Fields contains the fields of struct types and the fields of the
+	// runtime representation of strings, slices and interfaces.
+	Fields []TypeField `json:"fields,omitempty"`
+	// Elem is the element type of pointers, arrays, slices, maps and
+	// channels, Key the key type of maps.
+	Elem string `json:"elem,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// Len is the length of array types.
+	Len int64 `json:"len,omitempty"`
+	// Methods contains the methods of the type, and of pointers to it,
+	// that were compiled in the target binary.
+	Methods []TypeMethod `json:"methods,omitempty"`
+}
+
+// TypeField is a field of a struct type.
+type TypeField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Offset is the offset of the field from the start of the struct and
+	// Size its size, in bytes.
+	Offset   int64 `json:"offset"`
+	Size     int64 `json:"size"`
+	Embedded bool  `json:"embedded,omitempty"`
+	// BitOffset and BitSize are set for bit fields (only in C types).
+	BitOffset int64 `json:"bitOffset,omitempty"`
+	BitSize   int64 `json:"bitSize,omitempty"`
+}
+
+// TypeMethod is a method of a type.
+type TypeMethod struct {
+	Name string `json:"name"`
+	// Function is the full name of the function implementing the method.
+	Function string `json:"function"`
+	// PointerReceiver is true if the method has a pointer receiver.
+	PointerReceiver bool `json:"pointerReceiver,omitempty"`
+}
+
 // Function represents thread-scoped function information.
 type Function struct {
 	// Name is the function name.
@@ -296,6 +580,7 @@ type Variable struct {
 	// For maps each map entry will have to items in this slice, even numbered items will represent map keys and odd numbered items will represent their values
 	// This field's length is capped at proc.maxArrayValues for slices and arrays and 2*proc.maxArrayValues for maps, in the circumstances where the cap takes effect len(Children) != Len
 	// The other length cap applied to this field is related to maximum recursion depth, when the maximum recursion depth is reached this field is left empty, contrary to the previous one this cap also applies to structs (otherwise structs will always have all their member fields returned)
+	// For the "~panic" return value of a function call the first item is the panic value, the following items (named "~unwrapped") are the errors it wraps, in order, ending with the root cause
 	Children []Variable `json:"children"`
 
 	// Base address of arrays, Base address of the backing array for slices (0 for nil slices)
@@ -307,12 +592,48 @@ type Variable struct {
 	// Unreadable addresses will have this field set
 	Unreadable string `json:"unreadable"`
 
+	// LenTruncated is set if the value of a string was truncated because of
+	// LoadConfig.MaxStringLen.
+	LenTruncated bool `json:"lenTruncated,omitempty"`
+	// CapTruncated is set if Children was truncated because of
+	// LoadConfig.MaxArrayValues or, for structs, LoadConfig.MaxStructFields.
+	CapTruncated bool `json:"capTruncated,omitempty"`
+	// RecurseTruncated is set if Children was left empty because
+	// LoadConfig.MaxVariableRecurse was reached.
+	RecurseTruncated bool `json:"recurseTruncated,omitempty"`
+
+	// ChanBuffer contains the elements buffered in a channel, in the order
+	// they will be received, up to LoadConfig.MaxArrayValues.
+	ChanBuffer []Variable `json:"chanBuffer,omitempty"`
+	// ChanSendWaiters and ChanRecvWaiters are the number of goroutines
+	// blocked sending to and receiving from a channel.
+	ChanSendWaiters int `json:"chanSendWaiters,omitempty"`
+	ChanRecvWaiters int `json:"chanRecvWaiters,omitempty"`
+
 	// LocationExpr describes the location expression of this variable's address
 	LocationExpr string
 	// DeclLine is the line number of this variable's declaration
 	DeclLine int64
 }
 
+// TypeInfo describes the type of an expression without its value.
+type TypeInfo struct {
+	// Go type of the expression
+	Type string `json:"type"`
+	// Type of the expression after resolving any typedefs
+	RealType string `json:"realType"`
+	// Type of the value stored in an interface, empty if the expression
+	// is not an interface or the interface is nil
+	ConcreteType string `json:"concreteType,omitempty"`
+
+	Kind reflect.Kind `json:"kind"`
+
+	// Size of Type in bytes
+	Size int64 `json:"size"`
+	// Alignment of Type in bytes
+	Align int64 `json:"align"`
+}
+
 // LoadConfig describes how to load values from target's memory
 type LoadConfig struct {
 	// FollowPointers requests pointers to be automatically dereferenced.
@@ -325,6 +646,14 @@ type LoadConfig struct {
 	MaxArrayValues int
 	// MaxStructFields is the maximum number of fields read from a struct, -1 will read all fields.
 	MaxStructFields int
+	// MaxElementBytes is the maximum number of bytes read from each element
+	// of an array, a slice or a map, 0 will read elements entirely.
+	MaxElementBytes int
+	// PrettyKnownTypes requests that the internal state of sync.Map,
+	// sync.WaitGroup and sync.Once values is decoded: sync.Map values are
+	// returned as maps, sync.WaitGroup values have the fields counter and
+	// waiters and sync.Once values have the field done.
+	PrettyKnownTypes bool
 }
 
 // Goroutine represents the information relevant to Delve from the runtime's
@@ -341,7 +670,9 @@ type Goroutine struct {
 	// Location of the starting function
 	StartLoc Location `json:"startLoc"`
 	// ID of the associated thread for running goroutines
-	ThreadID   int    `json:"threadID"`
+	ThreadID int `json:"threadID"`
+	// Status is the scheduling status of the goroutine, the value of the
+	// atomicstatus field of the runtime.g struct, see GoroutineStatusName.
 	Status     uint64 `json:"status"`
 	WaitSince  int64  `json:"waitSince"`
 	WaitReason int64  `json:"waitReason"`
@@ -355,6 +686,52 @@ const (
 	GoroutineSyscall = proc.Gsyscall
 )
 
+// Goroutine statuses, the possible values of the Status field of Goroutine,
+// see src/runtime/runtime2.go.
+const (
+	Gidle      = proc.Gidle
+	Grunnable  = proc.Grunnable
+	Grunning   = proc.Grunning
+	Gsyscall   = proc.Gsyscall
+	Gwaiting   = proc.Gwaiting
+	Gdead      = proc.Gdead
+	Gcopystack = proc.Gcopystack
+
+	// Gscan is set in the status of a goroutine while its stack is being
+	// scanned by the garbage collector, in combination with one of the
+	// statuses above.
+	Gscan = 0x1000
+)
+
+// GoroutineStatusName returns the name of a goroutine status: "idle",
+// "runnable", "running", "syscall", "waiting", "dead" or "copystack". The
+// Gscan bit is ignored.
+func GoroutineStatusName(status uint64) string {
+	switch status &^ Gscan {
+	case Gidle:
+		return "idle"
+	case Grunnable:
+		return "runnable"
+	case Grunning:
+		return "running"
+	case Gsyscall:
+		return "syscall"
+	case Gwaiting:
+		return "waiting"
+	case Gdead:
+		return "dead"
+	case Gcopystack:
+		return "copystack"
+	default:
+		return fmt.Sprintf("unknown(%d)", status)
+	}
+}
+
+// DefaultStepExcludingPackages is a list of packages, suitable for
+// DebuggerCommand.StepExcludingPackages, that users rarely want to step
+// into.
+var DefaultStepExcludingPackages = []string{"runtime", "internal/*"}
+
 // DebuggerCommand is a command which changes the debugger's execution state.
 type DebuggerCommand struct {
 	// Name is the command to run.
@@ -385,6 +762,45 @@ type DebuggerCommand struct {
 	// violate the rules about stack objects you can disable this safety check
 	// by setting UnsafeCall to true.
 	UnsafeCall bool `json:"unsafeCall,omitempty"`
+
+	// CallTimeout, if greater than zero, is the maximum amount of time a
+	// Call command can run for. If the injected call has not returned by
+	// then the target is stopped, the called function is unwound, without
+	// running its deferred calls, and an error is returned.
+	// A call that is blocked (for example on a channel operation) can not be
+	// unwound, it is left in progress: continuing the target will resume
+	// it, CallInjectionStack shows where it is stuck.
+	CallTimeout time.Duration `json:"callTimeout,omitempty"`
+
+	// Count is the number of instructions to execute for the StepInstruction
+	// and ReverseStepInstruction commands, values smaller than 1 are
+	// interpreted as 1. Stepping stops early if a breakpoint is reached or
+	// the target is halted.
+	Count int `json:"count,omitempty"`
+
+	// StepExcludingPackages is a list of packages that the Step command will
+	// not step into, calls to their functions are stepped over. Entries are
+	// either package import paths or a prefix followed by "/*", matching
+	// all the packages below it. See DefaultStepExcludingPackages.
+	StepExcludingPackages []string `json:"stepExcludingPackages,omitempty"`
+
+	// SkipTracepoints, if set for the Continue and DirectionCongruentContinue
+	// commands, makes breakpoints with the Tracepoint or TraceReturn flags
+	// set behave as if they weren't there: the target will not stop on them
+	// and no data will be collected. Their hit counts are still updated.
+	SkipTracepoints bool `json:"skipTracepoints,omitempty"`
+
+	// File and Line are the target of the StepToLine command, if File is
+	// empty the file of the current frame is used.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+
+	// Functions are the targets of the ContinueToFunctions command.
+	Functions []string `json:"functions,omitempty"`
+
+	// Signal is the signal delivered to the current thread by the
+	// ContinueWithSignal command, 0 suppresses the pending signal.
+	Signal int `json:"signal,omitempty"`
 }
 
 // BreakpointInfo contains informations about the current breakpoint
@@ -394,6 +810,133 @@ type BreakpointInfo struct {
 	Variables  []Variable   `json:"variables,omitempty"`
 	Arguments  []Variable   `json:"arguments,omitempty"`
 	Locals     []Variable   `json:"locals,omitempty"`
+	// AssertError describes the failure of the Assert expression of the
+	// breakpoint.
+	AssertError string `json:"assertError,omitempty"`
+	// TraceMessage is the TraceMessage of the breakpoint, formatted.
+	TraceMessage string `json:"traceMessage,omitempty"`
+	// Time is the time at which the target stopped at the breakpoint.
+	Time time.Time `json:"time"`
+}
+
+// GoroutineDump is a dump of all goroutines, recorded the first time a
+// breakpoint with DumpGoroutinesOnce set was hit.
+type GoroutineDump struct {
+	// BreakpointID is the ID of the breakpoint that recorded the dump.
+	BreakpointID int `json:"breakpointID"`
+	// GoroutineID is the ID of the goroutine that hit the breakpoint.
+	GoroutineID int `json:"goroutineID"`
+	// Goroutines contains all the goroutines that existed when the
+	// breakpoint was hit, with their stacktraces.
+	Goroutines []GoroutineStacktrace `json:"goroutines"`
+}
+
+// GoroutineStacktrace is a goroutine and its stacktrace.
+type GoroutineStacktrace struct {
+	Goroutine  *Goroutine   `json:"goroutine"`
+	Stacktrace []Stackframe `json:"stacktrace,omitempty"`
+	// Err is set if the stacktrace of the goroutine could not be read.
+	Err string `json:"err,omitempty"`
+}
+
+// GoroutineDiff describes how the goroutines of the target changed between
+// two snapshots.
+type GoroutineDiff struct {
+	// Created lists the goroutines that did not exist in the previous
+	// snapshot.
+	Created []GoroutineStacktrace `json:"created,omitempty"`
+	// Exited lists the goroutines of the previous snapshot that no longer
+	// exist.
+	Exited []GoroutineStacktrace `json:"exited,omitempty"`
+	// Moved lists the goroutines whose stacktrace changed.
+	Moved []GoroutineMove `json:"moved,omitempty"`
+	// Current is the current snapshot, it can be used as the previous
+	// snapshot for the next comparison.
+	Current []GoroutineStacktrace `json:"current"`
+}
+
+// GoroutineMove describes a goroutine whose stacktrace changed between two
+// snapshots.
+type GoroutineMove struct {
+	Prev    GoroutineStacktrace `json:"prev"`
+	Current GoroutineStacktrace `json:"current"`
+	// CommonFrames is the number of outermost frames that did not change.
+	CommonFrames int `json:"commonFrames"`
+}
+
+// DiffGoroutineStacktraces compares two snapshots of the goroutines of the
+// target. Goroutines are matched by ID, a goroutine moved if the PC of any
+// of its frames changed.
+func DiffGoroutineStacktraces(prev, cur []GoroutineStacktrace) *GoroutineDiff {
+	diff := &GoroutineDiff{Current: cur}
+	prevByID := make(map[int]GoroutineStacktrace, len(prev))
+	for _, g := range prev {
+		prevByID[g.Goroutine.ID] = g
+	}
+	curIDs := make(map[int]bool, len(cur))
+	for _, g := range cur {
+		curIDs[g.Goroutine.ID] = true
+		p, ok := prevByID[g.Goroutine.ID]
+		if !ok {
+			diff.Created = append(diff.Created, g)
+			continue
+		}
+		common := commonOutermostFrames(p.Stacktrace, g.Stacktrace)
+		if common != len(p.Stacktrace) || common != len(g.Stacktrace) {
+			diff.Moved = append(diff.Moved, GoroutineMove{Prev: p, Current: g, CommonFrames: common})
+		}
+	}
+	for _, g := range prev {
+		if !curIDs[g.Goroutine.ID] {
+			diff.Exited = append(diff.Exited, g)
+		}
+	}
+	return diff
+}
+
+// commonOutermostFrames returns the number of outermost frames a and b have
+// in common.
+func commonOutermostFrames(a, b []Stackframe) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n].PC == b[len(b)-1-n].PC {
+		n++
+	}
+	return n
+}
+
+// TracepointSpec describes a tracepoint created by Client.Trace.
+type TracepointSpec struct {
+	// FunctionName is the function to trace. If File is empty the tracepoint
+	// is set at Line lines from the start of the function, or at its entry
+	// point if Line is 0.
+	FunctionName string `json:"functionName,omitempty"`
+	// File and Line are the source location of the tracepoint.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// Variables is a list of expressions evaluated every time the
+	// tracepoint is hit.
+	Variables []string `json:"variables,omitempty"`
+	// LoadArgs, if not nil, is used to load the arguments of the function
+	// every time the tracepoint is hit.
+	LoadArgs *LoadConfig `json:"loadArgs,omitempty"`
+}
+
+// TraceEvent is a hit of a tracepoint created by Client.Trace.
+type TraceEvent struct {
+	// BreakpointID is the ID of the tracepoint that was hit.
+	BreakpointID int    `json:"breakpointID"`
+	Function     string `json:"function,omitempty"`
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	GoroutineID  int    `json:"goroutineID"`
+	// Args contains the arguments of the function, if the tracepoint was
+	// created with LoadArgs.
+	Args []Variable `json:"args,omitempty"`
+	// Variables contains the values of the Variables of the tracepoint.
+	Variables []Variable `json:"variables,omitempty"`
+	// Time is the time at which the target stopped at the tracepoint, as
+	// measured by the server.
+	Time time.Time `json:"time"`
 }
 
 // EvalScope is the scope a command should
@@ -419,6 +962,19 @@ const (
 	StepOut = "stepOut"
 	// ReverseStepOut continues backward to the calle rof the current function.
 	ReverseStepOut = "reverseStepOut"
+	// StepOutToUserCode steps out of the current function and then out of
+	// its callers until a function that does not belong to the standard
+	// library is reached.
+	StepOutToUserCode = "stepOutToUserCode"
+	// StepToLine continues until the specified line of the current function
+	// is reached or the current function returns.
+	StepToLine = "stepToLine"
+	// ContinueToFunctions continues until any of the specified functions is
+	// entered.
+	ContinueToFunctions = "continueToFunctions"
+	// ContinueWithSignal resumes process execution delivering a signal to
+	// the current thread, in place of the one that stopped it.
+	ContinueWithSignal = "continueWithSignal"
 	// StepInstruction continues for exactly 1 cpu instruction.
 	StepInstruction = "stepInstruction"
 	// ReverseStepInstruction reverses execution for exactly 1 cpu instruction.
@@ -459,6 +1015,10 @@ type AsmInstruction struct {
 	Loc Location
 	// Destination of CALL instructions
 	DestLoc *Location
+	// BranchTarget is the destination of conditional and unconditional jump
+	// instructions, if it is an instruction of the disassembled range, 0
+	// if it is outside of the range or computed at runtime.
+	BranchTarget uint64 `json:"branchTarget,omitempty"`
 	// Text is the formatted representation of the instruction
 	Text string
 	// Bytes is the instruction as read from memory
@@ -528,6 +1088,42 @@ type DiscardedBreakpoint struct {
 	Reason     string
 }
 
+// EvalAlias is an alias for an expression, that can be referenced as
+// @Name in the expressions passed to EvalVariable.
+type EvalAlias struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+// Watch is an expression registered with AddWatch, it is evaluated every
+// time the state of the debugger is returned.
+type Watch struct {
+	ID    int       `json:"id"`
+	Scope EvalScope `json:"scope"`
+	Expr  string    `json:"expr"`
+	// Value is the value of Expr, nil if it could not be evaluated.
+	Value *Variable `json:"value,omitempty"`
+	// Err is the error returned evaluating Expr.
+	Err string `json:"err,omitempty"`
+}
+
+// MemoryMap is a region of the target's address space.
+type MemoryMap struct {
+	Addr uint64 `json:"addr"`
+	Size uint64 `json:"size"`
+
+	Read  bool `json:"read"`
+	Write bool `json:"write"`
+	Exec  bool `json:"exec"`
+
+	// Filename is the file backing the region, or the name of special
+	// regions, like "[heap]" and "[stack]" on linux. Empty for anonymous
+	// regions or if the backend does not report it.
+	Filename string `json:"filename,omitempty"`
+	// Offset is the offset of the region in Filename.
+	Offset uint64 `json:"offset,omitempty"`
+}
+
 // Checkpoint is a point in the program that
 // can be returned to in certain execution modes.
 type Checkpoint struct {
@@ -536,10 +1132,118 @@ type Checkpoint struct {
 	Where string
 }
 
+// OperationStats describes the last command that resumed the target, or
+// the one currently running.
+type OperationStats struct {
+	// Command is the name of the command, one of the commands accepted by
+	// the Command RPC or "restart" for the restart of a recording.
+	Command string `json:"command"`
+	// Start is the time at which the command started.
+	Start time.Time `json:"start"`
+	// Duration is the time taken by the command, or the time elapsed so far
+	// if the command is still running.
+	Duration time.Duration `json:"duration"`
+	// Running is true if the command has not terminated yet.
+	Running bool `json:"running"`
+	// StartEvent and EndEvent are the rr event numbers at the start and at
+	// the end of the command, they are 0 if the target isn't a recording.
+	StartEvent int64 `json:"startEvent,omitempty"`
+	EndEvent   int64 `json:"endEvent,omitempty"`
+	// EventsTraversed is the number of rr events traversed by the command,
+	// in either direction.
+	EventsTraversed int64 `json:"eventsTraversed,omitempty"`
+}
+
+// DebuggerStats describes the resources used by the debugger itself to
+// debug the target.
+type DebuggerStats struct {
+	// HeapAlloc, HeapSys and Sys are the corresponding fields of the memory
+	// statistics of the debugger process, most of the memory allocated by
+	// the debugger is used to cache the debug information of the target.
+	HeapAlloc uint64 `json:"heapAlloc"`
+	HeapSys   uint64 `json:"heapSys"`
+	Sys       uint64 `json:"sys"`
+	// NumGC is the number of garbage collections run by the debugger.
+	NumGC uint32 `json:"numGC"`
+	// Functions, Types and Sources are the number of functions, named types
+	// and source files found in the debug information of the target.
+	Functions int `json:"functions"`
+	Types     int `json:"types"`
+	Sources   int `json:"sources"`
+	// Images contains statistics about the debug information of the
+	// executable (the first element) and of each dynamic library.
+	Images []ImageStats `json:"images"`
+}
+
+// ImageStats describes the debug information loaded for an image and the
+// usage of the caches used to read it.
+type ImageStats struct {
+	Path string `json:"path"`
+	// CompileUnits is the number of compile units parsed.
+	CompileUnits int `json:"compileUnits"`
+	// TypeCacheEntries is the number of types read from debug_info so far.
+	TypeCacheEntries int `json:"typeCacheEntries"`
+	// DwarfTreeCacheEntries is the number of trees of debug_info entries
+	// currently cached, DwarfTreeCacheHits and DwarfTreeCacheMisses count
+	// the lookups that were served by the cache and the ones that had to
+	// read debug_info.
+	DwarfTreeCacheEntries int    `json:"dwarfTreeCacheEntries"`
+	DwarfTreeCacheHits    uint64 `json:"dwarfTreeCacheHits"`
+	DwarfTreeCacheMisses  uint64 `json:"dwarfTreeCacheMisses"`
+}
+
 // Image represents a loaded shared object (go plugin or shared library)
 type Image struct {
 	Path    string
 	Address uint64
+	// DebugInfo is true if debug information was found for this image,
+	// breakpoints can only be set on images that have debug information.
+	DebugInfo bool
+	// LoadError describes the error encountered while loading the debug
+	// information of this image, if any.
+	LoadError string
+}
+
+// BuildInfo describes how the target binary was built, it contains the
+// same information printed by 'go version -m'.
+type BuildInfo struct {
+	// GoVersion is the version of the Go toolchain that built the binary.
+	GoVersion string `json:"goVersion"`
+	// Path is the package path of the main package.
+	Path string `json:"path,omitempty"`
+	// Main describes the module containing the main package.
+	Main Module `json:"main"`
+	// Deps are the modules the binary depends on.
+	Deps []Module `json:"deps,omitempty"`
+	// Settings are the build settings used to build the binary, including
+	// the version control stamps vcs.revision, vcs.time and vcs.modified.
+	Settings []BuildSetting `json:"settings,omitempty"`
+}
+
+// Setting returns the value of the build setting key or the empty string
+// if it was not recorded.
+func (bi *BuildInfo) Setting(key string) string {
+	for _, s := range bi.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// Module describes a module used to build the target binary.
+type Module struct {
+	Path    string  `json:"path"`
+	Version string  `json:"version,omitempty"`
+	Sum     string  `json:"sum,omitempty"`
+	Replace *Module `json:"replace,omitempty"`
+}
+
+// BuildSetting is a key/value pair describing a setting used to build the
+// target binary.
+type BuildSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 // Ancestor represents a goroutine ancestor
@@ -572,6 +1276,7 @@ const (
 // ImportPathToDirectoryPath maps an import path to a directory path.
 type PackageBuildInfo struct {
 	ImportPath    string
+	PackageName   string
 	DirectoryPath string
 	Files         []string
 }
@@ -607,6 +1312,8 @@ const (
 	GoroutineLabel                     // the goroutine's label
 	GoroutineRunning                   // the goroutine is running
 	GoroutineUser                      // the goroutine is a user goroutine
+	GoroutineStatus                    // the goroutine's scheduling status, see GoroutineStatusName
+	GoroutineUserCode                  // the goroutine's UserLoc is in user (non-runtime) code
 )
 
 // GoroutineGroup represents a group of goroutines in the return value of
@@ -624,3 +1331,19 @@ type GoroutineGroupingOptions struct {
 	MaxGroupMembers int
 	MaxGroups       int
 }
+
+// ValueSample is one of the values taken by an expression during a
+// recording, see ValueHistory.
+type ValueSample struct {
+	// Event is the rr event at which the value was observed.
+	Event int64 `json:"event"`
+	// When is a description of the position in the recording, as returned
+	// by the When field of DebuggerState.
+	When string `json:"when"`
+	// Location is the location of the current thread when the value was
+	// observed, for all values except the first one this is where the
+	// target stopped right after writing it.
+	Location Location `json:"location"`
+	// Value is the value of the expression.
+	Value Variable `json:"value"`
+}
@@ -0,0 +1,35 @@
+package api
+
+import "testing"
+
+func TestDiffGoroutineStacktraces(t *testing.T) {
+	mkg := func(id int, pcs ...uint64) GoroutineStacktrace {
+		frames := make([]Stackframe, len(pcs))
+		for i, pc := range pcs {
+			frames[i].PC = pc
+		}
+		return GoroutineStacktrace{Goroutine: &Goroutine{ID: id}, Stacktrace: frames}
+	}
+
+	prev := []GoroutineStacktrace{mkg(1, 0x10, 0x20, 0x30), mkg(2, 0x40, 0x50), mkg(3, 0x60)}
+	cur := []GoroutineStacktrace{mkg(1, 0x11, 0x20, 0x30), mkg(2, 0x40, 0x50), mkg(4, 0x70)}
+
+	diff := DiffGoroutineStacktraces(prev, cur)
+	if len(diff.Created) != 1 || diff.Created[0].Goroutine.ID != 4 {
+		t.Errorf("wrong created goroutines: %#v", diff.Created)
+	}
+	if len(diff.Exited) != 1 || diff.Exited[0].Goroutine.ID != 3 {
+		t.Errorf("wrong exited goroutines: %#v", diff.Exited)
+	}
+	if len(diff.Moved) != 1 || diff.Moved[0].Current.Goroutine.ID != 1 || diff.Moved[0].CommonFrames != 2 {
+		t.Errorf("wrong moved goroutines: %#v", diff.Moved)
+	}
+	if len(diff.Current) != len(cur) {
+		t.Errorf("wrong current snapshot: %#v", diff.Current)
+	}
+
+	diff = DiffGoroutineStacktraces(nil, cur)
+	if len(diff.Created) != len(cur) || len(diff.Exited) != 0 || len(diff.Moved) != 0 {
+		t.Errorf("wrong diff from empty snapshot: %#v", diff)
+	}
+}
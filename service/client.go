@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/go-delve/delve/service/api"
@@ -17,11 +18,19 @@ type Client interface {
 
 	// Detach detaches the debugger, optionally killing the process.
 	Detach(killProcess bool) error
+	// DetachWithSignal detaches the debugger, leaving the process running,
+	// and then sends it signal sig. Only processes the debugger attached to
+	// can be signaled.
+	DetachWithSignal(sig int) error
 
 	// Restarts program. Set true if you want to rebuild the process we are debugging.
 	Restart(rebuild bool) ([]api.DiscardedBreakpoint, error)
 	// Restarts program from the specified position.
 	RestartFrom(rerecord bool, pos string, resetArgs bool, newArgs []string, newRedirects [3]string, rebuild bool) ([]api.DiscardedBreakpoint, error)
+	// RestartWithEnv restarts the program changing its environment, entries
+	// of newEnv of the form KEY=VALUE set KEY, entries of the form KEY remove
+	// it. Returns the environment the program was launched with.
+	RestartWithEnv(newEnv []string, rebuild bool) ([]api.DiscardedBreakpoint, []string, error)
 
 	// GetState returns the current debugger state.
 	GetState() (*api.DebuggerState, error)
@@ -30,33 +39,91 @@ type Client interface {
 
 	// Continue resumes process execution.
 	Continue() <-chan *api.DebuggerState
+	// ContinueSkippingTracepoints is like Continue but the target does not
+	// stop on tracepoints and no tracepoint data is collected.
+	ContinueSkippingTracepoints() <-chan *api.DebuggerState
 	// Rewind resumes process execution backwards.
 	Rewind() <-chan *api.DebuggerState
 	// DirecitonCongruentContinue resumes process execution, if a reverse next, step or stepout operation is in progress it will resume execution backward.
 	DirectionCongruentContinue() <-chan *api.DebuggerState
+	// Trace creates a tracepoint for each element of specs, resumes the
+	// target and returns a channel of the tracepoint hits and a function
+	// that stops tracing.
+	Trace(specs []api.TracepointSpec) (<-chan api.TraceEvent, func(), error)
 	// Next continues to the next source line, not entering function calls.
 	Next() (*api.DebuggerState, error)
 	// ReverseNext continues backward to the previous line of source code, not entering function calls.
 	ReverseNext() (*api.DebuggerState, error)
 	// Step continues to the next source line, entering function calls.
 	Step() (*api.DebuggerState, error)
+	// StepExcluding is like Step but steps over calls to functions belonging
+	// to one of the packages in excludedPkgs, see api.DefaultStepExcludingPackages.
+	StepExcluding(excludedPkgs []string) (*api.DebuggerState, error)
 	// ReverseStep continues backward to the previous line of source code, entering function calls.
 	ReverseStep() (*api.DebuggerState, error)
 	// StepOut continues to the return address of the current function.
 	StepOut() (*api.DebuggerState, error)
+	// StepOutToUserCode steps out of the current function, and out of its
+	// callers, until it returns to a function that is not part of the
+	// standard library or there are no more callers.
+	StepOutToUserCode() (*api.DebuggerState, error)
+	// StepToLine continues until the specified line of the current function
+	// is reached or the current function returns. If file is empty the file
+	// of the current frame is used.
+	StepToLine(file string, line int) (*api.DebuggerState, error)
+	// ContinueToFunctions continues until any goroutine enters one of the
+	// named functions, the function that was entered is reported in the
+	// ReachedFunction field of the returned state.
+	ContinueToFunctions(names []string) (*api.DebuggerState, error)
+	// ContinueWithSignal resumes the target delivering sig to the current
+	// thread in place of the signal that stopped it, 0 suppresses it.
+	ContinueWithSignal(sig int) (*api.DebuggerState, error)
+	// SetStopSignals sets the signals that stop the target, instead of being
+	// delivered to it, when they are received by one of its threads, the
+	// signal is reported in the Signal field of the returned state.
+	SetStopSignals(sigs []int) error
 	// ReverseStepOut continues backward to the calle rof the current function.
 	ReverseStepOut() (*api.DebuggerState, error)
 	// Call resumes process execution while making a function call.
 	Call(goroutineID int, expr string, unsafe bool) (*api.DebuggerState, error)
+	// CallMethod calls method on the value of receiverExpr, evaluated in the
+	// topmost frame of goroutine scope.GoroutineID, with arguments args.
+	CallMethod(scope api.EvalScope, receiverExpr, method string, args []string) (*api.DebuggerState, error)
+	// SetNextStatement moves the program counter of goroutine
+	// scope.GoroutineID to file:line, in the function of its topmost frame,
+	// without resuming the target.
+	SetNextStatement(scope api.EvalScope, file string, line int) (*api.DebuggerState, error)
+	// CallWithTimeout is like Call but, if the call does not return within
+	// timeout, it aborts the call and returns an error, see
+	// api.DebuggerCommand.CallTimeout.
+	CallWithTimeout(goroutineID int, expr string, unsafe bool, timeout time.Duration) (*api.DebuggerState, error)
 
 	// SingleStep will step a single cpu instruction.
 	StepInstruction() (*api.DebuggerState, error)
+	// StepInstructions will step n cpu instructions, stopping early if a
+	// breakpoint is reached or the target is halted.
+	StepInstructions(n int) (*api.DebuggerState, error)
 	// ReverseSingleStep will reverse step a single cpu instruction.
 	ReverseStepInstruction() (*api.DebuggerState, error)
 	// SwitchThread switches the current thread context.
 	SwitchThread(threadID int) (*api.DebuggerState, error)
 	// SwitchGoroutine switches the current goroutine (and the current thread as well)
 	SwitchGoroutine(goroutineID int) (*api.DebuggerState, error)
+	// ReferencesTo scans the stacks of all goroutines for words equal to addr
+	// and returns up to maxResults of them (all if maxResults is zero),
+	// with the goroutine, frame and variable where they were found.
+	ReferencesTo(addr uint64, maxResults int) ([]api.Reference, error)
+	// ParkGoroutine prevents the goroutine from running when the target is
+	// continued, while the rest of the program runs normally. Only a
+	// goroutine that is running on a thread can be held and not all
+	// backends support it.
+	ParkGoroutine(gid int) error
+	// UnparkGoroutine lets a parked goroutine run again.
+	UnparkGoroutine(gid int) error
+	// SetGoroutineLabels attaches labels to a goroutine in the debugger's
+	// view of the target, they are used by ListGoroutinesWithFilter but
+	// not written to the target. An empty map removes them.
+	SetGoroutineLabels(gid int, labels map[string]string) error
 	// Halt suspends the process.
 	Halt() (*api.DebuggerState, error)
 
@@ -66,10 +133,24 @@ type Client interface {
 	GetBreakpointByName(name string) (*api.Breakpoint, error)
 	// CreateBreakpoint creates a new breakpoint.
 	CreateBreakpoint(*api.Breakpoint) (*api.Breakpoint, error)
+	// CreateBreakpointsInPackage sets a tracepoint on every function of a
+	// package, and on their return locations unless entryOnly is set. It
+	// returns the breakpoints created.
+	CreateBreakpointsInPackage(pkg string, entryOnly bool) ([]*api.Breakpoint, error)
+	// BreakOnNextPanic arms the specified goroutine so that the target stops
+	// the next time it panics, after which the goroutine is disarmed. If
+	// goroutineID is negative the selected goroutine is used. It returns the
+	// IDs of all the armed goroutines.
+	BreakOnNextPanic(goroutineID int) ([]int, error)
 	// CreateWatchpoint creates a new watchpoint.
 	CreateWatchpoint(api.EvalScope, string, api.WatchType) (*api.Breakpoint, error)
 	// ListBreakpoints gets all breakpoints.
 	ListBreakpoints() ([]*api.Breakpoint, error)
+	// BreakpointHitStats returns the hit counters of all enabled breakpoints.
+	BreakpointHitStats() ([]api.BreakpointStat, error)
+	// BreakpointsSharingAddress returns the addresses used by more than one
+	// logical breakpoint (including disabled ones), mapped to their IDs.
+	BreakpointsSharingAddress() (map[uint64][]int, error)
 	// ClearBreakpoint deletes a breakpoint by ID.
 	ClearBreakpoint(id int) (*api.Breakpoint, error)
 	// ClearBreakpointByName deletes a breakpoint by name
@@ -78,14 +159,31 @@ type Client interface {
 	ToggleBreakpoint(id int) (*api.Breakpoint, error)
 	// ToggleBreakpointByName toggles on or off a breakpoint by name.
 	ToggleBreakpointByName(name string) (*api.Breakpoint, error)
+	// EnableBreakpoint enables a breakpoint by ID, it does nothing if the
+	// breakpoint is already enabled.
+	EnableBreakpoint(id int) (*api.Breakpoint, error)
+	// DisableBreakpoint disables a breakpoint by ID, it does nothing if the
+	// breakpoint is already disabled.
+	DisableBreakpoint(id int) (*api.Breakpoint, error)
+	// GetGoroutineDump returns the dump of all goroutines recorded the
+	// first time a breakpoint with DumpGoroutinesOnce set was hit.
+	GetGoroutineDump(id int) (*api.GoroutineDump, error)
+	// GoroutineStacktraceDiff takes a snapshot of all goroutines and
+	// compares it with prev, the Current field of an earlier result.
+	GoroutineStacktraceDiff(prev []api.GoroutineStacktrace) (*api.GoroutineDiff, error)
 	// Allows user to update an existing breakpoint for example to change the information
 	// retrieved when the breakpoint is hit or to change, add or remove the break condition
 	AmendBreakpoint(*api.Breakpoint) error
+	// AmendBreakpointEx is like AmendBreakpoint but also returns the
+	// amended breakpoint, with its Addrs populated.
+	AmendBreakpointEx(*api.Breakpoint) (*api.Breakpoint, error)
 	// Cancels a Next or Step call that was interrupted by a manual stop or by another breakpoint
 	CancelNext() error
 
 	// ListThreads lists all threads.
 	ListThreads() ([]*api.Thread, error)
+	// ListThreadsWithFilter lists the threads matching filter.
+	ListThreadsWithFilter(filter api.ThreadFilter) ([]*api.Thread, error)
 	// GetThread gets a thread by its ID.
 	GetThread(id int) (*api.Thread, error)
 
@@ -93,27 +191,82 @@ type Client interface {
 	ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error)
 	// EvalVariable returns a variable in the context of the current thread.
 	EvalVariable(scope api.EvalScope, symbol string, cfg api.LoadConfig) (*api.Variable, error)
+	// EvalAcrossGoroutines evaluates expr in the topmost frame of every
+	// goroutine, the results are keyed by goroutine ID. Goroutines where the
+	// evaluation fails have an unreadable variable.
+	EvalAcrossGoroutines(expr string, cfg api.LoadConfig) (map[int]*api.Variable, error)
+	// EvalToJSON evaluates an expression and returns its value as a JSON
+	// tree, with the format described by api.JSONValue.
+	EvalToJSON(scope api.EvalScope, expr string, cfg api.LoadConfig) (json.RawMessage, error)
+	// WhatIs returns the type of an expression without loading its value.
+	WhatIs(scope api.EvalScope, expr string) (*api.TypeInfo, error)
+	// SetEvalAlias defines an alias for expr, that can be used as @name in
+	// the expressions passed to EvalVariable. An empty expr removes the alias.
+	SetEvalAlias(name, expr string) error
+	// ListEvalAliases returns all aliases defined with SetEvalAlias.
+	ListEvalAliases() ([]api.EvalAlias, error)
+	// AddWatch registers expr, to be evaluated in scope every time the state
+	// of the debugger is returned (see DebuggerState.Watches), and returns
+	// the ID of the watch.
+	AddWatch(scope api.EvalScope, expr string) (int, error)
+	// RemoveWatch removes the watch with the specified ID.
+	RemoveWatch(id int) error
 
 	// SetVariable sets the value of a variable
 	SetVariable(scope api.EvalScope, symbol, value string) error
 
 	// ListSources lists all source files in the process matching filter.
 	ListSources(filter string) ([]string, error)
+	// SourceLines returns the lines from start to end (inclusive) of a source
+	// file of the target, read on the server. The substitutePathRules have the
+	// same meaning as in FindLocation.
+	SourceLines(file string, start, end int, substitutePathRules [][2]string) ([]string, error)
+	// ListSourcesGrouped lists all source files in the process matching
+	// filter, grouped by the import path of their package.
+	ListSourcesGrouped(filter string) (map[string][]string, error)
 	// ListFunctions lists all functions in the process matching filter.
 	ListFunctions(filter string) ([]string, error)
 	// ListTypes lists all types in the process matching filter.
 	ListTypes(filter string) ([]string, error)
+	// SetDefaultLoadConfig sets the LoadConfig used by the server, for this
+	// connection only, when a request does not specify one.
+	SetDefaultLoadConfig(cfg api.LoadConfig) error
+	// FindType returns the definition of a type: its size, the layout of its
+	// fields and its methods.
+	FindType(name string) (*api.TypeDefinition, error)
 	// ListLocals lists all local variables in scope.
 	ListLocalVariables(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error)
+	// ListLocalVariablesFiltered lists the local variables in scope whose
+	// name matches the regular expression filter.
+	ListLocalVariablesFiltered(scope api.EvalScope, filter string, cfg api.LoadConfig) ([]api.Variable, error)
 	// ListFunctionArgs lists all arguments to the current function.
 	ListFunctionArgs(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error)
+	// VisibleNames returns the identifiers that can be used in expressions
+	// evaluated in the specified scope.
+	VisibleNames(scope api.EvalScope) ([]string, error)
+	// MutexOwner returns whether the sync.Mutex or sync.RWMutex expr is
+	// locked and the goroutines that could be holding it, the candidates
+	// are found with a heuristic.
+	MutexOwner(scope api.EvalScope, expr string) (locked bool, candidates []*api.Goroutine, err error)
 	// ListThreadRegisters lists registers and their values, for the given thread.
 	ListThreadRegisters(threadID int, includeFp bool) (api.Registers, error)
 	// ListScopeRegisters lists registers and their values, for the given scope.
 	ListScopeRegisters(scope api.EvalScope, includeFp bool) (api.Registers, error)
+	// SetRegister changes the value of a register of the given thread. The
+	// name can select a lane of a vector register, as in XMM0.float32[1].
+	// Registers of 64 bits or less are set from an integer, larger ones
+	// from a string of hexadecimal digits and lanes from a value of the
+	// lane type.
+	SetRegister(threadID int, name, value string) error
 
 	// ListGoroutines lists all goroutines.
 	ListGoroutines(start, count int) ([]*api.Goroutine, int, error)
+	// DeferredCalls returns the deferred calls pending on a goroutine, for the
+	// frame of scope and the frames below it, in the order they will run.
+	DeferredCalls(scope api.EvalScope, cfg api.LoadConfig) ([]api.DeferredCall, error)
+	// Goroutine returns the goroutine with the specified ID, or an "unknown
+	// goroutine" error if it does not exist.
+	Goroutine(id int) (*api.Goroutine, error)
 	// ListGoroutinesWithFilter lists goroutines matching the filters
 	ListGoroutinesWithFilter(start, count int, filters []api.ListGoroutinesFilter, group *api.GoroutineGroupingOptions) ([]*api.Goroutine, []api.GoroutineGroup, int, bool, error)
 
@@ -123,6 +276,13 @@ type Client interface {
 	// Returns ancestor stacktraces
 	Ancestors(goroutineID int, numAncestors int, depth int) ([]api.Ancestor, error)
 
+	// Returns the goroutines created by the specified goroutine
+	Children(goroutineID int) ([]*api.Goroutine, error)
+
+	// Returns the stack frames of the injected function call in progress, up
+	// to the runtime function used to inject it
+	CallInjectionStack() ([]api.Stackframe, error)
+
 	// Returns whether we attached to a running process or not
 	AttachedToExistingProcess() bool
 
@@ -139,11 +299,27 @@ type Client interface {
 	// NOTE: this function does not actually set breakpoints.
 	// If findInstruction is true FindLocation will only return locations that correspond to instructions.
 	FindLocation(scope api.EvalScope, loc string, findInstruction bool, substitutePathRules [][2]string) ([]api.Location, error)
+	// FindLocationCandidates is like FindLocation but, when loc matches more
+	// than one file or function, it returns a location for each of them
+	// instead of an error. The exact match comes first, followed by the
+	// candidates in the package of the selected frame and then by the
+	// distance of their import path from it, ties are broken by name.
+	FindLocationCandidates(scope api.EvalScope, loc string, findInstruction bool, substitutePathRules [][2]string) ([]api.Location, error)
+	// ResolveAddress returns the file, line, function and symbol containing
+	// pc, along with the offset of pc from the start of the symbol.
+	ResolveAddress(pc uint64) (*api.Location, error)
+	// PreviewBreakpoint returns the physical breakpoints that would be
+	// created by a breakpoint on loc, one for each address, without creating
+	// them. The returned locations report whether each address belongs to an
+	// inlined call or to a compiler generated wrapper.
+	PreviewBreakpoint(loc string) ([]api.Location, error)
 
 	// Disassemble code between startPC and endPC
 	DisassembleRange(scope api.EvalScope, startPC, endPC uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error)
 	// Disassemble code of the function containing PC
 	DisassemblePC(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error)
+	// Disassemble code of the function named funcName
+	DisassembleFunction(scope api.EvalScope, funcName string, flavour api.AssemblyFlavour) (api.AsmInstructions, error)
 
 	// Recorded returns true if the target is a recording.
 	Recorded() bool
@@ -155,21 +331,85 @@ type Client interface {
 	ListCheckpoints() ([]api.Checkpoint, error)
 	// ClearCheckpoint removes a checkpoint
 	ClearCheckpoint(id int) error
+	// LastOperationStats returns the duration and the number of rr events
+	// traversed by the last command that resumed the target, or by the one
+	// currently running. Returns nil if the target was never resumed.
+	LastOperationStats() (*api.OperationStats, error)
+	// ExportChromeTrace replays the recording between the rr events
+	// fromEvent and toEvent (to the end of the recording if toEvent is
+	// negative) and returns a timeline of the goroutines executed on each
+	// thread, in the Chrome trace event format.
+	ExportChromeTrace(fromEvent, toEvent int64) ([]byte, error)
+	// ValueHistory evaluates expr in the specified scope and returns the
+	// values it takes over the whole recording, at most maxSamples of them
+	// (all if maxSamples is not positive), with the rr event at which each
+	// one was written. The expression must be a global or heap allocated
+	// variable that can be watched with a watchpoint.
+	ValueHistory(scope api.EvalScope, expr string, maxSamples int) ([]api.ValueSample, error)
 
 	// SetReturnValuesLoadConfig sets the load configuration for return values.
 	SetReturnValuesLoadConfig(*api.LoadConfig)
 
+	// SetOutputRadix sets the base (8, 10 or 16) used to format integer
+	// values in the variables returned by subsequent calls to EvalVariable,
+	// ListLocalVariables, ListFunctionArgs, ListPackageVariables and
+	// Stacktrace.
+	SetOutputRadix(base int)
+
+	// ReadString returns the full value of a string or of a slice or array
+	// of bytes, regardless of the limits of the load configuration.
+	ReadString(scope api.EvalScope, expr string) (string, error)
+	// ReadBytes returns the full contents of a string or of a slice or array
+	// of bytes, regardless of the limits of the load configuration.
+	ReadBytes(scope api.EvalScope, expr string) ([]byte, error)
+	// SetReadLimit sets the maximum number of bytes read by ReadString and
+	// ReadBytes, zero means the default limit of the server.
+	SetReadLimit(limit int)
+
 	// IsMulticlien returns true if the headless instance is multiclient.
 	IsMulticlient() bool
 
-	// ListDynamicLibraries returns a list of loaded dynamic libraries.
+	// ListDynamicLibraries returns a list of loaded dynamic libraries,
+	// including plugins, with their load address and whether debug
+	// information was found for them.
 	ListDynamicLibraries() ([]api.Image, error)
 
+	// InlinedPackages returns the sorted list of packages whose functions
+	// were inlined into the body of the function fnName.
+	InlinedPackages(fnName string) ([]string, error)
+	// FunctionLineEntries returns the rows of the line number table of the
+	// function fnName, each with its PC, file, line and whether it is a
+	// statement boundary.
+	FunctionLineEntries(fnName string) ([]api.LineEntry, error)
+
+	// DebuggerStats returns the memory used by the debugger process, the
+	// size of the debug information of the target and the hit rates of the
+	// caches used to read it.
+	DebuggerStats() (*api.DebuggerStats, error)
+
+	// BuildInfo returns the Go version, module information and build
+	// settings embedded in the target binary.
+	BuildInfo() (*api.BuildInfo, error)
+
+	// ListPackages returns the import path, name and build directory of
+	// every package compiled into the target binary.
+	ListPackages() ([]api.PackageBuildInfo, error)
+
 	// ExamineMemory returns the raw memory stored at the given address.
 	// The amount of data to be read is specified by length which must be less than or equal to 1000.
 	// This function will return an error if it reads less than `length` bytes.
 	ExamineMemory(address uint64, length int) ([]byte, bool, error)
 
+	// ReadMemory reads count bytes of target memory starting at addr, count
+	// can be at most 1MiB. If only part of the range can be read the bytes
+	// up to the first unreadable address are returned along with an error.
+	ReadMemory(addr uint64, count int) ([]byte, error)
+	// WriteMemory writes data to the target memory starting at addr and
+	// returns the number of bytes written.
+	WriteMemory(addr uint64, data []byte) (int, error)
+	// ProcessMemoryMaps returns the memory map of the target process.
+	ProcessMemoryMaps() ([]api.MemoryMap, error)
+
 	// StopRecording stops a recording if one is in progress.
 	StopRecording() error
 
@@ -179,6 +419,9 @@ type Client interface {
 	CoreDumpWait(msec int) api.DumpState
 	// CoreDumpCancel cancels a core dump in progress
 	CoreDumpCancel() error
+	// DumpCore writes a core dump of the target to dest, waiting for it to
+	// finish.
+	DumpCore(dest string) error
 
 	// Disconnect closes the connection to the server without sending a Detach request first.
 	// If cont is true a continue command will be sent instead.
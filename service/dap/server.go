@@ -1634,7 +1634,7 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 	argScope := &fullyQualifiedVariable{&proc.Variable{Name: fmt.Sprintf("Arguments%s", suffix), Children: slicePtrVarToSliceVar(args)}, "", true, 0}
 
 	// Retrieve local variables
-	locals, err := s.debugger.LocalVariables(goid, frame, 0, DefaultLoadConfig)
+	locals, err := s.debugger.LocalVariables(goid, frame, 0, "", DefaultLoadConfig)
 	if err != nil {
 		s.sendErrorResponse(request.Request, UnableToListLocals, "Unable to list locals", err.Error())
 		return
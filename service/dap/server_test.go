@@ -952,7 +952,7 @@ func TestScopesAndVariablesRequests(t *testing.T) {
 					// reflect.Kind == Float64
 					checkVarExact(t, locals, -1, "a3", "a3", "7.23", "float64", noChildren)
 					// reflect.Kind == Complex64
-					ref = checkVarExact(t, locals, -1, "c64", "c64", "(1 + 2i)", "complex64", hasChildren)
+					ref = checkVarExact(t, locals, -1, "c64", "c64", "(1+2i)", "complex64", hasChildren)
 					if ref > 0 {
 						client.VariablesRequest(ref)
 						c64 := client.ExpectVariablesResponse(t)
@@ -961,7 +961,7 @@ func TestScopesAndVariablesRequests(t *testing.T) {
 						checkVarExact(t, c64, 1, "imaginary", "", "2", "float32", noChildren)
 					}
 					// reflect.Kind == Complex128
-					ref = checkVarExact(t, locals, -1, "c128", "c128", "(2 + 3i)", "complex128", hasChildren)
+					ref = checkVarExact(t, locals, -1, "c128", "c128", "(2+3i)", "complex128", hasChildren)
 					if ref > 0 {
 						client.VariablesRequest(ref)
 						c128 := client.ExpectVariablesResponse(t)
@@ -4339,9 +4339,9 @@ func TestSetVariable(t *testing.T) {
 					tester.evaluate("a13[0]", `*main.FooBar {Baz: 777, Bur: "f"}`, hasChildren)
 
 					// complex
-					tester.evaluate("c64", `(1 + 2i)`, hasChildren)
+					tester.evaluate("c64", `(1+2i)`, hasChildren)
 					tester.expectSetVariable(1001, "c64", "(2 + 3i)")
-					tester.evaluate("c64", `(2 + 3i)`, hasChildren)
+					tester.evaluate("c64", `(2+3i)`, hasChildren)
 					// note: complex's real, imaginary part can't be directly mutable.
 
 					//
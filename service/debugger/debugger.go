@@ -3,19 +3,28 @@ package debugger
 import (
 	"bytes"
 	"debug/dwarf"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go/ast"
+	"go/constant"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/gobuild"
@@ -45,6 +54,14 @@ var (
 
 	// ErrCoreDumpNotSupported is returned when core dumping is not supported
 	ErrCoreDumpNotSupported = errors.New("core dumping not supported")
+
+	// ErrCheckpointsNotSupported is returned by checkpoint operations when
+	// the target is not a recording.
+	ErrCheckpointsNotSupported = errors.New("checkpoints not supported by backend")
+
+	// ErrCheckpointsCoreFile is returned by checkpoint operations when the
+	// target is a core file.
+	ErrCheckpointsCoreFile = errors.New("checkpoints not supported on core files")
 )
 
 // Debugger service.
@@ -67,6 +84,9 @@ type Debugger struct {
 
 	running      bool
 	runningMutex sync.Mutex
+	// lastOperation describes the last command that resumed the target, it
+	// is protected by runningMutex.
+	lastOperation *api.OperationStats
 
 	stopRecording func() error
 	recordMutex   sync.Mutex
@@ -76,6 +96,26 @@ type Debugger struct {
 	// so lower layers like proc doesn't need to deal
 	// with them
 	disabledBreakpoints map[int]*api.Breakpoint
+	// evalAliases maps the names of the aliases defined with SetEvalAlias
+	// to their expressions.
+	evalAliases map[string]string
+	// goroutineDumps maps the ID of breakpoints with DumpGoroutinesOnce set
+	// to the dump recorded the first time they were hit. Dumps are deleted
+	// when their breakpoint is cleared with ClearBreakpoint and when the
+	// target is restarted.
+	goroutineDumps map[int]*api.GoroutineDump
+	// watches contains the expressions registered with AddWatch, sorted by
+	// ID, their Value and Err fields are not used.
+	watches     []api.Watch
+	lastWatchID int
+	// outputStreams follow stdout and stderr of the target when
+	// Config.StreamOutput is set.
+	outputStreams [2]*outputStream
+	// haltRequested is set by Halt, it is used to tell a manual stop
+	// requested by the user apart from one requested by watchOutput. It is
+	// cleared when the Halt command acquires targetMutex, after the command
+	// it interrupted has finished.
+	haltRequested int32
 }
 
 type ExecuteKind int
@@ -101,6 +141,21 @@ type Config struct {
 	// attach.
 	AttachPid int
 
+	// AttachWaitFor, if not empty, makes the debugger wait for a process
+	// matching it to appear and then attach to it, instead of using
+	// AttachPid. A process matches if the base name of its executable is
+	// AttachWaitFor or if the first arguments of its command line, joined
+	// by spaces, are AttachWaitFor.
+	// Only supported on linux.
+	AttachWaitFor string
+	// AttachWaitForInterval is the interval between two scans of the list
+	// of processes while waiting for AttachWaitFor, if it is zero
+	// defaultAttachWaitForInterval is used.
+	AttachWaitForInterval time.Duration
+	// AttachWaitForDuration is the maximum amount of time to wait for
+	// AttachWaitFor to appear, zero means no limit.
+	AttachWaitForDuration time.Duration
+
 	// CoreFile specifies the path to the core dump to open.
 	CoreFile string
 
@@ -135,8 +190,22 @@ type Config struct {
 	// Redirects specifies redirect rules for stdin, stdout and stderr
 	Redirects [3]string
 
+	// Env is applied on top of the environment of Delve to obtain the
+	// environment of the target process: entries of the form KEY=VALUE set
+	// KEY, entries of the form KEY remove it.
+	Env []string
+
 	// DisableASLR disables ASLR
 	DisableASLR bool
+
+	// StreamOutput captures stdout and stderr of the target, redirecting
+	// them to temporary files unless they are already redirected to a file,
+	// and reports what was written to them in the Stdout and Stderr fields
+	// of the states returned by Command.
+	// While the target is running, because of a Continue command, it is
+	// stopped as soon as it writes something so that the output can be
+	// reported, see api.DebuggerState.OutputInProgress.
+	StreamOutput bool
 }
 
 // New creates a new Debugger. ProcessArgs specify the commandline arguments for the
@@ -149,6 +218,15 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 		log:         logger,
 	}
 
+	if d.config.AttachWaitFor != "" {
+		d.log.Infof("waiting for a process matching %q", d.config.AttachWaitFor)
+		pid, err := d.waitForProcess()
+		if err != nil {
+			return nil, err
+		}
+		d.config.AttachPid = pid
+	}
+
 	// Create the process by either attaching or launching.
 	switch {
 	case d.config.AttachPid > 0:
@@ -206,10 +284,53 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 	}
 
 	d.disabledBreakpoints = make(map[int]*api.Breakpoint)
+	d.evalAliases = make(map[string]string)
+	d.goroutineDumps = make(map[int]*api.GoroutineDump)
 
 	return d, nil
 }
 
+// defaultAttachWaitForInterval is the default value of
+// Config.AttachWaitForInterval.
+const defaultAttachWaitForInterval = 10 * time.Millisecond
+
+// waitForProcess scans the list of processes until one matching
+// d.config.AttachWaitFor appears and returns its pid.
+func (d *Debugger) waitForProcess() (int, error) {
+	interval := d.config.AttachWaitForInterval
+	if interval <= 0 {
+		interval = defaultAttachWaitForInterval
+	}
+	var deadline time.Time
+	if d.config.AttachWaitForDuration > 0 {
+		deadline = time.Now().Add(d.config.AttachWaitForDuration)
+	}
+	for {
+		pid, err := findWaitForProcess(d.config.AttachWaitFor)
+		if err != nil || pid != 0 {
+			return pid, err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return 0, fmt.Errorf("no process matching %q appeared within %v", d.config.AttachWaitFor, d.config.AttachWaitForDuration)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// waitForMatches returns true if a process with command line cmdline
+// matches waitFor, see Config.AttachWaitFor.
+func waitForMatches(cmdline []string, waitFor string) bool {
+	if len(cmdline) == 0 {
+		return false
+	}
+	if filepath.Base(cmdline[0]) == waitFor {
+		return true
+	}
+	// the prefix must end at an argument boundary
+	s := strings.Join(cmdline, " ")
+	return s == waitFor || strings.HasPrefix(s, waitFor+" ")
+}
+
 // canRestart returns true if the target was started with Launch and can be restarted
 func (d *Debugger) canRestart() bool {
 	switch {
@@ -257,18 +378,23 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 		launchFlags |= proc.LaunchDisableASLR
 	}
 
+	redirects, err := d.launchRedirects()
+	if err != nil {
+		return nil, err
+	}
+
 	switch d.config.Backend {
 	case "native":
-		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects)
+		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, redirects, d.launchEnv())
 	case "lldb":
-		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects))
+		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, redirects, d.launchEnv()))
 	case "rr":
 		if d.target != nil {
 			// restart should not call us if the backend is 'rr'
 			panic("internal error: call to Launch with rr backend and target already exists")
 		}
 
-		run, stop, err := gdbserial.RecordAsync(processArgs, wd, false, d.config.Redirects)
+		run, stop, err := gdbserial.RecordAsync(processArgs, wd, false, redirects, d.launchEnv())
 		if err != nil {
 			return nil, err
 		}
@@ -303,9 +429,9 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 
 	case "default":
 		if runtime.GOOS == "darwin" {
-			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects))
+			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, redirects, d.launchEnv()))
 		}
-		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, d.config.Redirects)
+		return native.Launch(processArgs, wd, launchFlags, d.config.DebugInfoDirectories, d.config.TTY, redirects, d.launchEnv())
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
@@ -390,7 +516,10 @@ func (d *Debugger) LastModified() time.Time {
 func (d *Debugger) FunctionReturnLocations(fnName string) ([]uint64, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	return d.functionReturnLocations(fnName)
+}
 
+func (d *Debugger) functionReturnLocations(fnName string) ([]uint64, error) {
 	var (
 		p = d.target
 		g = p.SelectedGoroutine()
@@ -422,6 +551,63 @@ func (d *Debugger) FunctionReturnLocations(fnName string) ([]uint64, error) {
 	return addrs, nil
 }
 
+// InlinedPackages returns the sorted list of packages containing the
+// functions that were inlined into the body of fnName, including the
+// functions inlined into other inlined functions.
+func (d *Debugger) InlinedPackages(fnName string) ([]string, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bi := d.target.BinInfo()
+	fn, ok := bi.LookupFunc[fnName]
+	if !ok {
+		return nil, fmt.Errorf("unable to find function %s", fnName)
+	}
+	if fn.Entry == 0 {
+		return nil, fmt.Errorf("function %s has no body, all its calls were inlined", fnName)
+	}
+
+	seen := make(map[string]bool)
+	pkgs := []string{}
+	for i := range bi.Functions {
+		inlfn := &bi.Functions[i]
+		for _, call := range inlfn.InlinedCalls {
+			if call.LowPC < fn.Entry || call.LowPC >= fn.End {
+				continue
+			}
+			if pkg := inlfn.PackageName(); !seen[pkg] {
+				seen[pkg] = true
+				pkgs = append(pkgs, pkg)
+			}
+			break
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs, nil
+}
+
+// FunctionLineEntries returns the rows of the line number table of the
+// function fnName, sorted by address.
+func (d *Debugger) FunctionLineEntries(fnName string) ([]api.LineEntry, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	fn, ok := d.target.BinInfo().LookupFunc[fnName]
+	if !ok {
+		return nil, fmt.Errorf("unable to find function %s", fnName)
+	}
+	if fn.Entry == 0 {
+		return nil, fmt.Errorf("function %s has no body, all its calls were inlined", fnName)
+	}
+
+	rows := fn.LineTable()
+	entries := make([]api.LineEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, api.LineEntry{PC: row.Address, File: row.File, Line: row.Line, IsStmt: row.IsStmt})
+	}
+	return entries, nil
+}
+
 // Detach detaches from the target process.
 // If `kill` is true we will kill the process after
 // detaching.
@@ -429,12 +615,93 @@ func (d *Debugger) Detach(kill bool) error {
 	d.log.Debug("detaching")
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	defer d.closeOutputStreams()
 	if ok, _ := d.target.Valid(); !ok {
 		return nil
 	}
 	return d.detach(kill)
 }
 
+// DetachWithSignal detaches from the target process, leaving it running,
+// and then sends it signal sig.
+// Only processes the debugger attached to can be signaled, processes
+// launched by the debugger are always killed when detaching.
+func (d *Debugger) DetachWithSignal(sig int) error {
+	d.log.Debugf("detaching with signal %d", sig)
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	if sig <= 0 {
+		return fmt.Errorf("invalid signal %d", sig)
+	}
+	if _, err := d.target.Valid(); err != nil {
+		return err
+	}
+	if d.config.AttachPid == 0 {
+		return errors.New("can not send a signal to a process that was not attached to")
+	}
+	pid := d.target.Pid()
+	// signal 0 only checks that signals can be sent to the target, so that we
+	// don't detach from it if we can't.
+	if err := signalProcess(pid, 0); err != nil {
+		return err
+	}
+	if err := d.detach(false); err != nil {
+		return err
+	}
+	return signalProcess(pid, sig)
+}
+
+// launchEnv returns the environment used to launch the target process, nil
+// if the target inherits the environment of Delve.
+func (d *Debugger) launchEnv() []string {
+	if len(d.config.Env) == 0 {
+		return nil
+	}
+	return applyEnvOverlay(os.Environ(), d.config.Env)
+}
+
+// mergeEnvOverlay returns an overlay equivalent to applying overlay1 and
+// then overlay2, see Config.Env.
+func mergeEnvOverlay(overlay1, overlay2 []string) []string {
+	r := append([]string(nil), overlay1...)
+	for _, kv := range overlay2 {
+		r = removeEnv(r, envKey(kv))
+		r = append(r, kv)
+	}
+	return r
+}
+
+// applyEnvOverlay returns a copy of env with the entries of overlay
+// applied to it, see Config.Env.
+func applyEnvOverlay(env, overlay []string) []string {
+	r := append([]string(nil), env...)
+	for _, kv := range overlay {
+		r = removeEnv(r, envKey(kv))
+		if strings.Contains(kv, "=") {
+			r = append(r, kv)
+		}
+	}
+	return r
+}
+
+// removeEnv removes all entries for key from env.
+func removeEnv(env []string, key string) []string {
+	r := env[:0]
+	for _, kv := range env {
+		if envKey(kv) != key {
+			r = append(r, kv)
+		}
+	}
+	return r
+}
+
+func envKey(kv string) string {
+	if i := strings.Index(kv, "="); i >= 0 {
+		return kv[:i]
+	}
+	return kv
+}
+
 func (d *Debugger) detach(kill bool) error {
 	if d.config.AttachPid == 0 {
 		kill = true
@@ -447,13 +714,39 @@ func (d *Debugger) detach(kill bool) error {
 // If the target process is a recording it will restart it from the given
 // position. If pos starts with 'c' it's a checkpoint ID, otherwise it's an
 // event number. If resetArgs is true, newArgs will replace the process args.
-func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []string, newRedirects [3]string, rebuild bool) ([]api.DiscardedBreakpoint, error) {
+// The entries of newEnv are applied on top of the environment of the
+// previous launch, see Config.Env. The environment the target was launched
+// with is returned, nil if it inherited the environment of Delve.
+func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []string, newRedirects [3]string, newEnv []string, rebuild bool) ([]api.DiscardedBreakpoint, []string, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	discarded, err := d.restart(rerecord, pos, resetArgs, newArgs, newRedirects, newEnv, rebuild)
+	return discarded, d.launchEnv(), err
+}
+
+func (d *Debugger) restart(rerecord bool, pos string, resetArgs bool, newArgs []string, newRedirects [3]string, newEnv []string, rebuild bool) ([]api.DiscardedBreakpoint, error) {
+	for _, kv := range newEnv {
+		if kv == "" || kv[0] == '=' {
+			return nil, fmt.Errorf("invalid environment entry %q", kv)
+		}
+	}
+
 	recorded, _ := d.target.Recorded()
+	if recorded && !rerecord && len(newEnv) > 0 {
+		return nil, errors.New("can not change the environment of a recording without rerecording it")
+	}
 	if recorded && !rerecord {
-		return nil, d.target.Restart(pos)
+		d.startOperation("restart")
+		defer d.endOperation()
+		if err := d.target.Restart(pos); err != nil {
+			return nil, err
+		}
+		for _, bp := range d.target.Breakpoints().M {
+			bp.ResetFirstPerGoroutine()
+			bp.ResetLogicalState()
+		}
+		return d.discardTemporaryBreakpoints()
 	}
 
 	if pos != "" {
@@ -477,6 +770,7 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		d.processArgs = append([]string{d.processArgs[0]}, newArgs...)
 		d.config.Redirects = newRedirects
 	}
+	d.config.Env = mergeEnvOverlay(d.config.Env, newEnv)
 	var p *proc.Target
 	var err error
 
@@ -499,7 +793,11 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 	}
 
 	if recorded {
-		run, stop, err2 := gdbserial.RecordAsync(d.processArgs, d.config.WorkingDir, false, d.config.Redirects)
+		redirects, err2 := d.launchRedirects()
+		if err2 != nil {
+			return nil, err2
+		}
+		run, stop, err2 := gdbserial.RecordAsync(d.processArgs, d.config.WorkingDir, false, redirects, d.launchEnv())
 		if err2 != nil {
 			return nil, err2
 		}
@@ -516,7 +814,13 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 
 	discarded := []api.DiscardedBreakpoint{}
 	breakpoints := api.ConvertBreakpoints(d.breakpoints())
+	if sigs := d.target.StopSignals(); len(sigs) > 0 {
+		if err := p.SetStopSignals(sigs); err != nil {
+			return nil, err
+		}
+	}
 	d.target = p
+	d.goroutineDumps = make(map[int]*api.GoroutineDump)
 	maxID := 0
 	for _, oldBp := range breakpoints {
 		if oldBp.ID < 0 {
@@ -525,8 +829,20 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		if oldBp.ID > maxID {
 			maxID = oldBp.ID
 		}
-		if oldBp.WatchExpr != "" {
+		if oldBp.Temporary {
+			discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: "temporary breakpoints are not recreated on restart"})
+		} else if oldBp.WatchExpr != "" {
 			discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: "can not recreate watchpoints on restart"})
+		} else if oldBp.OnReturn {
+			addrs, err := d.functionReturnLocations(oldBp.FunctionName)
+			if err == nil && len(addrs) == 0 {
+				err = fmt.Errorf("function %s has no return locations", oldBp.FunctionName)
+			}
+			if err != nil {
+				discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: err.Error()})
+				continue
+			}
+			createLogicalBreakpoint(d, addrs, oldBp, oldBp.ID)
 		} else if len(oldBp.File) > 0 {
 			addrs, err := proc.FindFileLocation(p, oldBp.File, oldBp.Line)
 			if err != nil {
@@ -553,6 +869,10 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		if bp.ID > maxID {
 			maxID = bp.ID
 		}
+		if bp.Temporary {
+			delete(d.disabledBreakpoints, bp.ID)
+			discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: bp, Reason: "temporary breakpoints are not recreated on restart"})
+		}
 	}
 	d.target.SetNextBreakpointID(maxID)
 	return discarded, nil
@@ -601,6 +921,16 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 	state = &api.DebuggerState{
 		SelectedGoroutine: goroutine,
 		Exited:            exited,
+		StopReason:        api.ConvertStopReason(d.target.StopReason),
+		FatalSignal:       d.target.FatalSignal,
+		ParkedGoroutines:  d.target.ParkedGoroutines(),
+	}
+
+	if d.target.StopReason == proc.StopSignal {
+		tid := d.target.CurrentThread().ThreadID()
+		if sig, name := d.target.PendingSignal(tid); sig != 0 {
+			state.Signal = &api.Signal{ThreadID: tid, Number: sig, Name: name}
+		}
 	}
 
 	for _, thread := range d.target.ThreadList() {
@@ -618,6 +948,7 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 	}
 
 	state.NextInProgress = d.target.Breakpoints().HasInternalBreakpoints()
+	state.Watches = d.evalWatches()
 
 	if recorded, _ := d.target.Recorded(); recorded {
 		state.When, _ = d.target.When()
@@ -650,6 +981,11 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 //
 // - Otherwise the value specified by arg.Breakpoint.Addr will be used.
 //
+// If the location specified by the fields above can not be resolved (or no
+// location was specified at all) each location expression in
+// requestedBp.FallbackLocations is tried in order and the first one that
+// resolves is used.
+//
 // Note that this method will use the first successful method in order to
 // create a breakpoint, so mixing different fields will not result is multiple
 // breakpoints being set.
@@ -671,6 +1007,11 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 	switch {
 	case requestedBp.TraceReturn:
 		addrs = []uint64{requestedBp.Addr}
+	case len(requestedBp.FunctionName) > 0 && requestedBp.OnReturn:
+		addrs, err = d.functionReturnLocations(requestedBp.FunctionName)
+		if err == nil && len(addrs) == 0 {
+			err = fmt.Errorf("function %s has no return locations", requestedBp.FunctionName)
+		}
 	case len(requestedBp.File) > 0:
 		fileName := requestedBp.File
 		if runtime.GOOS == "windows" {
@@ -688,10 +1029,22 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 		addrs, err = proc.FindFunctionLocation(d.target, requestedBp.FunctionName, requestedBp.Line)
 	case len(requestedBp.Addrs) > 0:
 		addrs = requestedBp.Addrs
+	case requestedBp.Addr == 0 && len(requestedBp.FallbackLocations) > 0:
+		err = errors.New("no location specified")
 	default:
 		addrs = []uint64{requestedBp.Addr}
 	}
 
+	if err != nil && len(requestedBp.FallbackLocations) > 0 {
+		var fallbackErr error
+		addrs, fallbackErr = d.findFallbackLocation(requestedBp.FallbackLocations)
+		if fallbackErr == nil {
+			err = nil
+		} else {
+			err = fmt.Errorf("%v (%v)", err, fallbackErr)
+		}
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -704,6 +1057,115 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 	return createdBp, nil
 }
 
+// CreateBreakpointsInPackage sets a tracepoint on the entry point of every
+// function of package pkg and, unless entryOnly is set, a tracepoint on all
+// their return locations. Functions generated by the compiler and functions
+// that already have a breakpoint on their entry point are skipped.
+// Either all the tracepoints are created or none of them is.
+func (d *Debugger) CreateBreakpointsInPackage(pkg string, entryOnly bool) ([]*api.Breakpoint, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if pkg == "" {
+		return nil, errors.New("no package specified")
+	}
+
+	var fns []string
+	seen := make(map[string]bool)
+	for _, fn := range d.target.BinInfo().Functions {
+		if fn.PackageName() != pkg || seen[fn.Name] || fn.Wrapper() {
+			continue
+		}
+		seen[fn.Name] = true
+		fns = append(fns, fn.Name)
+	}
+	if len(fns) == 0 {
+		return nil, fmt.Errorf("no functions found in package %s", pkg)
+	}
+	sort.Strings(fns)
+
+	var created []*api.Breakpoint
+	rollback := func(err error) error {
+		for _, bp := range created {
+			if _, err1 := d.clearBreakpoint(bp); err1 != nil {
+				return fmt.Errorf("error while creating breakpoints: %v, additionally breakpoint %d could not be rolled back: %v", err, bp.ID, err1)
+			}
+		}
+		return err
+	}
+
+	for _, fnName := range fns {
+		addrs, err := proc.FindFunctionLocation(d.target, fnName, 0)
+		if err != nil {
+			if _, notFound := err.(*proc.ErrFunctionNotFound); notFound {
+				// the function was optimized away
+				continue
+			}
+			return nil, rollback(err)
+		}
+		bp, err := createLogicalBreakpoint(d, addrs, &api.Breakpoint{FunctionName: fnName, Tracepoint: true}, 0)
+		if err != nil {
+			if isBreakpointExistsErr(err) {
+				continue
+			}
+			return nil, rollback(err)
+		}
+		created = append(created, bp)
+
+		if entryOnly {
+			continue
+		}
+		addrs, err = d.functionReturnLocations(fnName)
+		if err != nil {
+			return nil, rollback(err)
+		}
+		if len(addrs) == 0 {
+			continue
+		}
+		bp, err = createLogicalBreakpoint(d, addrs, &api.Breakpoint{FunctionName: fnName, Tracepoint: true, OnReturn: true}, 0)
+		if err != nil {
+			if isBreakpointExistsErr(err) {
+				continue
+			}
+			return nil, rollback(err)
+		}
+		created = append(created, bp)
+	}
+	d.log.Infof("created %d breakpoints in package %s", len(created), pkg)
+	return created, nil
+}
+
+// findFallbackLocation returns the addresses of the first location
+// expression in locs that resolves to at least one address. If the
+// expression resolves to more than one location the addresses of all of
+// them are returned.
+func (d *Debugger) findFallbackLocation(locs []string) ([]uint64, error) {
+	for _, locStr := range locs {
+		loc, err := locspec.Parse(locStr)
+		if err != nil {
+			d.log.Debugf("fallback location %q: %v", locStr, err)
+			continue
+		}
+		found, err := d.findLocation(-1, 0, 0, locStr, loc, false, nil)
+		if err != nil || len(found) == 0 {
+			d.log.Debugf("fallback location %q could not be resolved: %v", locStr, err)
+			continue
+		}
+		var addrs []uint64
+		for _, l := range found {
+			if len(l.PCs) > 0 {
+				addrs = append(addrs, l.PCs...)
+			} else if l.PC != 0 {
+				addrs = append(addrs, l.PC)
+			}
+		}
+		if len(addrs) > 0 {
+			return addrs, nil
+		}
+	}
+	return nil, errors.New("none of the fallback locations could be resolved")
+}
+
 // createLogicalBreakpoint creates one physical breakpoint for each address
 // in addrs and associates all of them with the same logical breakpoint.
 func createLogicalBreakpoint(d *Debugger, addrs []uint64, requestedBp *api.Breakpoint, id int) (*api.Breakpoint, error) {
@@ -716,21 +1178,22 @@ func createLogicalBreakpoint(d *Debugger, addrs []uint64, requestedBp *api.Break
 	bps := make([]*proc.Breakpoint, len(addrs))
 	var err error
 	for i := range addrs {
-		if id > 0 {
+		switch {
+		case id > 0:
 			bps[i], err = p.SetBreakpointWithID(id, addrs[i])
-		} else {
+		case i > 0:
+			bps[i], err = p.SetBreakpointWithID(bps[0].LogicalID, addrs[i])
+		default:
 			bps[i], err = p.SetBreakpoint(addrs[i], proc.UserBreakpoint, nil)
 		}
 		if err != nil {
 			break
 		}
-		if i > 0 {
-			bps[i].LogicalID = bps[0].LogicalID
-		}
 		err = copyBreakpointInfo(bps[i], requestedBp)
 		if err != nil {
 			break
 		}
+		bps[i].OnReturn = requestedBp.OnReturn
 	}
 	if err != nil {
 		if isBreakpointExistsErr(err) {
@@ -830,9 +1293,173 @@ func copyBreakpointInfo(bp *proc.Breakpoint, requested *api.Breakpoint) (err err
 			}{opTok, val}
 		}
 	}
+	if requested.SampleRate < 0 && err == nil {
+		err = fmt.Errorf("invalid sample rate %d", requested.SampleRate)
+	}
+	bp.SampleRate = requested.SampleRate
+	if requested.MinHitInterval < 0 && err == nil {
+		err = fmt.Errorf("invalid minimum hit interval %v", requested.MinHitInterval)
+	}
+	bp.MinHitInterval = requested.MinHitInterval
+	bp.FirstPerGoroutine = requested.FirstPerGoroutine
+	bp.Temporary = requested.Temporary
+	bp.Assert = nil
+	if requested.Assert != "" {
+		assert, parseErr := parser.ParseExpr(requested.Assert)
+		if err == nil {
+			err = parseErr
+		}
+		bp.Assert = assert
+	}
+	bp.DumpGoroutinesOnce = requested.DumpGoroutinesOnce
+	bp.UserData = requested.UserData
+	bp.TraceMessage = requested.TraceMessage
+	if requested.TraceMessage != "" {
+		if _, _, parseErr := parseTraceMessage(requested.TraceMessage); err == nil {
+			err = parseErr
+		}
+	}
 	return err
 }
 
+// goroutineDumpStacktraceDepth is the depth of the stacktraces recorded
+// by breakpoints with DumpGoroutinesOnce set.
+const goroutineDumpStacktraceDepth = 50
+
+// dumpGoroutines records the stacktraces of all goroutines for the
+// breakpoint with the specified ID, hit by thread.
+func (d *Debugger) dumpGoroutines(id int, thread proc.Thread) (*api.GoroutineDump, error) {
+	gs, _, err := proc.GoroutinesInfo(d.target, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	dump := &api.GoroutineDump{BreakpointID: id, Goroutines: make([]api.GoroutineStacktrace, len(gs))}
+	if g, _ := proc.GetG(thread); g != nil {
+		dump.GoroutineID = g.ID
+	}
+	for i, g := range gs {
+		dump.Goroutines[i].Goroutine = api.ConvertGoroutine(d.target, g)
+		rawlocs, err := g.Stacktrace(goroutineDumpStacktraceDepth, 0)
+		if err == nil {
+			dump.Goroutines[i].Stacktrace, err = d.convertStacktrace(rawlocs, nil)
+		}
+		if err != nil {
+			dump.Goroutines[i].Err = err.Error()
+		}
+	}
+	return dump, nil
+}
+
+// GoroutineStacktraceDiff takes a snapshot of all goroutines and their
+// stacktraces and compares it with prev, a snapshot taken earlier.
+func (d *Debugger) GoroutineStacktraceDiff(prev []api.GoroutineStacktrace) (*api.GoroutineDiff, error) {
+	for i := range prev {
+		if prev[i].Goroutine == nil {
+			return nil, fmt.Errorf("entry %d of the previous snapshot has no goroutine", i)
+		}
+	}
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	dump, err := d.dumpGoroutines(0, d.target.CurrentThread())
+	if err != nil {
+		return nil, err
+	}
+	return api.DiffGoroutineStacktraces(prev, dump.Goroutines), nil
+}
+
+// GoroutineDump returns the dump of all goroutines recorded the first time
+// the breakpoint with the specified ID, which must have DumpGoroutinesOnce
+// set, was hit.
+func (d *Debugger) GoroutineDump(id int) (*api.GoroutineDump, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	dump := d.goroutineDumps[id]
+	if dump == nil {
+		return nil, fmt.Errorf("no goroutine dump recorded for breakpoint %d", id)
+	}
+	return dump, nil
+}
+
+// parseTraceMessage parses the TraceMessage of a breakpoint, a format
+// string followed by a comma separated list of expressions, and returns the
+// format string and the expressions.
+func parseTraceMessage(msg string) (string, []string, error) {
+	src := "f(" + msg + ")"
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not parse trace message: %v", err)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return "", nil, errors.New("trace message must be a format string followed by a list of expressions")
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", nil, errors.New("trace message must start with a format string")
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not parse trace message: %v", err)
+	}
+	// ParseExpr positions start at 1
+	args := make([]string, 0, len(call.Args)-1)
+	for _, arg := range call.Args[1:] {
+		args = append(args, src[arg.Pos()-1:arg.End()-1])
+	}
+	return format, args, nil
+}
+
+// formatTraceMessage formats the trace message msg evaluating its operands
+// in scope s.
+func formatTraceMessage(s *proc.EvalScope, msg string) string {
+	format, args, err := parseTraceMessage(msg)
+	if err != nil {
+		return err.Error()
+	}
+	vals := make([]interface{}, len(args))
+	for i := range args {
+		v, err := s.EvalVariable(args[i], proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1})
+		if err != nil {
+			vals[i] = fmt.Sprintf("<eval error: %v>", err)
+			continue
+		}
+		vals[i] = traceMessageOperand(v)
+	}
+	return fmt.Sprintf(format, vals...)
+}
+
+// traceMessageOperand converts v to a value that can be passed to
+// fmt.Sprintf: booleans, numbers and strings are converted to the
+// corresponding Go values, everything else to its single line
+// representation.
+func traceMessageOperand(v *proc.Variable) interface{} {
+	if v.Unreadable != nil {
+		return fmt.Sprintf("<unreadable: %v>", v.Unreadable)
+	}
+	if v.Value != nil {
+		switch v.Value.Kind() {
+		case constant.Bool:
+			return constant.BoolVal(v.Value)
+		case constant.String:
+			return constant.StringVal(v.Value)
+		case constant.Int:
+			if n, exact := constant.Int64Val(v.Value); exact {
+				return n
+			}
+			if n, exact := constant.Uint64Val(v.Value); exact {
+				return n
+			}
+		case constant.Float:
+			f, _ := constant.Float64Val(v.Value)
+			return f
+		}
+	}
+	return api.ConvertVar(v).SinglelineString()
+}
+
 func parseHitCondition(hitCond string) (token.Token, int, error) {
 	// A hit condition can be in the following formats:
 	// - "number"
@@ -878,7 +1505,11 @@ func parseHitCondition(hitCond string) (token.Token, int, error) {
 func (d *Debugger) ClearBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoint, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
-	return d.clearBreakpoint(requestedBp)
+	bp, err := d.clearBreakpoint(requestedBp)
+	if err == nil {
+		delete(d.goroutineDumps, requestedBp.ID)
+	}
+	return bp, err
 }
 
 // clearBreakpoint clears a breakpoint, we can consume this function to avoid locking a goroutine
@@ -949,30 +1580,93 @@ func (d *Debugger) Breakpoints() []*api.Breakpoint {
 	return bps
 }
 
-func (d *Debugger) breakpoints() []*proc.Breakpoint {
-	bps := []*proc.Breakpoint{}
-	for _, bp := range d.target.Breakpoints().M {
-		if bp.IsUser() {
-			bps = append(bps, bp)
+// BreakpointHitStats returns the hit counters of all enabled breakpoints,
+// aggregated over all the addresses of each breakpoint.
+func (d *Debugger) BreakpointHitStats() []api.BreakpointStat {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	r := []api.BreakpointStat{}
+	var goroutines map[int]bool
+	for _, bp := range d.breakpoints() {
+		if len(r) == 0 || r[len(r)-1].ID != bp.LogicalID {
+			r = append(r, api.BreakpointStat{ID: bp.LogicalID, Name: bp.Name})
+			goroutines = map[int]bool{}
+		}
+		stat := &r[len(r)-1]
+		stat.TotalHits += bp.TotalHitCount
+		for gid := range bp.HitCount {
+			goroutines[gid] = true
+		}
+		stat.DistinctGoroutines = len(goroutines)
+		if bp.LastHitTime.After(stat.LastHitTime) {
+			stat.LastHitTime = bp.LastHitTime
 		}
 	}
-	sort.Sort(breakpointsByLogicalID(bps))
-	return bps
+	return r
 }
 
-// FindBreakpoint returns the breakpoint specified by 'id'.
-func (d *Debugger) FindBreakpoint(id int) *api.Breakpoint {
+// BreakpointsSharingAddress returns, for each address that is used by more
+// than one logical breakpoint, the sorted list of IDs of those breakpoints.
+// Disabled breakpoints are included, since re-enabling them will fail if
+// another breakpoint already occupies the same address.
+func (d *Debugger) BreakpointsSharingAddress() map[uint64][]int {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
-	bps := api.ConvertBreakpoints(d.findBreakpoint(id))
-	bps = append(bps, d.findDisabledBreakpoint(id)...)
-	if len(bps) <= 0 {
-		return nil
-	}
-	return bps[0]
-}
 
-func (d *Debugger) findBreakpoint(id int) []*proc.Breakpoint {
+	ids := make(map[uint64]map[int]struct{})
+	add := func(addr uint64, id int) {
+		if ids[addr] == nil {
+			ids[addr] = make(map[int]struct{})
+		}
+		ids[addr][id] = struct{}{}
+	}
+	for _, bp := range d.breakpoints() {
+		add(bp.Addr, bp.LogicalID)
+	}
+	for _, bp := range d.disabledBreakpoints {
+		for _, addr := range bp.Addrs {
+			add(addr, bp.ID)
+		}
+	}
+
+	r := make(map[uint64][]int)
+	for addr, m := range ids {
+		if len(m) <= 1 {
+			continue
+		}
+		for id := range m {
+			r[addr] = append(r[addr], id)
+		}
+		sort.Ints(r[addr])
+	}
+	return r
+}
+
+func (d *Debugger) breakpoints() []*proc.Breakpoint {
+	bps := []*proc.Breakpoint{}
+	for _, bp := range d.target.Breakpoints().M {
+		if bp.IsUser() {
+			bps = append(bps, bp)
+		}
+	}
+	sort.Sort(breakpointsByLogicalID(bps))
+	return bps
+}
+
+// FindBreakpoint returns the breakpoint specified by 'id'.
+func (d *Debugger) FindBreakpoint(id int) *api.Breakpoint {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	bps := api.ConvertBreakpoints(d.findBreakpoint(id))
+	bps = append(bps, d.findDisabledBreakpoint(id)...)
+	if len(bps) <= 0 {
+		return nil
+	}
+	return bps[0]
+}
+
+func (d *Debugger) findBreakpoint(id int) []*proc.Breakpoint {
 	var bps []*proc.Breakpoint
 	for _, bp := range d.target.Breakpoints().M {
 		if bp.IsUser() && bp.LogicalID == id {
@@ -1056,6 +1750,52 @@ func (d *Debugger) Threads() ([]proc.Thread, error) {
 	return d.target.ThreadList(), nil
 }
 
+// ThreadsWithFilter returns the threads matching filter.
+func (d *Debugger) ThreadsWithFilter(filter api.ThreadFilter) ([]proc.Thread, error) {
+	switch filter.State {
+	case "", api.ThreadRunning, api.ThreadSyscall, api.ThreadBlocked, api.ThreadIdle:
+	default:
+		return nil, fmt.Errorf("unknown thread state %q", filter.State)
+	}
+
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	r := []proc.Thread{}
+	for _, th := range d.target.ThreadList() {
+		if filter.State != "" {
+			g, _ := proc.GetG(th)
+			if api.ConvertThreadState(th, g) != filter.State {
+				continue
+			}
+		}
+		if filter.InScheduler && !threadInScheduler(th) {
+			continue
+		}
+		r = append(r, th)
+	}
+	return r, nil
+}
+
+// schedulerStacktraceDepth is the depth of the stacktrace searched for
+// runtime.schedule by threadInScheduler.
+const schedulerStacktraceDepth = 20
+
+// threadInScheduler returns true if th is executing runtime.schedule.
+func threadInScheduler(th proc.Thread) bool {
+	frames, _ := proc.ThreadStacktrace(th, schedulerStacktraceDepth)
+	for _, frame := range frames {
+		if frame.Call.Fn != nil && frame.Call.Fn.Name == "runtime.schedule" {
+			return true
+		}
+	}
+	return false
+}
+
 // FindThread returns the thread for the given 'id'.
 func (d *Debugger) FindThread(id int) (proc.Thread, error) {
 	d.targetMutex.Lock()
@@ -1081,6 +1821,113 @@ func (d *Debugger) FindGoroutine(id int) (*proc.G, error) {
 	return proc.FindGoroutine(d.target, id)
 }
 
+// ReferencesTo returns up to maxResults words of memory, found on the
+// stacks of goroutines, that are equal to addr.
+func (d *Debugger) ReferencesTo(addr uint64, maxResults int) ([]api.Reference, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	refs, err := proc.FindReferences(d.target, addr, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]api.Reference, len(refs))
+	for i, ref := range refs {
+		r[i] = api.Reference{
+			GoroutineID: ref.Goroutine.ID,
+			Addr:        ref.Addr,
+			Frame:       ref.Frame,
+			Location:    api.ConvertLocation(ref.Location),
+			Offset:      ref.Offset,
+		}
+		if ref.Variable != nil {
+			r[i].Variable = ref.Variable.Name
+		}
+	}
+	return r, nil
+}
+
+// BreakOnNextPanic arms goroutine gid, or the selected goroutine if gid is
+// negative, so that the target stops the next time it panics, see
+// proc.(*Target).BreakOnNextPanic.
+func (d *Debugger) BreakOnNextPanic(gid int) ([]int, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	if gid < 0 {
+		g := d.target.SelectedGoroutine()
+		if g == nil {
+			return nil, errors.New("no selected goroutine")
+		}
+		gid = g.ID
+	}
+	return d.target.BreakOnNextPanic(gid)
+}
+
+// ParkGoroutine prevents goroutine gid from running when the target is
+// continued, see proc.(*Target).ParkGoroutine.
+func (d *Debugger) ParkGoroutine(gid int) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return d.target.ParkGoroutine(gid)
+}
+
+// SetStopSignals sets the signals that stop the target when they are
+// received by one of its threads, see proc.(*Target).SetStopSignals.
+func (d *Debugger) SetStopSignals(sigs []int) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return d.target.SetStopSignals(sigs)
+}
+
+// UnparkGoroutine lets goroutine gid, previously parked, run again.
+func (d *Debugger) UnparkGoroutine(gid int) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return d.target.UnparkGoroutine(gid)
+}
+
+// SetGoroutineLabels attaches labels to goroutine gid in the debugger's
+// view of the target, see proc.(*Target).SetGoroutineLabels.
+func (d *Debugger) SetGoroutineLabels(gid int, labels map[string]string) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	return d.target.SetGoroutineLabels(gid, labels)
+}
+
+// stepInstructions executes count single instruction steps in the current
+// direction of execution, stopping early if a breakpoint is reached or a
+// manual stop is requested.
+func (d *Debugger) stepInstructions(count int) error {
+	if count < 1 {
+		count = 1
+	}
+	d.target.CheckAndClearManualStopRequest()
+	for i := 0; i < count; i++ {
+		if err := d.target.StepInstruction(); err != nil {
+			return err
+		}
+		if d.target.CheckAndClearManualStopRequest() {
+			return nil
+		}
+		if bp := d.target.CurrentThread().Breakpoint(); bp.Breakpoint != nil && bp.Active && !bp.Internal {
+			return nil
+		}
+	}
+	return nil
+}
+
 func (d *Debugger) setRunning(running bool) {
 	d.runningMutex.Lock()
 	d.running = running
@@ -1093,6 +1940,59 @@ func (d *Debugger) IsRunning() bool {
 	return d.running
 }
 
+// startOperation records the start of the command name, which resumes the
+// target.
+func (d *Debugger) startOperation(name string) {
+	op := &api.OperationStats{Command: name, Start: time.Now(), Running: true, StartEvent: d.recordedEvent()}
+	d.runningMutex.Lock()
+	d.lastOperation = op
+	d.runningMutex.Unlock()
+}
+
+// endOperation records the end of the command started by startOperation.
+func (d *Debugger) endOperation() {
+	end := d.recordedEvent()
+	d.runningMutex.Lock()
+	defer d.runningMutex.Unlock()
+	op := d.lastOperation
+	op.Running = false
+	op.Duration = time.Since(op.Start)
+	op.EndEvent = end
+	if op.StartEvent > 0 && op.EndEvent > 0 {
+		op.EventsTraversed = op.EndEvent - op.StartEvent
+		if op.EventsTraversed < 0 {
+			op.EventsTraversed = -op.EventsTraversed
+		}
+	}
+}
+
+// recordedEvent returns the current rr event number if the target is a
+// recording, 0 otherwise.
+func (d *Debugger) recordedEvent() int64 {
+	if recorded, _ := d.target.Recorded(); !recorded {
+		return 0
+	}
+	ev, _ := d.currentEvent()
+	return ev
+}
+
+// LastOperationStats returns a description of the last command that
+// resumed the target, or of the currently running one, or nil if the
+// target was never resumed.
+// Can be called while the target is running.
+func (d *Debugger) LastOperationStats() *api.OperationStats {
+	d.runningMutex.Lock()
+	defer d.runningMutex.Unlock()
+	if d.lastOperation == nil {
+		return nil
+	}
+	op := *d.lastOperation
+	if op.Running {
+		op.Duration = time.Since(op.Start)
+	}
+	return &op
+}
+
 // Command handles commands which control the debugger lifecycle
 func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struct{}) (*api.DebuggerState, error) {
 	var err error
@@ -1104,6 +2004,7 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 
 		d.recordMutex.Lock()
 		if d.stopRecording == nil {
+			atomic.StoreInt32(&d.haltRequested, 1)
 			err = d.target.RequestManualStop()
 		}
 		d.recordMutex.Unlock()
@@ -1114,25 +2015,42 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	if command.Name == api.Halt {
+		// The command that was running when Halt was called, if any, has
+		// finished and observed the stop.
+		atomic.StoreInt32(&d.haltRequested, 0)
+	}
+
 	d.setRunning(true)
 	defer d.setRunning(false)
 
 	if command.Name != api.SwitchGoroutine && command.Name != api.SwitchThread && command.Name != api.Halt {
+		d.startOperation(command.Name)
+		defer d.endOperation()
 		d.target.ResumeNotify(resumeNotify)
 	} else if resumeNotify != nil {
 		close(resumeNotify)
 	}
 
+	var stackBefore *stackSnapshot
+	if isReverseCommand(command.Name) || (command.Name == api.DirectionCongruentContinue && d.target.GetDirection() == proc.Backward) {
+		stackBefore = d.selectedGoroutineStack()
+	}
+
+	var reachedFunction string
+	stoppedForOutput := false
 	switch command.Name {
 	case api.Continue:
 		d.log.Debug("continuing")
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
 			return nil, err
 		}
-		err = d.target.Continue()
+		stopWatching := d.watchOutput()
+		err = d.continueTarget(command.SkipTracepoints)
+		stoppedForOutput = stopWatching()
 	case api.DirectionCongruentContinue:
 		d.log.Debug("continuing (direction congruent)")
-		err = d.target.Continue()
+		err = d.continueTarget(command.SkipTracepoints)
 	case api.Call:
 		d.log.Debugf("function call %s", command.Expr)
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
@@ -1148,7 +2066,23 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 				return nil, err
 			}
 		}
+		var timer *time.Timer
+		var timedOut int32
+		if command.CallTimeout > 0 {
+			timer = time.AfterFunc(command.CallTimeout, func() {
+				atomic.StoreInt32(&timedOut, 1)
+				// RequestManualStop is safe to call while the target is running,
+				// see Halt.
+				d.target.RequestManualStop()
+			})
+		}
 		err = proc.EvalExpressionWithCalls(d.target, g, command.Expr, *api.LoadConfigToProc(command.ReturnInfoLoadConfig), !command.UnsafeCall)
+		if timer != nil {
+			timer.Stop()
+		}
+		if err == nil && atomic.LoadInt32(&timedOut) != 0 && d.callInProgress(g) {
+			err = d.abortCall(g, command.CallTimeout)
+		}
 	case api.Rewind:
 		d.log.Debug("rewinding")
 		if err := d.target.ChangeDirection(proc.Backward); err != nil {
@@ -1172,7 +2106,7 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
 			return nil, err
 		}
-		err = d.target.Step()
+		err = d.target.StepExcluding(command.StepExcludingPackages)
 	case api.ReverseStep:
 		d.log.Debug("reverse stepping")
 		if err := d.target.ChangeDirection(proc.Backward); err != nil {
@@ -1184,13 +2118,13 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
 			return nil, err
 		}
-		err = d.target.StepInstruction()
+		err = d.stepInstructions(command.Count)
 	case api.ReverseStepInstruction:
 		d.log.Debug("reverse single stepping")
 		if err := d.target.ChangeDirection(proc.Backward); err != nil {
 			return nil, err
 		}
-		err = d.target.StepInstruction()
+		err = d.stepInstructions(command.Count)
 	case api.StepOut:
 		d.log.Debug("step out")
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
@@ -1203,6 +2137,33 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 			return nil, err
 		}
 		err = d.target.StepOut()
+	case api.StepOutToUserCode:
+		d.log.Debug("step out to user code")
+		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+			return nil, err
+		}
+		err = d.target.StepOutToUserCode()
+	case api.StepToLine:
+		d.log.Debugf("stepping to line %s:%d", command.File, command.Line)
+		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+			return nil, err
+		}
+		err = d.target.StepToLine(command.File, command.Line)
+	case api.ContinueWithSignal:
+		d.log.Debugf("continuing with signal %d", command.Signal)
+		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+			return nil, err
+		}
+		if err := d.target.SetPendingSignal(d.target.CurrentThread().ThreadID(), command.Signal); err != nil {
+			return nil, err
+		}
+		err = d.continueTarget(command.SkipTracepoints)
+	case api.ContinueToFunctions:
+		d.log.Debugf("continuing to functions %v", command.Functions)
+		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+			return nil, err
+		}
+		reachedFunction, err = d.target.ContinueToFunctions(command.Functions)
 	case api.SwitchThread:
 		d.log.Debugf("switching to thread %d", command.ThreadID)
 		err = d.target.SwitchThread(command.ThreadID)
@@ -1219,6 +2180,7 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 		// RequestManualStop already called
 		withBreakpointInfo = false
 	}
+	stopTime := time.Now()
 
 	if err != nil {
 		if pe, ok := err.(proc.ErrProcessExited); ok && command.Name != api.SwitchGoroutine && command.Name != api.SwitchThread {
@@ -1226,17 +2188,33 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 			state.Pid = d.target.Pid()
 			state.Exited = true
 			state.ExitStatus = pe.Status
+			state.StopReason = api.StopExited
 			state.Err = pe
+			state.Stdout, state.Stderr = d.readOutput()
 			return state, nil
 		}
 		return nil, err
 	}
+	if stoppedForOutput && d.target.StopReason == proc.StopManual {
+		// The client resumes the target right away, the full state is not
+		// needed.
+		state := &api.DebuggerState{Pid: d.target.Pid(), OutputInProgress: true}
+		state.Stdout, state.Stderr = d.readOutput()
+		return state, nil
+	}
 	state, stateErr := d.state(api.LoadConfigToProc(command.ReturnInfoLoadConfig))
 	if stateErr != nil {
 		return state, stateErr
 	}
+	state.Stdout, state.Stderr = d.readOutput()
+	state.StopReason = commandStopReason(command.Name, state.StopReason)
+	state.StackCopy = d.stackCopy(stackBefore)
+	state.ReachedFunction = reachedFunction
+	if command.Name == api.Halt && d.target.StopReason == proc.StopManual {
+		state.RunningThreads = d.target.RunningThreads()
+	}
 	if withBreakpointInfo {
-		err = d.collectBreakpointInformation(state)
+		err = d.collectBreakpointInformation(state, stopTime)
 	}
 	for _, th := range state.Threads {
 		if th.Breakpoint != nil && th.Breakpoint.TraceReturn {
@@ -1247,10 +2225,169 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 			}
 		}
 	}
+	if clearErr := d.clearTemporaryBreakpoints(state); clearErr != nil && err == nil {
+		err = clearErr
+	}
 	return state, err
 }
 
-func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error {
+// CallMethod calls method on the value of receiverExpr, passing args as
+// arguments. Like all function calls it is executed on the topmost frame of
+// goroutine goid, see proc.MethodCallExpr for the checks done on the
+// receiver before starting the call.
+func (d *Debugger) CallMethod(goid int, receiverExpr, method string, args []string, unsafe bool, retLoadCfg *api.LoadConfig, resumeNotify chan struct{}) (*api.DebuggerState, error) {
+	d.targetMutex.Lock()
+	s, err := proc.ConvertEvalScope(d.target, goid, 0, 0)
+	var expr string
+	if err == nil {
+		expr, err = proc.MethodCallExpr(s, receiverExpr, method, args)
+	}
+	d.targetMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if goid < 0 {
+		goid = 0
+	}
+	return d.Command(&api.DebuggerCommand{Name: api.Call, GoroutineID: goid, Expr: expr, UnsafeCall: unsafe, ReturnInfoLoadConfig: retLoadCfg}, resumeNotify)
+}
+
+// SetNextStatement moves the program counter of goroutine goid to file:line,
+// which must be in the function of its topmost frame, without resuming the
+// target, see proc.(*Target).SetNextStatement.
+func (d *Debugger) SetNextStatement(goid int, file string, line int) (*api.DebuggerState, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if err := d.target.SetNextStatement(goid, file, line); err != nil {
+		return nil, err
+	}
+	return d.state(nil)
+}
+
+// discardTemporaryBreakpoints clears all temporary breakpoints, including
+// disabled ones, and returns them as discarded breakpoints.
+func (d *Debugger) discardTemporaryBreakpoints() ([]api.DiscardedBreakpoint, error) {
+	discarded := []api.DiscardedBreakpoint{}
+	for _, bp := range api.ConvertBreakpoints(d.breakpoints()) {
+		if bp.Temporary {
+			if _, err := d.clearBreakpoint(bp); err != nil {
+				return discarded, err
+			}
+			discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: bp, Reason: "temporary breakpoints are not recreated on restart"})
+		}
+	}
+	for _, bp := range d.disabledBreakpoints {
+		if bp.Temporary {
+			delete(d.disabledBreakpoints, bp.ID)
+			discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: bp, Reason: "temporary breakpoints are not recreated on restart"})
+		}
+	}
+	return discarded, nil
+}
+
+// clearTemporaryBreakpoints clears all temporary breakpoints that were hit
+// by the threads in state.
+func (d *Debugger) clearTemporaryBreakpoints(state *api.DebuggerState) error {
+	hit := map[int]bool{}
+	for _, th := range state.Threads {
+		if th.Breakpoint != nil && th.Breakpoint.Temporary {
+			hit[th.Breakpoint.ID] = true
+		}
+	}
+	if len(hit) == 0 {
+		return nil
+	}
+	for _, bp := range api.ConvertBreakpoints(d.breakpoints()) {
+		if hit[bp.ID] {
+			if _, err := d.clearBreakpoint(bp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isReverseCommand returns true if cmd executes the target backward.
+func isReverseCommand(cmd string) bool {
+	switch cmd {
+	case api.Rewind, api.ReverseNext, api.ReverseStep, api.ReverseStepOut, api.ReverseStepInstruction:
+		return true
+	}
+	return false
+}
+
+// stackSnapshot records the stack bounds of a goroutine.
+type stackSnapshot struct {
+	goid   int
+	lo, hi uint64
+}
+
+// selectedGoroutineStack returns the stack bounds of the selected
+// goroutine, or nil if there is no selected goroutine.
+func (d *Debugger) selectedGoroutineStack() *stackSnapshot {
+	g := d.target.SelectedGoroutine()
+	if g == nil {
+		return nil
+	}
+	lo, hi := g.StackBounds()
+	return &stackSnapshot{goid: g.ID, lo: lo, hi: hi}
+}
+
+// stackCopy returns a description of the copy of the stack of the
+// goroutine of s that happened since s was taken, or nil if the stack was
+// not copied.
+func (d *Debugger) stackCopy(s *stackSnapshot) *api.StackCopy {
+	if s == nil {
+		return nil
+	}
+	g, err := proc.FindGoroutine(d.target, s.goid)
+	if err != nil || g == nil {
+		return nil
+	}
+	lo, hi := g.StackBounds()
+	if lo == s.lo && hi == s.hi {
+		return nil
+	}
+	return &api.StackCopy{GoroutineID: s.goid, OldLo: s.lo, OldHi: s.hi, NewLo: lo, NewHi: hi}
+}
+
+// commandStopReason refines the stop reason reported by the target with
+// the command that was executed, so that the completion of different
+// stepping commands can be told apart.
+func commandStopReason(cmd string, sr api.StopReason) api.StopReason {
+	switch cmd {
+	case api.Halt:
+		return api.StopManual
+	case api.StepInstruction, api.ReverseStepInstruction:
+		return api.StopStepInstruction
+	}
+	if sr != api.StopNextFinished {
+		return sr
+	}
+	switch cmd {
+	case api.Next, api.ReverseNext:
+		return api.StopNext
+	case api.Step, api.ReverseStep:
+		return api.StopStep
+	case api.StepOut, api.ReverseStepOut, api.StepOutToUserCode:
+		return api.StopStepOut
+	case api.ContinueToFunctions:
+		return api.StopFunctionReached
+	}
+	return sr
+}
+
+// continueTarget resumes the target, if skipTracepoints is set the target
+// will not stop on tracepoints.
+func (d *Debugger) continueTarget(skipTracepoints bool) error {
+	if skipTracepoints {
+		return d.target.ContinueSkippingTracepoints()
+	}
+	return d.target.Continue()
+}
+
+func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState, stopTime time.Time) error {
 	if state == nil {
 		return nil
 	}
@@ -1261,7 +2398,7 @@ func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error
 		}
 
 		bp := state.Threads[i].Breakpoint
-		bpi := &api.BreakpointInfo{}
+		bpi := &api.BreakpointInfo{Time: stopTime}
 		state.Threads[i].BreakpointInfo = bpi
 
 		if bp.Goroutine {
@@ -1288,7 +2425,19 @@ func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error
 			return fmt.Errorf("could not find thread %d", state.Threads[i].ID)
 		}
 
-		if len(bp.Variables) == 0 && bp.LoadArgs == nil && bp.LoadLocals == nil {
+		if assertErr := thread.Breakpoint().AssertError; assertErr != nil {
+			bpi.AssertError = assertErr.Error()
+		}
+
+		if bp.DumpGoroutinesOnce && d.goroutineDumps[bp.ID] == nil {
+			dump, err := d.dumpGoroutines(bp.ID, thread)
+			if err != nil {
+				return err
+			}
+			d.goroutineDumps[bp.ID] = dump
+		}
+
+		if len(bp.Variables) == 0 && bp.LoadArgs == nil && bp.LoadLocals == nil && bp.TraceMessage == "" {
 			// don't try to create goroutine scope if there is nothing to load
 			continue
 		}
@@ -1309,6 +2458,9 @@ func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error
 				bpi.Variables[i] = *api.ConvertVar(v)
 			}
 		}
+		if bp.TraceMessage != "" {
+			bpi.TraceMessage = formatTraceMessage(s, bp.TraceMessage)
+		}
 		if bp.LoadArgs != nil {
 			if vars, err := s.FunctionArguments(*api.LoadConfigToProc(bp.LoadArgs)); err == nil {
 				bpi.Arguments = api.ConvertVars(vars)
@@ -1343,23 +2495,96 @@ func (d *Debugger) Sources(filter string) ([]string, error) {
 	return files, nil
 }
 
-// Functions returns a list of functions in the target process.
-func (d *Debugger) Functions(filter string) ([]string, error) {
-	d.targetMutex.Lock()
-	defer d.targetMutex.Unlock()
-
-	regex, err := regexp.Compile(filter)
-	if err != nil {
-		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+// SourceLines returns the lines from start to end (inclusive, counting from
+// 1) of the source file file, read from the filesystem of the debugger.
+// File must be one of the source files of the target, either as it appears
+// in the executable or after applying substitutePathRules to it.
+func (d *Debugger) SourceLines(file string, start, end int, substitutePathRules [][2]string) ([]string, error) {
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("invalid line range %d-%d", start, end)
 	}
 
-	funcs := []string{}
-	for _, f := range d.target.BinInfo().Functions {
-		if regex.MatchString(f.Name) {
-			funcs = append(funcs, f.Name)
+	d.targetMutex.Lock()
+	path := ""
+	for _, f := range d.target.BinInfo().Sources {
+		if f == file || (len(substitutePathRules) > 0 && locspec.SubstitutePath(f, substitutePathRules) == file) {
+			path = f
+			break
 		}
 	}
-	return funcs, nil
+	d.targetMutex.Unlock()
+	if path == "" {
+		return nil, fmt.Errorf("unknown source file %s", file)
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(buf), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if start > len(lines) {
+		return nil, fmt.Errorf("line %d is past the end of %s (%d lines)", start, file, len(lines))
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	r := make([]string, 0, end-start+1)
+	for _, line := range lines[start-1 : end] {
+		r = append(r, strings.TrimSuffix(line, "\r"))
+	}
+	return r, nil
+}
+
+// SourcesByPackage returns the source files of the target binary grouped
+// by the import path of the package they belong to, optionally filtered
+// using the regexp described in 'filter'. Packages that have no matching
+// source files are omitted.
+func (d *Debugger) SourcesByPackage(filter string) (map[string][]string, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	regex, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+	}
+
+	r := make(map[string][]string)
+	for _, pkg := range d.target.BinInfo().ListPackagesBuildInfo(true) {
+		files := []string{}
+		for f := range pkg.Files {
+			if regex.MatchString(f) {
+				files = append(files, f)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		sort.Strings(files)
+		r[pkg.ImportPath] = files
+	}
+	return r, nil
+}
+
+// Functions returns a list of functions in the target process.
+func (d *Debugger) Functions(filter string) ([]string, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	regex, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+	}
+
+	funcs := []string{}
+	for _, f := range d.target.BinInfo().Functions {
+		if regex.MatchString(f.Name) {
+			funcs = append(funcs, f.Name)
+		}
+	}
+	return funcs, nil
 }
 
 // Types returns all type information in the binary.
@@ -1387,6 +2612,141 @@ func (d *Debugger) Types(filter string) ([]string, error) {
 	return r, nil
 }
 
+// FindType returns the definition of the type named name, see
+// proc.BinaryInfo.FindType for how name is resolved.
+func (d *Debugger) FindType(name string) (*api.TypeDefinition, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bi := d.target.BinInfo()
+	typ, err := bi.FindType(name)
+	if err != nil {
+		return nil, err
+	}
+	r := api.ConvertTypeDefinition(typ)
+
+	seen := make(map[string]bool)
+	for _, fn := range bi.Functions {
+		recv := fn.ReceiverName()
+		ptr := strings.HasPrefix(recv, "(*") && strings.HasSuffix(recv, ")")
+		if ptr {
+			recv = recv[2 : len(recv)-1]
+		}
+		if recv == "" || fn.PackageName()+"."+recv != r.Name || seen[fn.Name] {
+			continue
+		}
+		seen[fn.Name] = true
+		r.Methods = append(r.Methods, api.TypeMethod{Name: fn.BaseName(), Function: fn.Name, PointerReceiver: ptr})
+	}
+	sort.Slice(r.Methods, func(i, j int) bool { return r.Methods[i].Name < r.Methods[j].Name })
+	return r, nil
+}
+
+// BuildInfo returns the build information of the target binary, read from
+// the runtime.buildVersion and runtime.modinfo variables.
+func (d *Debugger) BuildInfo() (*api.BuildInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	scope, err := proc.ThreadScope(d.target, d.target.CurrentThread())
+	if err != nil {
+		return nil, err
+	}
+	cfg := proc.LoadConfig{MaxStringLen: maxModInfoLen}
+	v, err := scope.EvalExpression("runtime.buildVersion", cfg)
+	if err != nil || v.Unreadable != nil || v.Kind != reflect.String {
+		v, err = scope.EvalExpression("$goversion", cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	bi := &api.BuildInfo{GoVersion: constant.StringVal(v.Value)}
+	if v, err := scope.EvalExpression("runtime.modinfo", cfg); err == nil && v.Unreadable == nil && v.Kind == reflect.String {
+		parseModInfo(bi, constant.StringVal(v.Value))
+	}
+	return bi, nil
+}
+
+// maxModInfoLen is the maximum length of runtime.modinfo read by BuildInfo.
+const maxModInfoLen = 1 << 20
+
+// parseModInfo parses the module information embedded by the go command in
+// runtime.modinfo into bi. The format is the one used by 'go version -m'.
+func parseModInfo(bi *api.BuildInfo, modinfo string) {
+	// modinfo is surrounded by 16 byte sentinels
+	if len(modinfo) >= 33 && modinfo[len(modinfo)-17] == '\n' {
+		modinfo = modinfo[16 : len(modinfo)-16]
+	}
+
+	unquote := func(s string) string {
+		if strings.HasPrefix(s, "\"") {
+			if r, err := strconv.Unquote(s); err == nil {
+				return r
+			}
+		}
+		return s
+	}
+
+	readModule := func(fields []string) api.Module {
+		m := api.Module{Path: fields[0]}
+		if len(fields) > 1 {
+			m.Version = fields[1]
+		}
+		if len(fields) > 2 {
+			m.Sum = fields[2]
+		}
+		return m
+	}
+
+	var last *api.Module
+	for _, line := range strings.Split(modinfo, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			if bi.GoVersion == "" {
+				bi.GoVersion = fields[1]
+			}
+		case "path":
+			bi.Path = fields[1]
+		case "mod":
+			bi.Main = readModule(fields[1:])
+			last = &bi.Main
+		case "dep":
+			bi.Deps = append(bi.Deps, readModule(fields[1:]))
+			last = &bi.Deps[len(bi.Deps)-1]
+		case "=>":
+			if last != nil {
+				m := readModule(fields[1:])
+				last.Replace = &m
+				last = nil
+			}
+		case "build":
+			kv := strings.Join(fields[1:], "\t")
+			var key, value string
+			if strings.HasPrefix(kv, "\"") {
+				// quoted key, find the closing quote
+				for i := 1; i < len(kv); i++ {
+					if kv[i] == '\\' {
+						i++
+					} else if kv[i] == '"' {
+						key = unquote(kv[:i+1])
+						value = strings.TrimPrefix(kv[i+1:], "=")
+						break
+					}
+				}
+			} else if i := strings.Index(kv, "="); i >= 0 {
+				key, value = kv[:i], kv[i+1:]
+			}
+			if key != "" {
+				bi.Settings = append(bi.Settings, api.BuildSetting{Key: key, Value: unquote(value)})
+			}
+		}
+	}
+}
+
 // PackageVariables returns a list of package variables for the thread,
 // optionally regexp filtered using regexp described in 'filter'.
 func (d *Debugger) PackageVariables(filter string, cfg proc.LoadConfig) ([]*proc.Variable, error) {
@@ -1431,6 +2791,23 @@ func (d *Debugger) ThreadRegisters(threadID int, floatingPoint bool) (*op.DwarfR
 	return d.target.BinInfo().Arch.RegistersToDwarfRegisters(0, regs), nil
 }
 
+// SetRegister changes the value of a register of the specified thread,
+// see proc.SetRegister for the accepted register names and values.
+func (d *Debugger) SetRegister(threadID int, name, value string) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	thread, found := d.target.FindThread(threadID)
+	if !found {
+		return fmt.Errorf("couldn't find thread %d", threadID)
+	}
+	if err := proc.SetRegister(thread, name, value); err != nil {
+		return err
+	}
+	d.target.ClearCaches()
+	return nil
+}
+
 // ScopeRegisters returns registers for the specified scope.
 func (d *Debugger) ScopeRegisters(goid, frame, deferredCall int, floatingPoint bool) (*op.DwarfRegisters, error) {
 	d.targetMutex.Lock()
@@ -1449,15 +2826,36 @@ func (d *Debugger) DwarfRegisterToString(i int, reg *op.DwarfRegister) (string,
 }
 
 // LocalVariables returns a list of the local variables.
-func (d *Debugger) LocalVariables(goid, frame, deferredCall int, cfg proc.LoadConfig) ([]*proc.Variable, error) {
+// If filter is not empty only variables whose name matches the filter
+// regular expression are returned.
+func (d *Debugger) LocalVariables(goid, frame, deferredCall int, filter string, cfg proc.LoadConfig) ([]*proc.Variable, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	var regex *regexp.Regexp
+	if filter != "" {
+		var err error
+		regex, err = regexp.Compile(filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+		}
+	}
+
 	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
 	if err != nil {
 		return nil, err
 	}
-	return s.LocalVariables(cfg)
+	vars, err := s.LocalVariables(cfg)
+	if err != nil || regex == nil {
+		return vars, err
+	}
+	r := vars[:0]
+	for _, v := range vars {
+		if regex.MatchString(v.Name) {
+			r = append(r, v)
+		}
+	}
+	return r, nil
 }
 
 // FunctionArguments returns the arguments to the current function.
@@ -1472,6 +2870,190 @@ func (d *Debugger) FunctionArguments(goid, frame, deferredCall int, cfg proc.Loa
 	return s.FunctionArguments(cfg)
 }
 
+// VisibleNames returns the names that can be used in expressions evaluated
+// in the specified scope.
+func (d *Debugger) VisibleNames(goid, frame, deferredCall int) ([]string, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	return s.VisibleNames()
+}
+
+// MutexOwner evaluates expr, which must be a sync.Mutex or a
+// sync.RWMutex (or a pointer to one), and returns whether it is locked and
+// the goroutines that could be holding it.
+// The Go runtime does not record which goroutine locked a mutex, the
+// candidates are found with a heuristic, see mutexOwnerCandidates: they can
+// include goroutines that reference the mutex without holding it and miss
+// the owner if it only reaches the mutex through the heap.
+func (d *Debugger) MutexOwner(goid, frame, deferredCall int, expr string) (locked bool, candidates []*proc.G, err error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return false, nil, err
+	}
+	v, err := s.EvalExpression(expr, proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 3, MaxStructFields: -1})
+	if err != nil {
+		return false, nil, err
+	}
+	if v.Kind == reflect.Ptr && len(v.Children) == 1 {
+		v = &v.Children[0]
+	}
+	if v.Unreadable != nil {
+		return false, nil, v.Unreadable
+	}
+	switch v.RealType.String() {
+	case "sync.Mutex":
+		locked, err = mutexLocked(v)
+	case "sync.RWMutex":
+		locked, err = rwmutexLocked(v)
+	default:
+		return false, nil, fmt.Errorf("%s (type %s) is not a sync.Mutex or sync.RWMutex", expr, v.TypeString())
+	}
+	if err != nil || !locked {
+		return locked, nil, err
+	}
+	candidates, err = d.mutexOwnerCandidates(v.Addr, v.RealType.Size())
+	return locked, candidates, err
+}
+
+// mutexLockFunctions are the functions that a goroutine waiting to lock a
+// mutex is executing.
+var mutexLockFunctions = map[string]bool{
+	"sync.(*Mutex).Lock":              true,
+	"sync.(*Mutex).lockSlow":          true,
+	"sync.(*RWMutex).Lock":            true,
+	"sync.(*RWMutex).RLock":           true,
+	"internal/sync.(*Mutex).Lock":     true,
+	"internal/sync.(*Mutex).lockSlow": true,
+}
+
+// mutexOwnerStacktraceDepth is the depth of the stacktraces searched for
+// mutexLockFunctions by mutexOwnerCandidates.
+const mutexOwnerStacktraceDepth = 50
+
+// mutexOwnerCandidates returns the goroutines that could hold the mutex of
+// the given size at addr: the goroutines whose stack contains the mutex or
+// has a pointer to it between the stack pointer of the topmost frame and
+// the base of the stack. Goroutines that are waiting to lock a mutex are
+// excluded.
+func (d *Debugger) mutexOwnerCandidates(addr uint64, size int64) ([]*proc.G, error) {
+	gs, _, err := proc.GoroutinesInfo(d.target, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	mem := d.target.Memory()
+	ptrSize := d.target.BinInfo().Arch.PtrSize()
+	refersTo := func(ptr uint64) bool { return ptr >= addr && ptr < addr+uint64(size) }
+
+	var r []*proc.G
+	for _, g := range gs {
+		frames, err := g.Stacktrace(mutexOwnerStacktraceDepth, 0)
+		if err != nil || len(frames) == 0 {
+			continue
+		}
+		waiting := false
+		for _, frame := range frames {
+			if frame.Current.Fn != nil && mutexLockFunctions[frame.Current.Fn.Name] {
+				waiting = true
+				break
+			}
+		}
+		if waiting {
+			continue
+		}
+		lo, hi := g.StackBounds()
+		if addr >= lo && addr < hi {
+			r = append(r, g)
+			continue
+		}
+		sp := frames[0].Regs.SP()
+		if sp < lo || sp >= hi {
+			continue
+		}
+		buf := make([]byte, hi-sp)
+		if _, err := mem.ReadMemory(buf, sp); err != nil {
+			continue
+		}
+		for i := 0; i+ptrSize <= len(buf); i += ptrSize {
+			var ptr uint64
+			if ptrSize == 4 {
+				ptr = uint64(binary.LittleEndian.Uint32(buf[i:]))
+			} else {
+				ptr = binary.LittleEndian.Uint64(buf[i:])
+			}
+			if refersTo(ptr) {
+				r = append(r, g)
+				break
+			}
+		}
+	}
+	return r, nil
+}
+
+// mutexLocked returns true if the mutexLocked bit of the state word of the
+// sync.Mutex v is set.
+func mutexLocked(v *proc.Variable) (bool, error) {
+	if mu := mutexField(v, "mu"); mu != nil {
+		// Go 1.24 and later, the implementation lives in internal/sync.Mutex
+		v = mu
+	}
+	state, err := mutexIntField(v, "state")
+	if err != nil {
+		return false, err
+	}
+	const mutexLocked = 1
+	return state&mutexLocked != 0, nil
+}
+
+// rwmutexLocked returns true if the sync.RWMutex v is held by a writer or
+// by at least one reader.
+func rwmutexLocked(v *proc.Variable) (bool, error) {
+	w := mutexField(v, "w")
+	if w == nil {
+		return false, errors.New("could not find field w of sync.RWMutex")
+	}
+	locked, err := mutexLocked(w)
+	if err != nil || locked {
+		return locked, err
+	}
+	readerCount, err := mutexIntField(v, "readerCount")
+	if err != nil {
+		return false, err
+	}
+	return readerCount != 0, nil
+}
+
+func mutexField(v *proc.Variable, name string) *proc.Variable {
+	for i := range v.Children {
+		if v.Children[i].Name == name {
+			return &v.Children[i]
+		}
+	}
+	return nil
+}
+
+// mutexIntField returns the value of the integer field name of v, the
+// field can also be one of the sync/atomic integer types.
+func mutexIntField(v *proc.Variable, name string) (int64, error) {
+	f := mutexField(v, name)
+	if f != nil && f.Kind == reflect.Struct {
+		// sync/atomic.Int32 and similar
+		f = mutexField(f, "v")
+	}
+	if f == nil || f.Unreadable != nil || f.Value == nil {
+		return 0, fmt.Errorf("could not read field %s of %s", name, v.TypeString())
+	}
+	n, _ := constant.Int64Val(f.Value)
+	return n, nil
+}
+
 // Function returns the current function.
 func (d *Debugger) Function(goid, frame, deferredCall int, cfg proc.LoadConfig) (*proc.Function, error) {
 	d.targetMutex.Lock()
@@ -1490,11 +3072,296 @@ func (d *Debugger) EvalVariableInScope(goid, frame, deferredCall int, symbol str
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	expr, err := expandEvalAliases(symbol, d.evalAliases, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
 	if err != nil {
 		return nil, err
 	}
-	return s.EvalVariable(symbol, cfg)
+	v, err := s.EvalVariable(expr, cfg)
+	if err == nil && v.Name == expr {
+		v.Name = symbol
+	}
+	return v, err
+}
+
+// EvalAcrossGoroutines evaluates symbol in the topmost frame of every
+// goroutine, the results are keyed by goroutine ID. If the evaluation
+// fails on a goroutine the corresponding variable is unreadable.
+func (d *Debugger) EvalAcrossGoroutines(symbol string, cfg proc.LoadConfig) (map[int]*api.Variable, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	expr, err := expandEvalAliases(symbol, d.evalAliases, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gs, _, err := proc.GoroutinesInfo(d.target, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	r := make(map[int]*api.Variable, len(gs))
+	for _, g := range gs {
+		var v *proc.Variable
+		s, err := proc.ConvertEvalScope(d.target, g.ID, 0, 0)
+		if err == nil {
+			v, err = s.EvalVariable(expr, cfg)
+		}
+		if err != nil {
+			r[g.ID] = &api.Variable{Name: symbol, Unreadable: err.Error()}
+			continue
+		}
+		if v.Name == expr {
+			v.Name = symbol
+		}
+		r[g.ID] = api.ConvertVar(v)
+	}
+	return r, nil
+}
+
+// WhatIs evaluates expr in the scope provided and returns its type. The
+// value of expr is not loaded, except for the dynamic type of interfaces.
+func (d *Debugger) WhatIs(goid, frame, deferredCall int, expr string) (*api.TypeInfo, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	expr, err := expandEvalAliases(expr, d.evalAliases, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable(expr, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &api.TypeInfo{
+		Type:     api.PrettyTypeName(v.DwarfType),
+		RealType: api.PrettyTypeName(v.RealType),
+		Kind:     v.Kind,
+	}
+	if v.RealType != nil {
+		r.Size = v.RealType.Size()
+		r.Align = proc.TypeAlign(v.RealType, d.target.BinInfo().Arch.PtrSize())
+	}
+	if v.Kind == reflect.Interface && v.Unreadable == nil && len(v.Children) > 0 && v.Children[0].Kind != reflect.Invalid {
+		r.ConcreteType = api.PrettyTypeName(v.Children[0].DwarfType)
+	}
+	return r, nil
+}
+
+// watchLoadConfig is the configuration used to load the values of the
+// expressions registered with AddWatch.
+var watchLoadConfig = proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+
+// AddWatch registers expr, it will be evaluated in the specified scope and
+// included in the state of the debugger every time it is returned. Returns
+// the ID of the new watch.
+func (d *Debugger) AddWatch(scope api.EvalScope, expr string) (int, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	expanded, err := expandEvalAliases(expr, d.evalAliases, nil)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := parser.ParseExpr(expanded); err != nil {
+		return 0, err
+	}
+
+	d.lastWatchID++
+	d.watches = append(d.watches, api.Watch{ID: d.lastWatchID, Scope: scope, Expr: expr})
+	return d.lastWatchID, nil
+}
+
+// RemoveWatch removes the watch with the specified ID.
+func (d *Debugger) RemoveWatch(id int) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	for i := range d.watches {
+		if d.watches[i].ID == id {
+			d.watches = append(d.watches[:i], d.watches[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no watch with ID %d", id)
+}
+
+// evalWatches evaluates all the expressions registered with AddWatch.
+func (d *Debugger) evalWatches() []api.Watch {
+	if len(d.watches) == 0 {
+		return nil
+	}
+	r := make([]api.Watch, len(d.watches))
+	for i, w := range d.watches {
+		r[i] = w
+		v, err := d.evalWatch(w)
+		if err != nil {
+			r[i].Err = err.Error()
+			continue
+		}
+		r[i].Value = api.ConvertVar(v)
+	}
+	return r
+}
+
+func (d *Debugger) evalWatch(w api.Watch) (*proc.Variable, error) {
+	expr, err := expandEvalAliases(w.Expr, d.evalAliases, nil)
+	if err != nil {
+		return nil, err
+	}
+	s, err := proc.ConvertEvalScope(d.target, w.Scope.GoroutineID, w.Scope.Frame, w.Scope.DeferredCall)
+	if err != nil {
+		return nil, err
+	}
+	return s.EvalVariable(expr, watchLoadConfig)
+}
+
+// DefaultReadBytesLimit is the maximum number of bytes returned by
+// ReadBytes when no limit is specified.
+const DefaultReadBytesLimit = 16 * 1024 * 1024
+
+// ReadBytes evaluates expr, which must be a string or a slice or array of
+// bytes, in the specified scope and returns its full contents. If limit is
+// zero DefaultReadBytesLimit is used, values longer than limit bytes are
+// not read.
+func (d *Debugger) ReadBytes(goid, frame, deferredCall int, expr string, limit int) ([]byte, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if limit <= 0 {
+		limit = DefaultReadBytesLimit
+	}
+
+	expanded, err := expandEvalAliases(expr, d.evalAliases, nil)
+	if err != nil {
+		return nil, err
+	}
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable(expanded, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	v.Name = expr
+	return v.ReadBytes(limit)
+}
+
+// SetEvalAlias defines name as an alias for the expression expr. Aliases
+// can be used as @name in the expressions passed to EvalVariableInScope
+// and are expanded, between parenthesis, before the expression is parsed.
+// If expr is empty the alias is removed.
+// An alias can refer to other aliases, as long as they are already defined
+// and no cycle is created.
+func (d *Debugger) SetEvalAlias(name, expr string) error {
+	if !isEvalAliasName(name) {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if expr == "" {
+		delete(d.evalAliases, name)
+		return nil
+	}
+	aliases := make(map[string]string, len(d.evalAliases)+1)
+	for k, v := range d.evalAliases {
+		aliases[k] = v
+	}
+	aliases[name] = expr
+	if _, err := expandEvalAliases("@"+name, aliases, nil); err != nil {
+		return err
+	}
+	d.evalAliases = aliases
+	return nil
+}
+
+// ListEvalAliases returns all aliases defined with SetEvalAlias, sorted by
+// name.
+func (d *Debugger) ListEvalAliases() []api.EvalAlias {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	r := make([]api.EvalAlias, 0, len(d.evalAliases))
+	for name, expr := range d.evalAliases {
+		r = append(r, api.EvalAlias{Name: name, Expr: expr})
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Name < r[j].Name })
+	return r
+}
+
+// isEvalAliasName returns true if name is a valid alias name, i.e. a Go
+// identifier.
+func isEvalAliasName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, ch := range name {
+		if !(ch == '_' || unicode.IsLetter(ch) || (i > 0 && unicode.IsDigit(ch))) {
+			return false
+		}
+	}
+	return true
+}
+
+// expandEvalAliases replaces every reference @name to an alias in expr
+// with the expression of the alias, surrounded by parenthesis. Aliases are
+// expanded recursively, stack contains the names of the aliases being
+// expanded and is used to detect cycles.
+func expandEvalAliases(expr string, aliases map[string]string, stack []string) (string, error) {
+	if !strings.Contains(expr, "@") {
+		return expr, nil
+	}
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expr))
+	var s scanner.Scanner
+	s.Init(file, []byte(expr), nil, 0)
+	var buf strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.ILLEGAL || lit != "@" {
+			continue
+		}
+		off := file.Offset(pos)
+		namepos, tok, name := s.Scan()
+		if tok != token.IDENT || file.Offset(namepos) != off+1 {
+			continue
+		}
+		for _, n := range stack {
+			if n == name {
+				return "", fmt.Errorf("alias cycle: @%s -> @%s", strings.Join(stack, " -> @"), name)
+			}
+		}
+		aliasExpr, ok := aliases[name]
+		if !ok {
+			return "", fmt.Errorf("unknown alias @%s", name)
+		}
+		expanded, err := expandEvalAliases(aliasExpr, aliases, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(expr[last:off])
+		buf.WriteString("(" + expanded + ")")
+		last = off + 1 + len(name)
+	}
+	buf.WriteString(expr[last:])
+	return buf.String(), nil
 }
 
 // LoadResliced will attempt to 'reslice' a map, array or slice so that the values
@@ -1566,14 +3433,18 @@ func matchGoroutineFilter(tgt *proc.Target, g *proc.G, filter *api.ListGoroutine
 	case api.GoroutineLabel:
 		idx := strings.Index(filter.Arg, "=")
 		if idx >= 0 {
-			val = g.Labels()[filter.Arg[:idx]] == filter.Arg[idx+1:]
+			val = tgt.GoroutineLabels(g)[filter.Arg[:idx]] == filter.Arg[idx+1:]
 		} else {
-			_, val = g.Labels()[filter.Arg]
+			_, val = tgt.GoroutineLabels(g)[filter.Arg]
 		}
 	case api.GoroutineRunning:
 		val = g.Thread != nil
 	case api.GoroutineUser:
 		val = !g.System(tgt)
+	case api.GoroutineStatus:
+		val = api.GoroutineStatusName(g.Status) == filter.Arg
+	case api.GoroutineUserCode:
+		val = g.InUserCode()
 	}
 	if filter.Negated {
 		val = !val
@@ -1618,11 +3489,15 @@ func (d *Debugger) GroupGoroutines(gs []*proc.G, group *api.GoroutineGroupingOpt
 		case api.GoroutineStartLoc:
 			key = formatLoc(g.StartLoc(d.target))
 		case api.GoroutineLabel:
-			key = fmt.Sprintf("%s=%s", group.GroupByKey, g.Labels()[group.GroupByKey])
+			key = fmt.Sprintf("%s=%s", group.GroupByKey, d.target.GoroutineLabels(g)[group.GroupByKey])
 		case api.GoroutineRunning:
 			key = fmt.Sprintf("running=%v", g.Thread != nil)
 		case api.GoroutineUser:
 			key = fmt.Sprintf("user=%v", !g.System(d.target))
+		case api.GoroutineStatus:
+			key = fmt.Sprintf("status=%s", api.GoroutineStatusName(g.Status))
+		case api.GoroutineUserCode:
+			key = fmt.Sprintf("usercode=%v", g.InUserCode())
 		}
 		if len(groupMembers[key]) < group.MaxGroupMembers {
 			groupMembers[key] = append(groupMembers[key], g)
@@ -1673,6 +3548,121 @@ func (d *Debugger) Stacktrace(goroutineID, depth int, opts api.StacktraceOptions
 	}
 }
 
+// maxCallInjectionStackDepth is the maximum number of frames read by
+// CallInjectionStack.
+const maxCallInjectionStackDepth = 100
+
+// abortCall unwinds the injected function call in progress on g, which did
+// not return within timeout, and resumes the target to let the function
+// call protocol complete. It always returns an error describing the
+// outcome.
+func (d *Debugger) abortCall(g *proc.G, timeout time.Duration) error {
+	if err := d.target.AbortFunctionCall(g); err != nil {
+		return fmt.Errorf("function call did not return within %v and could not be aborted, the target was stopped with the call still in progress: %v", timeout, err)
+	}
+	if err := d.target.Continue(); err != nil && err != proc.ErrFunctionCallAborted {
+		return err
+	}
+	if d.callInProgress(g) {
+		return fmt.Errorf("function call did not return within %v, the target stopped before the aborted call was unwound", timeout)
+	}
+	return fmt.Errorf("function call did not return within %v and was aborted", timeout)
+}
+
+// callInProgress returns true if an injected function call is in progress
+// on goroutine g.
+func (d *Debugger) callInProgress(g *proc.G) bool {
+	if g == nil {
+		return false
+	}
+	for _, gid := range d.target.FunctionCallGoroutines() {
+		if gid == g.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// CallInjectionStack returns the stack frames that belong to the injected
+// function call currently in progress: from the innermost frame up to and
+// including the frame of the runtime function that delve used to inject the
+// call.
+// If more than one goroutine is executing an injected call the selected
+// goroutine is preferred.
+func (d *Debugger) CallInjectionStack() ([]proc.Stackframe, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	goids := d.target.FunctionCallGoroutines()
+	if len(goids) == 0 {
+		return nil, errors.New("no function call injection in progress")
+	}
+	goid := goids[len(goids)-1]
+	if selg := d.target.SelectedGoroutine(); selg != nil {
+		for i := range goids {
+			if goids[i] == selg.ID {
+				goid = selg.ID
+				break
+			}
+		}
+	}
+
+	g, err := proc.FindGoroutine(d.target, goid)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := g.Stacktrace(maxCallInjectionStackDepth, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range frames {
+		if proc.IsDebugCallFunction(frames[i].Current.Fn) {
+			return frames[:i+1], nil
+		}
+	}
+	return nil, fmt.Errorf("could not find the injected call frame on the stack of goroutine %d", goid)
+}
+
+// Children returns the goroutines that were created by the goroutine
+// goroutineID.
+func (d *Debugger) Children(goroutineID int) ([]*proc.G, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	if goroutineID < 0 {
+		g := d.target.SelectedGoroutine()
+		if g == nil {
+			return nil, errors.New("no selected goroutine")
+		}
+		goroutineID = g.ID
+	}
+
+	gs, _, err := proc.GoroutinesInfo(d.target, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	r := []*proc.G{}
+	for _, g := range gs {
+		creator, err := proc.CreatorID(d.target, g)
+		if err == proc.ErrCreatorNotRecorded {
+			return nil, err
+		}
+		if err == nil && creator == goroutineID {
+			r = append(r, g)
+		}
+	}
+	return r, nil
+}
+
 // Ancestors returns the stacktraces for the ancestors of a goroutine.
 func (d *Debugger) Ancestors(goroutineID, numAncestors, depth int) ([]api.Ancestor, error) {
 	d.targetMutex.Lock()
@@ -1733,10 +3723,17 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 			FrameOffset:        rawlocs[i].FrameOffset(),
 			FramePointerOffset: rawlocs[i].FramePointerOffset(),
 
-			Defers: d.convertDefers(rawlocs[i].Defers),
+			Defers:    d.convertDefers(rawlocs[i].Defers),
+			Panicking: rawlocs[i].Panicking,
 
 			Bottom: rawlocs[i].Bottom,
 		}
+		if rawlocs[i].Inlined {
+			frame.Inlined = true
+			if rawlocs[i].Current.Fn != nil {
+				frame.InlineParentPC = rawlocs[i].Current.Fn.Entry
+			}
+		}
 		if rawlocs[i].Err != nil {
 			frame.Err = rawlocs[i].Err.Error()
 		}
@@ -1788,7 +3785,72 @@ func (d *Debugger) convertDefers(defers []*proc.Defer) []api.Defer {
 		}
 	}
 
-	return r
+	return r
+}
+
+// deferredCallsMaxDepth is the maximum depth of the stack trace scanned by
+// DeferredCalls.
+const deferredCallsMaxDepth = 100
+
+// DeferredCalls returns the deferred calls pending on goroutine goid, for
+// frame and all the frames below it, in the order they will be executed.
+// The arguments of each deferred call are loaded using cfg.
+func (d *Debugger) DeferredCalls(goid, frame int, cfg proc.LoadConfig) ([]api.DeferredCall, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	g, err := proc.FindGoroutine(d.target, goid)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, errors.New("no goroutine selected")
+	}
+	frames, err := g.Stacktrace(deferredCallsMaxDepth, proc.StacktraceReadDefers)
+	if err != nil {
+		return nil, err
+	}
+	if frame < 0 || frame >= len(frames) {
+		return nil, fmt.Errorf("Frame %d does not exist in goroutine %d", frame, g.ID)
+	}
+
+	r := []api.DeferredCall{}
+	for i := frame; i < len(frames); i++ {
+		defers := d.convertDefers(frames[i].Defers)
+		for j, def := range frames[i].Defers {
+			dc := api.DeferredCall{Defer: defers[j], Frame: i, DeferredCall: j + 1}
+			if def.Unreadable != nil {
+				dc.ArgsUnreadable = def.Unreadable.Error()
+			} else {
+				dc.Args, dc.ArgsUnreadable = d.deferredCallArgs(def, cfg)
+			}
+			r = append(r, dc)
+		}
+	}
+	return r, nil
+}
+
+func (d *Debugger) deferredCallArgs(def *proc.Defer, cfg proc.LoadConfig) ([]api.Variable, string) {
+	if def.Wrapped(d.target) {
+		// Go 1.17 and later defer a wrapper that captures the arguments.
+		args, err := def.WrappedArgs(d.target, cfg)
+		if err != nil {
+			return nil, err.Error()
+		}
+		return api.ConvertVars(args), ""
+	}
+	scope, err := def.EvalScope(d.target, d.target.CurrentThread())
+	if err != nil {
+		return nil, err.Error()
+	}
+	args, err := scope.FunctionArguments(cfg)
+	if err != nil {
+		return nil, err.Error()
+	}
+	return api.ConvertVars(args), ""
 }
 
 // CurrentPackage returns the fully qualified name of the
@@ -1828,6 +3890,38 @@ func (d *Debugger) FindLocation(goid, frame, deferredCall int, locStr string, in
 	return d.findLocation(goid, frame, deferredCall, locStr, loc, includeNonExecutableLines, substitutePathRules)
 }
 
+// FindLocationCandidates is like FindLocation but, if 'locStr' is an
+// ambiguous file or function name, it returns a location for each
+// candidate, ranked as described by locspec.(*NormalLocationSpec).FindRanked.
+func (d *Debugger) FindLocationCandidates(goid, frame, deferredCall int, locStr string, includeNonExecutableLines bool, substitutePathRules [][2]string) ([]api.Location, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	loc, err := locspec.Parse(locStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if normloc, ok := loc.(*locspec.NormalLocationSpec); ok {
+		loc = rankedLocationSpec{normloc}
+	}
+	return d.findLocation(goid, frame, deferredCall, locStr, loc, includeNonExecutableLines, substitutePathRules)
+}
+
+// rankedLocationSpec is a locspec.NormalLocationSpec that returns all the
+// candidates of an ambiguous location.
+type rankedLocationSpec struct {
+	*locspec.NormalLocationSpec
+}
+
+func (loc rankedLocationSpec) Find(t *proc.Target, processArgs []string, scope *proc.EvalScope, locStr string, includeNonExecutableLines bool, substitutePathRules [][2]string) ([]api.Location, error) {
+	return loc.FindRanked(t, processArgs, scope, locStr, includeNonExecutableLines, substitutePathRules)
+}
+
 // FindLocationSpec will find the location specified by 'locStr' and 'locSpec'.
 // 'locSpec' should be the result of calling 'locspec.Parse(locStr)'. 'locStr'
 // is also passed, because it made be used to broaden the search criteria, if
@@ -1843,6 +3937,74 @@ func (d *Debugger) FindLocationSpec(goid, frame, deferredCall int, locStr string
 	return d.findLocation(goid, frame, deferredCall, locStr, locSpec, includeNonExecutableLines, substitutePathRules)
 }
 
+// PreviewBreakpoint returns the physical breakpoints that would be created
+// by setting a breakpoint on locStr, one for each address, without
+// creating them.
+func (d *Debugger) PreviewBreakpoint(goid, frame, deferredCall int, locStr string, substitutePathRules [][2]string) ([]api.Location, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+
+	loc, err := locspec.Parse(locStr)
+	if err != nil {
+		return nil, err
+	}
+
+	locs, err := d.findLocation(goid, frame, deferredCall, locStr, loc, false, substitutePathRules)
+	if err != nil {
+		return nil, err
+	}
+
+	bi := d.target.BinInfo()
+	seen := make(map[uint64]bool)
+	r := []api.Location{}
+	for _, loc := range locs {
+		pcs := loc.PCs
+		if len(pcs) == 0 {
+			pcs = []uint64{loc.PC}
+		}
+		for _, pc := range pcs {
+			if seen[pc] {
+				continue
+			}
+			seen[pc] = true
+			file, line, fn := bi.PCToLine(pc)
+			ploc := api.Location{PC: pc, PCs: []uint64{pc}, File: file, Line: line, Function: api.ConvertFunction(fn)}
+			if fn != nil {
+				inlfn := bi.PCToInlineFunc(pc)
+				ploc.Inlined = inlfn != nil && inlfn.Name != fn.Name
+				ploc.Wrapper = fn.Wrapper()
+			}
+			r = append(r, ploc)
+		}
+	}
+	return r, nil
+}
+
+// ResolveAddress returns the location of pc: its file, line, containing
+// function and symbol.
+func (d *Debugger) ResolveAddress(pc uint64) (*api.Location, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	bi := d.target.BinInfo()
+	file, line, fn := bi.PCToLine(pc)
+	loc := &api.Location{PC: pc, File: file, Line: line, Function: api.ConvertFunction(fn)}
+	if fn != nil {
+		inlfn := bi.PCToInlineFunc(pc)
+		loc.Inlined = inlfn != nil && inlfn.Name != fn.Name
+		loc.Wrapper = fn.Wrapper()
+	}
+	loc.Symbol, loc.SymbolOffset = bi.PCToSymbol(pc)
+	if fn == nil && loc.Symbol == "" {
+		return nil, fmt.Errorf("address %#x does not belong to any symbol", pc)
+	}
+	return loc, nil
+}
+
 func (d *Debugger) findLocation(goid, frame, deferredCall int, locStr string, locSpec locspec.LocationSpec, includeNonExecutableLines bool, substitutePathRules [][2]string) ([]api.Location, error) {
 	s, _ := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
 
@@ -1892,6 +4054,36 @@ func (d *Debugger) Disassemble(goroutineID int, addr1, addr2 uint64) ([]proc.Asm
 	return proc.Disassemble(d.target.Memory(), regs, d.target.Breakpoints(), d.target.BinInfo(), addr1, addr2)
 }
 
+// DisassembleFunction disassembles the whole body of the function named
+// funcName. The name is resolved like a location expression, if it matches
+// more than one function an error is returned, unless one of the matches is
+// exact.
+func (d *Debugger) DisassembleFunction(goroutineID int, funcName string) ([]proc.AsmInstruction, error) {
+	loc, err := locspec.Parse(funcName)
+	if err != nil {
+		return nil, err
+	}
+	if nloc, ok := loc.(*locspec.NormalLocationSpec); !ok || nloc.FuncBase == nil || nloc.LineOffset >= 0 {
+		return nil, fmt.Errorf("%q is not a function name", funcName)
+	}
+
+	d.targetMutex.Lock()
+	if _, err := d.target.Valid(); err != nil {
+		d.targetMutex.Unlock()
+		return nil, err
+	}
+	locs, err := d.findLocation(goroutineID, 0, 0, funcName, loc, false, nil)
+	d.targetMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(locs) != 1 || locs[0].Function == nil {
+		return nil, fmt.Errorf("%q does not match exactly one function", funcName)
+	}
+
+	return d.Disassemble(goroutineID, locs[0].PC, 0)
+}
+
 func (d *Debugger) AsmInstructionText(inst *proc.AsmInstruction, flavour proc.AssemblyFlavour) string {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
@@ -1927,13 +4119,31 @@ func (d *Debugger) FindThreadReturnValues(id int, cfg proc.LoadConfig) ([]*proc.
 func (d *Debugger) Checkpoint(where string) (int, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	if err := d.checkpointsSupported(); err != nil {
+		return -1, err
+	}
 	return d.target.Checkpoint(where)
 }
 
+// checkpointsSupported returns an error if the target does not support
+// checkpoints, only recordings that are not core files do.
+func (d *Debugger) checkpointsSupported() error {
+	if d.config.CoreFile != "" {
+		return ErrCheckpointsCoreFile
+	}
+	if recorded, _ := d.target.Recorded(); !recorded {
+		return ErrCheckpointsNotSupported
+	}
+	return nil
+}
+
 // Checkpoints will return a list of checkpoints.
 func (d *Debugger) Checkpoints() ([]proc.Checkpoint, error) {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	if err := d.checkpointsSupported(); err != nil {
+		return nil, err
+	}
 	return d.target.Checkpoints()
 }
 
@@ -1941,9 +4151,301 @@ func (d *Debugger) Checkpoints() ([]proc.Checkpoint, error) {
 func (d *Debugger) ClearCheckpoint(id int) error {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	if err := d.checkpointsSupported(); err != nil {
+		return err
+	}
 	return d.target.ClearCheckpoint(id)
 }
 
+// ValueHistory returns the sequence of values taken by expr over the whole
+// recording, with the position of each change. The expression is evaluated
+// once in the specified scope and must be a global or heap allocated
+// variable that can be watched with a watchpoint, the value history is
+// then collected by restarting the recording from the beginning and
+// continuing to the end with a write watchpoint on its address.
+// At most maxSamples values are returned, if maxSamples is not positive
+// all values are returned.
+// The target is moved back to the current position, and its direction
+// restored, before returning. The other breakpoints are suspended while
+// the value history is collected, their hit counts do not change.
+func (d *Debugger) ValueHistory(goid, frame, deferredCall int, expr string, maxSamples int, cfg proc.LoadConfig) ([]api.ValueSample, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if recorded, _ := d.target.Recorded(); !recorded {
+		return nil, proc.ErrNotRecorded
+	}
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.EvalVariable(expr, proc.LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	// the watched memory is read back through a typed pointer, since the
+	// scope of expr might not exist at the positions being examined.
+	valueExpr := fmt.Sprintf("*(*%s)(%#x)", v.TypeString(), v.Addr)
+
+	cpid, err := d.target.Checkpoint("value history of " + expr)
+	if err != nil {
+		return nil, err
+	}
+	defer d.target.ClearCheckpoint(cpid)
+
+	bp, err := d.target.SetWatchpoint(s, expr, proc.WatchWrite, nil)
+	if err != nil {
+		return nil, err
+	}
+	resume, err := d.target.SuspendBreakpoints(bp)
+	if err != nil {
+		d.target.ClearBreakpoint(bp.Addr)
+		return nil, err
+	}
+	dir := d.target.GetDirection()
+
+	samples, err := d.collectValueHistory(expr, valueExpr, maxSamples, cfg)
+
+	// go back to where we were, the watchpoint can only be cleared while
+	// the target is valid.
+	if rerr := d.target.Restart(fmt.Sprintf("c%d", cpid)); rerr != nil && err == nil {
+		err = rerr
+	}
+	if _, cerr := d.target.ClearBreakpoint(bp.Addr); cerr != nil && err == nil {
+		err = cerr
+	}
+	if rerr := resume(); rerr != nil && err == nil {
+		err = rerr
+	}
+	if derr := d.target.ChangeDirection(dir); derr != nil && err == nil {
+		err = derr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// collectValueHistory restarts the recording from the beginning and
+// continues it until the end, sampling the value of valueExpr every time
+// the target stops and recording it if it changed.
+func (d *Debugger) collectValueHistory(expr, valueExpr string, maxSamples int, cfg proc.LoadConfig) ([]api.ValueSample, error) {
+	if err := d.target.Restart(""); err != nil {
+		return nil, err
+	}
+	if err := d.target.ChangeDirection(proc.Forward); err != nil {
+		return nil, err
+	}
+
+	var samples []api.ValueSample
+	last := ""
+	for maxSamples <= 0 || len(samples) < maxSamples {
+		if sample := d.valueSample(expr, valueExpr, cfg); sample != nil {
+			if cur := sample.Value.SinglelineString(); len(samples) == 0 || cur != last {
+				samples = append(samples, *sample)
+				last = cur
+			}
+		}
+		if err := d.target.Continue(); err != nil {
+			if _, exited := err.(proc.ErrProcessExited); exited {
+				break
+			}
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// valueSample evaluates valueExpr at the current position of the target,
+// it returns nil if the value can not be read, for example because the
+// memory it refers to has not been mapped yet.
+func (d *Debugger) valueSample(expr, valueExpr string, cfg proc.LoadConfig) *api.ValueSample {
+	s, err := proc.ConvertEvalScope(d.target, -1, 0, 0)
+	if err != nil {
+		return nil
+	}
+	v, err := s.EvalVariable(valueExpr, cfg)
+	if err != nil || v.Unreadable != nil {
+		return nil
+	}
+	v.Name = expr
+	sample := &api.ValueSample{Value: *api.ConvertVar(v)}
+	sample.When, _ = d.target.When()
+	sample.Event, _ = d.currentEvent()
+	if loc, err := d.target.CurrentThread().Location(); err == nil {
+		sample.Location = api.Location{PC: loc.PC, File: loc.File, Line: loc.Line, Function: api.ConvertFunction(loc.Fn)}
+	}
+	return sample
+}
+
+// chromeTraceEvent is a complete event ("ph": "X") of the Chrome trace
+// event format.
+type chromeTraceEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat"`
+	Ph   string         `json:"ph"`
+	Ts   int64          `json:"ts"`
+	Dur  int64          `json:"dur"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]int `json:"args,omitempty"`
+}
+
+// ExportChromeTrace replays the recording from the rr event fromEvent to
+// the rr event toEvent (or to the end of the recording if toEvent is
+// negative) and returns a JSON document, in the Chrome trace event format,
+// describing which goroutine was running on each thread.
+// Goroutine switches are detected by stopping on runtime.execute and the
+// timestamps used in the trace are rr event numbers.
+// The other breakpoints are suspended during the replay, the current
+// position in the recording and the direction are restored before
+// returning.
+// The target can not be used by other commands until the replay is
+// complete, a Halt command interrupts it and the trace up to the current
+// event is returned.
+func (d *Debugger) ExportChromeTrace(fromEvent, toEvent int64) ([]byte, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if recorded, _ := d.target.Recorded(); !recorded {
+		return nil, proc.ErrNotRecorded
+	}
+	if fromEvent < 0 || (toEvent >= 0 && toEvent < fromEvent) {
+		return nil, fmt.Errorf("invalid event range %d-%d", fromEvent, toEvent)
+	}
+
+	addrs, err := proc.FindFunctionLocation(d.target, "runtime.execute", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	cpid, err := d.target.Checkpoint("chrome trace export")
+	if err != nil {
+		return nil, err
+	}
+	resume, err := d.target.SuspendBreakpoints(nil)
+	if err != nil {
+		d.target.ClearCheckpoint(cpid)
+		return nil, err
+	}
+	dir := d.target.GetDirection()
+	defer func() {
+		if err := d.target.Restart(fmt.Sprintf("c%d", cpid)); err != nil {
+			d.log.Errorf("could not restore position after exporting trace: %v", err)
+		}
+		d.target.ClearCheckpoint(cpid)
+		if err := resume(); err != nil {
+			d.log.Errorf("could not restore breakpoints after exporting trace: %v", err)
+		}
+		if err := d.target.ChangeDirection(dir); err != nil {
+			d.log.Errorf("could not restore direction after exporting trace: %v", err)
+		}
+	}()
+
+	if err := d.target.Restart(strconv.FormatInt(fromEvent, 10)); err != nil {
+		return nil, err
+	}
+	if err := d.target.ChangeDirection(proc.Forward); err != nil {
+		return nil, err
+	}
+
+	isExecute := make(map[uint64]bool)
+	for _, addr := range addrs {
+		isExecute[addr] = true
+		if _, err := d.target.SetBreakpoint(addr, proc.DebuggerBreakpoint, nil); err != nil {
+			return nil, err
+		}
+		defer d.target.ClearBreakpointKind(addr, proc.DebuggerBreakpoint)
+	}
+
+	type span struct {
+		goid  int
+		start int64
+	}
+	running := make(map[int]span) // thread ID -> goroutine currently running on it
+	events := []chromeTraceEvent{}
+	pid := d.target.Pid()
+
+	closeSpan := func(tid int, end int64) {
+		if s, ok := running[tid]; ok {
+			events = append(events, chromeTraceEvent{
+				Name: fmt.Sprintf("goroutine %d", s.goid),
+				Cat:  "goroutine",
+				Ph:   "X",
+				Ts:   s.start,
+				Dur:  end - s.start,
+				Pid:  pid,
+				Tid:  tid,
+				Args: map[string]int{"goid": s.goid},
+			})
+			delete(running, tid)
+		}
+	}
+
+	now := fromEvent
+	for {
+		err := d.target.Continue()
+		if _, exited := err.(proc.ErrProcessExited); exited {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d.target.StopReason == proc.StopManual {
+			break
+		}
+		ev, err := d.currentEvent()
+		if err != nil {
+			return nil, err
+		}
+		if toEvent >= 0 && ev > toEvent {
+			break
+		}
+		now = ev
+		th := d.target.CurrentThread()
+		if bp := th.Breakpoint(); bp.Breakpoint == nil || !isExecute[bp.Addr] {
+			continue
+		}
+		scope, err := proc.ThreadScope(d.target, th)
+		if err != nil {
+			return nil, err
+		}
+		goidv, err := scope.EvalExpression("gp.goid", proc.LoadConfig{})
+		if err != nil {
+			return nil, err
+		}
+		goid, _ := constant.Int64Val(goidv.Value)
+		closeSpan(th.ThreadID(), now)
+		running[th.ThreadID()] = span{goid: int(goid), start: now}
+	}
+
+	if toEvent >= 0 {
+		now = toEvent
+	}
+	for tid := range running {
+		closeSpan(tid, now)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Ts < events[j].Ts })
+
+	return json.Marshal(struct {
+		TraceEvents []chromeTraceEvent `json:"traceEvents"`
+	}{events})
+}
+
+// currentEvent returns the current rr event number.
+func (d *Debugger) currentEvent() (int64, error) {
+	when, err := d.target.When()
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(when)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("could not parse rr event %q", when)
+	}
+	return strconv.ParseInt(fields[len(fields)-1], 10, 64)
+}
+
 // ListDynamicLibraries returns a list of loaded dynamic libraries.
 func (d *Debugger) ListDynamicLibraries() []*proc.Image {
 	d.targetMutex.Lock()
@@ -1952,6 +4454,40 @@ func (d *Debugger) ListDynamicLibraries() []*proc.Image {
 
 }
 
+// DebuggerStats returns statistics about the memory used by the debugger
+// and about the debug information it loaded.
+func (d *Debugger) DebuggerStats() *api.DebuggerStats {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	bi := d.target.BinInfo()
+	r := &api.DebuggerStats{
+		HeapAlloc: ms.HeapAlloc,
+		HeapSys:   ms.HeapSys,
+		Sys:       ms.Sys,
+		NumGC:     ms.NumGC,
+		Functions: len(bi.Functions),
+		Types:     bi.NumTypes(),
+		Sources:   len(bi.Sources),
+		Images:    make([]api.ImageStats, 0, len(bi.Images)),
+	}
+	for _, image := range bi.Images {
+		stats := image.Stats()
+		r.Images = append(r.Images, api.ImageStats{
+			Path:                  image.Path,
+			CompileUnits:          stats.CompileUnits,
+			TypeCacheEntries:      stats.TypeCacheEntries,
+			DwarfTreeCacheEntries: stats.DwarfTreeCacheEntries,
+			DwarfTreeCacheHits:    stats.DwarfTreeCacheHits,
+			DwarfTreeCacheMisses:  stats.DwarfTreeCacheMisses,
+		})
+	}
+	return r
+}
+
 // ExamineMemory returns the raw memory stored at the given address.
 // The amount of data to be read is specified by length.
 // This function will return an error if it reads less than `length` bytes.
@@ -1971,6 +4507,82 @@ func (d *Debugger) ExamineMemory(address uint64, length int) ([]byte, error) {
 	return data, nil
 }
 
+// MaxReadMemoryLength is the maximum number of bytes that can be read with
+// a single call to ReadMemory.
+const MaxReadMemoryLength = 1024 * 1024
+
+// readMemoryPageSize is the granularity used by ReadMemory to find where
+// the readable part of a memory range ends.
+const readMemoryPageSize = 4096
+
+// ReadMemory reads count bytes of target memory starting at addr. If part
+// of the range can not be read the bytes up to the first unreadable page
+// are returned along with an error.
+func (d *Debugger) ReadMemory(addr uint64, count int) ([]byte, error) {
+	if count <= 0 || count > MaxReadMemoryLength {
+		return nil, fmt.Errorf("invalid count %d, must be between 1 and %d", count, MaxReadMemoryLength)
+	}
+
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	mem := d.target.Memory()
+	data := make([]byte, count)
+	if n, err := mem.ReadMemory(data, addr); err == nil && n == count {
+		return data, nil
+	}
+
+	// Read one page at a time to find the end of the readable range, each
+	// page is either entirely readable or not.
+	n := 0
+	for n < count {
+		sz := readMemoryPageSize - int((addr+uint64(n))%readMemoryPageSize)
+		if sz > count-n {
+			sz = count - n
+		}
+		m, err := mem.ReadMemory(data[n:n+sz], addr+uint64(n))
+		if err == nil && m != sz {
+			err = errors.New("short read")
+		}
+		if err != nil {
+			return data[:n], fmt.Errorf("could not read memory at %#x: %v", addr+uint64(n), err)
+		}
+		n += sz
+	}
+	return data, nil
+}
+
+// WriteMemory writes data to the target memory starting at addr and
+// returns the number of bytes written.
+func (d *Debugger) WriteMemory(addr uint64, data []byte) (int, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return 0, err
+	}
+	n, err := d.target.Memory().WriteMemory(addr, data)
+	if n > 0 {
+		// the write could have changed the runtime data structures we cache
+		d.target.ClearCaches()
+	}
+	return n, err
+}
+
+// MemoryMap returns the memory map of the target process.
+func (d *Debugger) MemoryMap() ([]proc.MemoryMapEntry, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	if _, err := d.target.Valid(); err != nil {
+		return nil, err
+	}
+	return d.target.MemoryMap()
+}
+
 func (d *Debugger) GetVersion(out *api.GetVersionOut) error {
 	if d.config.CoreFile != "" {
 		if d.config.Backend == "rr" {
@@ -1,6 +1,7 @@
 package debugger
 
 import (
+	"errors"
 	"fmt"
 	sys "golang.org/x/sys/unix"
 )
@@ -12,3 +13,11 @@ func attachErrorMessage(pid int, err error) error {
 func stopProcess(pid int) error {
 	return sys.Kill(pid, sys.SIGSTOP)
 }
+
+func signalProcess(pid int, sig int) error {
+	return sys.Kill(pid, sys.Signal(sig))
+}
+
+func findWaitForProcess(waitFor string) (int, error) {
+	return 0, errors.New("waiting for a process to attach to is not supported on freebsd")
+}
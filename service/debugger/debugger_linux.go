@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
 
 	sys "golang.org/x/sys/unix"
@@ -34,3 +36,38 @@ func attachErrorMessage(pid int, err error) error {
 func stopProcess(pid int) error {
 	return sys.Kill(pid, sys.SIGSTOP)
 }
+
+func signalProcess(pid int, sig int) error {
+	return sys.Kill(pid, sys.Signal(sig))
+}
+
+// findWaitForProcess returns the pid of a process matching waitFor, see
+// Config.AttachWaitFor, or 0 if there is none.
+func findWaitForProcess(waitFor string) (int, error) {
+	dir, err := os.Open("/proc")
+	if err != nil {
+		return 0, err
+	}
+	names, err := dir.Readdirnames(-1)
+	dir.Close()
+	if err != nil {
+		return 0, err
+	}
+	self := os.Getpid()
+	for _, name := range names {
+		pid, err := strconv.Atoi(name)
+		if err != nil || pid == self {
+			continue
+		}
+		buf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil || len(buf) == 0 {
+			// the process exited or it's a kernel thread
+			continue
+		}
+		cmdline := strings.Split(strings.TrimSuffix(string(buf), "\x00"), "\x00")
+		if waitForMatches(cmdline, waitFor) {
+			return pid, nil
+		}
+	}
+	return 0, nil
+}
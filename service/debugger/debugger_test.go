@@ -68,3 +68,149 @@ func TestDebugger_LaunchInvalidFormat(t *testing.T) {
 		t.Fatalf("expected error \"%s\" got \"%v\"", api.ErrNotExecutable, err)
 	}
 }
+
+func TestParseModInfo(t *testing.T) {
+	const sentinel = "0123456789abcdef"
+	modinfo := sentinel + "path\texample.com/cmd/prog\n" +
+		"mod\texample.com\tv1.2.3\th1:abc=\n" +
+		"dep\tgolang.org/x/sys\tv0.1.0\th1:def=\n" +
+		"dep\texample.com/lib\tv0.0.1\n" +
+		"=>\t../lib\t(devel)\t\n" +
+		"build\t-compiler=gc\n" +
+		"build\t\"key with space\"=\"quoted value\"\n" +
+		"build\tvcs.revision=0123abcd\n" +
+		"build\tvcs.time=2022-01-02T03:04:05Z\n" +
+		"build\tvcs.modified=true\n" + sentinel
+
+	bi := &api.BuildInfo{GoVersion: "go1.18"}
+	parseModInfo(bi, modinfo)
+
+	if bi.Path != "example.com/cmd/prog" {
+		t.Errorf("wrong path %q", bi.Path)
+	}
+	if bi.Main != (api.Module{Path: "example.com", Version: "v1.2.3", Sum: "h1:abc="}) {
+		t.Errorf("wrong main module %#v", bi.Main)
+	}
+	if len(bi.Deps) != 2 {
+		t.Fatalf("wrong number of dependencies %d", len(bi.Deps))
+	}
+	if bi.Deps[0].Replace != nil || bi.Deps[1].Replace == nil || bi.Deps[1].Replace.Path != "../lib" {
+		t.Errorf("wrong replacements %#v %#v", bi.Deps[0].Replace, bi.Deps[1].Replace)
+	}
+	for _, tc := range []struct{ key, value string }{
+		{"-compiler", "gc"},
+		{"key with space", "quoted value"},
+		{"vcs.revision", "0123abcd"},
+		{"vcs.time", "2022-01-02T03:04:05Z"},
+		{"vcs.modified", "true"},
+	} {
+		if v := bi.Setting(tc.key); v != tc.value {
+			t.Errorf("wrong value for setting %q: %q (expected %q)", tc.key, v, tc.value)
+		}
+	}
+}
+
+func TestExpandEvalAliases(t *testing.T) {
+	aliases := map[string]string{
+		"buf":   `(*(*"[]byte")(0x1234))`,
+		"tail":  "@buf[64:]",
+		"self":  "@self + 1",
+		"loop1": "@loop2",
+		"loop2": "@loop1",
+	}
+	for _, tc := range []struct {
+		in, tgt string
+		err     string
+	}{
+		{"a + b", "a + b", ""},
+		{"@buf", `((*(*"[]byte")(0x1234)))`, ""},
+		{"len(@tail) + 1", `len((((*(*"[]byte")(0x1234)))[64:])) + 1`, ""},
+		{`"@buf" + s`, `"@buf" + s`, ""},
+		{"@ buf", "@ buf", ""},
+		{"@nothere", "", "unknown alias @nothere"},
+		{"@self", "", "alias cycle: @self -> @self"},
+		{"@loop1", "", "alias cycle: @loop1 -> @loop2 -> @loop1"},
+	} {
+		out, err := expandEvalAliases(tc.in, aliases, nil)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("expandEvalAliases(%q): expected error %q got %v", tc.in, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandEvalAliases(%q): unexpected error %v", tc.in, err)
+			continue
+		}
+		if out != tc.tgt {
+			t.Errorf("expandEvalAliases(%q) = %q, expected %q", tc.in, out, tc.tgt)
+		}
+	}
+}
+
+func TestParseTraceMessage(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		format string
+		args   []string
+		err    bool
+	}{
+		{`"hello"`, "hello", []string{}, false},
+		{`"handling %d from %s", id, name`, "handling %d from %s", []string{"id", "name"}, false},
+		{"`%v`, a.b[1], f(x, y)", "%v", []string{"a.b[1]", "f(x, y)"}, false},
+		{`"%d\n", len(s)+1`, "%d\n", []string{"len(s)+1"}, false},
+		{`x, y`, "", nil, true},
+		{`"a") + f("b"`, "", nil, true},
+		{`"%d", x...`, "", nil, true},
+		{``, "", nil, true},
+	} {
+		format, args, err := parseTraceMessage(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("parseTraceMessage(%q): expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTraceMessage(%q): unexpected error %v", tc.in, err)
+			continue
+		}
+		if format != tc.format || fmt.Sprint(args) != fmt.Sprint(tc.args) {
+			t.Errorf("parseTraceMessage(%q) = %q %q, expected %q %q", tc.in, format, args, tc.format, tc.args)
+		}
+	}
+}
+
+func TestWaitForMatches(t *testing.T) {
+	for _, tc := range []struct {
+		cmdline []string
+		waitFor string
+		tgt     bool
+	}{
+		{[]string{"/usr/bin/myserver", "-port", "80"}, "myserver", true},
+		{[]string{"/usr/bin/myserver", "-port", "80"}, "/usr/bin/myserver -port", true},
+		{[]string{"/usr/bin/myserver", "-port", "80"}, "/usr/bin/myserver -port 80", true},
+		{[]string{"/usr/bin/myserver", "-port", "80"}, "/usr/bin/myserver -po", false},
+		{[]string{"/usr/bin/myserver", "-port", "80"}, "/usr/bin/my", false},
+		{[]string{"/usr/bin/myserver", "-port", "80"}, "server", false},
+		{[]string{"/usr/bin/myserver2"}, "myserver", false},
+		{[]string{"tail", "myserver.log"}, "myserver", false},
+		{[]string{}, "myserver", false},
+	} {
+		if out := waitForMatches(tc.cmdline, tc.waitFor); out != tc.tgt {
+			t.Errorf("waitForMatches(%q, %q) = %v, expected %v", tc.cmdline, tc.waitFor, out, tc.tgt)
+		}
+	}
+}
+
+func TestEnvOverlay(t *testing.T) {
+	overlay := mergeEnvOverlay([]string{"A=1", "B=2", "C"}, []string{"B", "C=3", "D=4"})
+	if tgt := []string{"A=1", "B", "C=3", "D=4"}; fmt.Sprint(overlay) != fmt.Sprint(tgt) {
+		t.Errorf("mergeEnvOverlay: got %q expected %q", overlay, tgt)
+	}
+
+	env := applyEnvOverlay([]string{"A=0", "B=0", "E=0"}, overlay)
+	if tgt := []string{"E=0", "A=1", "C=3", "D=4"}; fmt.Sprint(env) != fmt.Sprint(tgt) {
+		t.Errorf("applyEnvOverlay: got %q expected %q", env, tgt)
+	}
+}
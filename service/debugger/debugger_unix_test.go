@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/creack/pty"
 	"github.com/go-delve/delve/pkg/gobuild"
@@ -95,3 +96,40 @@ func TestDebugger_LaunchWithTTY(t *testing.T) {
 		t.Fatal("process open file list does not contain expected tty")
 	}
 }
+
+func TestDebugger_AttachWaitFor(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("waiting for a process is only supported on linux")
+	}
+
+	d := &Debugger{config: &Config{AttachWaitFor: "sleep 123.25", AttachWaitForDuration: 10 * time.Second}}
+	cmdch := make(chan *exec.Cmd, 1)
+	time.AfterFunc(100*time.Millisecond, func() {
+		cmd := exec.Command("sleep", "123.25")
+		if err := cmd.Start(); err != nil {
+			t.Error(err)
+			cmd = nil
+		}
+		cmdch <- cmd
+	})
+	pid, err := d.waitForProcess()
+	cmd := <-cmdch
+	if cmd == nil {
+		t.FailNow()
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	if err != nil {
+		t.Fatalf("waitForProcess: %v", err)
+	}
+	if pid != cmd.Process.Pid {
+		t.Fatalf("wrong pid %d, expected %d", pid, cmd.Process.Pid)
+	}
+
+	d = &Debugger{config: &Config{AttachWaitFor: "nonexistent-process-name", AttachWaitForDuration: 50 * time.Millisecond}}
+	if _, err := d.waitForProcess(); err == nil {
+		t.Fatal("waitForProcess returned without a matching process")
+	}
+}
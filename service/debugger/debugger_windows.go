@@ -2,6 +2,7 @@ package debugger
 
 import (
 	"debug/pe"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -21,6 +22,10 @@ func stopProcess(pid int) error {
 	return nil
 }
 
+func signalProcess(pid int, sig int) error {
+	return errors.New("sending signals is not supported on windows")
+}
+
 func verifyBinaryFormat(exePath string) error {
 	f, err := os.Open(exePath)
 	if err != nil {
@@ -40,3 +45,7 @@ func verifyBinaryFormat(exePath string) error {
 	}
 	return nil
 }
+
+func findWaitForProcess(waitFor string) (int, error) {
+	return 0, errors.New("waiting for a process to attach to is not supported on windows")
+}
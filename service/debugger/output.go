@@ -0,0 +1,126 @@
+package debugger
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// outputPollInterval is how often the files stdout and stderr of a running
+// target are written to are checked, see watchOutput.
+const outputPollInterval = 100 * time.Millisecond
+
+// outputStream follows the file that stdout or stderr of the target is
+// written to, see Config.StreamOutput.
+type outputStream struct {
+	path string
+	temp bool // the file was created by the debugger and is removed on detach
+	off  int64
+}
+
+// read returns what was written to the file since the last call.
+func (s *outputStream) read() []byte {
+	if s == nil {
+		return nil
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	buf, _ := ioutil.ReadAll(io.NewSectionReader(f, s.off, math.MaxInt64-s.off))
+	s.off += int64(len(buf))
+	return buf
+}
+
+// pending returns true if something was written to the file since the
+// last call to read.
+func (s *outputStream) pending() bool {
+	if s == nil {
+		return false
+	}
+	fi, err := os.Stat(s.path)
+	return err == nil && fi.Size() > s.off
+}
+
+// watchOutput stops the target, while it is running, as soon as it writes
+// to stdout or stderr so that the output can be reported to the client.
+// The returned function must be called after the target stops, it stops
+// watching and returns true if the target was stopped by watchOutput and
+// not by a Halt command.
+func (d *Debugger) watchOutput() func() bool {
+	if !d.config.StreamOutput {
+		return func() bool { return false }
+	}
+	done := make(chan struct{})
+	stopped := make(chan bool, 1)
+	go func() {
+		ticker := time.NewTicker(outputPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				stopped <- false
+				return
+			case <-ticker.C:
+				if d.outputStreams[0].pending() || d.outputStreams[1].pending() {
+					d.target.RequestManualStop()
+					stopped <- true
+					return
+				}
+			}
+		}
+	}()
+	return func() bool {
+		close(done)
+		r := <-stopped
+		halted := atomic.SwapInt32(&d.haltRequested, 0) != 0
+		return r && !halted
+	}
+}
+
+// launchRedirects returns the redirects used to launch the target. When
+// output streaming is enabled stdout and stderr, if they are not redirected
+// to a file already, are redirected to temporary files so that their
+// contents can be reported to the client.
+func (d *Debugger) launchRedirects() ([3]string, error) {
+	redirects := d.config.Redirects
+	if !d.config.StreamOutput {
+		return redirects, nil
+	}
+	d.closeOutputStreams()
+	for i := range d.outputStreams {
+		s := &outputStream{path: redirects[i+1]}
+		if s.path == "" {
+			f, err := ioutil.TempFile("", "dlv-output-")
+			if err != nil {
+				return redirects, err
+			}
+			f.Close()
+			s.path, s.temp = f.Name(), true
+			redirects[i+1] = s.path
+		}
+		d.outputStreams[i] = s
+	}
+	return redirects, nil
+}
+
+// readOutput returns what the target wrote to stdout and stderr since the
+// last call, it returns nil if output streaming is disabled.
+func (d *Debugger) readOutput() (stdout, stderr []byte) {
+	return d.outputStreams[0].read(), d.outputStreams[1].read()
+}
+
+// closeOutputStreams stops following stdout and stderr of the target and
+// removes the temporary files created by launchRedirects.
+func (d *Debugger) closeOutputStreams() {
+	for i, s := range d.outputStreams {
+		if s != nil && s.temp {
+			os.Remove(s.path)
+		}
+		d.outputStreams[i] = nil
+	}
+}
@@ -42,7 +42,7 @@ func (s *RPCServer) Restart(arg1 interface{}, arg2 *int) error {
 	if s.config.Debugger.AttachPid != 0 {
 		return errors.New("cannot restart process Delve did not create")
 	}
-	_, err := s.debugger.Restart(false, "", false, nil, [3]string{}, false)
+	_, _, err := s.debugger.Restart(false, "", false, nil, [3]string{}, nil, false)
 	return err
 }
 
@@ -212,7 +212,7 @@ func (s *RPCServer) ListRegisters(arg interface{}, registers *string) error {
 }
 
 func (s *RPCServer) ListLocalVars(scope api.EvalScope, variables *[]api.Variable) error {
-	vars, err := s.debugger.LocalVariables(scope.GoroutineID, scope.Frame, scope.DeferredCall, defaultLoadConfig)
+	vars, err := s.debugger.LocalVariables(scope.GoroutineID, scope.Frame, scope.DeferredCall, "", defaultLoadConfig)
 	if err != nil {
 		return err
 	}
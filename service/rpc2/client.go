@@ -1,11 +1,14 @@
 package rpc2
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/rpc"
 	"net/rpc/jsonrpc"
+	"sync"
 	"time"
 
 	"github.com/go-delve/delve/service"
@@ -17,6 +20,8 @@ type RPCClient struct {
 	client *rpc.Client
 
 	retValLoadCfg *api.LoadConfig
+	readLimit     int
+	outputRadix   int
 }
 
 // Ensure the implementation satisfies the interface.
@@ -57,21 +62,36 @@ func (c *RPCClient) LastModified() time.Time {
 func (c *RPCClient) Detach(kill bool) error {
 	defer c.client.Close()
 	out := new(DetachOut)
-	return c.call("Detach", DetachIn{kill}, out)
+	return c.call("Detach", DetachIn{kill, 0}, out)
+}
+
+func (c *RPCClient) DetachWithSignal(sig int) error {
+	defer c.client.Close()
+	out := new(DetachOut)
+	return c.call("Detach", DetachIn{Signal: sig}, out)
 }
 
 func (c *RPCClient) Restart(rebuild bool) ([]api.DiscardedBreakpoint, error) {
 	out := new(RestartOut)
-	err := c.call("Restart", RestartIn{"", false, nil, false, rebuild, [3]string{}}, out)
+	err := c.call("Restart", RestartIn{"", false, nil, false, rebuild, [3]string{}, nil}, out)
 	return out.DiscardedBreakpoints, err
 }
 
 func (c *RPCClient) RestartFrom(rerecord bool, pos string, resetArgs bool, newArgs []string, newRedirects [3]string, rebuild bool) ([]api.DiscardedBreakpoint, error) {
 	out := new(RestartOut)
-	err := c.call("Restart", RestartIn{pos, resetArgs, newArgs, rerecord, rebuild, newRedirects}, out)
+	err := c.call("Restart", RestartIn{pos, resetArgs, newArgs, rerecord, rebuild, newRedirects, nil}, out)
 	return out.DiscardedBreakpoints, err
 }
 
+// RestartWithEnv restarts the program applying the entries of newEnv to
+// its environment, see RestartIn.NewEnv, and returns the environment the
+// program was launched with.
+func (c *RPCClient) RestartWithEnv(newEnv []string, rebuild bool) ([]api.DiscardedBreakpoint, []string, error) {
+	out := new(RestartOut)
+	err := c.call("Restart", RestartIn{Rebuild: rebuild, NewEnv: newEnv}, out)
+	return out.DiscardedBreakpoints, out.Env, err
+}
+
 func (c *RPCClient) GetState() (*api.DebuggerState, error) {
 	var out StateOut
 	err := c.call("State", StateIn{NonBlocking: false}, &out)
@@ -96,12 +116,22 @@ func (c *RPCClient) DirectionCongruentContinue() <-chan *api.DebuggerState {
 	return c.continueDir(api.DirectionCongruentContinue)
 }
 
+// ContinueSkippingTracepoints is like Continue but the target will not
+// stop on tracepoints, see api.DebuggerCommand.SkipTracepoints.
+func (c *RPCClient) ContinueSkippingTracepoints() <-chan *api.DebuggerState {
+	return c.continueDirSkipping(api.Continue, true)
+}
+
 func (c *RPCClient) continueDir(cmd string) <-chan *api.DebuggerState {
+	return c.continueDirSkipping(cmd, false)
+}
+
+func (c *RPCClient) continueDirSkipping(cmd string, skipTracepoints bool) <-chan *api.DebuggerState {
 	ch := make(chan *api.DebuggerState)
 	go func() {
 		for {
 			out := new(CommandOut)
-			err := c.call("Command", &api.DebuggerCommand{Name: cmd, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+			err := c.call("Command", &api.DebuggerCommand{Name: cmd, ReturnInfoLoadConfig: c.retValLoadCfg, SkipTracepoints: skipTracepoints}, &out)
 			state := out.State
 			if err != nil {
 				state.Err = err
@@ -116,12 +146,20 @@ func (c *RPCClient) continueDir(cmd string) <-chan *api.DebuggerState {
 				return
 			}
 
+			if state.OutputInProgress {
+				continue
+			}
+
 			isbreakpoint := false
 			istracepoint := true
 			for i := range state.Threads {
 				if state.Threads[i].Breakpoint != nil {
 					isbreakpoint = true
 					istracepoint = istracepoint && (state.Threads[i].Breakpoint.Tracepoint || state.Threads[i].Breakpoint.TraceReturn)
+					if bpi := state.Threads[i].BreakpointInfo; bpi != nil && bpi.AssertError != "" {
+						// failed assertions always stop the target
+						istracepoint = false
+					}
 				}
 			}
 
@@ -134,6 +172,114 @@ func (c *RPCClient) continueDir(cmd string) <-chan *api.DebuggerState {
 	return ch
 }
 
+// Trace creates a tracepoint for each element of specs, resumes the
+// target and returns a channel of the tracepoint hits.
+// The channel is closed when the target stops for any other reason, for
+// example because it exited, it hit a breakpoint or Halt was called; the
+// tracepoints created by Trace are cleared before the channel is closed.
+// Calling the returned stop function halts the target, if it is running,
+// and blocks until the channel is closed; events that were not received
+// yet are discarded.
+func (c *RPCClient) Trace(specs []api.TracepointSpec) (<-chan api.TraceEvent, func(), error) {
+	tracepoints := make(map[int]bool, len(specs))
+	clearTracepoints := func() {
+		for id := range tracepoints {
+			c.ClearBreakpoint(id)
+		}
+	}
+	for _, spec := range specs {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{
+			FunctionName: spec.FunctionName,
+			File:         spec.File,
+			Line:         spec.Line,
+			Tracepoint:   true,
+			Variables:    spec.Variables,
+			LoadArgs:     spec.LoadArgs,
+		})
+		if err != nil {
+			clearTracepoints()
+			return nil, nil, err
+		}
+		tracepoints[bp.ID] = true
+	}
+
+	ch := make(chan api.TraceEvent)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(ch)
+		defer clearTracepoints()
+		for {
+			// The target is not resumed once stop was called, so that the
+			// Halt sent by stop can not be lost between two Continue
+			// commands.
+			select {
+			case <-done:
+				return
+			default:
+			}
+			var out CommandOut
+			err := c.call("Command", &api.DebuggerCommand{Name: api.Continue, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+			state := out.State
+			if err != nil || state.Exited {
+				return
+			}
+			if state.OutputInProgress {
+				continue
+			}
+			hit := false
+			for _, th := range state.Threads {
+				if th.Breakpoint == nil {
+					continue
+				}
+				if !(th.Breakpoint.Tracepoint || th.Breakpoint.TraceReturn) || (th.BreakpointInfo != nil && th.BreakpointInfo.AssertError != "") {
+					// the target stopped for some other reason
+					return
+				}
+				hit = true
+				if !tracepoints[th.Breakpoint.ID] {
+					continue
+				}
+				ev := api.TraceEvent{
+					BreakpointID: th.Breakpoint.ID,
+					File:         th.File,
+					Line:         th.Line,
+					GoroutineID:  th.GoroutineID,
+				}
+				if th.Function != nil {
+					ev.Function = th.Function.Name()
+				}
+				if th.BreakpointInfo != nil {
+					ev.Args = th.BreakpointInfo.Arguments
+					ev.Variables = th.BreakpointInfo.Variables
+					ev.Time = th.BreakpointInfo.Time
+				}
+				select {
+				case ch <- ev:
+				case <-done:
+				}
+			}
+			if !hit {
+				return
+			}
+		}
+	}()
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() {
+			close(done)
+			select {
+			case <-finished:
+			default:
+				c.Halt()
+			}
+		})
+		<-finished
+	}
+	return ch, stop, nil
+}
+
 func (c *RPCClient) Next() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.Next, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
@@ -152,6 +298,14 @@ func (c *RPCClient) Step() (*api.DebuggerState, error) {
 	return &out.State, err
 }
 
+// StepExcluding is like Step but calls to functions belonging to one of
+// the packages in excludedPkgs are stepped over.
+func (c *RPCClient) StepExcluding(excludedPkgs []string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.Step, ReturnInfoLoadConfig: c.retValLoadCfg, StepExcludingPackages: excludedPkgs}, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) ReverseStep() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseStep, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
@@ -164,6 +318,39 @@ func (c *RPCClient) StepOut() (*api.DebuggerState, error) {
 	return &out.State, err
 }
 
+// StepOutToUserCode steps out of the current function until a function that
+// does not belong to the standard library is reached.
+func (c *RPCClient) StepOutToUserCode() (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.StepOutToUserCode, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	return &out.State, err
+}
+
+func (c *RPCClient) StepToLine(file string, line int) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.StepToLine, File: file, Line: line, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	return &out.State, err
+}
+
+func (c *RPCClient) ContinueWithSignal(sig int) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.ContinueWithSignal, Signal: sig, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	return &out.State, err
+}
+
+// SetStopSignals sets the signals that stop the target when they are
+// received by one of its threads.
+func (c *RPCClient) SetStopSignals(sigs []int) error {
+	var out SetStopSignalsOut
+	return c.call("SetStopSignals", SetStopSignalsIn{sigs}, &out)
+}
+
+func (c *RPCClient) ContinueToFunctions(names []string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.ContinueToFunctions, Functions: names, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) ReverseStepOut() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseStepOut, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
@@ -176,12 +363,42 @@ func (c *RPCClient) Call(goroutineID int, expr string, unsafe bool) (*api.Debugg
 	return &out.State, err
 }
 
+// CallMethod calls method on the value of receiverExpr with arguments
+// args, see RPCServer.CallMethod.
+func (c *RPCClient) CallMethod(scope api.EvalScope, receiverExpr, method string, args []string) (*api.DebuggerState, error) {
+	var out CallMethodOut
+	err := c.call("CallMethod", CallMethodIn{Scope: scope, Receiver: receiverExpr, Method: method, Args: args, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	return &out.State, err
+}
+
+// SetNextStatement moves the program counter of goroutine
+// scope.GoroutineID to file:line, see RPCServer.SetNextStatement.
+func (c *RPCClient) SetNextStatement(scope api.EvalScope, file string, line int) (*api.DebuggerState, error) {
+	var out SetNextStatementOut
+	err := c.call("SetNextStatement", SetNextStatementIn{Scope: scope, File: file, Line: line}, &out)
+	return &out.State, err
+}
+
+// CallWithTimeout is like Call but aborts the call and returns an error if
+// it does not return within timeout, see api.DebuggerCommand.CallTimeout.
+func (c *RPCClient) CallWithTimeout(goroutineID int, expr string, unsafe bool, timeout time.Duration) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.Call, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: expr, UnsafeCall: unsafe, GoroutineID: goroutineID, CallTimeout: timeout}, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) StepInstruction() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.StepInstruction}, &out)
 	return &out.State, err
 }
 
+func (c *RPCClient) StepInstructions(n int) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.StepInstruction, Count: n}, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) ReverseStepInstruction() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseStepInstruction}, &out)
@@ -208,6 +425,34 @@ func (c *RPCClient) SwitchGoroutine(goroutineID int) (*api.DebuggerState, error)
 	return &out.State, err
 }
 
+// ReferencesTo returns up to maxResults locations, on the stacks of
+// goroutines, where the value addr is stored.
+func (c *RPCClient) ReferencesTo(addr uint64, maxResults int) ([]api.Reference, error) {
+	var out ReferencesToOut
+	err := c.call("ReferencesTo", ReferencesToIn{addr, maxResults}, &out)
+	return out.References, err
+}
+
+// ParkGoroutine prevents goroutine gid from running when the target is
+// continued.
+func (c *RPCClient) ParkGoroutine(gid int) error {
+	var out ParkGoroutineOut
+	return c.call("ParkGoroutine", ParkGoroutineIn{gid}, &out)
+}
+
+// UnparkGoroutine lets goroutine gid run again.
+func (c *RPCClient) UnparkGoroutine(gid int) error {
+	var out UnparkGoroutineOut
+	return c.call("UnparkGoroutine", UnparkGoroutineIn{gid}, &out)
+}
+
+// SetGoroutineLabels attaches synthetic labels to goroutine gid, they are
+// only seen by delve and not written to the target.
+func (c *RPCClient) SetGoroutineLabels(gid int, labels map[string]string) error {
+	var out SetGoroutineLabelsOut
+	return c.call("SetGoroutineLabels", SetGoroutineLabelsIn{gid, labels}, &out)
+}
+
 func (c *RPCClient) Halt() (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.Halt}, &out)
@@ -236,6 +481,20 @@ func (c *RPCClient) CreateBreakpoint(breakPoint *api.Breakpoint) (*api.Breakpoin
 	return &out.Breakpoint, err
 }
 
+// CreateBreakpointsInPackage sets a tracepoint on every function of a
+// package, see the documentation for `Debugger.CreateBreakpointsInPackage`.
+func (c *RPCClient) CreateBreakpointsInPackage(pkg string, entryOnly bool) ([]*api.Breakpoint, error) {
+	var out CreateBreakpointsInPackageOut
+	err := c.call("CreateBreakpointsInPackage", CreateBreakpointsInPackageIn{pkg, entryOnly}, &out)
+	return out.Breakpoints, err
+}
+
+func (c *RPCClient) BreakOnNextPanic(goroutineID int) ([]int, error) {
+	var out BreakOnNextPanicOut
+	err := c.call("BreakOnNextPanic", BreakOnNextPanicIn{goroutineID}, &out)
+	return out.Goroutines, err
+}
+
 func (c *RPCClient) CreateWatchpoint(scope api.EvalScope, expr string, wtype api.WatchType) (*api.Breakpoint, error) {
 	var out CreateWatchpointOut
 	err := c.call("CreateWatchpoint", CreateWatchpointIn{scope, expr, wtype}, &out)
@@ -248,6 +507,18 @@ func (c *RPCClient) ListBreakpoints() ([]*api.Breakpoint, error) {
 	return out.Breakpoints, err
 }
 
+func (c *RPCClient) BreakpointHitStats() ([]api.BreakpointStat, error) {
+	var out BreakpointHitStatsOut
+	err := c.call("BreakpointHitStats", BreakpointHitStatsIn{}, &out)
+	return out.Stats, err
+}
+
+func (c *RPCClient) BreakpointsSharingAddress() (map[uint64][]int, error) {
+	var out BreakpointsSharingAddressOut
+	err := c.call("BreakpointsSharingAddress", BreakpointsSharingAddressIn{}, &out)
+	return out.Breakpoints, err
+}
+
 func (c *RPCClient) ClearBreakpoint(id int) (*api.Breakpoint, error) {
 	var out ClearBreakpointOut
 	err := c.call("ClearBreakpoint", ClearBreakpointIn{id, ""}, &out)
@@ -272,10 +543,39 @@ func (c *RPCClient) ToggleBreakpointByName(name string) (*api.Breakpoint, error)
 	return out.Breakpoint, err
 }
 
+func (c *RPCClient) EnableBreakpoint(id int) (*api.Breakpoint, error) {
+	var out EnableBreakpointOut
+	err := c.call("EnableBreakpoint", EnableBreakpointIn{id}, &out)
+	return out.Breakpoint, err
+}
+
+func (c *RPCClient) DisableBreakpoint(id int) (*api.Breakpoint, error) {
+	var out DisableBreakpointOut
+	err := c.call("DisableBreakpoint", DisableBreakpointIn{id}, &out)
+	return out.Breakpoint, err
+}
+
+func (c *RPCClient) GetGoroutineDump(id int) (*api.GoroutineDump, error) {
+	var out GetGoroutineDumpOut
+	err := c.call("GetGoroutineDump", GetGoroutineDumpIn{id}, &out)
+	return out.Dump, err
+}
+
+func (c *RPCClient) GoroutineStacktraceDiff(prev []api.GoroutineStacktrace) (*api.GoroutineDiff, error) {
+	var out GoroutineStacktraceDiffOut
+	err := c.call("GoroutineStacktraceDiff", GoroutineStacktraceDiffIn{prev}, &out)
+	return out.Diff, err
+}
+
 func (c *RPCClient) AmendBreakpoint(bp *api.Breakpoint) error {
+	_, err := c.AmendBreakpointEx(bp)
+	return err
+}
+
+func (c *RPCClient) AmendBreakpointEx(bp *api.Breakpoint) (*api.Breakpoint, error) {
 	out := new(AmendBreakpointOut)
 	err := c.call("AmendBreakpoint", AmendBreakpointIn{*bp}, out)
-	return err
+	return &out.Breakpoint, err
 }
 
 func (c *RPCClient) CancelNext() error {
@@ -289,6 +589,12 @@ func (c *RPCClient) ListThreads() ([]*api.Thread, error) {
 	return out.Threads, err
 }
 
+func (c *RPCClient) ListThreadsWithFilter(filter api.ThreadFilter) ([]*api.Thread, error) {
+	var out ListThreadsOut
+	err := c.call("ListThreads", ListThreadsIn{filter}, &out)
+	return out.Threads, err
+}
+
 func (c *RPCClient) GetThread(id int) (*api.Thread, error) {
 	var out GetThreadOut
 	err := c.call("GetThread", GetThreadIn{id}, &out)
@@ -298,9 +604,64 @@ func (c *RPCClient) GetThread(id int) (*api.Thread, error) {
 func (c *RPCClient) EvalVariable(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.Variable, error) {
 	var out EvalOut
 	err := c.call("Eval", EvalIn{scope, expr, &cfg}, &out)
+	if out.Variable != nil {
+		out.Variable.FormatIntegers(c.outputRadix)
+	}
 	return out.Variable, err
 }
 
+func (c *RPCClient) EvalAcrossGoroutines(expr string, cfg api.LoadConfig) (map[int]*api.Variable, error) {
+	var out EvalAcrossGoroutinesOut
+	err := c.call("EvalAcrossGoroutines", EvalAcrossGoroutinesIn{expr, &cfg}, &out)
+	for _, v := range out.Variables {
+		v.FormatIntegers(c.outputRadix)
+	}
+	return out.Variables, err
+}
+
+// WhatIs returns the type of expr evaluated in scope.
+func (c *RPCClient) WhatIs(scope api.EvalScope, expr string) (*api.TypeInfo, error) {
+	var out WhatIsOut
+	err := c.call("WhatIs", WhatIsIn{scope, expr}, &out)
+	return out.TypeInfo, err
+}
+
+// EvalToJSON evaluates expr and returns its value as a JSON tree, see
+// api.JSONValue.
+func (c *RPCClient) EvalToJSON(scope api.EvalScope, expr string, cfg api.LoadConfig) (json.RawMessage, error) {
+	var out EvalToJSONOut
+	err := c.call("EvalToJSON", EvalToJSONIn{scope, expr, &cfg}, &out)
+	return out.Value, err
+}
+
+// SetEvalAlias defines an alias for expr, that can be used as @name in
+// the expressions passed to EvalVariable. An empty expr removes the alias.
+func (c *RPCClient) SetEvalAlias(name, expr string) error {
+	var out SetEvalAliasOut
+	return c.call("SetEvalAlias", SetEvalAliasIn{name, expr}, &out)
+}
+
+// AddWatch registers expr, to be evaluated in scope every time the state of
+// the debugger is returned, and returns the ID of the watch.
+func (c *RPCClient) AddWatch(scope api.EvalScope, expr string) (int, error) {
+	var out AddWatchOut
+	err := c.call("AddWatch", AddWatchIn{scope, expr}, &out)
+	return out.ID, err
+}
+
+// RemoveWatch removes the watch with the specified ID.
+func (c *RPCClient) RemoveWatch(id int) error {
+	var out RemoveWatchOut
+	return c.call("RemoveWatch", RemoveWatchIn{id}, &out)
+}
+
+// ListEvalAliases returns all aliases defined with SetEvalAlias.
+func (c *RPCClient) ListEvalAliases() ([]api.EvalAlias, error) {
+	var out ListEvalAliasesOut
+	err := c.call("ListEvalAliases", ListEvalAliasesIn{}, &out)
+	return out.Aliases, err
+}
+
 func (c *RPCClient) SetVariable(scope api.EvalScope, symbol, value string) error {
 	out := new(SetOut)
 	return c.call("Set", SetIn{scope, symbol, value}, out)
@@ -312,6 +673,18 @@ func (c *RPCClient) ListSources(filter string) ([]string, error) {
 	return sources.Sources, err
 }
 
+func (c *RPCClient) SourceLines(file string, start, end int, substitutePathRules [][2]string) ([]string, error) {
+	var out SourceLinesOut
+	err := c.call("SourceLines", SourceLinesIn{file, start, end, substitutePathRules}, &out)
+	return out.Lines, err
+}
+
+func (c *RPCClient) ListSourcesGrouped(filter string) (map[string][]string, error) {
+	sources := new(ListSourcesGroupedOut)
+	err := c.call("ListSourcesGrouped", ListSourcesGroupedIn{filter}, sources)
+	return sources.Sources, err
+}
+
 func (c *RPCClient) ListFunctions(filter string) ([]string, error) {
 	funcs := new(ListFunctionsOut)
 	err := c.call("ListFunctions", ListFunctionsIn{filter}, funcs)
@@ -324,15 +697,34 @@ func (c *RPCClient) ListTypes(filter string) ([]string, error) {
 	return types.Types, err
 }
 
+func (c *RPCClient) SetDefaultLoadConfig(cfg api.LoadConfig) error {
+	return c.call("SetDefaultLoadConfig", SetDefaultLoadConfigIn{&cfg}, &SetDefaultLoadConfigOut{})
+}
+
+func (c *RPCClient) FindType(name string) (*api.TypeDefinition, error) {
+	var out FindTypeOut
+	err := c.call("FindType", FindTypeIn{name}, &out)
+	return out.Type, err
+}
+
 func (c *RPCClient) ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error) {
 	var out ListPackageVarsOut
 	err := c.call("ListPackageVars", ListPackageVarsIn{filter, cfg}, &out)
+	c.formatIntegers(out.Variables)
 	return out.Variables, err
 }
 
 func (c *RPCClient) ListLocalVariables(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
 	var out ListLocalVarsOut
-	err := c.call("ListLocalVars", ListLocalVarsIn{scope, cfg}, &out)
+	err := c.call("ListLocalVars", ListLocalVarsIn{scope, cfg, ""}, &out)
+	c.formatIntegers(out.Variables)
+	return out.Variables, err
+}
+
+func (c *RPCClient) ListLocalVariablesFiltered(scope api.EvalScope, filter string, cfg api.LoadConfig) ([]api.Variable, error) {
+	var out ListLocalVarsOut
+	err := c.call("ListLocalVars", ListLocalVarsIn{scope, cfg, filter}, &out)
+	c.formatIntegers(out.Variables)
 	return out.Variables, err
 }
 
@@ -348,18 +740,52 @@ func (c *RPCClient) ListScopeRegisters(scope api.EvalScope, includeFp bool) (api
 	return out.Regs, err
 }
 
+// SetRegister changes the value of a register of the specified thread.
+func (c *RPCClient) SetRegister(threadID int, name, value string) error {
+	var out SetRegisterOut
+	return c.call("SetRegister", SetRegisterIn{threadID, name, value}, &out)
+}
+
 func (c *RPCClient) ListFunctionArgs(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
 	var out ListFunctionArgsOut
 	err := c.call("ListFunctionArgs", ListFunctionArgsIn{scope, cfg}, &out)
+	c.formatIntegers(out.Args)
 	return out.Args, err
 }
 
+func (c *RPCClient) VisibleNames(scope api.EvalScope) ([]string, error) {
+	var out VisibleNamesOut
+	err := c.call("VisibleNames", VisibleNamesIn{scope}, &out)
+	return out.Names, err
+}
+
+func (c *RPCClient) MutexOwner(scope api.EvalScope, expr string) (bool, []*api.Goroutine, error) {
+	var out MutexOwnerOut
+	err := c.call("MutexOwner", MutexOwnerIn{scope, expr}, &out)
+	return out.Locked, out.Candidates, err
+}
+
 func (c *RPCClient) ListGoroutines(start, count int) ([]*api.Goroutine, int, error) {
 	var out ListGoroutinesOut
 	err := c.call("ListGoroutines", ListGoroutinesIn{start, count, nil, api.GoroutineGroupingOptions{}}, &out)
 	return out.Goroutines, out.Nextg, err
 }
 
+func (c *RPCClient) Goroutine(id int) (*api.Goroutine, error) {
+	var out GoroutineOut
+	err := c.call("Goroutine", GoroutineIn{id}, &out)
+	return out.Goroutine, err
+}
+
+func (c *RPCClient) DeferredCalls(scope api.EvalScope, cfg api.LoadConfig) ([]api.DeferredCall, error) {
+	var out DeferredCallsOut
+	err := c.call("DeferredCalls", DeferredCallsIn{scope, &cfg}, &out)
+	for i := range out.DeferredCalls {
+		c.formatIntegers(out.DeferredCalls[i].Args)
+	}
+	return out.DeferredCalls, err
+}
+
 func (c *RPCClient) ListGoroutinesWithFilter(start, count int, filters []api.ListGoroutinesFilter, group *api.GoroutineGroupingOptions) ([]*api.Goroutine, []api.GoroutineGroup, int, bool, error) {
 	if group == nil {
 		group = &api.GoroutineGroupingOptions{}
@@ -372,6 +798,10 @@ func (c *RPCClient) ListGoroutinesWithFilter(start, count int, filters []api.Lis
 func (c *RPCClient) Stacktrace(goroutineId, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error) {
 	var out StacktraceOut
 	err := c.call("Stacktrace", StacktraceIn{goroutineId, depth, false, false, opts, cfg}, &out)
+	for i := range out.Locations {
+		c.formatIntegers(out.Locations[i].Locals)
+		c.formatIntegers(out.Locations[i].Arguments)
+	}
 	return out.Locations, err
 }
 
@@ -381,6 +811,18 @@ func (c *RPCClient) Ancestors(goroutineID int, numAncestors int, depth int) ([]a
 	return out.Ancestors, err
 }
 
+func (c *RPCClient) Children(goroutineID int) ([]*api.Goroutine, error) {
+	var out ChildrenOut
+	err := c.call("Children", ChildrenIn{goroutineID}, &out)
+	return out.Goroutines, err
+}
+
+func (c *RPCClient) CallInjectionStack() ([]api.Stackframe, error) {
+	var out CallInjectionStackOut
+	err := c.call("CallInjectionStack", CallInjectionStackIn{}, &out)
+	return out.Locations, err
+}
+
 func (c *RPCClient) AttachedToExistingProcess() bool {
 	out := new(AttachedToExistingProcessOut)
 	c.call("AttachedToExistingProcess", AttachedToExistingProcessIn{}, out)
@@ -389,7 +831,31 @@ func (c *RPCClient) AttachedToExistingProcess() bool {
 
 func (c *RPCClient) FindLocation(scope api.EvalScope, loc string, findInstructions bool, substitutePathRules [][2]string) ([]api.Location, error) {
 	var out FindLocationOut
-	err := c.call("FindLocation", FindLocationIn{scope, loc, !findInstructions, substitutePathRules}, &out)
+	err := c.call("FindLocation", FindLocationIn{scope, loc, !findInstructions, substitutePathRules, false}, &out)
+	return out.Locations, err
+}
+
+// FindLocationCandidates is like FindLocation but, if loc is ambiguous,
+// returns a location for each candidate, ranked by proximity to the
+// package of the selected frame, instead of an error.
+func (c *RPCClient) FindLocationCandidates(scope api.EvalScope, loc string, findInstructions bool, substitutePathRules [][2]string) ([]api.Location, error) {
+	var out FindLocationOut
+	err := c.call("FindLocation", FindLocationIn{scope, loc, !findInstructions, substitutePathRules, true}, &out)
+	return out.Locations, err
+}
+
+// ResolveAddress returns the file, line, function and symbol of pc.
+func (c *RPCClient) ResolveAddress(pc uint64) (*api.Location, error) {
+	var out ResolveAddressOut
+	err := c.call("ResolveAddress", ResolveAddressIn{pc}, &out)
+	return out.Location, err
+}
+
+// PreviewBreakpoint returns the physical breakpoints that would be created
+// by a breakpoint on loc, without creating them.
+func (c *RPCClient) PreviewBreakpoint(loc string) ([]api.Location, error) {
+	var out PreviewBreakpointOut
+	err := c.call("PreviewBreakpoint", PreviewBreakpointIn{Scope: api.EvalScope{GoroutineID: -1}, Loc: loc}, &out)
 	return out.Locations, err
 }
 
@@ -400,6 +866,13 @@ func (c *RPCClient) DisassembleRange(scope api.EvalScope, startPC, endPC uint64,
 	return out.Disassemble, err
 }
 
+// DisassembleFunction disassembles the function named funcName
+func (c *RPCClient) DisassembleFunction(scope api.EvalScope, funcName string, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
+	var out DisassembleFunctionOut
+	err := c.call("DisassembleFunction", DisassembleFunctionIn{scope, funcName, flavour}, &out)
+	return out.Disassemble, err
+}
+
 // Disassemble function containing pc
 func (c *RPCClient) DisassemblePC(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
 	var out DisassembleOut
@@ -442,16 +915,89 @@ func (c *RPCClient) ClearCheckpoint(id int) error {
 	return err
 }
 
+// LastOperationStats returns the duration and the number of rr events
+// traversed by the last command that resumed the target, or by the one
+// currently running.
+func (c *RPCClient) LastOperationStats() (*api.OperationStats, error) {
+	var out LastOperationStatsOut
+	err := c.call("LastOperationStats", LastOperationStatsIn{}, &out)
+	return out.Stats, err
+}
+
+// ExportChromeTrace returns a Chrome trace of the recording between two rr events.
+func (c *RPCClient) ExportChromeTrace(fromEvent, toEvent int64) ([]byte, error) {
+	var out ExportChromeTraceOut
+	err := c.call("ExportChromeTrace", ExportChromeTraceIn{fromEvent, toEvent}, &out)
+	return out.Trace, err
+}
+
+// ValueHistory returns the values taken by expr over the whole recording.
+func (c *RPCClient) ValueHistory(scope api.EvalScope, expr string, maxSamples int) ([]api.ValueSample, error) {
+	var out ValueHistoryOut
+	err := c.call("ValueHistory", ValueHistoryIn{scope, expr, maxSamples, nil}, &out)
+	return out.Samples, err
+}
+
 func (c *RPCClient) SetReturnValuesLoadConfig(cfg *api.LoadConfig) {
 	c.retValLoadCfg = cfg
 }
 
+// SetOutputRadix sets the base (8, 10 or 16) used to format the values of
+// integer variables returned by EvalVariable, ListLocalVariables,
+// ListFunctionArgs, ListPackageVariables and Stacktrace. Other bases
+// restore the default (base 10).
+func (c *RPCClient) SetOutputRadix(base int) {
+	c.outputRadix = base
+}
+
+func (c *RPCClient) formatIntegers(vars []api.Variable) {
+	for i := range vars {
+		vars[i].FormatIntegers(c.outputRadix)
+	}
+}
+
+// SetReadLimit sets the maximum number of bytes read by ReadString and
+// ReadBytes, zero means the default limit of the server.
+func (c *RPCClient) SetReadLimit(limit int) {
+	c.readLimit = limit
+}
+
+// ReadString returns the full value of expr, which must be a string or a
+// slice or array of bytes, regardless of its length.
+func (c *RPCClient) ReadString(scope api.EvalScope, expr string) (string, error) {
+	b, err := c.ReadBytes(scope, expr)
+	return string(b), err
+}
+
+// ReadBytes returns the full contents of expr, which must be a string or a
+// slice or array of bytes, regardless of its length.
+func (c *RPCClient) ReadBytes(scope api.EvalScope, expr string) ([]byte, error) {
+	var out ReadBytesOut
+	err := c.call("ReadBytes", ReadBytesIn{scope, expr, c.readLimit}, &out)
+	return out.Bytes, err
+}
+
 func (c *RPCClient) FunctionReturnLocations(fnName string) ([]uint64, error) {
 	var out FunctionReturnLocationsOut
 	err := c.call("FunctionReturnLocations", FunctionReturnLocationsIn{fnName}, &out)
 	return out.Addrs, err
 }
 
+// InlinedPackages returns the packages whose functions were inlined into
+// the body of the function fnName.
+func (c *RPCClient) InlinedPackages(fnName string) ([]string, error) {
+	var out InlinedPackagesOut
+	err := c.call("InlinedPackages", InlinedPackagesIn{fnName}, &out)
+	return out.Packages, err
+}
+
+// FunctionLineEntries returns the line number table of the function fnName.
+func (c *RPCClient) FunctionLineEntries(fnName string) ([]api.LineEntry, error) {
+	var out FunctionLineEntriesOut
+	err := c.call("FunctionLineEntries", FunctionLineEntriesIn{fnName}, &out)
+	return out.Entries, err
+}
+
 func (c *RPCClient) IsMulticlient() bool {
 	var out IsMulticlientOut
 	c.call("IsMulticlient", IsMulticlientIn{}, &out)
@@ -468,8 +1014,30 @@ func (c *RPCClient) Disconnect(cont bool) error {
 
 func (c *RPCClient) ListDynamicLibraries() ([]api.Image, error) {
 	var out ListDynamicLibrariesOut
-	c.call("ListDynamicLibraries", ListDynamicLibrariesIn{}, &out)
-	return out.List, nil
+	err := c.call("ListDynamicLibraries", ListDynamicLibrariesIn{}, &out)
+	return out.List, err
+}
+
+// DebuggerStats returns statistics about the memory used by the debugger
+// and the debug information it loaded.
+func (c *RPCClient) DebuggerStats() (*api.DebuggerStats, error) {
+	var out DebuggerStatsOut
+	err := c.call("DebuggerStats", DebuggerStatsIn{}, &out)
+	return out.Stats, err
+}
+
+func (c *RPCClient) BuildInfo() (*api.BuildInfo, error) {
+	var out BuildInfoOut
+	err := c.call("BuildInfo", BuildInfoIn{}, &out)
+	return &out.BuildInfo, err
+}
+
+// ListPackages returns the packages compiled into the target, sorted by
+// import path, with the directory each one was built from.
+func (c *RPCClient) ListPackages() ([]api.PackageBuildInfo, error) {
+	var out ListPackagesBuildInfoOut
+	err := c.call("ListPackagesBuildInfo", ListPackagesBuildInfoIn{}, &out)
+	return out.List, err
 }
 
 func (c *RPCClient) ExamineMemory(address uint64, count int) ([]byte, bool, error) {
@@ -482,6 +1050,30 @@ func (c *RPCClient) ExamineMemory(address uint64, count int) ([]byte, bool, erro
 	return out.Mem, out.IsLittleEndian, nil
 }
 
+func (c *RPCClient) ReadMemory(addr uint64, count int) ([]byte, error) {
+	var out ReadMemoryOut
+	err := c.call("ReadMemory", ReadMemoryIn{addr, count}, &out)
+	if err != nil {
+		return nil, err
+	}
+	if out.Err != "" {
+		return out.Mem, errors.New(out.Err)
+	}
+	return out.Mem, nil
+}
+
+func (c *RPCClient) WriteMemory(addr uint64, data []byte) (int, error) {
+	var out WriteMemoryOut
+	err := c.call("WriteMemory", WriteMemoryIn{addr, data}, &out)
+	return out.Written, err
+}
+
+func (c *RPCClient) ProcessMemoryMaps() ([]api.MemoryMap, error) {
+	var out ProcessMemoryMapsOut
+	err := c.call("ProcessMemoryMaps", ProcessMemoryMapsIn{}, &out)
+	return out.MemoryMaps, err
+}
+
 func (c *RPCClient) StopRecording() error {
 	return c.call("StopRecording", StopRecordingIn{}, &StopRecordingOut{})
 }
@@ -503,6 +1095,25 @@ func (c *RPCClient) CoreDumpCancel() error {
 	return c.call("DumpCancel", DumpCancelIn{}, out)
 }
 
+// DumpCore writes a core dump of the target to dest and waits for it to
+// be completed. An error is returned if the dump fails or is canceled.
+func (c *RPCClient) DumpCore(dest string) error {
+	dumpState, err := c.CoreDumpStart(dest)
+	if err != nil {
+		return err
+	}
+	for dumpState.Dumping {
+		dumpState = c.CoreDumpWait(1000)
+	}
+	switch {
+	case dumpState.Err != "":
+		return errors.New(dumpState.Err)
+	case !dumpState.AllDone:
+		return errors.New("core dump canceled")
+	}
+	return nil
+}
+
 func (c *RPCClient) call(method string, args, reply interface{}) error {
 	return c.client.Call("RPCServer."+method, args, reply)
 }
@@ -1,6 +1,7 @@
 package rpc2
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -18,10 +19,47 @@ type RPCServer struct {
 	config *service.Config
 	// debugger is a debugger service.
 	debugger *debugger.Debugger
+	// defaultLoadConfig is the LoadConfig used by requests that do not
+	// specify one, set by SetDefaultLoadConfig for a single connection.
+	defaultLoadConfig *api.LoadConfig
 }
 
 func NewServer(config *service.Config, debugger *debugger.Debugger) *RPCServer {
-	return &RPCServer{config, debugger}
+	return &RPCServer{config: config, debugger: debugger}
+}
+
+// NewConnection returns a copy of s that holds the state of a single
+// client connection.
+func (s *RPCServer) NewConnection() *RPCServer {
+	return &RPCServer{config: s.config, debugger: s.debugger}
+}
+
+// loadConfig returns cfg or, if cfg is nil, the default LoadConfig of the
+// connection.
+func (s *RPCServer) loadConfig(cfg *api.LoadConfig) *api.LoadConfig {
+	if cfg != nil {
+		return cfg
+	}
+	if s.defaultLoadConfig != nil {
+		return s.defaultLoadConfig
+	}
+	return &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+}
+
+type SetDefaultLoadConfigIn struct {
+	Cfg *api.LoadConfig
+}
+
+type SetDefaultLoadConfigOut struct {
+}
+
+// SetDefaultLoadConfig sets the LoadConfig used, for the rest of the
+// connection, by requests that accept a nil LoadConfig (for example Eval).
+// Other connections are not affected. If arg.Cfg is nil the default
+// LoadConfig of the server is restored.
+func (s *RPCServer) SetDefaultLoadConfig(arg SetDefaultLoadConfigIn, out *SetDefaultLoadConfigOut) error {
+	s.defaultLoadConfig = arg.Cfg
+	return nil
 }
 
 type ProcessPidIn struct {
@@ -51,13 +89,23 @@ func (s *RPCServer) LastModified(arg LastModifiedIn, out *LastModifiedOut) error
 
 type DetachIn struct {
 	Kill bool
+	// Signal, if not zero, is sent to the process after detaching, it can not
+	// be used together with Kill.
+	Signal int
 }
 
 type DetachOut struct {
 }
 
-// Detach detaches the debugger, optionally killing the process.
+// Detach detaches the debugger, optionally killing the process or sending
+// it a signal.
 func (s *RPCServer) Detach(arg DetachIn, out *DetachOut) error {
+	if arg.Signal != 0 {
+		if arg.Kill {
+			return errors.New("can not kill the process and send it a signal")
+		}
+		return s.debugger.DetachWithSignal(arg.Signal)
+	}
 	return s.debugger.Detach(arg.Kill)
 }
 
@@ -79,10 +127,18 @@ type RestartIn struct {
 	Rebuild bool
 
 	NewRedirects [3]string
+
+	// NewEnv is applied on top of the environment used for the previous
+	// launch: entries of the form KEY=VALUE set KEY, entries of the form KEY
+	// remove it. Changes are kept for subsequent restarts.
+	NewEnv []string
 }
 
 type RestartOut struct {
 	DiscardedBreakpoints []api.DiscardedBreakpoint
+	// Env is the environment the target was launched with, nil if it
+	// inherited the environment of Delve.
+	Env []string
 }
 
 // Restart restarts program.
@@ -94,7 +150,7 @@ func (s *RPCServer) Restart(arg RestartIn, cb service.RPCCallback) {
 	}
 	var out RestartOut
 	var err error
-	out.DiscardedBreakpoints, err = s.debugger.Restart(arg.Rerecord, arg.Position, arg.ResetArgs, arg.NewArgs, arg.NewRedirects, arg.Rebuild)
+	out.DiscardedBreakpoints, out.Env, err = s.debugger.Restart(arg.Rerecord, arg.Position, arg.ResetArgs, arg.NewArgs, arg.NewRedirects, arg.NewEnv, arg.Rebuild)
 	cb.Return(out, err)
 }
 
@@ -136,6 +192,73 @@ func (s *RPCServer) Command(command api.DebuggerCommand, cb service.RPCCallback)
 	cb.Return(out, nil)
 }
 
+type CallMethodIn struct {
+	// Scope.GoroutineID is the goroutine the method will be called on, the
+	// receiver is evaluated in its topmost frame.
+	Scope    api.EvalScope
+	Receiver string
+	Method   string
+	Args     []string
+	// UnsafeCall disables parameter escape checking, see api.DebuggerCommand.UnsafeCall.
+	UnsafeCall           bool
+	ReturnInfoLoadConfig *api.LoadConfig
+}
+
+type CallMethodOut struct {
+	State api.DebuggerState
+}
+
+// CallMethod calls the method called Method on the value of the
+// expression Receiver, passing the expressions in Args as arguments.
+// Receiver must not contain function calls. The call is rejected, without
+// resuming the target, if Receiver does not have the method or the method
+// can not be called on it (a value method on a nil pointer or a pointer
+// method on a value that isn't addressable).
+// Once started the call behaves like the Call command.
+func (s *RPCServer) CallMethod(arg CallMethodIn, cb service.RPCCallback) {
+	if arg.Scope.Frame != 0 || arg.Scope.DeferredCall != 0 {
+		cb.Return(nil, errors.New("methods can only be called on the topmost frame of a goroutine"))
+		return
+	}
+	st, err := s.debugger.CallMethod(arg.Scope.GoroutineID, arg.Receiver, arg.Method, arg.Args, arg.UnsafeCall, arg.ReturnInfoLoadConfig, cb.SetupDoneChan())
+	if err != nil {
+		cb.Return(nil, err)
+		return
+	}
+	var out CallMethodOut
+	out.State = *st
+	cb.Return(out, nil)
+}
+
+type SetNextStatementIn struct {
+	// Scope.GoroutineID is the goroutine whose program counter is moved.
+	Scope api.EvalScope
+	File  string
+	Line  int
+}
+
+type SetNextStatementOut struct {
+	State api.DebuggerState
+}
+
+// SetNextStatement moves the program counter of the goroutine to the first
+// instruction of File:Line, without resuming the target, so that it is the
+// next statement executed. The line must be in the function of the topmost
+// frame of the goroutine, if File is empty the file of that frame is used.
+// The jump is refused if it would corrupt the stack frame or skip the
+// initialization of a variable visible at the destination.
+func (s *RPCServer) SetNextStatement(arg SetNextStatementIn, out *SetNextStatementOut) error {
+	if arg.Scope.Frame != 0 || arg.Scope.DeferredCall != 0 {
+		return errors.New("the next statement can only be set on the topmost frame of a goroutine")
+	}
+	st, err := s.debugger.SetNextStatement(arg.Scope.GoroutineID, arg.File, arg.Line)
+	if err != nil {
+		return err
+	}
+	out.State = *st
+	return nil
+}
+
 type GetBreakpointIn struct {
 	Id   int
 	Name string
@@ -182,8 +305,8 @@ type StacktraceOut struct {
 // and function arguments of all stack frames.
 func (s *RPCServer) Stacktrace(arg StacktraceIn, out *StacktraceOut) error {
 	cfg := arg.Cfg
-	if cfg == nil && arg.Full {
-		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	if arg.Full {
+		cfg = s.loadConfig(cfg)
 	}
 	if arg.Defers {
 		arg.Opts |= api.StacktraceReadDefers
@@ -197,6 +320,25 @@ func (s *RPCServer) Stacktrace(arg StacktraceIn, out *StacktraceOut) error {
 	return err
 }
 
+type CallInjectionStackIn struct {
+}
+
+type CallInjectionStackOut struct {
+	Locations []api.Stackframe
+}
+
+// CallInjectionStack returns the stack frames of the injected function
+// call currently in progress, up to and including the frame of the runtime
+// function used to inject the call.
+func (s *RPCServer) CallInjectionStack(arg CallInjectionStackIn, out *CallInjectionStackOut) error {
+	rawlocs, err := s.debugger.CallInjectionStack()
+	if err != nil {
+		return err
+	}
+	out.Locations, err = s.debugger.ConvertStacktrace(rawlocs, nil)
+	return err
+}
+
 type AncestorsIn struct {
 	GoroutineID  int
 	NumAncestors int
@@ -214,6 +356,25 @@ func (s *RPCServer) Ancestors(arg AncestorsIn, out *AncestorsOut) error {
 	return err
 }
 
+type ChildrenIn struct {
+	GoroutineID int
+}
+
+type ChildrenOut struct {
+	Goroutines []*api.Goroutine
+}
+
+// Children returns the goroutines created by a goroutine.
+// If arg.GoroutineID is negative the selected goroutine is used.
+func (s *RPCServer) Children(arg ChildrenIn, out *ChildrenOut) error {
+	gs, err := s.debugger.Children(arg.GoroutineID)
+	if err != nil {
+		return err
+	}
+	out.Goroutines = api.ConvertGoroutines(s.debugger.Target(), gs)
+	return nil
+}
+
 type ListBreakpointsIn struct {
 }
 
@@ -227,6 +388,34 @@ func (s *RPCServer) ListBreakpoints(arg ListBreakpointsIn, out *ListBreakpointsO
 	return nil
 }
 
+type BreakpointHitStatsIn struct {
+}
+
+type BreakpointHitStatsOut struct {
+	Stats []api.BreakpointStat
+}
+
+// BreakpointHitStats returns the hit counters of all enabled breakpoints.
+func (s *RPCServer) BreakpointHitStats(arg BreakpointHitStatsIn, out *BreakpointHitStatsOut) error {
+	out.Stats = s.debugger.BreakpointHitStats()
+	return nil
+}
+
+type BreakpointsSharingAddressIn struct {
+}
+
+type BreakpointsSharingAddressOut struct {
+	// Breakpoints maps each shared address to the IDs of the breakpoints set on it.
+	Breakpoints map[uint64][]int
+}
+
+// BreakpointsSharingAddress returns the addresses used by more than one
+// logical breakpoint, including disabled breakpoints.
+func (s *RPCServer) BreakpointsSharingAddress(arg BreakpointsSharingAddressIn, out *BreakpointsSharingAddressOut) error {
+	out.Breakpoints = s.debugger.BreakpointsSharingAddress()
+	return nil
+}
+
 type CreateBreakpointIn struct {
 	Breakpoint api.Breakpoint
 }
@@ -251,6 +440,28 @@ func (s *RPCServer) CreateBreakpoint(arg CreateBreakpointIn, out *CreateBreakpoi
 	return nil
 }
 
+type CreateBreakpointsInPackageIn struct {
+	Package   string
+	EntryOnly bool
+}
+
+type CreateBreakpointsInPackageOut struct {
+	Breakpoints []*api.Breakpoint
+}
+
+// CreateBreakpointsInPackage sets a tracepoint on every function of
+// arg.Package, see the documentation of
+// `debugger.CreateBreakpointsInPackage`. If arg.EntryOnly is false
+// tracepoints are also set on the return locations of the functions.
+func (s *RPCServer) CreateBreakpointsInPackage(arg CreateBreakpointsInPackageIn, out *CreateBreakpointsInPackageOut) error {
+	bps, err := s.debugger.CreateBreakpointsInPackage(arg.Package, arg.EntryOnly)
+	if err != nil {
+		return err
+	}
+	out.Breakpoints = bps
+	return nil
+}
+
 type ClearBreakpointIn struct {
 	Id   int
 	Name string
@@ -318,11 +529,108 @@ func (s *RPCServer) ToggleBreakpoint(arg ToggleBreakpointIn, out *ToggleBreakpoi
 	return nil
 }
 
+type EnableBreakpointIn struct {
+	Id int
+}
+
+type EnableBreakpointOut struct {
+	Breakpoint *api.Breakpoint
+}
+
+// EnableBreakpoint enables the breakpoint with the specified ID, preserving
+// its configuration. Enabling a breakpoint that is already enabled is not
+// an error.
+func (s *RPCServer) EnableBreakpoint(arg EnableBreakpointIn, out *EnableBreakpointOut) error {
+	bp, err := s.setBreakpointDisabled(arg.Id, false)
+	out.Breakpoint = bp
+	return err
+}
+
+type DisableBreakpointIn struct {
+	Id int
+}
+
+type DisableBreakpointOut struct {
+	Breakpoint *api.Breakpoint
+}
+
+// DisableBreakpoint disables the breakpoint with the specified ID,
+// preserving its ID and configuration so that it can later be re-enabled
+// with EnableBreakpoint. Disabling a breakpoint that is already disabled is
+// not an error.
+func (s *RPCServer) DisableBreakpoint(arg DisableBreakpointIn, out *DisableBreakpointOut) error {
+	bp, err := s.setBreakpointDisabled(arg.Id, true)
+	out.Breakpoint = bp
+	return err
+}
+
+func (s *RPCServer) setBreakpointDisabled(id int, disabled bool) (*api.Breakpoint, error) {
+	bp := s.debugger.FindBreakpoint(id)
+	if bp == nil {
+		return nil, fmt.Errorf("no breakpoint with id %d", id)
+	}
+	if bp.Disabled == disabled {
+		return bp, nil
+	}
+	bp.Disabled = disabled
+	if err := s.debugger.AmendBreakpoint(bp); err != nil {
+		return nil, err
+	}
+	return bp, nil
+}
+
+type GetGoroutineDumpIn struct {
+	Id int
+}
+
+type GetGoroutineDumpOut struct {
+	Dump *api.GoroutineDump
+}
+
+// GetGoroutineDump returns the dump of all goroutines recorded the first
+// time the breakpoint with the specified ID was hit. The breakpoint must
+// have been created with DumpGoroutinesOnce set. The dump is discarded when
+// the breakpoint is cleared.
+func (s *RPCServer) GetGoroutineDump(arg GetGoroutineDumpIn, out *GetGoroutineDumpOut) error {
+	dump, err := s.debugger.GoroutineDump(arg.Id)
+	if err != nil {
+		return err
+	}
+	out.Dump = dump
+	return nil
+}
+
+type GoroutineStacktraceDiffIn struct {
+	// Prev is the previous snapshot, usually the Current field of the
+	// result of an earlier call. If it is nil every goroutine is reported
+	// as created.
+	Prev []api.GoroutineStacktrace
+}
+
+type GoroutineStacktraceDiffOut struct {
+	Diff *api.GoroutineDiff
+}
+
+// GoroutineStacktraceDiff takes a snapshot of all goroutines and their
+// stacktraces and reports which goroutines were created, exited or moved
+// since the snapshot in arg.Prev.
+func (s *RPCServer) GoroutineStacktraceDiff(arg GoroutineStacktraceDiffIn, out *GoroutineStacktraceDiffOut) error {
+	diff, err := s.debugger.GoroutineStacktraceDiff(arg.Prev)
+	if err != nil {
+		return err
+	}
+	out.Diff = diff
+	return nil
+}
+
 type AmendBreakpointIn struct {
 	Breakpoint api.Breakpoint
 }
 
 type AmendBreakpointOut struct {
+	// Breakpoint is the breakpoint after the amendment, including the
+	// addresses it is set on.
+	Breakpoint api.Breakpoint
 }
 
 // AmendBreakpoint allows user to update an existing breakpoint
@@ -334,7 +642,13 @@ func (s *RPCServer) AmendBreakpoint(arg AmendBreakpointIn, out *AmendBreakpointO
 	if err := api.ValidBreakpointName(arg.Breakpoint.Name); err != nil {
 		return err
 	}
-	return s.debugger.AmendBreakpoint(&arg.Breakpoint)
+	if err := s.debugger.AmendBreakpoint(&arg.Breakpoint); err != nil {
+		return err
+	}
+	if bp := s.debugger.FindBreakpoint(arg.Breakpoint.ID); bp != nil {
+		out.Breakpoint = *bp
+	}
+	return nil
 }
 
 type CancelNextIn struct {
@@ -348,21 +662,32 @@ func (s *RPCServer) CancelNext(arg CancelNextIn, out *CancelNextOut) error {
 }
 
 type ListThreadsIn struct {
+	Filter api.ThreadFilter
 }
 
 type ListThreadsOut struct {
 	Threads []*api.Thread
 }
 
-// ListThreads lists all threads.
+// ListThreads lists all threads matching arg.Filter, the State field of
+// the returned threads is filled.
 func (s *RPCServer) ListThreads(arg ListThreadsIn, out *ListThreadsOut) (err error) {
-	threads, err := s.debugger.Threads()
+	var threads []proc.Thread
+	if arg.Filter == (api.ThreadFilter{}) {
+		threads, err = s.debugger.Threads()
+	} else {
+		threads, err = s.debugger.ThreadsWithFilter(arg.Filter)
+	}
 	if err != nil {
 		return err
 	}
 	s.debugger.LockTarget()
 	defer s.debugger.UnlockTarget()
 	out.Threads = api.ConvertThreads(threads)
+	for i, th := range threads {
+		g, _ := proc.GetG(th)
+		out.Threads[i].State = api.ConvertThreadState(th, g)
+	}
 	return nil
 }
 
@@ -448,9 +773,41 @@ func (s *RPCServer) ListRegisters(arg ListRegistersIn, out *ListRegistersOut) er
 	return nil
 }
 
+type SetRegisterIn struct {
+	// ThreadID is the thread whose register will be changed, if it is 0 the
+	// current thread is used.
+	ThreadID int
+	// Name is the name of the register, optionally followed by a lane
+	// selector (for example XMM0.float32[1]).
+	Name  string
+	Value string
+}
+
+type SetRegisterOut struct {
+}
+
+// SetRegister changes the value of a CPU register of a thread.
+// Registers of 64 bits or less are set from an integer, larger registers
+// from a string of hexadecimal digits, most significant byte first, and
+// lanes of vector registers (REGNAME.typeN[index], same as expressions)
+// from a value of the lane type.
+func (s *RPCServer) SetRegister(arg SetRegisterIn, out *SetRegisterOut) error {
+	if arg.ThreadID == 0 {
+		state, err := s.debugger.State(false)
+		if err != nil {
+			return err
+		}
+		arg.ThreadID = state.CurrentThread.ID
+	}
+	return s.debugger.SetRegister(arg.ThreadID, arg.Name, arg.Value)
+}
+
 type ListLocalVarsIn struct {
 	Scope api.EvalScope
 	Cfg   api.LoadConfig
+	// Filter, if not empty, is a regular expression, only local variables
+	// whose name matches it are returned.
+	Filter string
 }
 
 type ListLocalVarsOut struct {
@@ -459,7 +816,7 @@ type ListLocalVarsOut struct {
 
 // ListLocalVars lists all local variables in scope.
 func (s *RPCServer) ListLocalVars(arg ListLocalVarsIn, out *ListLocalVarsOut) error {
-	vars, err := s.debugger.LocalVariables(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, *api.LoadConfigToProc(&arg.Cfg))
+	vars, err := s.debugger.LocalVariables(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Filter, *api.LoadConfigToProc(&arg.Cfg))
 	if err != nil {
 		return err
 	}
@@ -486,6 +843,57 @@ func (s *RPCServer) ListFunctionArgs(arg ListFunctionArgsIn, out *ListFunctionAr
 	return nil
 }
 
+type VisibleNamesIn struct {
+	Scope api.EvalScope
+}
+
+type VisibleNamesOut struct {
+	Names []string
+}
+
+// VisibleNames returns the sorted list of identifiers that can be used in
+// expressions evaluated in the specified scope: local variables and
+// arguments, package level variables, functions and constants of the
+// current package and package names.
+func (s *RPCServer) VisibleNames(arg VisibleNamesIn, out *VisibleNamesOut) error {
+	var err error
+	out.Names, err = s.debugger.VisibleNames(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall)
+	return err
+}
+
+type MutexOwnerIn struct {
+	Scope api.EvalScope
+	Expr  string
+}
+
+type MutexOwnerOut struct {
+	// Locked is true if the mutex is locked.
+	Locked bool
+	// Candidates are the goroutines that could be holding the mutex.
+	Candidates []*api.Goroutine
+}
+
+// MutexOwner evaluates arg.Expr, which must be a sync.Mutex or a
+// sync.RWMutex, and reports whether it is locked and, if it is, the
+// goroutines that could be holding it.
+// Since the Go runtime does not record the owner of a mutex the candidates
+// are the goroutines referencing the mutex from their stack that are not
+// waiting to lock a mutex: the list can contain goroutines that do not
+// hold the mutex and miss the owner if it only references the mutex
+// through the heap.
+func (s *RPCServer) MutexOwner(arg MutexOwnerIn, out *MutexOwnerOut) error {
+	locked, gs, err := s.debugger.MutexOwner(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr)
+	if err != nil {
+		return err
+	}
+	out.Locked = locked
+	out.Candidates = make([]*api.Goroutine, 0, len(gs))
+	for _, g := range gs {
+		out.Candidates = append(out.Candidates, api.ConvertGoroutine(s.debugger.Target(), g))
+	}
+	return nil
+}
+
 type EvalIn struct {
 	Scope api.EvalScope
 	Expr  string
@@ -501,10 +909,7 @@ type EvalOut struct {
 // See https://github.com/go-delve/delve/blob/master/Documentation/cli/expr.md
 // for a description of acceptable values of arg.Expr.
 func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
-	cfg := arg.Cfg
-	if cfg == nil {
-		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
-	}
+	cfg := s.loadConfig(arg.Cfg)
 	v, err := s.debugger.EvalVariableInScope(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, *api.LoadConfigToProc(cfg))
 	if err != nil {
 		return err
@@ -513,6 +918,135 @@ func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
 	return nil
 }
 
+type EvalAcrossGoroutinesIn struct {
+	Expr string
+	Cfg  *api.LoadConfig
+}
+
+type EvalAcrossGoroutinesOut struct {
+	Variables map[int]*api.Variable
+}
+
+// EvalAcrossGoroutines evaluates arg.Expr in the topmost frame of every
+// goroutine and returns the results keyed by goroutine ID. Goroutines where
+// the evaluation fails are reported with an unreadable variable, the call
+// itself only fails if the goroutines can not be listed.
+func (s *RPCServer) EvalAcrossGoroutines(arg EvalAcrossGoroutinesIn, out *EvalAcrossGoroutinesOut) error {
+	cfg := s.loadConfig(arg.Cfg)
+	var err error
+	out.Variables, err = s.debugger.EvalAcrossGoroutines(arg.Expr, *api.LoadConfigToProc(cfg))
+	return err
+}
+
+type EvalToJSONIn struct {
+	Scope api.EvalScope
+	Expr  string
+	Cfg   *api.LoadConfig
+}
+
+type EvalToJSONOut struct {
+	Value json.RawMessage
+}
+
+// EvalToJSON evaluates an expression in the specified context and returns
+// its value as a JSON tree, see api.JSONValue for the format.
+func (s *RPCServer) EvalToJSON(arg EvalToJSONIn, out *EvalToJSONOut) error {
+	cfg := s.loadConfig(arg.Cfg)
+	v, err := s.debugger.EvalVariableInScope(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Value, err = api.ConvertVar(v).JSON()
+	return err
+}
+
+type WhatIsIn struct {
+	Scope api.EvalScope
+	Expr  string
+}
+
+type WhatIsOut struct {
+	TypeInfo *api.TypeInfo
+}
+
+// WhatIs returns the type of an expression in the specified context.
+// Unlike Eval the value of the expression is not loaded, for interfaces
+// only the dynamic type of the value they contain is read.
+func (s *RPCServer) WhatIs(arg WhatIsIn, out *WhatIsOut) error {
+	ti, err := s.debugger.WhatIs(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr)
+	if err != nil {
+		return err
+	}
+	out.TypeInfo = ti
+	return nil
+}
+
+type SetEvalAliasIn struct {
+	Name string
+	// Expr is the expression the alias stands for, an empty Expr removes
+	// the alias.
+	Expr string
+}
+
+type SetEvalAliasOut struct {
+}
+
+// SetEvalAlias defines an alias for an expression, the alias can be used
+// as @name in the expressions evaluated by Eval. Aliases are shared by all
+// clients connected to the server and last until the server is shut down.
+// Aliases can refer to other, already defined, aliases; an alias that
+// would create a cycle is rejected.
+func (s *RPCServer) SetEvalAlias(arg SetEvalAliasIn, out *SetEvalAliasOut) error {
+	return s.debugger.SetEvalAlias(arg.Name, arg.Expr)
+}
+
+type AddWatchIn struct {
+	Scope api.EvalScope
+	Expr  string
+}
+
+type AddWatchOut struct {
+	ID int
+}
+
+// AddWatch registers an expression that will be evaluated in the specified
+// scope every time the state of the debugger is returned, its value is
+// reported in the Watches field of api.DebuggerState.
+// Watches are shared by all clients connected to the server.
+func (s *RPCServer) AddWatch(arg AddWatchIn, out *AddWatchOut) error {
+	id, err := s.debugger.AddWatch(arg.Scope, arg.Expr)
+	if err != nil {
+		return err
+	}
+	out.ID = id
+	return nil
+}
+
+type RemoveWatchIn struct {
+	ID int
+}
+
+type RemoveWatchOut struct {
+}
+
+// RemoveWatch removes a watch registered with AddWatch.
+func (s *RPCServer) RemoveWatch(arg RemoveWatchIn, out *RemoveWatchOut) error {
+	return s.debugger.RemoveWatch(arg.ID)
+}
+
+type ListEvalAliasesIn struct {
+}
+
+type ListEvalAliasesOut struct {
+	Aliases []api.EvalAlias
+}
+
+// ListEvalAliases lists all aliases defined with SetEvalAlias.
+func (s *RPCServer) ListEvalAliases(arg ListEvalAliasesIn, out *ListEvalAliasesOut) error {
+	out.Aliases = s.debugger.ListEvalAliases()
+	return nil
+}
+
 type SetIn struct {
 	Scope  api.EvalScope
 	Symbol string
@@ -546,6 +1080,52 @@ func (s *RPCServer) ListSources(arg ListSourcesIn, out *ListSourcesOut) error {
 	return nil
 }
 
+type SourceLinesIn struct {
+	File  string
+	Start int
+	End   int
+
+	// SubstitutePathRules is a slice of source code path substitution
+	// rules, with the same meaning as FindLocationIn.SubstitutePathRules,
+	// used to map File back to the path of the source file on the server.
+	SubstitutePathRules [][2]string
+}
+
+type SourceLinesOut struct {
+	Lines []string
+}
+
+// SourceLines returns the lines from arg.Start to arg.End (inclusive) of a
+// source file of the target. The file is read on the server, which lets
+// clients display source code they can not access directly.
+func (s *RPCServer) SourceLines(arg SourceLinesIn, out *SourceLinesOut) error {
+	lines, err := s.debugger.SourceLines(arg.File, arg.Start, arg.End, arg.SubstitutePathRules)
+	if err != nil {
+		return err
+	}
+	out.Lines = lines
+	return nil
+}
+
+type ListSourcesGroupedIn struct {
+	Filter string
+}
+
+type ListSourcesGroupedOut struct {
+	Sources map[string][]string
+}
+
+// ListSourcesGrouped lists all source files in the process matching
+// filter, grouped by the import path of the package they belong to.
+func (s *RPCServer) ListSourcesGrouped(arg ListSourcesGroupedIn, out *ListSourcesGroupedOut) error {
+	ss, err := s.debugger.SourcesByPackage(arg.Filter)
+	if err != nil {
+		return err
+	}
+	out.Sources = ss
+	return nil
+}
+
 type ListFunctionsIn struct {
 	Filter string
 }
@@ -582,6 +1162,26 @@ func (s *RPCServer) ListTypes(arg ListTypesIn, out *ListTypesOut) error {
 	return nil
 }
 
+type FindTypeIn struct {
+	Name string
+}
+
+type FindTypeOut struct {
+	Type *api.TypeDefinition
+}
+
+// FindType returns the definition of the type named arg.Name: its size,
+// the offsets and types of its fields and its methods. If arg.Name is not
+// the full name of a type it is resolved like type names in expressions.
+func (s *RPCServer) FindType(arg FindTypeIn, out *FindTypeOut) error {
+	typ, err := s.debugger.FindType(arg.Name)
+	if err != nil {
+		return err
+	}
+	out.Type = typ
+	return nil
+}
+
 type ListGoroutinesIn struct {
 	Start int
 	Count int
@@ -618,6 +1218,14 @@ type ListGoroutinesOut struct {
 //    ListGoroutineFilter{ Kind: ListGoroutinesFilterLabel, Negated: false, Arg: "key=value" }
 // this filter will only return goroutines that have a key=value label.
 //
+// Or to their scheduling status:
+//    ListGoroutineFilter{ Kind: GoroutineStatus, Negated: false, Arg: "waiting" }
+// see api.GoroutineStatusName for the list of statuses.
+//
+// Or to whether their UserLoc is in user code, which excludes goroutines
+// that are only executing runtime code:
+//    ListGoroutineFilter{ Kind: GoroutineUserCode, Negated: false }
+//
 // If arg.GroupBy is not GoroutineFieldNone then the goroutines will
 // be grouped with the specified criterion.
 // If the value of arg.GroupBy is GoroutineLabel goroutines will
@@ -641,6 +1249,51 @@ func (s *RPCServer) ListGoroutines(arg ListGoroutinesIn, out *ListGoroutinesOut)
 	return nil
 }
 
+type GoroutineIn struct {
+	ID int
+}
+
+type GoroutineOut struct {
+	Goroutine *api.Goroutine
+}
+
+// Goroutine returns the goroutine with the specified ID, -1 for the
+// selected goroutine. If no goroutine with that ID exists an "unknown
+// goroutine" error is returned.
+func (s *RPCServer) Goroutine(arg GoroutineIn, out *GoroutineOut) error {
+	g, err := s.debugger.FindGoroutine(arg.ID)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return errors.New("no goroutine selected")
+	}
+	s.debugger.LockTarget()
+	defer s.debugger.UnlockTarget()
+	out.Goroutine = api.ConvertGoroutine(s.debugger.Target(), g)
+	return nil
+}
+
+type DeferredCallsIn struct {
+	Scope api.EvalScope
+	Cfg   *api.LoadConfig
+}
+
+type DeferredCallsOut struct {
+	DeferredCalls []api.DeferredCall
+}
+
+// DeferredCalls returns the deferred calls pending on the goroutine of
+// arg.Scope, registered by frame arg.Scope.Frame and the frames below it,
+// in the order they will be executed. The values of their arguments are
+// loaded using arg.Cfg.
+func (s *RPCServer) DeferredCalls(arg DeferredCallsIn, out *DeferredCallsOut) error {
+	cfg := s.loadConfig(arg.Cfg)
+	var err error
+	out.DeferredCalls, err = s.debugger.DeferredCalls(arg.Scope.GoroutineID, arg.Scope.Frame, *api.LoadConfigToProc(cfg))
+	return err
+}
+
 type AttachedToExistingProcessIn struct {
 }
 
@@ -667,6 +1320,14 @@ type FindLocationIn struct {
 	// was compiled), the second entry of each pair is the location of the same
 	// directory on the client system.
 	SubstitutePathRules [][2]string
+
+	// RankedCandidates, if set, makes FindLocation return one location for
+	// each candidate of an ambiguous location expression, instead of an
+	// error. The exact match comes first, followed by the candidates in the
+	// package of the selected frame and then by the distance of their
+	// import path (or directory, for files) from it, ties are broken by
+	// name.
+	RankedCandidates bool
 }
 
 type FindLocationOut struct {
@@ -688,10 +1349,54 @@ type FindLocationOut struct {
 // NOTE: this function does not actually set breakpoints.
 func (c *RPCServer) FindLocation(arg FindLocationIn, out *FindLocationOut) error {
 	var err error
+	if arg.RankedCandidates {
+		out.Locations, err = c.debugger.FindLocationCandidates(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Loc, arg.IncludeNonExecutableLines, arg.SubstitutePathRules)
+		return err
+	}
 	out.Locations, err = c.debugger.FindLocation(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Loc, arg.IncludeNonExecutableLines, arg.SubstitutePathRules)
 	return err
 }
 
+type ResolveAddressIn struct {
+	PC uint64
+}
+
+type ResolveAddressOut struct {
+	Location *api.Location
+}
+
+// ResolveAddress returns the file, line, function and symbol of the
+// specified address. It is the inverse of FindLocation.
+func (c *RPCServer) ResolveAddress(arg ResolveAddressIn, out *ResolveAddressOut) error {
+	var err error
+	out.Location, err = c.debugger.ResolveAddress(arg.PC)
+	return err
+}
+
+type PreviewBreakpointIn struct {
+	Scope api.EvalScope
+	Loc   string
+
+	// SubstitutePathRules is a slice of source code path substitution rules,
+	// see FindLocationIn.
+	SubstitutePathRules [][2]string
+}
+
+type PreviewBreakpointOut struct {
+	Locations []api.Location
+}
+
+// PreviewBreakpoint returns the physical breakpoints that would be created
+// by a breakpoint on the location expression Loc (see FindLocation for the
+// syntax), without creating them. One location is returned for each
+// address, reporting whether the address belongs to an inlined call and
+// whether its function is a compiler generated wrapper.
+func (c *RPCServer) PreviewBreakpoint(arg PreviewBreakpointIn, out *PreviewBreakpointOut) error {
+	var err error
+	out.Locations, err = c.debugger.PreviewBreakpoint(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Loc, arg.SubstitutePathRules)
+	return err
+}
+
 type DisassembleIn struct {
 	Scope          api.EvalScope
 	StartPC, EndPC uint64
@@ -722,6 +1427,34 @@ func (c *RPCServer) Disassemble(arg DisassembleIn, out *DisassembleOut) error {
 	return nil
 }
 
+type DisassembleFunctionIn struct {
+	Scope        api.EvalScope
+	FunctionName string
+	Flavour      api.AssemblyFlavour
+}
+
+type DisassembleFunctionOut struct {
+	Disassemble api.AsmInstructions
+}
+
+// DisassembleFunction disassembles the whole body of the function named
+// arg.FunctionName.
+//
+// The function name is resolved the same way as a location expression, if
+// it matches more than one function, and none of them matches exactly, an
+// error is returned.
+func (c *RPCServer) DisassembleFunction(arg DisassembleFunctionIn, out *DisassembleFunctionOut) error {
+	insts, err := c.debugger.DisassembleFunction(arg.Scope.GoroutineID, arg.FunctionName)
+	if err != nil {
+		return err
+	}
+	out.Disassemble = make(api.AsmInstructions, len(insts))
+	for i := range insts {
+		out.Disassemble[i] = api.ConvertAsmInstruction(insts[i], c.debugger.AsmInstructionText(&insts[i], proc.AssemblyFlavour(arg.Flavour)))
+	}
+	return nil
+}
+
 type RecordedIn struct {
 }
 
@@ -780,6 +1513,175 @@ func (s *RPCServer) ClearCheckpoint(arg ClearCheckpointIn, out *ClearCheckpointO
 	return s.debugger.ClearCheckpoint(arg.ID)
 }
 
+type ReferencesToIn struct {
+	Addr uint64
+	// MaxResults is the maximum number of references returned, all
+	// references are returned if it is zero.
+	MaxResults int
+}
+
+type ReferencesToOut struct {
+	References []api.Reference
+}
+
+// ReferencesTo scans the stacks of all goroutines for words of memory
+// equal to Addr, returning the goroutine, frame and variable where each one
+// was found. The scan is conservative, every word equal to Addr is
+// reported whether it is a pointer or not. Pointers stored in the heap or
+// held in registers are not reported.
+func (s *RPCServer) ReferencesTo(arg ReferencesToIn, out *ReferencesToOut) error {
+	var err error
+	out.References, err = s.debugger.ReferencesTo(arg.Addr, arg.MaxResults)
+	return err
+}
+
+type BreakOnNextPanicIn struct {
+	GoroutineID int
+}
+
+type BreakOnNextPanicOut struct {
+	Goroutines []int
+}
+
+// BreakOnNextPanic arms the specified goroutine (the selected goroutine if
+// GoroutineID is negative) so that the target stops the next time it
+// panics, in runtime.gopanic. The goroutine is disarmed when it panics,
+// other armed goroutines stay armed. Returns the IDs of all the armed
+// goroutines.
+func (s *RPCServer) BreakOnNextPanic(arg BreakOnNextPanicIn, out *BreakOnNextPanicOut) error {
+	var err error
+	out.Goroutines, err = s.debugger.BreakOnNextPanic(arg.GoroutineID)
+	return err
+}
+
+type ParkGoroutineIn struct {
+	GoroutineID int
+}
+
+type ParkGoroutineOut struct {
+}
+
+// ParkGoroutine prevents the specified goroutine from running when the
+// target is continued, while the other goroutines run normally. The
+// goroutine is held by leaving stopped the thread it is running on, which
+// is only supported by the native backend on linux, a goroutine that is
+// not running on a thread when the target is continued is not held.
+func (s *RPCServer) ParkGoroutine(arg ParkGoroutineIn, out *ParkGoroutineOut) error {
+	return s.debugger.ParkGoroutine(arg.GoroutineID)
+}
+
+type SetStopSignalsIn struct {
+	Signals []int
+}
+
+type SetStopSignalsOut struct {
+}
+
+// SetStopSignals sets the signals that stop the target, instead of being
+// delivered to it, when they are received by one of its threads. The state
+// returned when the target stops this way has its Signal field set, the
+// signal is delivered when the target is resumed unless it is continued
+// with the ContinueWithSignal command. Only supported by the native
+// backend on linux.
+func (s *RPCServer) SetStopSignals(arg SetStopSignalsIn, out *SetStopSignalsOut) error {
+	return s.debugger.SetStopSignals(arg.Signals)
+}
+
+type UnparkGoroutineIn struct {
+	GoroutineID int
+}
+
+type UnparkGoroutineOut struct {
+}
+
+// UnparkGoroutine lets a goroutine previously parked with ParkGoroutine
+// run again.
+func (s *RPCServer) UnparkGoroutine(arg UnparkGoroutineIn, out *UnparkGoroutineOut) error {
+	return s.debugger.UnparkGoroutine(arg.GoroutineID)
+}
+
+type SetGoroutineLabelsIn struct {
+	GoroutineID int
+	Labels      map[string]string
+}
+
+type SetGoroutineLabelsOut struct {
+}
+
+// SetGoroutineLabels attaches synthetic labels to the specified goroutine,
+// replacing the ones attached by a previous call, an empty map removes
+// them. The labels are not written to the target, they are only seen by
+// delve: they are merged with the pprof labels of the goroutine, taking
+// precedence over them, in the results of ListGoroutines, including its
+// label filters and grouping.
+func (s *RPCServer) SetGoroutineLabels(arg SetGoroutineLabelsIn, out *SetGoroutineLabelsOut) error {
+	return s.debugger.SetGoroutineLabels(arg.GoroutineID, arg.Labels)
+}
+
+type LastOperationStatsIn struct {
+}
+
+type LastOperationStatsOut struct {
+	// Stats is nil if the target was never resumed.
+	Stats *api.OperationStats
+}
+
+// LastOperationStats returns the duration of the last command that resumed
+// the target and, for recorded targets, the number of rr events it
+// traversed. If the command is still running the time elapsed so far is
+// returned, so that clients can report the progress of long operations.
+func (s *RPCServer) LastOperationStats(arg LastOperationStatsIn, out *LastOperationStatsOut) error {
+	out.Stats = s.debugger.LastOperationStats()
+	return nil
+}
+
+type ExportChromeTraceIn struct {
+	FromEvent int64
+	// ToEvent is the last rr event to replay, a negative value replays until
+	// the end of the recording.
+	ToEvent int64
+}
+
+type ExportChromeTraceOut struct {
+	// Trace is a JSON document in the Chrome trace event format.
+	Trace []byte
+}
+
+// ExportChromeTrace replays the recording between two rr events and
+// returns a timeline of the goroutines running on each thread in the
+// Chrome trace event format.
+// Only available for recorded targets.
+func (s *RPCServer) ExportChromeTrace(arg ExportChromeTraceIn, out *ExportChromeTraceOut) error {
+	var err error
+	out.Trace, err = s.debugger.ExportChromeTrace(arg.FromEvent, arg.ToEvent)
+	return err
+}
+
+type ValueHistoryIn struct {
+	Scope api.EvalScope
+	Expr  string
+	// MaxSamples is the maximum number of values returned, if it is not
+	// positive all values are returned.
+	MaxSamples int
+	Cfg        *api.LoadConfig
+}
+
+type ValueHistoryOut struct {
+	Samples []api.ValueSample
+}
+
+// ValueHistory returns the sequence of values taken by an expression over
+// the whole recording, with the rr event at which each value was written.
+// The expression must be a global or heap allocated variable that can be
+// watched with a watchpoint.
+// Only available for recorded targets.
+func (s *RPCServer) ValueHistory(arg ValueHistoryIn, out *ValueHistoryOut) error {
+	cfg := s.loadConfig(arg.Cfg)
+	var err error
+	out.Samples, err = s.debugger.ValueHistory(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.MaxSamples, *api.LoadConfigToProc(cfg))
+	return err
+}
+
 type IsMulticlientIn struct {
 }
 
@@ -825,6 +1727,39 @@ func (s *RPCServer) FunctionReturnLocations(in FunctionReturnLocationsIn, out *F
 	return nil
 }
 
+type InlinedPackagesIn struct {
+	FnName string
+}
+
+type InlinedPackagesOut struct {
+	Packages []string
+}
+
+// InlinedPackages returns the sorted list of packages whose functions were
+// inlined into the body of the function FnName.
+func (s *RPCServer) InlinedPackages(arg InlinedPackagesIn, out *InlinedPackagesOut) error {
+	var err error
+	out.Packages, err = s.debugger.InlinedPackages(arg.FnName)
+	return err
+}
+
+type FunctionLineEntriesIn struct {
+	FnName string
+}
+
+type FunctionLineEntriesOut struct {
+	Entries []api.LineEntry
+}
+
+// FunctionLineEntries returns the rows of the line number table of the
+// function FnName, sorted by address. Lines that have no entry with IsStmt
+// set can not host a breakpoint.
+func (s *RPCServer) FunctionLineEntries(arg FunctionLineEntriesIn, out *FunctionLineEntriesOut) error {
+	var err error
+	out.Entries, err = s.debugger.FunctionLineEntries(arg.FnName)
+	return err
+}
+
 // ListDynamicLibrariesIn holds the arguments of ListDynamicLibraries
 type ListDynamicLibrariesIn struct {
 }
@@ -834,6 +1769,9 @@ type ListDynamicLibrariesOut struct {
 	List []api.Image
 }
 
+// ListDynamicLibraries returns the list of dynamic libraries and plugins
+// currently loaded by the target, with the address they are loaded at and
+// whether debug information was found for them.
 func (s *RPCServer) ListDynamicLibraries(in ListDynamicLibrariesIn, out *ListDynamicLibrariesOut) error {
 	imgs := s.debugger.ListDynamicLibraries()
 	out.List = make([]api.Image, 0, len(imgs))
@@ -843,6 +1781,42 @@ func (s *RPCServer) ListDynamicLibraries(in ListDynamicLibrariesIn, out *ListDyn
 	return nil
 }
 
+type DebuggerStatsIn struct {
+}
+
+type DebuggerStatsOut struct {
+	Stats *api.DebuggerStats
+}
+
+// DebuggerStats returns the memory used by the debugger process, the size
+// of the debug information of the target and the usage of the caches used
+// to read it.
+func (s *RPCServer) DebuggerStats(arg DebuggerStatsIn, out *DebuggerStatsOut) error {
+	out.Stats = s.debugger.DebuggerStats()
+	return nil
+}
+
+// BuildInfoIn holds the arguments of BuildInfo.
+type BuildInfoIn struct {
+}
+
+// BuildInfoOut holds the return values of BuildInfo.
+type BuildInfoOut struct {
+	BuildInfo api.BuildInfo
+}
+
+// BuildInfo returns the Go version, the module information and the build
+// settings (including version control stamps) embedded in the target
+// binary, like 'go version -m' does.
+func (s *RPCServer) BuildInfo(in BuildInfoIn, out *BuildInfoOut) error {
+	bi, err := s.debugger.BuildInfo()
+	if err != nil {
+		return err
+	}
+	out.BuildInfo = *bi
+	return nil
+}
+
 // ListPackagesBuildInfoIn holds the arguments of ListPackages.
 type ListPackagesBuildInfoIn struct {
 	IncludeFiles bool
@@ -855,7 +1829,7 @@ type ListPackagesBuildInfoOut struct {
 
 // ListPackagesBuildInfo returns the list of packages used by the program along with
 // the directory where each package was compiled and optionally the list of
-// files constituting the package.
+// files constituting the package. Packages are sorted by import path.
 // Note that the directory path is a best guess and may be wrong is a tool
 // other than cmd/go is used to perform the build.
 func (s *RPCServer) ListPackagesBuildInfo(in ListPackagesBuildInfoIn, out *ListPackagesBuildInfoOut) error {
@@ -875,10 +1849,39 @@ func (s *RPCServer) ListPackagesBuildInfo(in ListPackagesBuildInfoIn, out *ListP
 
 		out.List = append(out.List, api.PackageBuildInfo{
 			ImportPath:    pkg.ImportPath,
+			PackageName:   pkg.PackageName,
 			DirectoryPath: pkg.DirectoryPath,
 			Files:         files,
 		})
 	}
+	sort.Slice(out.List, func(i, j int) bool { return out.List[i].ImportPath < out.List[j].ImportPath })
+	return nil
+}
+
+// ReadBytesIn holds the arguments of ReadBytes.
+type ReadBytesIn struct {
+	Scope api.EvalScope
+	Expr  string
+	// Limit is the maximum length of the value, in bytes. If zero
+	// debugger.DefaultReadBytesLimit is used.
+	Limit int
+}
+
+// ReadBytesOut holds the return values of ReadBytes.
+type ReadBytesOut struct {
+	Bytes []byte
+}
+
+// ReadBytes evaluates Expr, which must be a string or a slice or array of
+// bytes, and returns its complete contents, ignoring the limits on the
+// length of strings and arrays normally used to load variables.
+// Values longer than Limit bytes are not read and an error is returned.
+func (s *RPCServer) ReadBytes(arg ReadBytesIn, out *ReadBytesOut) error {
+	b, err := s.debugger.ReadBytes(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.Limit)
+	if err != nil {
+		return err
+	}
+	out.Bytes = b
 	return nil
 }
 
@@ -909,6 +1912,68 @@ func (s *RPCServer) ExamineMemory(arg ExamineMemoryIn, out *ExaminedMemoryOut) e
 	return nil
 }
 
+type ReadMemoryIn struct {
+	Addr  uint64
+	Count int
+}
+
+type ReadMemoryOut struct {
+	Mem []byte
+	// Err is set if only part of the requested memory could be read, in
+	// which case Mem contains the bytes read up to the first unreadable
+	// address.
+	Err string
+}
+
+// ReadMemory reads arg.Count bytes of target memory starting at arg.Addr,
+// arg.Count can be at most debugger.MaxReadMemoryLength.
+func (s *RPCServer) ReadMemory(arg ReadMemoryIn, out *ReadMemoryOut) error {
+	mem, err := s.debugger.ReadMemory(arg.Addr, arg.Count)
+	if err != nil {
+		if len(mem) == 0 {
+			return err
+		}
+		out.Err = err.Error()
+	}
+	out.Mem = mem
+	return nil
+}
+
+type WriteMemoryIn struct {
+	Addr uint64
+	Data []byte
+}
+
+type WriteMemoryOut struct {
+	Written int
+}
+
+// WriteMemory writes arg.Data to the target memory starting at arg.Addr.
+func (s *RPCServer) WriteMemory(arg WriteMemoryIn, out *WriteMemoryOut) error {
+	n, err := s.debugger.WriteMemory(arg.Addr, arg.Data)
+	out.Written = n
+	return err
+}
+
+type ProcessMemoryMapsIn struct {
+}
+
+type ProcessMemoryMapsOut struct {
+	MemoryMaps []api.MemoryMap
+}
+
+// ProcessMemoryMaps returns the memory map of the target process: the
+// address ranges it has mapped, their permissions and the files backing
+// them.
+func (s *RPCServer) ProcessMemoryMaps(arg ProcessMemoryMapsIn, out *ProcessMemoryMapsOut) error {
+	mmap, err := s.debugger.MemoryMap()
+	if err != nil {
+		return err
+	}
+	out.MemoryMaps = api.ConvertMemoryMap(mmap)
+	return nil
+}
+
 type StopRecordingIn struct {
 }
 
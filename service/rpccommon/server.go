@@ -256,6 +256,9 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 		}
 	}()
 
+	// s2 holds the state of this connection for APIv2 methods.
+	s2 := s.s2.NewConnection()
+
 	sending := new(sync.Mutex)
 	codec := jsonrpc.NewServerCodec(conn)
 	var req rpc.Request
@@ -295,6 +298,11 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 			argv = argv.Elem()
 		}
 
+		rcvr := mtype.Rcvr
+		if rcvr.Interface() == s.s2 {
+			rcvr = reflect.ValueOf(s2)
+		}
+
 		if mtype.Synchronous {
 			if logflags.RPC() {
 				argvbytes, _ := json.Marshal(argv.Interface())
@@ -310,7 +318,7 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 						errInter = newInternalError(ierr, 2)
 					}
 				}()
-				returnValues = function.Call([]reflect.Value{mtype.Rcvr, argv, replyv})
+				returnValues = function.Call([]reflect.Value{rcvr, argv, replyv})
 				errInter = returnValues[0].Interface()
 			}()
 
@@ -341,7 +349,7 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 						ctl.Return(nil, newInternalError(ierr, 2))
 					}
 				}()
-				function.Call([]reflect.Value{mtype.Rcvr, argv, reflect.ValueOf(ctl)})
+				function.Call([]reflect.Value{rcvr, argv, reflect.ValueOf(ctl)})
 			}()
 			<-ctl.setupDone
 		}
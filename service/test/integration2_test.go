@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -13,8 +14,10 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -179,6 +182,45 @@ func TestRestart_breakpointPreservation(t *testing.T) {
 	})
 }
 
+func TestBreakpointUserData(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("continuetestprog", t, func(c service.Client) {
+		const userData = `{"uuid":"7d3f2a10","tags":["editor",1]}`
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: 1, UserData: json.RawMessage(userData)})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if string(bp.UserData) != userData {
+			t.Fatalf("wrong user data returned by CreateBreakpoint: %q", bp.UserData)
+		}
+
+		checkUserData := func(when string) {
+			t.Helper()
+			bp, err := c.GetBreakpoint(bp.ID)
+			assertNoError(err, t, "GetBreakpoint()")
+			if string(bp.UserData) != userData {
+				t.Fatalf("wrong user data returned by GetBreakpoint %s: %q", when, bp.UserData)
+			}
+			bps, err := c.ListBreakpoints()
+			assertNoError(err, t, "ListBreakpoints()")
+			for _, lbp := range bps {
+				if lbp.ID == bp.ID && string(lbp.UserData) != userData {
+					t.Fatalf("wrong user data returned by ListBreakpoints %s: %q", when, lbp.UserData)
+				}
+			}
+		}
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if string(state.CurrentThread.Breakpoint.UserData) != userData {
+			t.Fatalf("wrong user data in the state: %q", state.CurrentThread.Breakpoint.UserData)
+		}
+		checkUserData("after hit")
+
+		_, err = c.Restart(false)
+		assertNoError(err, t, "Restart()")
+		checkUserData("after restart")
+	})
+}
+
 func TestRestart_duringStop(t *testing.T) {
 	withTestClient2("continuetestprog", t, func(c service.Client) {
 		origPid := c.ProcessPid()
@@ -320,6 +362,30 @@ func TestClientServer_step(t *testing.T) {
 	})
 }
 
+func TestClientServer_stepExcluding(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: 1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		state, err = c.StepExcluding([]string{"fmt"})
+		assertNoError(err, t, "StepExcluding()")
+		if fn := state.CurrentThread.Function; fn == nil || fn.Name() != "main.helloworld" || state.CurrentThread.Line != 11 {
+			t.Fatalf("stepped into excluded package: %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+		}
+
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		state, err = c.StepExcluding([]string{"runtime", "internal/*"})
+		assertNoError(err, t, "StepExcluding()")
+		if fn := state.CurrentThread.Function; fn == nil || fn.Name() != "fmt.Println" {
+			t.Fatalf("did not step into fmt.Println: %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+		}
+	})
+}
+
 func TestClientServer_stepout(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestClient2("testnextprog", t, func(c service.Client) {
@@ -614,6 +680,112 @@ func TestClientServer_toggleAmendedBreakpoint(t *testing.T) {
 	})
 }
 
+func TestClientServer_enableDisableBreakpoint(t *testing.T) {
+	withTestClient2("testtoggle", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.lineOne", Variables: []string{"n"}})
+		assertNoError(err, t, "CreateBreakpoint()")
+		bp.Cond = "n == 7"
+		bp.HitCond = "> 1"
+		assertNoError(c.AmendBreakpoint(bp), t, "AmendBreakpoint()")
+
+		check := func(dbp *api.Breakpoint, err error, disabled bool) {
+			t.Helper()
+			assertNoError(err, t, "Enable/DisableBreakpoint()")
+			if dbp.ID != bp.ID {
+				t.Fatalf("wrong breakpoint ID %d, expected %d", dbp.ID, bp.ID)
+			}
+			if dbp.Disabled != disabled {
+				t.Fatalf("wrong Disabled flag %v, expected %v", dbp.Disabled, disabled)
+			}
+			got, err := c.GetBreakpoint(bp.ID)
+			assertNoError(err, t, "GetBreakpoint()")
+			if got.Disabled != disabled {
+				t.Fatalf("wrong Disabled flag %v after GetBreakpoint, expected %v", got.Disabled, disabled)
+			}
+			if got.Cond != bp.Cond || got.HitCond != bp.HitCond || len(got.Variables) != 1 || got.Variables[0] != "n" {
+				t.Fatalf("breakpoint configuration not preserved: %#v", got)
+			}
+		}
+
+		dbp, err := c.DisableBreakpoint(bp.ID)
+		check(dbp, err, true)
+		dbp, err = c.DisableBreakpoint(bp.ID)
+		check(dbp, err, true)
+		dbp, err = c.EnableBreakpoint(bp.ID)
+		check(dbp, err, false)
+		dbp, err = c.EnableBreakpoint(bp.ID)
+		check(dbp, err, false)
+
+		_, err = c.EnableBreakpoint(1000)
+		assertError(err, t, "EnableBreakpoint(1000)")
+	})
+}
+
+func TestDumpGoroutinesOnce(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme", DumpGoroutinesOnce: true})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if !bp.DumpGoroutinesOnce {
+			t.Fatal("DumpGoroutinesOnce not set on the created breakpoint")
+		}
+
+		_, err = c.GetGoroutineDump(bp.ID)
+		assertError(err, t, "GetGoroutineDump() before the breakpoint is hit")
+
+		checkDump := func(dump *api.GoroutineDump, goid int) {
+			t.Helper()
+			if dump.BreakpointID != bp.ID {
+				t.Fatalf("wrong breakpoint ID %d, expected %d", dump.BreakpointID, bp.ID)
+			}
+			if dump.GoroutineID != goid {
+				t.Fatalf("wrong goroutine ID %d, expected %d", dump.GoroutineID, goid)
+			}
+			agoroutines := 0
+			for _, gs := range dump.Goroutines {
+				for _, frame := range gs.Stacktrace {
+					if frame.Function != nil && frame.Function.Name() == "main.agoroutine" {
+						agoroutines++
+						break
+					}
+				}
+				if gs.Goroutine.ID != goid {
+					continue
+				}
+				for _, frame := range gs.Stacktrace {
+					if frame.Function != nil && frame.Function.Name() == "main.func3" {
+						t.Fatal("goroutine dump recorded on the second hit of the breakpoint")
+					}
+				}
+			}
+			if agoroutines < 10 {
+				t.Fatalf("expected at least 10 goroutines in main.agoroutine, found %d", agoroutines)
+			}
+		}
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		goid := state.SelectedGoroutine.ID
+		dump, err := c.GetGoroutineDump(bp.ID)
+		assertNoError(err, t, "GetGoroutineDump()")
+		checkDump(dump, goid)
+
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatal("breakpoint not hit a second time")
+		}
+		dump, err = c.GetGoroutineDump(bp.ID)
+		assertNoError(err, t, "GetGoroutineDump()")
+		checkDump(dump, goid)
+
+		_, err = c.ClearBreakpoint(bp.ID)
+		assertNoError(err, t, "ClearBreakpoint()")
+		_, err = c.GetGoroutineDump(bp.ID)
+		assertError(err, t, "GetGoroutineDump() after the breakpoint was cleared")
+	})
+}
+
 func TestClientServer_switchThread(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestClient2("testnextprog", t, func(c service.Client) {
@@ -680,6 +852,32 @@ func TestClientServer_infoLocals(t *testing.T) {
 	})
 }
 
+func TestClientServer_infoLocalsFiltered(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		fp := testProgPath(t, "testnextprog")
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 24})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		locals, err := c.ListLocalVariablesFiltered(api.EvalScope{GoroutineID: -1}, "^j$", normalLoadConfig)
+		assertNoError(err, t, "ListLocalVariablesFiltered()")
+		if len(locals) != 1 || locals[0].Name != "j" {
+			t.Fatalf("Expected only j, got %#v", locals)
+		}
+
+		locals, err = c.ListLocalVariablesFiltered(api.EvalScope{GoroutineID: -1}, "", normalLoadConfig)
+		assertNoError(err, t, "ListLocalVariablesFiltered()")
+		if len(locals) != 3 {
+			t.Fatalf("Expected 3 locals, got %d %#v", len(locals), locals)
+		}
+
+		_, err = c.ListLocalVariablesFiltered(api.EvalScope{GoroutineID: -1}, "(", normalLoadConfig)
+		assertError(err, t, "ListLocalVariablesFiltered() with invalid regexp")
+	})
+}
+
 func TestClientServer_infoArgs(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestClient2("testnextprog", t, func(c service.Client) {
@@ -972,6 +1170,101 @@ func TestClientServer_FindLocationsExactMatch(t *testing.T) {
 	})
 }
 
+func TestClientServer_FindLocationCandidates(t *testing.T) {
+	withTestClient2("locationsprog3", t, func(c service.Client) {
+		<-c.Continue()
+		scope := api.EvalScope{GoroutineID: -1}
+
+		_, err := c.FindLocation(scope, "Intn", false, nil)
+		assertError(err, t, "FindLocation(Intn)")
+
+		for _, tc := range []struct {
+			loc  string
+			tgts []string
+		}{
+			{"Intn", []string{"math/rand.(*Rand).Intn", "math/rand.Intn"}},
+			// the exact match comes first
+			{"math/rand.Intn", []string{"math/rand.Intn", "math/rand.(*Rand).Intn"}},
+		} {
+			locs, err := c.FindLocationCandidates(scope, tc.loc, false, nil)
+			assertNoError(err, t, fmt.Sprintf("FindLocationCandidates(%s)", tc.loc))
+			var names []string
+			for _, loc := range locs {
+				names = append(names, loc.Function.Name())
+			}
+			t.Logf("%s: %v", tc.loc, names)
+			if len(names) < len(tc.tgts) || !reflect.DeepEqual(names[:len(tc.tgts)], tc.tgts) {
+				t.Errorf("wrong candidates for %s: %v (expected %v first)", tc.loc, names, tc.tgts)
+			}
+			for _, loc := range locs {
+				if loc.PC == 0 {
+					t.Errorf("no address for candidate %s", loc.Function.Name())
+				}
+			}
+		}
+
+		// unambiguous locations are returned as they are by FindLocation
+		locs, err := c.FindLocationCandidates(scope, "main.main", false, nil)
+		assertNoError(err, t, "FindLocationCandidates(main.main)")
+		if len(locs) != 1 || locs[0].Function.Name() != "main.main" {
+			t.Errorf("wrong locations for main.main: %v", locs)
+		}
+	})
+}
+
+func TestClientServer_ResolveAddress(t *testing.T) {
+	withTestClient2("locationsprog", t, func(c service.Client) {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "main.main", false, nil)
+		assertNoError(err, t, "FindLocation(main.main)")
+		if len(locs) != 1 {
+			t.Fatalf("wrong number of locations for main.main: %d", len(locs))
+		}
+
+		loc, err := c.ResolveAddress(locs[0].PC)
+		assertNoError(err, t, "ResolveAddress()")
+		t.Logf("%#x: %s:%d %s+%#x", loc.PC, loc.File, loc.Line, loc.Symbol, loc.SymbolOffset)
+		if loc.Function == nil || loc.Function.Name() != "main.main" {
+			t.Fatalf("wrong function %v", loc.Function)
+		}
+		if loc.File != locs[0].File || loc.Line != locs[0].Line {
+			t.Errorf("wrong file:line %s:%d, expected %s:%d", loc.File, loc.Line, locs[0].File, locs[0].Line)
+		}
+		if loc.Symbol != "main.main" || loc.SymbolOffset != locs[0].PC-loc.Function.Value {
+			t.Errorf("wrong symbol %s+%#x", loc.Symbol, loc.SymbolOffset)
+		}
+
+		_, err = c.ResolveAddress(0)
+		assertError(err, t, "ResolveAddress(0)")
+	})
+}
+
+func TestClientServer_DisassembleFunction(t *testing.T) {
+	withTestClient2("locationsprog3", t, func(c service.Client) {
+		<-c.Continue()
+		scope := api.EvalScope{GoroutineID: -1}
+
+		// exact match is prioritized over math/rand.(*Rand).Intn
+		insts, err := c.DisassembleFunction(scope, "math/rand.Intn", api.IntelFlavour)
+		assertNoError(err, t, "DisassembleFunction(math/rand.Intn)")
+		if len(insts) == 0 {
+			t.Fatal("no instructions returned")
+		}
+		if fn := insts[0].Loc.Function; fn == nil || fn.Name() != "math/rand.Intn" {
+			t.Fatalf("wrong function disassembled: %v", fn)
+		}
+		insts2, err := c.DisassemblePC(scope, insts[0].Loc.PC, api.IntelFlavour)
+		assertNoError(err, t, "DisassemblePC")
+		if len(insts) != len(insts2) {
+			t.Fatalf("mismatched number of instructions: %d %d", len(insts), len(insts2))
+		}
+
+		_, err = c.DisassembleFunction(scope, "Intn", api.IntelFlavour)
+		assertError(err, t, "DisassembleFunction(Intn)")
+		_, err = c.DisassembleFunction(scope, "main.main:1", api.IntelFlavour)
+		assertError(err, t, "DisassembleFunction(main.main:1)")
+	})
+}
+
 func TestClientServer_EvalVariable(t *testing.T) {
 	withTestClient2("testvariables", t, func(c service.Client) {
 		state := <-c.Continue()
@@ -1412,6 +1705,160 @@ func TestIssue419(t *testing.T) {
 	})
 }
 
+func TestWhatIs(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		scope := api.EvalScope{GoroutineID: -1}
+
+		ptrSize := int64(strconv.IntSize / 8)
+		testcases := []api.TypeInfo{
+			{Type: "main.astruct", RealType: "struct main.astruct", Kind: reflect.Struct, Size: 2 * ptrSize, Align: ptrSize},
+			{Type: "[4]int", RealType: "[4]int", Kind: reflect.Array, Size: 4 * ptrSize, Align: ptrSize},
+			{Type: "complex128", RealType: "complex128", Kind: reflect.Complex128, Size: 16, Align: 8},
+			{Type: "interface {}", RealType: "interface {}", ConcreteType: "*main.astruct", Kind: reflect.Interface, Size: 2 * ptrSize, Align: ptrSize},
+			{Type: "error", RealType: "error", ConcreteType: "*main.astruct", Kind: reflect.Interface, Size: 2 * ptrSize, Align: ptrSize},
+			{Type: "interface {}", RealType: "interface {}", Kind: reflect.Interface, Size: 2 * ptrSize, Align: ptrSize},
+		}
+		for i, expr := range []string{"as1", "arr1", "cpx1", "iface1", "err1", "ifacenil"} {
+			ti, err := c.WhatIs(scope, expr)
+			assertNoError(err, t, fmt.Sprintf("WhatIs(%s)", expr))
+			tgt := testcases[i]
+			if ptrSize == 4 && tgt.Kind == reflect.Complex128 {
+				tgt.Align = 4
+			}
+			if *ti != tgt {
+				t.Errorf("%s: expected %#v got %#v", expr, tgt, *ti)
+			}
+		}
+
+		_, err := c.WhatIs(scope, "nonexistentvariable")
+		assertError(err, t, "WhatIs(nonexistentvariable)")
+	})
+}
+
+func TestVariableTruncated(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		scope := api.EvalScope{GoroutineID: -1}
+
+		eval := func(expr string, cfg api.LoadConfig) *api.Variable {
+			v, err := c.EvalVariable(scope, expr, cfg)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", expr))
+			return v
+		}
+
+		if v := eval("longstr", normalLoadConfig); !v.LenTruncated || v.CapTruncated || v.RecurseTruncated {
+			t.Errorf("longstr: %#v", v)
+		}
+		if v := eval("longstr", api.LoadConfig{MaxStringLen: 1000}); v.LenTruncated {
+			t.Errorf("longstr: truncated with a large MaxStringLen")
+		}
+
+		cfg := normalLoadConfig
+		cfg.MaxArrayValues = 2
+		if v := eval("s2", cfg); !v.CapTruncated || v.LenTruncated || len(v.Children) != 2 {
+			t.Errorf("s2: %#v", v)
+		}
+		if v := eval("m1", cfg); !v.CapTruncated || len(v.Children) != 4 {
+			t.Errorf("m1: %#v", v)
+		}
+		if v := eval("a1", normalLoadConfig); v.CapTruncated {
+			t.Errorf("a1: truncated with a large MaxArrayValues")
+		}
+
+		cfg = normalLoadConfig
+		cfg.MaxVariableRecurse = 0
+		v := eval("c1", cfg)
+		if v.RecurseTruncated || len(v.Children) != 2 {
+			t.Fatalf("c1: %#v", v)
+		}
+		if pb := v.Children[0].Children[0]; !pb.RecurseTruncated || len(pb.Children) != 0 {
+			t.Errorf("c1.pb: %#v", pb)
+		}
+		if v := eval("c1", normalLoadConfig); v.Children[0].Children[0].RecurseTruncated {
+			t.Errorf("c1.pb: truncated with MaxVariableRecurse 1")
+		}
+	})
+}
+
+func TestOutputRadix(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		scope := api.EvalScope{GoroutineID: -1}
+
+		eval := func(expr string) string {
+			v, err := c.EvalVariable(scope, expr, normalLoadConfig)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", expr))
+			return v.SinglelineString()
+		}
+
+		c.SetOutputRadix(16)
+		if v := eval("i1 + 254"); v != "0xff" {
+			t.Errorf("wrong value in base 16: %s", v)
+		}
+		if v := eval("ni8"); v != "-0x5" {
+			t.Errorf("wrong value of ni8 in base 16: %s", v)
+		}
+		locals, err := c.ListLocalVariables(scope, normalLoadConfig)
+		assertNoError(err, t, "ListLocalVariables()")
+		for _, v := range locals {
+			if v.Name == "i3" && v.Value != "0x3" {
+				t.Errorf("wrong value of i3 in base 16: %s", v.Value)
+			}
+		}
+
+		c.SetOutputRadix(8)
+		if v := eval("i1 + 7"); v != "010" {
+			t.Errorf("wrong value in base 8: %s", v)
+		}
+
+		c.SetOutputRadix(10)
+		if v := eval("i1 + 254"); v != "255" {
+			t.Errorf("wrong value in base 10: %s", v)
+		}
+	})
+}
+
+func TestReferencesTo(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		p1, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "p1", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(p1)")
+		addr := p1.Children[0].Addr
+		t.Logf("&i1 = %#x", addr)
+
+		refs, err := c.ReferencesTo(addr, 0)
+		assertNoError(err, t, "ReferencesTo()")
+		found := map[string]bool{}
+		for _, ref := range refs {
+			t.Logf("goroutine %d frame %d %s %#x %s+%d", ref.GoroutineID, ref.Frame, ref.Location.Function.Name(), ref.Addr, ref.Variable, ref.Offset)
+			if ref.GoroutineID == state.SelectedGoroutine.ID && ref.Location.Function.Name() == "main.main" {
+				found[ref.Variable] = true
+			}
+		}
+		for _, name := range []string{"p1", "up1"} {
+			if !found[name] {
+				t.Errorf("reference from %s not found", name)
+			}
+		}
+
+		refs, err = c.ReferencesTo(addr, 1)
+		assertNoError(err, t, "ReferencesTo(maxResults = 1)")
+		if len(refs) != 1 {
+			t.Errorf("wrong number of references returned: %d", len(refs))
+		}
+	})
+}
+
 func TestTypesCommand(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestClient2("testvariables2", t, func(c service.Client) {
@@ -1701,6 +2148,60 @@ func TestClientServer_ReverseContinue(t *testing.T) {
 	})
 }
 
+func TestClientServer_ReverseNextStep(t *testing.T) {
+	protest.AllowRecording(t)
+	if testBackend != "rr" {
+		t.Skip("backend is not rr")
+	}
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: "testnextprog.go", Line: 24})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+		if state.CurrentThread.Line != 24 {
+			t.Fatalf("wrong line after continue: %d", state.CurrentThread.Line)
+		}
+
+		state, err = c.Next()
+		assertNoError(err, t, "Next")
+		if state.CurrentThread.Line != 26 {
+			t.Fatalf("wrong line after next: %d", state.CurrentThread.Line)
+		}
+
+		state, err = c.ReverseNext()
+		assertNoError(err, t, "ReverseNext")
+		if state.CurrentThread.Line != 24 {
+			t.Fatalf("wrong line after reverse next: %d", state.CurrentThread.Line)
+		}
+
+		state, err = c.Next()
+		assertNoError(err, t, "Next")
+		state, err = c.ReverseStep()
+		assertNoError(err, t, "ReverseStep")
+		if state.CurrentThread.Line != 24 {
+			t.Fatalf("wrong line after reverse step: %d", state.CurrentThread.Line)
+		}
+	})
+}
+
+func TestClientServer_ReverseNextNotRecorded(t *testing.T) {
+	if testBackend == "rr" {
+		t.Skip("backend is rr")
+	}
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.testnext", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		_, err = c.ReverseNext()
+		assertError(err, t, "ReverseNext")
+		_, err = c.ReverseStep()
+		assertError(err, t, "ReverseStep")
+	})
+}
+
 func TestClientServer_collectBreakpointInfoOnNext(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestClient2("testnextprog", t, func(c service.Client) {
@@ -1921,28 +2422,158 @@ func TestClientServerFunctionCall(t *testing.T) {
 	})
 }
 
-func TestClientServerFunctionCallBadPos(t *testing.T) {
+func TestClientServerCallMethod(t *testing.T) {
 	protest.MustSupportFunctionCalls(t, testBackend)
-	if goversion.VersionAfterOrEqual(runtime.Version(), 1, 12) {
-		t.Skip("this is a safe point for Go 1.12")
-	}
 	withTestClient2("fncall", t, func(c service.Client) {
-		loc, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "fmt/print.go:649", false, nil)
-		assertNoError(err, t, "could not find location")
-
-		_, err = c.CreateBreakpoint(&api.Breakpoint{File: loc[0].File, Line: loc[0].Line})
-		assertNoError(err, t, "CreateBreakpoin")
-
+		c.SetReturnValuesLoadConfig(&normalLoadConfig)
 		state := <-c.Continue()
 		assertNoError(state.Err, t, "Continue()")
+		scope := api.EvalScope{GoroutineID: -1}
 
-		state = <-c.Continue()
-		assertNoError(state.Err, t, "Continue()")
+		for _, tc := range []struct {
+			receiver, method, arg, tgt string
+		}{
+			{"a", "VRcvr", "1", "1 + 3 = 4"},
+			{"a", "PRcvr", "4", "4 - 3 = 1"},
+			{"pa", "VRcvr", "1", "1 + 6 = 7"},
+			{"pa", "PRcvr", "7", "7 - 6 = 1"},
+			{"vable_pa", "VRcvr", "2", "2 + 6 = 8"},
+			{"pable_pa", "PRcvr", "8", "8 - 6 = 2"},
+			{"registry", "Register", `"a"`, "0"},
+			{"nilRegistry", "Lookup", `"a"`, "0"}, // reading a nil map is fine
+		} {
+			state, err := c.CallMethod(scope, tc.receiver, tc.method, []string{tc.arg})
+			assertNoError(err, t, fmt.Sprintf("CallMethod(%s, %s)", tc.receiver, tc.method))
+			if len(state.CurrentThread.ReturnValues) != 1 || state.CurrentThread.ReturnValues[0].Value != tc.tgt {
+				t.Errorf("%s.%s(%s): wrong return values %v", tc.receiver, tc.method, tc.arg, state.CurrentThread.ReturnValues)
+			}
+		}
 
-		c.SetReturnValuesLoadConfig(&normalLoadConfig)
-		state, err = c.Call(-1, "main.call1(main.zero, main.zero)", false)
-		if err == nil || err.Error() != "call not at safe point" {
-			t.Fatalf("wrong error or no error: %v", err)
+		for _, tc := range []struct {
+			receiver, method, arg, tgtErr string
+		}{
+			{"pa2", "VRcvr", "1", "nil pointer dereference"},
+			{"a", "Nonexistent", "1", "has no method"},
+			{"a", "VRcvr(1)", "1", "invalid method name"},
+			{"call1(one, two)", "VRcvr", "1", ""},
+			{"nilRegistry", "Register", `"a"`, "assignment to entry in nil map"},
+		} {
+			_, err := c.CallMethod(scope, tc.receiver, tc.method, []string{tc.arg})
+			assertError(err, t, fmt.Sprintf("CallMethod(%s, %s)", tc.receiver, tc.method))
+			if err != nil && !strings.Contains(err.Error(), tc.tgtErr) {
+				t.Errorf("CallMethod(%s, %s): wrong error %q, expected %q", tc.receiver, tc.method, err, tc.tgtErr)
+			}
+		}
+	})
+}
+
+func TestClientServerFunctionCallTimeout(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("fncallblock", t, func(c service.Client) {
+		c.SetReturnValuesLoadConfig(&normalLoadConfig)
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		state, err := c.CallWithTimeout(-1, "quick()", false, 10*time.Second)
+		assertNoError(err, t, "CallWithTimeout(quick())")
+		if len(state.CurrentThread.ReturnValues) != 1 || state.CurrentThread.ReturnValues[0].Value != "42" {
+			t.Fatalf("wrong return values %v", state.CurrentThread.ReturnValues)
+		}
+
+		// a running call is unwound
+		t0 := time.Now()
+		_, err = c.CallWithTimeout(-1, "spin()", false, 500*time.Millisecond)
+		assertError(err, t, "CallWithTimeout(spin())")
+		if time.Since(t0) > 10*time.Second {
+			t.Fatalf("call was not stopped in time")
+		}
+		if !strings.Contains(err.Error(), "was aborted") {
+			t.Fatalf("call not aborted: %v", err)
+		}
+		if _, err := c.CallInjectionStack(); err == nil {
+			t.Fatalf("call still in progress after being aborted")
+		}
+		state, err = c.CallWithTimeout(-1, "quick()", false, 10*time.Second)
+		assertNoError(err, t, "CallWithTimeout(quick()) after abort")
+		if len(state.CurrentThread.ReturnValues) != 1 || state.CurrentThread.ReturnValues[0].Value != "42" {
+			t.Fatalf("wrong return values after abort %v", state.CurrentThread.ReturnValues)
+		}
+
+		// a blocked call can not be unwound and is left in progress
+		t0 = time.Now()
+		_, err = c.CallWithTimeout(-1, "block()", false, 500*time.Millisecond)
+		assertError(err, t, "CallWithTimeout(block())")
+		if time.Since(t0) > 10*time.Second {
+			t.Fatalf("call was not stopped in time")
+		}
+
+		frames, err := c.CallInjectionStack()
+		assertNoError(err, t, "CallInjectionStack()")
+		found := false
+		for _, frame := range frames {
+			if frame.Function != nil && frame.Function.Name() == "main.block" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("main.block not found in the stack of the call in progress: %v", frames)
+		}
+	})
+}
+
+func TestClientServerFunctionCallClosure(t *testing.T) {
+	// Calling a function value calls the closure it points to, using its
+	// closure context.
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("fncall", t, func(c service.Client) {
+		c.SetReturnValuesLoadConfig(&normalLoadConfig)
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		for i, tc := range []struct {
+			expr string
+			tgt  string
+		}{
+			{"fn2clos(11)", "1 + 6 + 11 = 18"},
+			{"fn2clos(12)", "2 + 6 + 12 = 20"},
+		} {
+			state, err := c.Call(-1, tc.expr, false)
+			assertNoError(err, t, fmt.Sprintf("Call(%q)", tc.expr))
+			if len(state.CurrentThread.ReturnValues) != 1 {
+				t.Fatalf("%d: wrong number of return values %v", i, state.CurrentThread.ReturnValues)
+			}
+			if state.CurrentThread.ReturnValues[0].Value != tc.tgt {
+				t.Fatalf("%d: wrong return value %q (expected %q)", i, state.CurrentThread.ReturnValues[0].Value, tc.tgt)
+			}
+		}
+
+		_, err := c.Call(-1, "fn2nil()", false)
+		assertError(err, t, "Call(fn2nil)")
+	})
+}
+
+func TestClientServerFunctionCallBadPos(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	if goversion.VersionAfterOrEqual(runtime.Version(), 1, 12) {
+		t.Skip("this is a safe point for Go 1.12")
+	}
+	withTestClient2("fncall", t, func(c service.Client) {
+		loc, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "fmt/print.go:649", false, nil)
+		assertNoError(err, t, "could not find location")
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{File: loc[0].File, Line: loc[0].Line})
+		assertNoError(err, t, "CreateBreakpoin")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		c.SetReturnValuesLoadConfig(&normalLoadConfig)
+		state, err = c.Call(-1, "main.call1(main.zero, main.zero)", false)
+		if err == nil || err.Error() != "call not at safe point" {
+			t.Fatalf("wrong error or no error: %v", err)
 		}
 	})
 }
@@ -1972,6 +2603,64 @@ func TestClientServerFunctionCallPanic(t *testing.T) {
 	})
 }
 
+func TestClientServerCallInjectionStack(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("fncall", t, func(c service.Client) {
+		c.SetReturnValuesLoadConfig(&normalLoadConfig)
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		_, err := c.CallInjectionStack()
+		assertError(err, t, "CallInjectionStack() without a call in progress")
+
+		state, err = c.Call(-1, "callbreak()", false)
+		assertNoError(err, t, "Call()")
+		t.Logf("at: %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+
+		frames, err := c.CallInjectionStack()
+		assertNoError(err, t, "CallInjectionStack()")
+		found := false
+		for _, frame := range frames {
+			t.Logf("\t%s", frame.Function.Name())
+			if frame.Function.Name() == "main.callbreak" {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("main.callbreak not found in call injection stack")
+		}
+		if last := frames[len(frames)-1].Function.Name(); !strings.HasPrefix(last, "runtime.debugCall") {
+			t.Errorf("last frame of call injection stack is not a debug call function: %s", last)
+		}
+	})
+}
+
+func TestClientServerFunctionCallPanicWrapped(t *testing.T) {
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("fncall", t, func(c service.Client) {
+		c.SetReturnValuesLoadConfig(&normalLoadConfig)
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		state, err := c.Call(-1, "callpanicwrapped()", false)
+		assertNoError(err, t, "Call()")
+		if len(state.CurrentThread.ReturnValues) != 1 || state.CurrentThread.ReturnValues[0].Name != "~panic" {
+			t.Fatalf("not a panic: %v", state.CurrentThread.ReturnValues)
+		}
+		chain := state.CurrentThread.ReturnValues[0].Children
+		if len(chain) != 3 {
+			t.Fatalf("wrong number of unwrapped errors: %d", len(chain))
+		}
+		for i, tgt := range []string{"*fmt.wrapError", "PathError", "*errors.errorString"} {
+			if i > 0 && chain[i].Name != "~unwrapped" {
+				t.Errorf("%d: wrong name %q", i, chain[i].Name)
+			}
+			if !strings.Contains(chain[i].SinglelineString(), tgt) {
+				t.Errorf("%d: expected %s got %s", i, tgt, chain[i].SinglelineString())
+			}
+		}
+	})
+}
+
 func TestClientServerFunctionCallStacktrace(t *testing.T) {
 	if goversion.VersionAfterOrEqual(runtime.Version(), 1, 15) {
 		t.Skip("Go 1.15 executes function calls in a different goroutine so the stack trace will not contain main.main or runtime.main")
@@ -2211,6 +2900,33 @@ func TestClearLogicalBreakpoint(t *testing.T) {
 	})
 }
 
+func TestStacktraceInlinedFrames(t *testing.T) {
+	withTestClient2Extended("testinline", t, protest.EnableInlining, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.inlineThis"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		frames, err := c.Stacktrace(-1, 10, 0, nil)
+		assertNoError(err, t, "Stacktrace()")
+		if len(frames) < 2 {
+			t.Fatalf("stacktrace too short: %#v", frames)
+		}
+		for i, frame := range frames[:2] {
+			t.Logf("%d %#x %s inlined=%v parent=%#x", i, frame.PC, frame.Function.Name(), frame.Inlined, frame.InlineParentPC)
+		}
+		if frames[0].Function.Name() != "main.inlineThis" || !frames[0].Inlined {
+			t.Errorf("frame 0 is not the inlined call of main.inlineThis: %#v", frames[0].Location)
+		}
+		if frames[1].Function.Name() != "main.main" || frames[1].Inlined || frames[1].InlineParentPC != 0 {
+			t.Errorf("frame 1 is not the physical frame of main.main: %#v", frames[1])
+		}
+		if frames[0].InlineParentPC == 0 || frames[0].InlineParentPC > frames[1].PC {
+			t.Errorf("wrong InlineParentPC %#x for a call inlined in main.main at %#x", frames[0].InlineParentPC, frames[1].PC)
+		}
+	})
+}
+
 func TestRedirects(t *testing.T) {
 	const (
 		infile  = "redirect-input.txt"
@@ -2319,6 +3035,46 @@ func TestDetachLeaveRunning(t *testing.T) {
 	assertNoError(client.Detach(false), t, "Detach")
 }
 
+func TestDetachWithSignal(t *testing.T) {
+	if testBackend == "rr" || runtime.GOOS == "windows" {
+		t.Skip("N/A")
+	}
+
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	var buildFlags protest.BuildFlags
+	if buildMode == "pie" {
+		buildFlags |= protest.BuildModePIE
+	}
+	fixture := protest.BuildFixture("loopprog", buildFlags)
+
+	cmd := exec.Command(fixture.Path)
+	assertNoError(cmd.Start(), t, "starting fixture")
+	defer cmd.Process.Kill()
+
+	server := rpccommon.NewServer(&service.Config{
+		Listener:   listener,
+		APIVersion: 2,
+		Debugger: debugger.Config{
+			AttachPid:  cmd.Process.Pid,
+			WorkingDir: ".",
+			Backend:    testBackend,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := rpc2.NewClientFromConn(clientConn)
+	assertNoError(client.DetachWithSignal(int(syscall.SIGTERM)), t, "DetachWithSignal")
+
+	cmd.Wait()
+	if ws := cmd.ProcessState.Sys().(syscall.WaitStatus); !ws.Signaled() || ws.Signal() != syscall.SIGTERM {
+		t.Fatalf("process not terminated by SIGTERM: %v", cmd.ProcessState)
+	}
+}
+
 func assertNoDuplicateBreakpoints(t *testing.T, c service.Client) {
 	t.Helper()
 	bps, _ := c.ListBreakpoints()
@@ -2407,6 +3163,108 @@ func TestGoroutinesGrouping(t *testing.T) {
 	})
 }
 
+func TestSetGoroutineLabels(t *testing.T) {
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		gs, _, err := c.ListGoroutines(0, 0)
+		assertNoError(err, t, "ListGoroutines")
+		if len(gs) < 2 {
+			t.Fatalf("not enough goroutines %d", len(gs))
+		}
+		gid1, gid2 := gs[0].ID, gs[1].ID
+		assertNoError(c.SetGoroutineLabels(gid1, map[string]string{"role": "worker"}), t, "SetGoroutineLabels")
+		assertNoError(c.SetGoroutineLabels(gid2, map[string]string{"role": "worker", "n": "2"}), t, "SetGoroutineLabels")
+
+		_, ggrp, _, _, err := c.ListGoroutinesWithFilter(0, 0, nil, &api.GoroutineGroupingOptions{GroupBy: api.GoroutineLabel, GroupByKey: "role", MaxGroupMembers: 5, MaxGroups: 10})
+		assertNoError(err, t, "ListGoroutinesWithFilter (group by label)")
+		t.Logf("%#v", ggrp)
+		found := false
+		for i := range ggrp {
+			if ggrp[i].Name == "role=worker" {
+				found = true
+				if ggrp[i].Total != 2 {
+					t.Errorf("wrong number of goroutines in group role=worker: %d", ggrp[i].Total)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("group role=worker not found")
+		}
+
+		filterWorkers := func(n int) []*api.Goroutine {
+			t.Helper()
+			gs, _, _, _, err := c.ListGoroutinesWithFilter(0, 0, []api.ListGoroutinesFilter{{Kind: api.GoroutineLabel, Arg: "role=worker"}}, nil)
+			assertNoError(err, t, "ListGoroutinesWithFilter (filter by label)")
+			if len(gs) != n {
+				t.Fatalf("wrong number of goroutines returned by filter: %d (expected %d)", len(gs), n)
+			}
+			return gs
+		}
+
+		gs = filterWorkers(2)
+		for _, g := range gs {
+			if g.Labels["role"] != "worker" {
+				t.Errorf("wrong labels for goroutine %d: %v", g.ID, g.Labels)
+			}
+		}
+
+		assertNoError(c.SetGoroutineLabels(gid1, nil), t, "SetGoroutineLabels (clear)")
+		gs = filterWorkers(1)
+		if gs[0].ID != gid2 || gs[0].Labels["n"] != "2" {
+			t.Errorf("wrong goroutine returned by filter: %d %v", gs[0].ID, gs[0].Labels)
+		}
+
+		assertError(c.SetGoroutineLabels(100000, map[string]string{"role": "worker"}), t, "SetGoroutineLabels (unknown goroutine)")
+	})
+}
+
+func TestGoroutineStatusFilter(t *testing.T) {
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		gs, _, _, _, err := c.ListGoroutinesWithFilter(0, 0, []api.ListGoroutinesFilter{{Kind: api.GoroutineStatus, Arg: "running"}}, nil)
+		assertNoError(err, t, "ListGoroutinesWithFilter (running)")
+		found := false
+		for _, g := range gs {
+			if g.Status&^api.Gscan != api.Grunning {
+				t.Errorf("goroutine %d has status %s", g.ID, api.GoroutineStatusName(g.Status))
+			}
+			if g.ID == state.SelectedGoroutine.ID {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("selected goroutine %d not returned by the running filter", state.SelectedGoroutine.ID)
+		}
+
+		gs, _, _, _, err = c.ListGoroutinesWithFilter(0, 0, []api.ListGoroutinesFilter{{Kind: api.GoroutineStatus, Arg: "waiting"}}, nil)
+		assertNoError(err, t, "ListGoroutinesWithFilter (waiting)")
+		if len(gs) < 10 {
+			t.Errorf("not enough waiting goroutines %d", len(gs))
+		}
+		for _, g := range gs {
+			if g.Status&^api.Gscan != api.Gwaiting {
+				t.Errorf("goroutine %d has status %s", g.ID, api.GoroutineStatusName(g.Status))
+			}
+		}
+
+		_, ggrp, _, _, err := c.ListGoroutinesWithFilter(0, 0, nil, &api.GoroutineGroupingOptions{GroupBy: api.GoroutineStatus, MaxGroupMembers: 5, MaxGroups: 10})
+		assertNoError(err, t, "ListGoroutinesWithFilter (group by status)")
+		for i := range ggrp {
+			if ggrp[i].Name == "status=waiting" && ggrp[i].Total != len(gs) {
+				t.Errorf("wrong number of goroutines in group status=waiting: %d (expected %d)", ggrp[i].Total, len(gs))
+			}
+		}
+	})
+}
+
 func TestLongStringArg(t *testing.T) {
 	// Test the ability to load more elements of a string argument, this could
 	// be broken if registerized variables are not handled correctly.
@@ -2441,3 +3299,2354 @@ func TestLongStringArg(t *testing.T) {
 		}
 	})
 }
+
+func TestReadString(t *testing.T) {
+	const (
+		sval = "very long string 012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789X"
+		qval = "very long string B 012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789X2"
+	)
+	withTestClient2("morestringarg", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.f"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		scope := api.EvalScope{GoroutineID: -1}
+		s, err := c.ReadString(scope, "s")
+		assertNoError(err, t, "ReadString(s)")
+		if s != sval {
+			t.Errorf("wrong value for s: %q", s)
+		}
+		b, err := c.ReadBytes(scope, "q")
+		assertNoError(err, t, "ReadBytes(q)")
+		if string(b) != qval {
+			t.Errorf("wrong value for q: %q", b)
+		}
+		s, err = c.ReadString(scope, "s[17:]")
+		assertNoError(err, t, "ReadString(s[17:])")
+		if s != sval[17:] {
+			t.Errorf("wrong value for s[17:]: %q", s)
+		}
+
+		_, err = c.ReadString(scope, "len(s)")
+		assertError(err, t, "ReadString(len(s))")
+
+		c.SetReadLimit(len(sval) - 1)
+		_, err = c.ReadString(scope, "s")
+		assertError(err, t, "ReadString(s) with a limit shorter than s")
+		c.SetReadLimit(0)
+	})
+}
+
+func TestCreateBreakpointFallbackLocations(t *testing.T) {
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{File: "nonexistent.go", Line: 1, FallbackLocations: []string{"main.nonexistent", "main.helloworld"}})
+		assertNoError(err, t, "CreateBreakpoint (with fallback)")
+		if bp.FunctionName != "main.helloworld" {
+			t.Fatalf("breakpoint set in wrong function %q", bp.FunctionName)
+		}
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{File: "nonexistent.go", Line: 1, FallbackLocations: []string{"main.nonexistent"}})
+		assertError(err, t, "CreateBreakpoint (all fallbacks fail)")
+
+		_, err = c.ClearBreakpoint(bp.ID)
+		assertNoError(err, t, "ClearBreakpoint()")
+		bp, err = c.CreateBreakpoint(&api.Breakpoint{File: "nonexistent.go", Line: 1, FallbackLocations: []string{"/^main\\.(helloworld|sleepytime)$/"}})
+		assertNoError(err, t, "CreateBreakpoint (ambiguous fallback)")
+		if len(bp.Addrs) < 2 {
+			t.Fatalf("ambiguous fallback location resolved to %#x, expected one address for each function", bp.Addrs)
+		}
+	})
+}
+
+func TestListSourcesGrouped(t *testing.T) {
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		fp := testProgPath(t, "testnextprog")
+		groups, err := c.ListSourcesGrouped("")
+		assertNoError(err, t, "ListSourcesGrouped")
+		found := false
+		for _, file := range groups["main"] {
+			if file == fp {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("could not find %s in package main: %v", fp, groups["main"])
+		}
+		if len(groups["runtime"]) == 0 {
+			t.Fatalf("no source files for package runtime")
+		}
+
+		groups, err = c.ListSourcesGrouped("testnextprog.go$")
+		assertNoError(err, t, "ListSourcesGrouped (filtered)")
+		if len(groups) != 1 || len(groups["main"]) != 1 {
+			t.Fatalf("wrong result for filtered ListSourcesGrouped: %v", groups)
+		}
+	})
+}
+
+func TestBreakpointsSharingAddress(t *testing.T) {
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		bp1, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		shared, err := c.BreakpointsSharingAddress()
+		assertNoError(err, t, "BreakpointsSharingAddress")
+		if len(shared) != 0 {
+			t.Fatalf("unexpected shared addresses: %v", shared)
+		}
+
+		_, err = c.ToggleBreakpoint(bp1.ID)
+		assertNoError(err, t, "ToggleBreakpoint")
+		bp2, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		shared, err = c.BreakpointsSharingAddress()
+		assertNoError(err, t, "BreakpointsSharingAddress")
+		t.Logf("%v", shared)
+		ids := shared[bp2.Addr]
+		if len(shared) != 1 || len(ids) != 2 || ids[0] != bp1.ID || ids[1] != bp2.ID {
+			t.Fatalf("wrong shared addresses: %v (expected %#x: [%d %d])", shared, bp2.Addr, bp1.ID, bp2.ID)
+		}
+	})
+}
+
+func TestStopReason(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: "testnextprog.go", Line: 34})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		assertStopReason := func(state *api.DebuggerState, err error, tgt api.StopReason, cmd string) {
+			t.Helper()
+			assertNoError(err, t, cmd)
+			if state.StopReason != tgt {
+				t.Fatalf("wrong stop reason after %s: %q (expected %q)", cmd, state.StopReason, tgt)
+			}
+		}
+
+		state := <-c.Continue()
+		assertStopReason(state, state.Err, api.StopBreakpoint, "Continue")
+		state, err = c.Step()
+		assertStopReason(state, err, api.StopStep, "Step")
+		state, err = c.Next()
+		assertStopReason(state, err, api.StopNext, "Next")
+		state, err = c.StepOut()
+		assertStopReason(state, err, api.StopStepOut, "StepOut")
+		state, err = c.StepInstruction()
+		assertStopReason(state, err, api.StopStepInstruction, "StepInstruction")
+		state, err = c.Halt()
+		assertStopReason(state, err, api.StopManual, "Halt")
+	})
+}
+
+func TestExportChromeTrace(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		if testBackend != "rr" {
+			_, err := c.ExportChromeTrace(0, -1)
+			assertError(err, t, "ExportChromeTrace")
+			return
+		}
+
+		// user breakpoints are not hit while the trace is collected
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "runtime.execute"})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		buf, err := c.ExportChromeTrace(0, -1)
+		assertNoError(err, t, "ExportChromeTrace")
+
+		bp, err = c.GetBreakpoint(bp.ID)
+		assertNoError(err, t, "GetBreakpoint")
+		if bp.TotalHitCount != 0 {
+			t.Errorf("user breakpoint hit %d times during ExportChromeTrace", bp.TotalHitCount)
+		}
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints")
+		for _, bp := range bps {
+			if bp.ID > 0 && bp.FunctionName != "runtime.execute" {
+				t.Errorf("breakpoint left behind by ExportChromeTrace: %#v", bp)
+			}
+		}
+
+		var trace struct {
+			TraceEvents []struct {
+				Name string
+				Ph   string
+				Ts   int64
+				Dur  int64
+				Tid  int
+				Args map[string]int
+			} `json:"traceEvents"`
+		}
+		assertNoError(json.Unmarshal(buf, &trace), t, "Unmarshal")
+		if len(trace.TraceEvents) == 0 {
+			t.Fatal("no events in trace")
+		}
+		goids := map[int]bool{}
+		for _, ev := range trace.TraceEvents {
+			if ev.Ph != "X" || ev.Dur < 0 {
+				t.Errorf("malformed event %#v", ev)
+			}
+			goids[ev.Args["goid"]] = true
+		}
+		if len(goids) < 2 {
+			t.Errorf("expected more than one goroutine in the trace: %v", goids)
+		}
+	})
+}
+
+func TestValueHistory(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("databpeasy", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		if testBackend != "rr" {
+			_, err := c.ValueHistory(api.EvalScope{GoroutineID: -1}, "main.globalvar2", 0)
+			assertError(err, t, "ValueHistory")
+			return
+		}
+
+		// breakpoints are not hit while the value history is collected
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{File: filepath.Join(protest.FindFixturesDir(), "databpeasy.go"), Line: 21})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		samples, err := c.ValueHistory(api.EvalScope{GoroutineID: -1}, "main.globalvar2", 0)
+		assertNoError(err, t, "ValueHistory")
+		bp, err = c.GetBreakpoint(bp.ID)
+		assertNoError(err, t, "GetBreakpoint")
+		if bp.TotalHitCount != 0 {
+			t.Errorf("breakpoint hit while collecting the value history: %d", bp.TotalHitCount)
+		}
+		values := []string{}
+		for _, sample := range samples {
+			values = append(values, sample.Value.Value)
+		}
+		if tgt := []string{"0", "1", "2", "4"}; !reflect.DeepEqual(values, tgt) {
+			t.Errorf("wrong value history %v, expected %v", values, tgt)
+		}
+
+		samples, err = c.ValueHistory(api.EvalScope{GoroutineID: -1}, "main.globalvar2", 2)
+		assertNoError(err, t, "ValueHistory")
+		if len(samples) != 2 {
+			t.Errorf("wrong number of samples %d, expected 2", len(samples))
+		}
+
+		state2, err := c.GetState()
+		assertNoError(err, t, "GetState")
+		if state2.When != state.When {
+			t.Errorf("position not restored: %q, expected %q", state2.When, state.When)
+		}
+	})
+}
+
+func TestAmendBreakpointEx(t *testing.T) {
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint")
+
+		bp.Cond = "1 == 1"
+		amended, err := c.AmendBreakpointEx(bp)
+		assertNoError(err, t, "AmendBreakpointEx")
+		if amended.ID != bp.ID || amended.Cond != bp.Cond {
+			t.Fatalf("wrong breakpoint returned: %#v", amended)
+		}
+		if !reflect.DeepEqual(amended.Addrs, bp.Addrs) {
+			t.Fatalf("breakpoint addresses changed: %#x -> %#x", bp.Addrs, amended.Addrs)
+		}
+
+		bp.ID = 1000
+		_, err = c.AmendBreakpointEx(bp)
+		assertError(err, t, "AmendBreakpointEx(nonexistent)")
+	})
+}
+
+func TestCheckpointsNotRecorded(t *testing.T) {
+	if testBackend == "rr" {
+		t.Skip("backend is rr")
+	}
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.Checkpoint("")
+		assertError(err, t, "Checkpoint")
+		_, err = c.ListCheckpoints()
+		assertError(err, t, "ListCheckpoints")
+		err = c.ClearCheckpoint(1)
+		assertError(err, t, "ClearCheckpoint")
+	})
+}
+
+func TestVisibleNames(t *testing.T) {
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		names, err := c.VisibleNames(api.EvalScope{GoroutineID: -1})
+		assertNoError(err, t, "VisibleNames")
+
+		if !sort.StringsAreSorted(names) {
+			t.Errorf("names not sorted")
+		}
+		found := map[string]bool{}
+		for _, name := range names {
+			found[name] = true
+			if strings.ContainsAny(name, ".()") {
+				t.Errorf("unexpected name %q", name)
+			}
+		}
+		// locals, package variables, functions and packages
+		for _, tgt := range []string{"i1", "as1", "p1", "afunc", "main", "runtime"} {
+			if !found[tgt] {
+				t.Errorf("%q not found", tgt)
+			}
+		}
+	})
+}
+
+func TestStepInstructions(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		const n = 10
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		start := state.CurrentThread.PC
+
+		for i := 0; i < n; i++ {
+			state, err = c.StepInstruction()
+			assertNoError(err, t, fmt.Sprintf("StepInstruction() %d", i))
+		}
+		pc := state.CurrentThread.PC
+
+		if _, err := c.Restart(false); err != nil {
+			t.Fatalf("Restart(): %v", err)
+		}
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.PC != start {
+			t.Fatalf("wrong starting PC after restart %#x (expected %#x)", state.CurrentThread.PC, start)
+		}
+
+		state, err = c.StepInstructions(n)
+		assertNoError(err, t, "StepInstructions()")
+		if state.CurrentThread.PC != pc {
+			t.Errorf("wrong PC after StepInstructions(%d) %#x (expected %#x)", n, state.CurrentThread.PC, pc)
+		}
+		if state.StopReason != api.StopStepInstruction {
+			t.Errorf("wrong stop reason %q", state.StopReason)
+		}
+	})
+}
+
+func TestMutexOwner(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("mutexowner", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		scope := api.EvalScope{GoroutineID: -1, Frame: 1}
+		for _, expr := range []string{"unlocked", "&unlocked", "rwunlocked"} {
+			locked, gs, err := c.MutexOwner(scope, expr)
+			assertNoError(err, t, fmt.Sprintf("MutexOwner(%s)", expr))
+			if locked || len(gs) != 0 {
+				t.Errorf("MutexOwner(%s): expected unlocked mutex without candidates, got %v %d", expr, locked, len(gs))
+			}
+		}
+		for _, expr := range []string{"locked", "plocked", "rlocked", "wlocked"} {
+			locked, gs, err := c.MutexOwner(scope, expr)
+			assertNoError(err, t, fmt.Sprintf("MutexOwner(%s)", expr))
+			found := false
+			for _, g := range gs {
+				if g.ID == state.SelectedGoroutine.ID {
+					found = true
+				}
+			}
+			if !locked || !found {
+				t.Errorf("MutexOwner(%s): expected locked mutex with candidate %d, got %v %#v", expr, state.SelectedGoroutine.ID, locked, gs)
+			}
+		}
+		_, _, err := c.MutexOwner(scope, "notamutex")
+		assertError(err, t, "MutexOwner(notamutex)")
+	})
+}
+
+func TestChildren(t *testing.T) {
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 11) {
+		t.Skip("not supported on Go <= 1.10")
+	}
+	savedGodebug := os.Getenv("GODEBUG")
+	os.Setenv("GODEBUG", "tracebackancestors=100")
+	defer os.Setenv("GODEBUG", savedGodebug)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		children, err := c.Children(-1)
+		assertNoError(err, t, "Children")
+		if len(children) != 10 {
+			t.Fatalf("expected 10 children got %d", len(children))
+		}
+		for _, g := range children {
+			if g.StartLoc.Function == nil || g.StartLoc.Function.Name() != "main.agoroutine" {
+				t.Errorf("unexpected child goroutine %d started at %#v", g.ID, g.StartLoc)
+			}
+		}
+
+		children, err = c.Children(children[0].ID)
+		assertNoError(err, t, "Children")
+		if len(children) != 0 {
+			t.Errorf("expected no children got %d", len(children))
+		}
+	})
+}
+
+func TestTemporaryBreakpoint(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1, Temporary: true})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if !bp.Temporary {
+			t.Fatalf("breakpoint not temporary")
+		}
+		// not hit before the restart
+		_, err = c.CreateBreakpoint(&api.Breakpoint{File: "testnextprog.go", Line: 34, Temporary: true})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatalf("wrong breakpoint hit: %#v", state.CurrentThread.Breakpoint)
+		}
+		if _, err := c.GetBreakpoint(bp.ID); err == nil {
+			t.Errorf("temporary breakpoint %d not cleared after being hit", bp.ID)
+		}
+
+		discarded, err := c.Restart(false)
+		assertNoError(err, t, "Restart()")
+		if len(discarded) != 1 || !discarded[0].Breakpoint.Temporary {
+			t.Errorf("wrong discarded breakpoints: %#v", discarded)
+		}
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		for _, bp := range bps {
+			if bp.Temporary {
+				t.Errorf("temporary breakpoint %d survived restart", bp.ID)
+			}
+		}
+	})
+}
+
+func TestBuildInfo(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		bi, err := c.BuildInfo()
+		assertNoError(err, t, "BuildInfo()")
+		t.Logf("%#v", bi)
+		if bi.GoVersion != runtime.Version() {
+			t.Errorf("wrong Go version %q (expected %q)", bi.GoVersion, runtime.Version())
+		}
+	})
+}
+
+func TestBreakOnNextPanic(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("panic", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		gid := state.SelectedGoroutine.ID
+		othergid := gid + 1000
+
+		// armed on a different goroutine, must stay armed after main panics
+		armed, err := c.BreakOnNextPanic(othergid)
+		assertNoError(err, t, "BreakOnNextPanic()")
+		if !reflect.DeepEqual(armed, []int{othergid}) {
+			t.Errorf("wrong armed goroutines %v", armed)
+		}
+
+		armed, err = c.BreakOnNextPanic(-1)
+		assertNoError(err, t, "BreakOnNextPanic(-1)")
+		if !reflect.DeepEqual(armed, []int{gid, othergid}) {
+			t.Errorf("wrong armed goroutines %v", armed)
+		}
+
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.Name != "next-panic" {
+			t.Fatalf("not stopped at panic breakpoint: %#v", state.CurrentThread.Breakpoint)
+		}
+		if state.CurrentThread.Function == nil || state.CurrentThread.Function.Name() != "runtime.gopanic" {
+			t.Errorf("not stopped in runtime.gopanic: %#v", state.CurrentThread.Function)
+		}
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		for _, bp := range bps {
+			if bp.Name == "next-panic" {
+				t.Errorf("panic breakpoint listed as a user breakpoint: %#v", bp)
+			}
+		}
+
+		// the goroutine that panicked is disarmed, the other one is not
+		armed, err = c.BreakOnNextPanic(othergid)
+		assertNoError(err, t, "BreakOnNextPanic()")
+		if !reflect.DeepEqual(armed, []int{othergid}) {
+			t.Errorf("panic breakpoint of a different goroutine cleared: %v", armed)
+		}
+	})
+
+	withTestClient2("panic", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		_, err = c.BreakOnNextPanic(-1)
+		assertNoError(err, t, "BreakOnNextPanic(-1)")
+
+		// a user breakpoint at the same address can be created and does not
+		// mask the panic breakpoint
+		userbp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "runtime.gopanic", Cond: "false"})
+		assertNoError(err, t, "CreateBreakpoint(runtime.gopanic)")
+
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != userbp.ID {
+			t.Fatalf("not stopped at panic breakpoint: %#v", state.CurrentThread.Breakpoint)
+		}
+		if _, err := c.GetBreakpoint(userbp.ID); err != nil {
+			t.Errorf("user breakpoint cleared: %v", err)
+		}
+	})
+}
+
+func TestTrace(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("integrationprog", t, func(c service.Client) {
+		fp := testProgPath(t, "integrationprog")
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 16})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		events, stop, err := c.Trace([]api.TracepointSpec{
+			{File: fp, Line: 15, Variables: []string{"i"}},
+			{FunctionName: "main.sayhi"},
+		})
+		assertNoError(err, t, "Trace()")
+		defer stop()
+
+		var hits []api.TraceEvent
+		for ev := range events {
+			t.Logf("%#v", ev)
+			hits = append(hits, ev)
+		}
+
+		if len(hits) != 2 {
+			t.Fatalf("wrong number of trace events: %d", len(hits))
+		}
+		if hits[0].Line != 15 || hits[0].Function != "main.main" || len(hits[0].Variables) != 1 || hits[0].Variables[0].Value != "0" {
+			t.Errorf("wrong first trace event: %#v", hits[0])
+		}
+		if hits[1].Function != "main.sayhi" {
+			t.Errorf("wrong second trace event: %#v", hits[1])
+		}
+		for _, ev := range hits {
+			if ev.GoroutineID <= 0 || ev.Time.IsZero() {
+				t.Errorf("missing goroutine or time in trace event: %#v", ev)
+			}
+		}
+		if hits[1].Time.Before(hits[0].Time) {
+			t.Errorf("trace events out of order: %v %v", hits[0].Time, hits[1].Time)
+		}
+
+		state, err := c.GetState()
+		assertNoError(err, t, "GetState()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatalf("target did not stop at breakpoint %d: %#v", bp.ID, state.CurrentThread)
+		}
+
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		for _, bp := range bps {
+			if bp.Tracepoint {
+				t.Errorf("tracepoint %d was not cleared", bp.ID)
+			}
+		}
+	})
+}
+
+func TestLastOperationStats(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("integrationprog", t, func(c service.Client) {
+		stats, err := c.LastOperationStats()
+		assertNoError(err, t, "LastOperationStats()")
+		if stats != nil {
+			t.Fatalf("unexpected stats before resuming the target: %#v", stats)
+		}
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.sayhi"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		stats, err = c.LastOperationStats()
+		assertNoError(err, t, "LastOperationStats()")
+		if stats == nil || stats.Command != api.Continue || stats.Running || stats.Duration <= 0 {
+			t.Fatalf("wrong stats after continue: %#v", stats)
+		}
+		if c.Recorded() && stats.EventsTraversed <= 0 {
+			t.Fatalf("no rr events traversed: %#v", stats)
+		}
+
+		_, err = c.Next()
+		assertNoError(err, t, "Next()")
+		stats2, err := c.LastOperationStats()
+		assertNoError(err, t, "LastOperationStats()")
+		if stats2 == nil || stats2.Command != api.Next || stats2.Start.Before(stats.Start) {
+			t.Fatalf("wrong stats after next: %#v", stats2)
+		}
+	})
+}
+
+func TestEvalAliases(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		assertNoError(c.SetEvalAlias("malone", `m1["Malone"]`), t, "SetEvalAlias(malone)")
+		assertNoError(c.SetEvalAlias("sum", "@malone.A + @malone.B"), t, "SetEvalAlias(sum)")
+
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "@sum * 2", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(@sum * 2)")
+		if v.Value != "10" || v.Name != "@sum * 2" {
+			t.Errorf("wrong value for @sum * 2: %s = %s", v.Name, v.Value)
+		}
+
+		if err := c.SetEvalAlias("malone", "@sum"); err == nil {
+			t.Errorf("SetEvalAlias did not reject a cycle")
+		}
+		if err := c.SetEvalAlias("1x", "a"); err == nil {
+			t.Errorf("SetEvalAlias did not reject an invalid name")
+		}
+
+		aliases, err := c.ListEvalAliases()
+		assertNoError(err, t, "ListEvalAliases()")
+		if len(aliases) != 2 || aliases[0].Name != "malone" || aliases[0].Expr != `m1["Malone"]` || aliases[1].Name != "sum" {
+			t.Errorf("wrong aliases: %#v", aliases)
+		}
+
+		assertNoError(c.SetEvalAlias("malone", ""), t, "SetEvalAlias(malone, \"\")")
+		_, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "@sum", normalLoadConfig)
+		assertError(err, t, "EvalVariable(@sum) after removing @malone")
+	})
+}
+
+func TestBreakpointAssert(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("integrationprog", t, func(c service.Client) {
+		fp := testProgPath(t, "integrationprog")
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 15, Assert: "i < 2"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if bp.Assert != "i < 2" {
+			t.Fatalf("wrong assertion %q", bp.Assert)
+		}
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		th := state.CurrentThread
+		if th.Breakpoint == nil || th.Breakpoint.ID != bp.ID {
+			t.Fatalf("target did not stop at breakpoint %d: %#v", bp.ID, th)
+		}
+		if th.Breakpoint.TotalHitCount != 3 {
+			t.Errorf("wrong hit count %d", th.Breakpoint.TotalHitCount)
+		}
+		if th.BreakpointInfo == nil || th.BreakpointInfo.AssertError != "assertion failed: i < 2" {
+			t.Errorf("wrong breakpoint info: %#v", th.BreakpointInfo)
+		}
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(i)")
+		if v.Value != "2" {
+			t.Errorf("wrong value of i: %s", v.Value)
+		}
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.sayhi", Assert: "i <"})
+		assertError(err, t, "CreateBreakpoint() with invalid assertion")
+	})
+}
+
+func TestBreakpointTraceMessage(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("integrationprog", t, func(c service.Client) {
+		fp := testProgPath(t, "integrationprog")
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 15, TraceMessage: `"iteration %d of %d %s", i, 3, nosuchvar`})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if bp.TraceMessage != `"iteration %d of %d %s", i, 3, nosuchvar` {
+			t.Fatalf("wrong trace message %q", bp.TraceMessage)
+		}
+
+		for i := 0; i < 2; i++ {
+			state := <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			th := state.CurrentThread
+			if th.Breakpoint == nil || th.Breakpoint.ID != bp.ID {
+				t.Fatalf("target did not stop at breakpoint %d: %#v", bp.ID, th)
+			}
+			tgt := fmt.Sprintf("iteration %d of 3 <eval error: ", i)
+			if th.BreakpointInfo == nil || !strings.HasPrefix(th.BreakpointInfo.TraceMessage, tgt) {
+				t.Errorf("wrong breakpoint info: %#v", th.BreakpointInfo)
+			}
+		}
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.sayhi", TraceMessage: `i, "%d"`})
+		assertError(err, t, "CreateBreakpoint() with invalid trace message")
+	})
+}
+
+func TestPreviewBreakpoint(t *testing.T) {
+	withTestClient2Extended("testinline", t, protest.EnableInlining, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		bps0, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+
+		locs, err := c.PreviewBreakpoint("main.inlineThis")
+		assertNoError(err, t, "PreviewBreakpoint()")
+		for _, loc := range locs {
+			t.Logf("%#x %s:%d %s inlined=%v wrapper=%v", loc.PC, loc.File, loc.Line, loc.Function.Name(), loc.Inlined, loc.Wrapper)
+		}
+		if len(locs) < 3 {
+			t.Fatalf("wrong number of locations: %d", len(locs))
+		}
+		inlined := 0
+		for _, loc := range locs {
+			if len(loc.PCs) != 1 || loc.PCs[0] != loc.PC {
+				t.Errorf("location %#x does not describe a single address: %#v", loc.PC, loc.PCs)
+			}
+			if loc.Wrapper {
+				t.Errorf("location %#x reported as a wrapper", loc.PC)
+			}
+			if loc.Inlined {
+				inlined++
+				if loc.Function.Name() != "main.main" {
+					t.Errorf("inlined location %#x in wrong function %s", loc.PC, loc.Function.Name())
+				}
+			}
+		}
+		if inlined != 2 {
+			t.Errorf("wrong number of inlined locations: %d", inlined)
+		}
+
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.inlineThis"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if len(bp.Addrs) != len(locs) {
+			t.Errorf("preview returned %d locations, breakpoint has %d addresses", len(locs), len(bp.Addrs))
+		}
+
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		if len(bps) != len(bps0)+1 {
+			t.Errorf("PreviewBreakpoint created breakpoints: %d before, %d after", len(bps0), len(bps)-1)
+		}
+	})
+}
+
+func TestInlinedPackages(t *testing.T) {
+	withTestClient2Extended("testinline", t, protest.EnableInlining, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		pkgs, err := c.InlinedPackages("main.main")
+		assertNoError(err, t, "InlinedPackages(main.main)")
+		t.Logf("%v", pkgs)
+		found := false
+		for _, pkg := range pkgs {
+			if pkg == "main" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("main not listed among the packages inlined into main.main: %v", pkgs)
+		}
+
+		_, err = c.InlinedPackages("main.nosuchfunction")
+		assertError(err, t, "InlinedPackages(main.nosuchfunction)")
+	})
+}
+
+func TestFunctionLineEntries(t *testing.T) {
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		entries, err := c.FunctionLineEntries("main.testnext")
+		assertNoError(err, t, "FunctionLineEntries(main.testnext)")
+		stmtLines := make(map[int]bool)
+		var lastPC uint64
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.File, "testnextprog.go") {
+				t.Errorf("wrong file for %#x: %s", entry.PC, entry.File)
+			}
+			if entry.PC < lastPC {
+				t.Errorf("entries not sorted by address: %#x after %#x", entry.PC, lastPC)
+			}
+			lastPC = entry.PC
+			if entry.IsStmt {
+				stmtLines[entry.Line] = true
+			}
+		}
+		for _, line := range []int{19, 23, 24, 26, 27, 31, 34} {
+			if !stmtLines[line] {
+				t.Errorf("line %d is not a statement boundary: %v", line, entries)
+			}
+		}
+		for _, line := range []int{22, 25, 30, 33} {
+			if stmtLines[line] {
+				t.Errorf("empty line %d is a statement boundary", line)
+			}
+		}
+
+		_, err = c.FunctionLineEntries("main.nosuchfunction")
+		assertError(err, t, "FunctionLineEntries(main.nosuchfunction)")
+	})
+}
+
+func TestStacktracePendingDefers(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("deferstack", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		frames, err := c.Stacktrace(-1, 10, 0, nil)
+		assertNoError(err, t, "Stacktrace()")
+		for _, frame := range frames {
+			if len(frame.Defers) != 0 {
+				t.Errorf("deferred calls returned without StacktraceReadDefers: %#v", frame)
+			}
+		}
+
+		frames, err = c.Stacktrace(-1, 10, api.StacktraceReadDefers, nil)
+		assertNoError(err, t, "Stacktrace(StacktraceReadDefers)")
+		pending := map[string]int{}
+		for _, frame := range frames {
+			if frame.Function == nil {
+				continue
+			}
+			pending[frame.Function.Name()] = len(frame.Defers)
+			if frame.Panicking {
+				t.Errorf("frame %s: unexpected Panicking flag", frame.Function.Name())
+			}
+		}
+		for _, fn := range []string{"main.call1", "main.call2"} {
+			if pending[fn] != 2 {
+				t.Errorf("frame %s: expected 2 pending defers, got %d", fn, pending[fn])
+			}
+		}
+		if pending["main.call3"] != 0 {
+			t.Errorf("frame main.call3: expected no pending defers, got %d", pending["main.call3"])
+		}
+	})
+
+	withTestClient2("panic", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		frames, err := c.Stacktrace(-1, 20, 0, nil)
+		assertNoError(err, t, "Stacktrace()")
+		seen := false
+		for _, frame := range frames {
+			if frame.Function == nil {
+				continue
+			}
+			switch frame.Function.Name() {
+			case "runtime.gopanic":
+				if frame.Panicking {
+					t.Errorf("runtime.gopanic marked as panicking")
+				}
+			case "main.main":
+				seen = true
+				if !frame.Panicking {
+					t.Errorf("main.main not marked as panicking")
+				}
+			}
+		}
+		if !seen {
+			t.Errorf("main.main not found in stacktrace")
+		}
+	})
+}
+
+func TestSetRegister(t *testing.T) {
+	if runtime.GOARCH != "amd64" {
+		t.Skip("test uses amd64 registers")
+	}
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		fp := testProgPath(t, "testnextprog")
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 47})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		scope := api.EvalScope{GoroutineID: -1}
+		cfg := normalLoadConfig
+
+		assertNoError(c.SetRegister(0, "RAX", "0x1234"), t, "SetRegister(RAX)")
+		v, err := c.EvalVariable(scope, "RAX", cfg)
+		assertNoError(err, t, "EvalVariable(RAX)")
+		if v.Value != "4660" {
+			t.Errorf("wrong value of RAX after SetRegister: %s", v.Value)
+		}
+
+		assertNoError(c.SetRegister(0, "XMM0.float32[1]", "1.5"), t, "SetRegister(XMM0.float32[1])")
+		v, err = c.EvalVariable(scope, "XMM0.float32[1]", cfg)
+		assertNoError(err, t, "EvalVariable(XMM0.float32[1])")
+		if v.Value != "1.5" {
+			t.Errorf("wrong value of XMM0.float32[1] after SetRegister: %s", v.Value)
+		}
+
+		assertNoError(c.SetRegister(0, "XMM1", "0x0102"), t, "SetRegister(XMM1)")
+		v, err = c.EvalVariable(scope, "XMM1.uint8[0]", cfg)
+		assertNoError(err, t, "EvalVariable(XMM1.uint8[0])")
+		if v.Value != "2" {
+			t.Errorf("wrong value of XMM1.uint8[0] after SetRegister: %s", v.Value)
+		}
+
+		assertError(c.SetRegister(0, "NOSUCHREG", "1"), t, "SetRegister(NOSUCHREG)")
+		assertError(c.SetRegister(0, "XMM0.float32[4]", "1"), t, "SetRegister(XMM0.float32[4])")
+		assertError(c.SetRegister(0, "Fs_base", "0"), t, "SetRegister(Fs_base)")
+	})
+}
+
+func TestListDynamicLibraries(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("dynamic libraries are only listed on linux")
+	}
+	protest.MustHaveCgo(t)
+	protest.AllowRecording(t)
+	withTestClient2("cgotest", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		libs, err := c.ListDynamicLibraries()
+		assertNoError(err, t, "ListDynamicLibraries()")
+		if len(libs) == 0 {
+			t.Fatal("no dynamic libraries listed for a cgo program")
+		}
+		for _, lib := range libs {
+			t.Logf("%#x %s debug info:%v %s", lib.Address, lib.Path, lib.DebugInfo, lib.LoadError)
+			if lib.Path == "" {
+				t.Errorf("dynamic library without path: %#v", lib)
+			}
+			if !lib.DebugInfo && lib.LoadError == "" {
+				t.Errorf("no load error reported for %s, which has no debug info", lib.Path)
+			}
+		}
+	})
+}
+
+func TestDebuggerStats(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("continuetestprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		stats, err := c.DebuggerStats()
+		assertNoError(err, t, "DebuggerStats()")
+		t.Logf("%#v", stats)
+		if stats.HeapAlloc == 0 || stats.Functions == 0 || stats.Sources == 0 {
+			t.Errorf("missing statistics: %#v", stats)
+		}
+		if len(stats.Images) == 0 {
+			t.Fatal("no images reported")
+		}
+		if stats.Images[0].CompileUnits == 0 {
+			t.Errorf("no compile units reported for the executable: %#v", stats.Images[0])
+		}
+	})
+}
+
+func TestListPackages(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("pkgrenames", t, func(c service.Client) {
+		pkgs, err := c.ListPackages()
+		assertNoError(err, t, "ListPackages()")
+		if !sort.SliceIsSorted(pkgs, func(i, j int) bool { return pkgs[i].ImportPath < pkgs[j].ImportPath }) {
+			t.Errorf("packages not sorted by import path")
+		}
+		names := map[string]string{}
+		for _, pkg := range pkgs {
+			if pkg.DirectoryPath == "" {
+				t.Errorf("no directory path for %q", pkg.ImportPath)
+			}
+			if len(pkg.Files) != 0 {
+				t.Errorf("files listed for %q", pkg.ImportPath)
+			}
+			names[pkg.ImportPath] = pkg.PackageName
+		}
+		for ip, name := range map[string]string{
+			"main":     "main",
+			"net/http": "http",
+			"github.com/go-delve/delve/_fixtures/internal/dir.io":              "dirio",
+			"github.com/go-delve/delve/_fixtures/internal/dir0/renamedpackage": "realname",
+		} {
+			if names[ip] != name {
+				t.Errorf("wrong package name for %q: %q (expected %q)", ip, names[ip], name)
+			}
+		}
+	})
+}
+
+func TestDumpCore(t *testing.T) {
+	if runtime.GOOS == "freebsd" || (runtime.GOOS == "darwin" && testBackend == "native") {
+		t.Skip("not supported")
+	}
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		dir, err := ioutil.TempDir("", "dlv-dumpcore")
+		assertNoError(err, t, "TempDir()")
+		defer os.RemoveAll(dir)
+		corePath := filepath.Join(dir, "core")
+
+		assertNoError(c.DumpCore(corePath), t, "DumpCore()")
+		fi, err := os.Stat(corePath)
+		assertNoError(err, t, "Stat()")
+		if fi.Size() == 0 {
+			t.Fatal("empty core file")
+		}
+
+		// the target is still usable after the dump
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i1", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable()")
+		if v.Value != "1" {
+			t.Errorf("wrong value of i1 after dump: %s", v.Value)
+		}
+
+		err = c.DumpCore(filepath.Join(dir, "nonexistent", "core"))
+		assertError(err, t, "DumpCore(nonexistent directory)")
+	})
+}
+
+func TestParkGoroutine(t *testing.T) {
+	if runtime.GOOS != "linux" || testBackend != "native" {
+		t.Skip("parking goroutines is only supported by the native backend on linux")
+	}
+	withTestClient2("teststepconcurrent", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.Foo"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		assertError(c.ParkGoroutine(-1), t, "ParkGoroutine(-1)")
+		assertError(c.UnparkGoroutine(state.SelectedGoroutine.ID), t, "UnparkGoroutine() on a goroutine that is not parked")
+
+		parked := state.SelectedGoroutine.ID
+		assertNoError(c.ParkGoroutine(parked), t, "ParkGoroutine()")
+		state, err = c.GetState()
+		assertNoError(err, t, "GetState()")
+		if len(state.ParkedGoroutines) != 1 || state.ParkedGoroutines[0] != parked {
+			t.Fatalf("wrong list of parked goroutines %v (expected [%d])", state.ParkedGoroutines, parked)
+		}
+
+		for i := 0; i < 10; i++ {
+			state = <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			t.Logf("breakpoint hit by goroutine %d", state.SelectedGoroutine.ID)
+			if state.SelectedGoroutine.ID == parked {
+				t.Fatalf("parked goroutine %d ran", parked)
+			}
+		}
+
+		assertNoError(c.UnparkGoroutine(parked), t, "UnparkGoroutine()")
+		state, err = c.GetState()
+		assertNoError(err, t, "GetState()")
+		if len(state.ParkedGoroutines) != 0 {
+			t.Fatalf("goroutines still parked: %v", state.ParkedGoroutines)
+		}
+
+		// parked goroutines are forgotten on restart
+		assertNoError(c.ParkGoroutine(parked), t, "ParkGoroutine()")
+		_, err = c.Restart(false)
+		assertNoError(err, t, "Restart()")
+		state, err = c.GetState()
+		assertNoError(err, t, "GetState()")
+		if len(state.ParkedGoroutines) != 0 {
+			t.Fatalf("goroutines still parked after restart: %v", state.ParkedGoroutines)
+		}
+	})
+}
+
+func TestCPPFunctionBreakpoints(t *testing.T) {
+	protest.MustHaveCgo(t)
+	protest.AllowRecording(t)
+	withTestClient2("cgocpptest/", t, func(c service.Client) {
+		// demangled name, with parameters to pick one of the overloads
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "shapes::Rect::scale(double)", false, nil)
+		assertNoError(err, t, "FindLocation(shapes::Rect::scale(double))")
+		if len(locs) != 1 || locs[0].Function == nil || locs[0].Function.Name() != "C.scale" {
+			t.Fatalf("wrong locations for shapes::Rect::scale(double): %#v", locs)
+		}
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{Addrs: locs[0].PCs})
+		assertNoError(err, t, "CreateBreakpoint(shapes::Rect::scale(double))")
+
+		// overloads without parameters are ambiguous
+		_, err = c.FindLocation(api.EvalScope{GoroutineID: -1}, "Rect::scale", false, nil)
+		assertError(err, t, "FindLocation(Rect::scale)")
+
+		// raw mangled name
+		bp2, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "_ZN6shapes4Rect5scaleEi"})
+		assertNoError(err, t, "CreateBreakpoint(_ZN6shapes4Rect5scaleEi)")
+		if bp2.Addr == bp.Addr {
+			t.Fatalf("overloads resolved to the same address %#x", bp.Addr)
+		}
+	})
+}
+
+func TestGoroutineStacktraceDiff(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		curgid := state.SelectedGoroutine.ID
+
+		diff, err := c.GoroutineStacktraceDiff(nil)
+		assertNoError(err, t, "GoroutineStacktraceDiff(nil)")
+		if len(diff.Created) != len(diff.Current) || len(diff.Created) == 0 {
+			t.Fatalf("expected all goroutines to be created, got %d created out of %d", len(diff.Created), len(diff.Current))
+		}
+
+		_, err = c.Next()
+		assertNoError(err, t, "Next()")
+
+		diff, err = c.GoroutineStacktraceDiff(diff.Current)
+		assertNoError(err, t, "GoroutineStacktraceDiff(prev)")
+		found := false
+		for _, m := range diff.Moved {
+			if m.Current.Goroutine.ID == curgid {
+				found = true
+				if m.CommonFrames == 0 {
+					t.Errorf("expected common outermost frames for goroutine %d", curgid)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("goroutine %d not reported as moved: %#v", curgid, diff.Moved)
+		}
+		for _, g := range diff.Created {
+			if g.Goroutine.ID == curgid {
+				t.Errorf("goroutine %d reported as created", curgid)
+			}
+		}
+
+		_, err = c.GoroutineStacktraceDiff([]api.GoroutineStacktrace{{}})
+		assertError(err, t, "GoroutineStacktraceDiff(entry without goroutine)")
+	})
+}
+
+func TestChanBuffer(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("chanbuffer", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		checkBuffer := func(ch *api.Variable, tgt ...string) {
+			t.Helper()
+			if len(ch.ChanBuffer) != len(tgt) {
+				t.Fatalf("wrong number of buffered elements, expected %d got %d", len(tgt), len(ch.ChanBuffer))
+			}
+			for i := range tgt {
+				if ch.ChanBuffer[i].Value != tgt[i] {
+					t.Errorf("wrong buffered element %d, expected %s got %s", i, tgt[i], ch.ChanBuffer[i].Value)
+				}
+			}
+		}
+
+		ch, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "ch", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(ch)")
+		checkBuffer(ch, "2", "3", "4")
+		if ch.ChanSendWaiters != 1 || ch.ChanRecvWaiters != 0 {
+			t.Errorf("wrong wait queue lengths, send %d recv %d", ch.ChanSendWaiters, ch.ChanRecvWaiters)
+		}
+
+		cfg := normalLoadConfig
+		cfg.MaxArrayValues = 2
+		ch, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "ch", cfg)
+		assertNoError(err, t, "EvalVariable(ch)")
+		checkBuffer(ch, "2", "3")
+	})
+}
+
+func TestListThreadsWithFilter(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		all, err := c.ListThreads()
+		assertNoError(err, t, "ListThreads()")
+
+		n := 0
+		foundCurrent := false
+		for _, st := range []api.ThreadState{api.ThreadRunning, api.ThreadSyscall, api.ThreadBlocked, api.ThreadIdle} {
+			threads, err := c.ListThreadsWithFilter(api.ThreadFilter{State: st})
+			assertNoError(err, t, fmt.Sprintf("ListThreadsWithFilter(%s)", st))
+			for _, th := range threads {
+				if th.State != st {
+					t.Errorf("thread %d in state %q returned for filter %q", th.ID, th.State, st)
+				}
+				if st == api.ThreadRunning && th.ID == state.CurrentThread.ID {
+					foundCurrent = true
+				}
+			}
+			n += len(threads)
+		}
+		if n != len(all) {
+			t.Errorf("threads filtered by state do not add up to all threads: %d %d", n, len(all))
+		}
+		if !foundCurrent {
+			t.Errorf("current thread %d not reported as running", state.CurrentThread.ID)
+		}
+
+		threads, err := c.ListThreadsWithFilter(api.ThreadFilter{InScheduler: true})
+		assertNoError(err, t, "ListThreadsWithFilter(InScheduler)")
+		for _, th := range threads {
+			if th.ID == state.CurrentThread.ID {
+				t.Errorf("current thread %d reported as executing the scheduler", th.ID)
+			}
+		}
+
+		_, err = c.ListThreadsWithFilter(api.ThreadFilter{State: "nonexistent"})
+		assertError(err, t, "ListThreadsWithFilter(nonexistent)")
+	})
+}
+
+func TestSourceLines(t *testing.T) {
+	withTestClient2Extended("testnextprog", t, 0, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		buf, err := ioutil.ReadFile(fixture.Source)
+		assertNoError(err, t, "ReadFile()")
+		want := strings.Split(string(buf), "\n")
+
+		lines, err := c.SourceLines(fixture.Source, 3, 5, nil)
+		assertNoError(err, t, "SourceLines()")
+		if !reflect.DeepEqual(lines, want[2:5]) {
+			t.Errorf("wrong lines %q, expected %q", lines, want[2:5])
+		}
+
+		// the range is clamped to the end of the file
+		lines, err = c.SourceLines(fixture.Source, 1, len(want)+10, nil)
+		assertNoError(err, t, "SourceLines(past end)")
+		if len(lines) != len(want)-1 {
+			t.Errorf("wrong number of lines %d, expected %d", len(lines), len(want)-1)
+		}
+
+		// the client sees the source in a different directory
+		dir := filepath.Dir(fixture.Source)
+		clientPath := "/client/fixtures/" + filepath.Base(fixture.Source)
+		lines, err = c.SourceLines(clientPath, 3, 3, [][2]string{{dir, "/client/fixtures"}})
+		assertNoError(err, t, "SourceLines(substitute path)")
+		if !reflect.DeepEqual(lines, want[2:3]) {
+			t.Errorf("wrong lines %q, expected %q", lines, want[2:3])
+		}
+
+		_, err = c.SourceLines(clientPath, 3, 3, nil)
+		assertError(err, t, "SourceLines(unknown file)")
+		_, err = c.SourceLines(fixture.Source, 5, 3, nil)
+		assertError(err, t, "SourceLines(invalid range)")
+	})
+}
+
+func TestWatches(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2Extended("break", t, 0, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 7})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		scope := api.EvalScope{GoroutineID: -1}
+		id, err := c.AddWatch(scope, "i")
+		assertNoError(err, t, "AddWatch(i)")
+		_, err = c.AddWatch(scope, "nonexistentvar")
+		assertNoError(err, t, "AddWatch(nonexistentvar)")
+		_, err = c.AddWatch(scope, "i +")
+		assertError(err, t, "AddWatch(syntax error)")
+
+		for _, tgt := range []string{"1", "2"} {
+			state := <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			if len(state.Watches) != 2 {
+				t.Fatalf("wrong number of watches %d", len(state.Watches))
+			}
+			w := state.Watches[0]
+			if w.ID != id || w.Expr != "i" || w.Err != "" || w.Value == nil || w.Value.Value != tgt {
+				t.Errorf("wrong watch %#v (expected i = %s)", w, tgt)
+			}
+			if state.Watches[1].Err == "" {
+				t.Errorf("expected error evaluating nonexistentvar")
+			}
+		}
+
+		assertNoError(c.RemoveWatch(id), t, "RemoveWatch()")
+		assertError(c.RemoveWatch(id), t, "RemoveWatch(removed)")
+		state, err := c.GetState()
+		assertNoError(err, t, "GetState()")
+		if len(state.Watches) != 1 || state.Watches[0].Expr != "nonexistentvar" {
+			t.Errorf("wrong watches after RemoveWatch: %#v", state.Watches)
+		}
+	})
+}
+
+func TestReadWriteMemory(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		scope := api.EvalScope{GoroutineID: -1}
+		i1, err := c.EvalVariable(scope, "i1", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(i1)")
+
+		mem, err := c.ReadMemory(i1.Addr, 8)
+		assertNoError(err, t, "ReadMemory()")
+		if !reflect.DeepEqual(mem, []byte{1, 0, 0, 0, 0, 0, 0, 0}) {
+			t.Errorf("wrong memory read %v", mem)
+		}
+
+		if testBackend != "rr" {
+			n, err := c.WriteMemory(i1.Addr, []byte{42, 0, 0, 0, 0, 0, 0, 0})
+			assertNoError(err, t, "WriteMemory()")
+			if n != 8 {
+				t.Errorf("wrong number of bytes written %d", n)
+			}
+			i1, err = c.EvalVariable(scope, "i1", normalLoadConfig)
+			assertNoError(err, t, "EvalVariable(i1)")
+			if i1.Value != "42" {
+				t.Errorf("wrong value of i1 after WriteMemory: %s", i1.Value)
+			}
+		}
+
+		_, err = c.ReadMemory(0, 8)
+		assertError(err, t, "ReadMemory(0)")
+		_, err = c.ReadMemory(i1.Addr, debugger.MaxReadMemoryLength+1)
+		assertError(err, t, "ReadMemory(too long)")
+	})
+}
+
+func TestReadMemoryPartial(t *testing.T) {
+	if runtime.GOOS == "freebsd" || (runtime.GOOS == "darwin" && testBackend == "native") {
+		t.Skip("memory maps not supported")
+	}
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		mmap, err := c.ProcessMemoryMaps()
+		assertNoError(err, t, "ProcessMemoryMaps()")
+
+		// find a readable region followed by an unmapped page
+		var end uint64
+		for i, mme := range mmap {
+			if mme.Read && mme.Size >= 16 && (i+1 >= len(mmap) || mmap[i+1].Addr > mme.Addr+mme.Size) {
+				end = mme.Addr + mme.Size
+				break
+			}
+		}
+		if end == 0 {
+			t.Skip("no readable region followed by an unmapped page")
+		}
+
+		mem, err := c.ReadMemory(end-16, 4096)
+		assertError(err, t, "ReadMemory(across an unmapped page)")
+		if len(mem) != 16 {
+			t.Errorf("wrong number of bytes read before the unmapped page: %d", len(mem))
+		}
+
+		for !state.Exited {
+			state = <-c.Continue()
+			if state.Err != nil && !state.Exited {
+				t.Fatalf("Continue(): %v", state.Err)
+			}
+		}
+		_, err = c.ReadMemory(end-16, 16)
+		assertError(err, t, "ReadMemory(after exit)")
+	})
+}
+
+func TestDisassembleBranchTargets(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("break", t, func(c service.Client) {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "main.main", false, nil)
+		assertNoError(err, t, "FindLocation()")
+
+		check := func(text api.AsmInstructions) int {
+			t.Helper()
+			pcs := map[uint64]bool{}
+			for _, inst := range text {
+				pcs[inst.Loc.PC] = true
+			}
+			backward := 0
+			for _, inst := range text {
+				if inst.BranchTarget == 0 {
+					continue
+				}
+				if !pcs[inst.BranchTarget] {
+					t.Errorf("branch target %#x of instruction at %#x is not an instruction of the range", inst.BranchTarget, inst.Loc.PC)
+				}
+				if inst.BranchTarget < inst.Loc.PC {
+					backward++
+				}
+			}
+			return backward
+		}
+
+		text, err := c.DisassemblePC(api.EvalScope{GoroutineID: -1}, locs[0].PC, api.IntelFlavour)
+		assertNoError(err, t, "DisassemblePC()")
+		// the loop in main.main jumps backwards
+		if check(text) == 0 {
+			t.Errorf("no backward branch found in main.main")
+		}
+
+		// branch targets outside of a partial range are not reported
+		text, err = c.DisassembleRange(api.EvalScope{GoroutineID: -1}, text[0].Loc.PC, text[len(text)/2].Loc.PC, api.IntelFlavour)
+		assertNoError(err, t, "DisassembleRange()")
+		check(text)
+	})
+}
+
+func TestGoroutineUserCodeFilter(t *testing.T) {
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue")
+
+		all, _, _, _, err := c.ListGoroutinesWithFilter(0, 0, nil, nil)
+		assertNoError(err, t, "ListGoroutinesWithFilter (all)")
+
+		usercode, _, _, _, err := c.ListGoroutinesWithFilter(0, 0, []api.ListGoroutinesFilter{{Kind: api.GoroutineUserCode}}, nil)
+		assertNoError(err, t, "ListGoroutinesWithFilter (usercode)")
+		agoroutines := 0
+		found := false
+		for _, g := range usercode {
+			if g.UserCurrentLoc.Function == nil {
+				t.Errorf("goroutine %d has no user location", g.ID)
+				continue
+			}
+			if g.UserCurrentLoc.Function.Name() == "main.agoroutine" {
+				agoroutines++
+			}
+			if g.ID == state.SelectedGoroutine.ID {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("selected goroutine %d not returned by the usercode filter", state.SelectedGoroutine.ID)
+		}
+		if agoroutines != 10 {
+			t.Errorf("expected 10 goroutines in main.agoroutine, got %d", agoroutines)
+		}
+
+		runtimecode, _, _, _, err := c.ListGoroutinesWithFilter(0, 0, []api.ListGoroutinesFilter{{Kind: api.GoroutineUserCode, Negated: true}}, nil)
+		assertNoError(err, t, "ListGoroutinesWithFilter (not usercode)")
+		if len(usercode)+len(runtimecode) != len(all) {
+			t.Errorf("usercode filter and its negation do not add up to all goroutines: %d+%d != %d", len(usercode), len(runtimecode), len(all))
+		}
+		for _, g := range runtimecode {
+			if g.UserCurrentLoc.Function != nil && strings.HasPrefix(g.UserCurrentLoc.Function.Name(), "main.") {
+				t.Errorf("goroutine %d in %s returned as runtime code", g.ID, g.UserCurrentLoc.Function.Name())
+			}
+		}
+
+		// composes with the other filters
+		gs, _, _, _, err := c.ListGoroutinesWithFilter(0, 0, []api.ListGoroutinesFilter{{Kind: api.GoroutineUserCode}, {Kind: api.GoroutineStatus, Arg: "running"}}, nil)
+		assertNoError(err, t, "ListGoroutinesWithFilter (usercode, running)")
+		found = false
+		for _, g := range gs {
+			if g.Status&^api.Gscan != api.Grunning {
+				t.Errorf("goroutine %d has status %s", g.ID, api.GoroutineStatusName(g.Status))
+			}
+			if g.ID == state.SelectedGoroutine.ID {
+				found = true
+			}
+		}
+		if !found || len(gs) > len(usercode) {
+			t.Errorf("wrong goroutines for usercode and running filters: %d", len(gs))
+		}
+	})
+}
+
+func TestContinueSkippingTracepoints(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		tp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.sleepytime", Line: 1, Tracepoint: true})
+		assertNoError(err, t, "CreateBreakpoint(tracepoint)")
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: 1})
+		assertNoError(err, t, "CreateBreakpoint(breakpoint)")
+
+		var states []*api.DebuggerState
+		for state := range c.ContinueSkippingTracepoints() {
+			assertNoError(state.Err, t, "ContinueSkippingTracepoints()")
+			states = append(states, state)
+		}
+		if len(states) != 1 {
+			t.Fatalf("wrong number of states %d, expected 1", len(states))
+		}
+		if states[0].CurrentThread.Breakpoint == nil || states[0].CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatalf("stopped at wrong breakpoint %#v", states[0].CurrentThread.Breakpoint)
+		}
+
+		tp, err = c.GetBreakpoint(tp.ID)
+		assertNoError(err, t, "GetBreakpoint()")
+		if tp.TotalHitCount != 2 {
+			t.Fatalf("wrong tracepoint hit count %d, expected 2", tp.TotalHitCount)
+		}
+	})
+}
+
+func TestEvalToJSON(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		buf, err := c.EvalToJSON(api.EvalScope{GoroutineID: -1}, "*ll", normalLoadConfig)
+		assertNoError(err, t, "EvalToJSON(*ll)")
+		var v api.JSONValue
+		assertNoError(json.Unmarshal(buf, &v), t, "Unmarshal")
+		if v.Type != "main.List" || v.Kind != "struct" || len(v.Fields) != 2 {
+			t.Fatalf("wrong value %s", buf)
+		}
+		if n := v.Fields[0]; n.Name != "N" || n.Value.Value != float64(0) {
+			t.Errorf("wrong N field %#v", n.Value)
+		}
+		next := v.Fields[1].Value
+		if next.Kind != "ptr" || next.Target == nil || next.Target.Type != "main.List" {
+			t.Errorf("wrong Next field %#v", next)
+		}
+
+		buf, err = c.EvalToJSON(api.EvalScope{GoroutineID: -1}, "nilptr", normalLoadConfig)
+		assertNoError(err, t, "EvalToJSON(nilptr)")
+		assertNoError(json.Unmarshal(buf, &v), t, "Unmarshal")
+		if !v.Nil {
+			t.Errorf("nil pointer not marked as nil: %s", buf)
+		}
+
+		buf, err = c.EvalToJSON(api.EvalScope{GoroutineID: -1}, "recursive1", normalLoadConfig)
+		assertNoError(err, t, "EvalToJSON(recursive1)")
+		v = api.JSONValue{}
+		assertNoError(json.Unmarshal(buf, &v), t, "Unmarshal")
+		if len(v.Fields) != 1 || v.Fields[0].Value.Ref != v.Addr {
+			t.Errorf("cyclic pointer not rendered as reference: %s", buf)
+		}
+	})
+}
+
+func TestCreateBreakpointOnReturn(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("binarytrees", t, func(c service.Client) {
+		const fnName = "main.(*Node).ItemCheck"
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: fnName, OnReturn: true})
+		assertNoError(err, t, "CreateBreakpoint()")
+		if len(bp.Addrs) < 2 {
+			t.Fatalf("expected at least two return locations, got %#x", bp.Addrs)
+		}
+		if !bp.OnReturn {
+			t.Fatalf("OnReturn not reported: %#v", bp)
+		}
+
+		// the breakpoint is set on all return locations again on restart
+		_, err = c.Restart(false)
+		assertNoError(err, t, "Restart()")
+		bp2, err := c.GetBreakpoint(bp.ID)
+		assertNoError(err, t, "GetBreakpoint()")
+		sortAddrs := func(addrs []uint64) []uint64 {
+			r := append([]uint64(nil), addrs...)
+			sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+			return r
+		}
+		if !bp2.OnReturn || !reflect.DeepEqual(sortAddrs(bp2.Addrs), sortAddrs(bp.Addrs)) {
+			t.Fatalf("wrong breakpoint after restart %#v, expected addresses %#x", bp2, bp.Addrs)
+		}
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatalf("stopped at wrong breakpoint %#v", state.CurrentThread.Breakpoint)
+		}
+		if state.CurrentThread.Function == nil || state.CurrentThread.Function.Name() != fnName {
+			t.Fatalf("stopped in wrong function %#v", state.CurrentThread.Function)
+		}
+		found := false
+		for _, addr := range bp.Addrs {
+			if addr == state.CurrentThread.PC {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("stopped at %#x, not one of the return locations %#x", state.CurrentThread.PC, bp.Addrs)
+		}
+
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.nonexistent", OnReturn: true})
+		assertError(err, t, "CreateBreakpoint(nonexistent)")
+	})
+}
+
+func TestProcessMemoryMaps(t *testing.T) {
+	if runtime.GOOS == "freebsd" || (runtime.GOOS == "darwin" && testBackend == "native") {
+		t.Skip("memory maps not supported")
+	}
+	protest.AllowRecording(t)
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		mmap, err := c.ProcessMemoryMaps()
+		assertNoError(err, t, "ProcessMemoryMaps()")
+		if len(mmap) == 0 {
+			t.Fatal("empty memory map")
+		}
+
+		pc := state.CurrentThread.PC
+		found := false
+		for i, mme := range mmap {
+			if i > 0 && mme.Addr < mmap[i-1].Addr+mmap[i-1].Size {
+				t.Errorf("overlapping regions %#v and %#v", mmap[i-1], mme)
+			}
+			if pc >= mme.Addr && pc < mme.Addr+mme.Size {
+				found = true
+				if !mme.Exec {
+					t.Errorf("region containing the current PC %#x is not executable: %#v", pc, mme)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("could not find region containing the current PC %#x", pc)
+		}
+	})
+}
+
+func TestRestartWithEnv(t *testing.T) {
+	os.Setenv("SOMEVAR", "bah")
+	defer os.Unsetenv("SOMEVAR")
+	withTestClient2("testenv", t, func(c service.Client) {
+		evalx := func() string {
+			state := <-c.Continue()
+			assertNoError(state.Err, t, "Continue()")
+			x, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "x", normalLoadConfig)
+			assertNoError(err, t, "EvalVariable(x)")
+			return x.Value
+		}
+		if x := evalx(); x != "bah" {
+			t.Fatalf("expected 'bah' got %q", x)
+		}
+
+		_, env, err := c.RestartWithEnv([]string{"SOMEVAR=blah"}, false)
+		assertNoError(err, t, "RestartWithEnv()")
+		found := false
+		for _, kv := range env {
+			if kv == "SOMEVAR=blah" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("SOMEVAR=blah not in the reported environment %q", env)
+		}
+		if x := evalx(); x != "blah" {
+			t.Fatalf("expected 'blah' got %q", x)
+		}
+
+		// The environment change must be kept across restarts.
+		_, err = c.Restart(false)
+		assertNoError(err, t, "Restart()")
+		if x := evalx(); x != "blah" {
+			t.Fatalf("expected 'blah' after restart got %q", x)
+		}
+
+		_, _, err = c.RestartWithEnv([]string{"SOMEVAR"}, false)
+		assertNoError(err, t, "RestartWithEnv()")
+		if x := evalx(); x != "" {
+			t.Fatalf("expected SOMEVAR to be unset, got %q", x)
+		}
+	})
+}
+
+func TestGoroutine(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		gs, _, err := c.ListGoroutines(0, 0)
+		assertNoError(err, t, "ListGoroutines()")
+		for _, g := range gs {
+			g2, err := c.Goroutine(g.ID)
+			assertNoError(err, t, fmt.Sprintf("Goroutine(%d)", g.ID))
+			if g2.ID != g.ID || g2.Status != g.Status || g2.CurrentLoc.PC != g.CurrentLoc.PC || g2.StartLoc.PC != g.StartLoc.PC {
+				t.Errorf("mismatched goroutine %d: %#v %#v", g.ID, g, g2)
+			}
+		}
+
+		g, err := c.Goroutine(-1)
+		assertNoError(err, t, "Goroutine(-1)")
+		if g.ID != state.SelectedGoroutine.ID {
+			t.Errorf("wrong selected goroutine %d, expected %d", g.ID, state.SelectedGoroutine.ID)
+		}
+
+		const staleID = 1000000
+		_, err = c.Goroutine(staleID)
+		if err == nil || !strings.Contains(err.Error(), "unknown goroutine") {
+			t.Errorf("wrong error for nonexistent goroutine: %v", err)
+		}
+	})
+}
+
+func TestDeferredCalls(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("deferstack", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		dcs, err := c.DeferredCalls(api.EvalScope{GoroutineID: -1, Frame: 0}, normalLoadConfig)
+		assertNoError(err, t, "DeferredCalls()")
+
+		// main.call2 defers main.f3 and main.f2, main.call1 defers main.f2 and
+		// main.f1, they run in reverse order.
+		tgt := []struct {
+			frame, deferredCall int
+			fn                  string
+		}{
+			{1, 1, "main.f2"},
+			{1, 2, "main.f3"},
+			{2, 1, "main.f1"},
+			{2, 2, "main.f2"},
+		}
+		if len(dcs) != len(tgt) {
+			t.Fatalf("wrong number of deferred calls %d: %#v", len(dcs), dcs)
+		}
+		for i := range tgt {
+			dc := dcs[i]
+			if dc.Frame != tgt[i].frame || dc.DeferredCall != tgt[i].deferredCall || dc.DeferredLoc.Function == nil || dc.DeferredLoc.Function.Name() != tgt[i].fn {
+				t.Errorf("wrong deferred call %d: %#v", i, dc)
+			}
+		}
+
+		checkArgs := func(dc api.DeferredCall, tgt ...string) {
+			t.Helper()
+			if dc.ArgsUnreadable != "" {
+				t.Errorf("arguments of %s unreadable: %s", dc.DeferredLoc.Function.Name(), dc.ArgsUnreadable)
+				return
+			}
+			values := []string{}
+			for _, arg := range dc.Args {
+				values = append(values, arg.Value)
+			}
+			if !reflect.DeepEqual(values, tgt) {
+				t.Errorf("wrong arguments for %s: %v, expected %v", dc.DeferredLoc.Function.Name(), values, tgt)
+			}
+		}
+		if goversion.VersionAfterOrEqual(runtime.Version(), 1, 17) {
+			// constant arguments are moved inside the defer wrapper
+			for _, i := range []int{0, 3} {
+				if dcs[i].ArgsUnreadable == "" {
+					t.Errorf("expected unreadable arguments for %s: %#v", dcs[i].DeferredLoc.Function.Name(), dcs[i].Args)
+				}
+			}
+		} else {
+			checkArgs(dcs[0], "42", "61")
+			checkArgs(dcs[3], "1", "-1")
+		}
+		checkArgs(dcs[1])
+		checkArgs(dcs[2])
+
+		dcs, err = c.DeferredCalls(api.EvalScope{GoroutineID: -1, Frame: 2}, normalLoadConfig)
+		assertNoError(err, t, "DeferredCalls(frame 2)")
+		if len(dcs) != 2 {
+			t.Errorf("wrong number of deferred calls for frame 2: %#v", dcs)
+		}
+	})
+}
+
+func TestDeferredCallsWrapperArgs(t *testing.T) {
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 23) {
+		t.Skip("closure layout of defer wrappers not described before Go 1.23")
+	}
+	protest.AllowRecording(t)
+	withTestClient2("deferwrapargs", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		dcs, err := c.DeferredCalls(api.EvalScope{GoroutineID: -1, Frame: 0}, normalLoadConfig)
+		assertNoError(err, t, "DeferredCalls()")
+		if len(dcs) != 2 {
+			t.Fatalf("wrong number of deferred calls %d: %#v", len(dcs), dcs)
+		}
+
+		// f2(x, y) captures its arguments, f2(42, 61) does not.
+		if dcs[0].ArgsUnreadable != "" {
+			t.Fatalf("arguments unreadable: %s", dcs[0].ArgsUnreadable)
+		}
+		values := []string{}
+		for _, arg := range dcs[0].Args {
+			values = append(values, arg.Name+"="+arg.Value)
+		}
+		if !reflect.DeepEqual(values, []string{"a=1", "b=-1"}) {
+			t.Errorf("wrong arguments: %v", values)
+		}
+		if dcs[1].ArgsUnreadable == "" {
+			t.Errorf("expected unreadable arguments: %#v", dcs[1].Args)
+		}
+	})
+}
+
+func TestCreateBreakpointsInPackage(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		bps, err := c.CreateBreakpointsInPackage("main", true)
+		assertNoError(err, t, "CreateBreakpointsInPackage(main, true)")
+		fns := map[string]bool{}
+		for _, bp := range bps {
+			if !bp.Tracepoint {
+				t.Errorf("breakpoint %d is not a tracepoint", bp.ID)
+			}
+			fns[bp.FunctionName] = true
+		}
+		for _, fn := range []string{"main.main", "main.helloworld", "main.testnext", "main.sleepytime", "main.testgoroutine"} {
+			if !fns[fn] {
+				t.Errorf("no tracepoint set on %s: %v", fn, fns)
+			}
+		}
+		if len(bps) != len(fns) {
+			t.Errorf("expected one tracepoint per function, got %d for %d functions", len(bps), len(fns))
+		}
+
+		// functions that already have a breakpoint are skipped
+		bps2, err := c.CreateBreakpointsInPackage("main", true)
+		assertNoError(err, t, "CreateBreakpointsInPackage(main, true) (again)")
+		if len(bps2) != 0 {
+			t.Errorf("expected no new tracepoints, got %d", len(bps2))
+		}
+
+		for _, bp := range bps {
+			_, err := c.ClearBreakpoint(bp.ID)
+			assertNoError(err, t, fmt.Sprintf("ClearBreakpoint(%d)", bp.ID))
+		}
+
+		bps2, err = c.CreateBreakpointsInPackage("main", false)
+		assertNoError(err, t, "CreateBreakpointsInPackage(main, false)")
+		if len(bps2) <= len(bps) {
+			t.Errorf("expected tracepoints on return locations, got %d tracepoints for %d functions", len(bps2), len(bps))
+		}
+
+		_, err = c.CreateBreakpointsInPackage("nonexistent/pkg", true)
+		assertError(err, t, "CreateBreakpointsInPackage(nonexistent/pkg, true)")
+
+		state := <-c.ContinueSkippingTracepoints()
+		if !state.Exited {
+			t.Errorf("expected the process to exit: %#v", state)
+		}
+	})
+}
+
+func TestStepOutToUserCode(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2Extended("stepoutusercode", t, 0, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 11})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Function.Name() != "main.main.func1" {
+			t.Fatalf("wrong function at breakpoint: %s", state.CurrentThread.Function.Name())
+		}
+		// the less function is called many times by sort.Slice
+		_, err = c.ClearBreakpoint(bp.ID)
+		assertNoError(err, t, "ClearBreakpoint()")
+
+		state, err = c.StepOutToUserCode()
+		assertNoError(err, t, "StepOutToUserCode()")
+		if state.CurrentThread.Function.Name() != "main.main" || state.CurrentThread.Line != 10 {
+			t.Fatalf("wrong location after StepOutToUserCode: %s %s:%d", state.CurrentThread.Function.Name(), state.CurrentThread.File, state.CurrentThread.Line)
+		}
+		if state.NextInProgress {
+			t.Fatal("next still in progress after StepOutToUserCode")
+		}
+	})
+}
+
+func TestStepToLine(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.testnext"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		state, err = c.StepToLine("", 34)
+		assertNoError(err, t, "StepToLine(34)")
+		if state.CurrentThread.Line != 34 || state.CurrentThread.Function.Name() != "main.testnext" {
+			t.Fatalf("wrong location after StepToLine(34): %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+		}
+
+		// line 10 belongs to main.sleepytime
+		_, err = c.StepToLine("", 10)
+		assertError(err, t, "StepToLine(10)")
+
+		// line 27 is not reached again, stops when main.testnext returns
+		state, err = c.StepToLine("", 27)
+		assertNoError(err, t, "StepToLine(27)")
+		if state.CurrentThread.Line != 39 || state.CurrentThread.Function.Name() != "main.main" {
+			t.Fatalf("wrong location after StepToLine(27): %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+		}
+
+		// no breakpoint is left behind
+		state = <-c.Continue()
+		if !state.Exited {
+			t.Fatalf("expected the process to exit: %#v", state)
+		}
+	})
+}
+
+func TestReverseStackCopy(t *testing.T) {
+	protest.AllowRecording(t)
+	if testBackend != "rr" {
+		t.Skip("backend is not rr")
+	}
+	withTestClient2Extended("stackgrowth", t, 0, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 16})
+		assertNoError(err, t, "CreateBreakpoint(16)")
+		_, err = c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 17})
+		assertNoError(err, t, "CreateBreakpoint(17)")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "first continue")
+		if state.StackCopy != nil {
+			t.Errorf("unexpected stack copy for forward execution: %#v", state.StackCopy)
+		}
+		state = <-c.Continue()
+		assertNoError(state.Err, t, "second continue")
+		if state.CurrentThread.Line != 17 {
+			t.Fatalf("wrong line after second continue %d", state.CurrentThread.Line)
+		}
+
+		// main.grow grows the stack of the main goroutine, rewinding before
+		// the call goes back to the smaller stack.
+		state = <-c.Rewind()
+		assertNoError(state.Err, t, "rewind")
+		if state.CurrentThread.Line != 16 {
+			t.Fatalf("wrong line after rewind %d", state.CurrentThread.Line)
+		}
+		sc := state.StackCopy
+		if sc == nil {
+			t.Fatal("stack copy not reported")
+		}
+		if sc.GoroutineID != state.SelectedGoroutine.ID || sc.NewHi-sc.NewLo >= sc.OldHi-sc.OldLo {
+			t.Errorf("wrong stack copy %#v", sc)
+		}
+	})
+}
+
+func TestFindType(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("binarytrees", t, func(c service.Client) {
+		for _, name := range []string{"main.Node", "Node"} {
+			typ, err := c.FindType(name)
+			assertNoError(err, t, fmt.Sprintf("FindType(%q)", name))
+			if typ.Name != "main.Node" || typ.Kind != reflect.Struct || typ.Size != 24 {
+				t.Fatalf("wrong type for %q: %#v", name, typ)
+			}
+			tgt := []api.TypeField{
+				{Name: "item", Type: "int", Offset: 0, Size: 8},
+				{Name: "left", Type: "*main.Node", Offset: 8, Size: 8},
+				{Name: "right", Type: "*main.Node", Offset: 16, Size: 8},
+			}
+			if !reflect.DeepEqual(typ.Fields, tgt) {
+				t.Errorf("wrong fields for %q: %#v", name, typ.Fields)
+			}
+			if len(typ.Methods) != 1 || typ.Methods[0].Name != "ItemCheck" || typ.Methods[0].Function != "main.(*Node).ItemCheck" || !typ.Methods[0].PointerReceiver {
+				t.Errorf("wrong methods for %q: %#v", name, typ.Methods)
+			}
+		}
+
+		typ, err := c.FindType("*main.Node")
+		assertNoError(err, t, "FindType(*main.Node)")
+		if typ.Kind != reflect.Ptr || typ.Elem != "main.Node" || typ.Size != 8 {
+			t.Errorf("wrong pointer type: %#v", typ)
+		}
+
+		_, err = c.FindType("main.nonexistent")
+		assertError(err, t, "FindType(main.nonexistent)")
+	})
+}
+
+func TestSetDefaultLoadConfig(t *testing.T) {
+	if testBackend == "rr" {
+		t.Skip("recording not allowed for TestSetDefaultLoadConfig")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %s\n", err)
+	}
+	serverDone := make(chan struct{})
+	go func() {
+		defer close(serverDone)
+		defer listener.Close()
+		disconnectChan := make(chan struct{})
+		server := rpccommon.NewServer(&service.Config{
+			Listener:       listener,
+			ProcessArgs:    []string{protest.BuildFixture("testvariables2", 0).Path},
+			AcceptMulti:    true,
+			DisconnectChan: disconnectChan,
+			Debugger: debugger.Config{
+				Backend:     testBackend,
+				ExecuteKind: debugger.ExecutingGeneratedTest,
+			},
+		})
+		if err := server.Run(); err != nil {
+			panic(err)
+		}
+		<-disconnectChan
+		server.Stop()
+	}()
+	client1 := rpc2.NewClient(listener.Addr().String())
+	client2 := rpc2.NewClient(listener.Addr().String())
+
+	state := <-client1.Continue()
+	assertNoError(state.Err, t, "Continue()")
+
+	cfg := normalLoadConfig
+	cfg.MaxStringLen = 3
+	assertNoError(client1.SetDefaultLoadConfig(cfg), t, "SetDefaultLoadConfig()")
+
+	eval := func(c *rpc2.RPCClient) string {
+		var out rpc2.EvalOut
+		err := c.CallAPI("Eval", rpc2.EvalIn{Scope: api.EvalScope{GoroutineID: -1}, Expr: "str1"}, &out)
+		assertNoError(err, t, "Eval(str1)")
+		return out.Variable.Value
+	}
+
+	if v := eval(client1); v != "012" {
+		t.Errorf("wrong value with the default LoadConfig of the connection %q", v)
+	}
+	if v := eval(client2); v != "01234567890" {
+		t.Errorf("wrong value with the default LoadConfig of the server %q", v)
+	}
+
+	client1.Disconnect(false)
+	client2.Detach(true)
+	<-serverDone
+}
+
+func TestEvalAcrossGoroutines(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		gs, _, err := c.ListGoroutines(0, 0)
+		assertNoError(err, t, "ListGoroutines()")
+
+		vars, err := c.EvalAcrossGoroutines("main.dummy", normalLoadConfig)
+		assertNoError(err, t, "EvalAcrossGoroutines(main.dummy)")
+		if len(vars) != len(gs) {
+			t.Errorf("expected %d results got %d", len(gs), len(vars))
+		}
+		for _, g := range gs {
+			v := vars[g.ID]
+			if v == nil || v.Unreadable != "" || v.Value != "0" {
+				t.Errorf("wrong value for goroutine %d: %#v", g.ID, v)
+			}
+		}
+
+		// the topmost frame of all goroutines is either main.stacktraceme or a
+		// runtime function, none of which has a variable i.
+		vars, err = c.EvalAcrossGoroutines("i", normalLoadConfig)
+		assertNoError(err, t, "EvalAcrossGoroutines(i)")
+		if len(vars) != len(gs) {
+			t.Errorf("expected %d results got %d", len(gs), len(vars))
+		}
+		for id, v := range vars {
+			if v.Unreadable == "" {
+				t.Errorf("expected an error for goroutine %d: %#v", id, v)
+			}
+		}
+	})
+}
+
+func TestHaltRunningThreads(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("loopprog", t, func(c service.Client) {
+		haltState := make(chan *api.DebuggerState)
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			state, err := c.Halt()
+			assertNoError(err, t, "Halt()")
+			haltState <- state
+		}()
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if len(state.RunningThreads) != 0 {
+			t.Errorf("running threads reported outside of Halt: %v", state.RunningThreads)
+		}
+
+		state = <-haltState
+		list, err := c.ListThreads()
+		assertNoError(err, t, "ListThreads()")
+		threads := map[int]*api.Thread{}
+		for _, th := range list {
+			threads[th.ID] = th
+		}
+		for _, id := range state.RunningThreads {
+			if th := threads[id]; th == nil || th.State != api.ThreadRunning {
+				t.Errorf("wrong running thread %d: %#v", id, th)
+			}
+		}
+		// a thread stopped in main.loop, rather than while printing, was
+		// running the busy loop.
+		for _, th := range state.Threads {
+			if th.Function != nil && th.Function.Name() == "main.loop" {
+				found := false
+				for _, id := range state.RunningThreads {
+					found = found || id == th.ID
+				}
+				if !found {
+					t.Errorf("thread %d running main.loop not reported", th.ID)
+				}
+			}
+		}
+	})
+}
+
+func TestBreakpointHitStats(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("goroutinestackprog", t, func(c service.Client) {
+		bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.agoroutine", Tracepoint: true})
+		assertNoError(err, t, "CreateBreakpoint(main.agoroutine)")
+		_, err = c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.stacktraceme"})
+		assertNoError(err, t, "CreateBreakpoint(main.stacktraceme)")
+
+		stats, err := c.BreakpointHitStats()
+		assertNoError(err, t, "BreakpointHitStats()")
+		for _, stat := range stats {
+			if stat.TotalHits != 0 || stat.DistinctGoroutines != 0 || !stat.LastHitTime.IsZero() {
+				t.Errorf("breakpoint hit before continuing: %#v", stat)
+			}
+		}
+
+		before := time.Now()
+		state := <-c.ContinueSkippingTracepoints()
+		assertNoError(state.Err, t, "Continue()")
+
+		stats, err = c.BreakpointHitStats()
+		assertNoError(err, t, "BreakpointHitStats()")
+		var stat *api.BreakpointStat
+		for i := range stats {
+			if stats[i].ID == bp.ID {
+				stat = &stats[i]
+			}
+		}
+		if stat == nil {
+			t.Fatalf("no stats for breakpoint %d: %#v", bp.ID, stats)
+		}
+		if stat.TotalHits != 10 || stat.DistinctGoroutines != 10 {
+			t.Errorf("wrong hit counters: %#v", stat)
+		}
+		if stat.LastHitTime.Before(before) {
+			t.Errorf("wrong last hit time: %v (before %v)", stat.LastHitTime, before)
+		}
+	})
+}
+
+func TestContinueToFunctions(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		state, err := c.ContinueToFunctions([]string{"main.helloworld", "main.sleepytime"})
+		assertNoError(err, t, "ContinueToFunctions(main.helloworld, main.sleepytime)")
+		if state.ReachedFunction != "main.sleepytime" || state.CurrentThread.Function.Name() != "main.sleepytime" || state.StopReason != api.StopFunctionReached {
+			t.Fatalf("wrong state: %q %s:%d %s", state.ReachedFunction, state.CurrentThread.File, state.CurrentThread.Line, state.StopReason)
+		}
+
+		state, err = c.ContinueToFunctions([]string{"main.helloworld", "main.testgoroutine"})
+		assertNoError(err, t, "ContinueToFunctions(main.helloworld, main.testgoroutine)")
+		if state.ReachedFunction != "main.helloworld" || state.CurrentThread.Function.Name() != "main.helloworld" {
+			t.Fatalf("wrong state: %q %s:%d", state.ReachedFunction, state.CurrentThread.File, state.CurrentThread.Line)
+		}
+
+		_, err = c.ContinueToFunctions([]string{"main.nonexistent"})
+		assertError(err, t, "ContinueToFunctions(main.nonexistent)")
+
+		// main.sleepytime is not called again
+		state, err = c.ContinueToFunctions([]string{"main.sleepytime"})
+		assertNoError(err, t, "ContinueToFunctions(main.sleepytime)")
+		if !state.Exited || state.ReachedFunction != "" {
+			t.Fatalf("expected the process to exit: %#v", state)
+		}
+	})
+}
+
+func TestStopSignals(t *testing.T) {
+	if runtime.GOOS != "linux" || testBackend != "native" {
+		t.Skip("stopping on signals is only supported by the native backend on linux")
+	}
+	const sigusr1 = 10
+	withTestClient2("signalprog", t, func(c service.Client) {
+		assertNoError(c.SetStopSignals([]int{sigusr1}), t, "SetStopSignals()")
+
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.StopReason != api.StopSignal || state.Signal == nil || state.Signal.Number != sigusr1 || state.Signal.Name != "SIGUSR1" || state.Signal.ThreadID != state.CurrentThread.ID {
+			t.Fatalf("wrong state after the first signal: %s %#v", state.StopReason, state.Signal)
+		}
+
+		// deliver the first signal, the target stops again on the second one
+		state, err := c.ContinueWithSignal(sigusr1)
+		assertNoError(err, t, "ContinueWithSignal(SIGUSR1)")
+		if state.StopReason != api.StopSignal || state.Signal == nil || state.Signal.Number != sigusr1 {
+			t.Fatalf("wrong state after the second signal: %s %#v", state.StopReason, state.Signal)
+		}
+
+		// suppress the second signal, the exit status is the number of signals
+		// received by the target
+		state, err = c.ContinueWithSignal(0)
+		assertNoError(err, t, "ContinueWithSignal(0)")
+		if !state.Exited || state.ExitStatus != 1 {
+			t.Fatalf("expected the process to exit with status 1: %#v", state)
+		}
+	})
+}
+
+func TestStreamOutput(t *testing.T) {
+	if testBackend == "rr" {
+		t.Skip("output is not replayed by rr")
+	}
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	fixture := protest.BuildFixture("testnextprog", 0)
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{fixture.Path},
+		Debugger: debugger.Config{
+			Backend:      testBackend,
+			ExecuteKind:  debugger.ExecutingGeneratedFile,
+			StreamOutput: true,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := rpc2.NewClientFromConn(clientConn)
+	defer c.Detach(true)
+
+	// continueOutput drains the channel returned by Continue, output can be
+	// split across the intermediate states.
+	continueOutput := func() (state *api.DebuggerState, stdout, stderr string) {
+		for st := range c.Continue() {
+			if st.OutputInProgress && len(st.Stdout) == 0 && len(st.Stderr) == 0 {
+				t.Errorf("intermediate state without output: %#v", st)
+			}
+			stdout += string(st.Stdout)
+			stderr += string(st.Stderr)
+			state = st
+		}
+		if state.OutputInProgress {
+			t.Errorf("last state has OutputInProgress set: %#v", state)
+		}
+		return state, stdout, stderr
+	}
+
+	_, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 34})
+	assertNoError(err, t, "CreateBreakpoint()")
+	state, stdout, stderr := continueOutput()
+	assertNoError(state.Err, t, "Continue()")
+	if stdout != "foo\n" || stderr != "" {
+		t.Errorf("wrong output at breakpoint: %q %q", stdout, stderr)
+	}
+
+	state, stdout, _ = continueOutput()
+	if !state.Exited {
+		t.Fatalf("expected the process to exit: %#v", state)
+	}
+	if stdout != "Hello, World!\ndone\n" {
+		t.Errorf("wrong output at exit: %q", stdout)
+	}
+}
+
+func TestTraceStop(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("loopprog", t, func(c service.Client) {
+		events, stop, err := c.Trace([]api.TracepointSpec{{FunctionName: "main.loop", Line: 3}})
+		assertNoError(err, t, "Trace()")
+
+		ev, ok := <-events
+		if !ok || ev.Function != "main.loop" {
+			t.Fatalf("wrong first trace event: %#v %v", ev, ok)
+		}
+		stop()
+		for range events {
+		}
+
+		bps, err := c.ListBreakpoints()
+		assertNoError(err, t, "ListBreakpoints()")
+		for _, bp := range bps {
+			if bp.Tracepoint {
+				t.Errorf("tracepoint %d was not cleared", bp.ID)
+			}
+		}
+		state, err := c.GetState()
+		assertNoError(err, t, "GetState()")
+		if state.Running || state.Exited {
+			t.Errorf("target not stopped after stop(): %#v", state)
+		}
+	})
+}
+
+func TestSetNextStatement(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.testnext"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		scope := api.EvalScope{GoroutineID: -1}
+
+		if testBackend == "rr" {
+			_, err = c.SetNextStatement(scope, "", 23)
+			assertError(err, t, "SetNextStatement() on a recording")
+			return
+		}
+
+		// j and f are not initialized yet
+		_, err = c.SetNextStatement(scope, "", 34)
+		assertError(err, t, "SetNextStatement(34) before the declaration of j")
+
+		_, err = c.StepToLine("", 23)
+		assertNoError(err, t, "StepToLine(23)")
+
+		// skips the loop
+		state, err = c.SetNextStatement(scope, "", 34)
+		assertNoError(err, t, "SetNextStatement(34)")
+		if state.CurrentThread.Line != 34 || state.CurrentThread.Function.Name() != "main.testnext" {
+			t.Fatalf("wrong location after SetNextStatement(34): %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+		}
+
+		// i is not initialized outside of the loop
+		_, err = c.SetNextStatement(scope, "", 26)
+		assertError(err, t, "SetNextStatement(26)")
+
+		// line 10 belongs to main.sleepytime
+		_, err = c.SetNextStatement(scope, "", 10)
+		assertError(err, t, "SetNextStatement(10)")
+
+		state, err = c.Next()
+		assertNoError(err, t, "Next()")
+		if state.CurrentThread.Line != 35 {
+			t.Fatalf("wrong location after Next: %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+		}
+	})
+}
@@ -6,11 +6,13 @@ import (
 	"go/constant"
 	"io/ioutil"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"sort"
 	"strings"
 	"testing"
 
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/gdbserial"
@@ -132,9 +134,9 @@ func withTestProcessArgs(name string, t *testing.T, wd string, args []string, bu
 	var tracedir string
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{}, nil)
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{})
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, 0, []string{}, "", [3]string{}, nil)
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
@@ -171,8 +173,8 @@ func TestVariableEvaluation(t *testing.T) {
 		{"baz", true, "\"bazburzum\"", "", "string", nil},
 		{"neg", true, "-1", "-20", "int", nil},
 		{"f32", true, "1.2", "1.1", "float32", nil},
-		{"c64", true, "(1 + 2i)", "(4 + 5i)", "complex64", nil},
-		{"c128", true, "(2 + 3i)", "(6.3 + 7i)", "complex128", nil},
+		{"c64", true, "(1+2i)", "(4+5i)", "complex64", nil},
+		{"c128", true, "(2+3i)", "(6.3+7i)", "complex128", nil},
 		{"a6.Baz", true, "8", "20", "int", nil},
 		{"a7.Baz", true, "5", "25", "int", nil},
 		{"a8.Baz", true, "\"feh\"", "", "string", nil},
@@ -256,6 +258,13 @@ func TestSetVariable(t *testing.T) {
 
 		{"s3", "[]int", `[]int len: 0, cap: 6, []`, "s4[2:5]", "[]int len: 3, cap: 3, [3,4,5]"},
 		{"s3", "[]int", "[]int len: 3, cap: 3, [3,4,5]", "arr1[:]", "[]int len: 4, cap: 4, [0,1,2,3]"},
+
+		// composite literals
+		{"as1", "main.astruct", "main.astruct {A: 2, B: 3}", "main.astruct{B: 5}", "main.astruct {A: 0, B: 5}"},
+		{"as1", "main.astruct", "main.astruct {A: 0, B: 5}", "main.astruct{7, 8}", "main.astruct {A: 7, B: 8}"},
+		{"s3", "[]int", "[]int len: 4, cap: 4, [0,1,2,3]", "[]int{9, 8}", "[]int len: 2, cap: 4, [9,8]"},
+		{"arr1", "[4]int", "[4]int [9,8,2,3]", "[4]int{1: 5, 6}", "[4]int [0,5,6,0]"},
+		{"s2", "[]main.astruct", "[]main.astruct len: 0, cap: 0, nil", "[]main.astruct{}", "[]main.astruct len: 0, cap: 0, nil"},
 	}
 
 	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -281,6 +290,56 @@ func TestSetVariable(t *testing.T) {
 	})
 }
 
+func TestSetVariableCompositeLiteralErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		expr string
+		err  string
+	}{
+		{"as1", "main.astruct{C: 1}", "unknown field C in struct literal of type main.astruct"},
+		{"as1", "main.astruct{1}", "wrong number of values in main.astruct literal: 1 (expected 2)"},
+		{"as1", "main.bstruct{}", "can not use main.bstruct literal as a main.astruct value"},
+		{"arr1", "[4]int{4: 1}", "array index 4 out of bounds [0:4]"},
+		{"s3", "[]int{1, 2, 3, 4, 5, 6, 7}", "slice literal has 7 elements but the capacity of s3 is only 6"},
+		{"i1", "main.astruct{}", "can not assign a composite literal to a variable of type int"},
+	}
+
+	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+
+		for _, tc := range testcases {
+			before, err := evalVariable(p, tc.name, pnormalLoadConfig)
+			assertNoError(err, t, "EvalVariable()")
+			err = setVariable(p, tc.name, tc.expr)
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%s = %s: expected error %q got %v", tc.name, tc.expr, tc.err, err)
+			}
+			after, err := evalVariable(p, tc.name, pnormalLoadConfig)
+			assertNoError(err, t, "EvalVariable()")
+			if api.ConvertVar(before).SinglelineString() != api.ConvertVar(after).SinglelineString() {
+				t.Errorf("%s = %s: variable changed after failed assignment", tc.name, tc.expr)
+			}
+		}
+	})
+}
+
+func TestMaxElementBytes(t *testing.T) {
+	testcases := []varTest{
+		{"s2", true, "[]main.astruct len: 8, cap: 8, [{A: 1,...+1 more},{A: 3,...+1 more},…", "", "[]main.astruct", nil},
+		{"as1", true, "main.astruct {A: 1, B: 1}", "", "main.astruct", nil},
+	}
+	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		cfg := pnormalLoadConfig
+		cfg.MaxElementBytes = 8
+		for _, tc := range testcases {
+			variable, err := evalVariable(p, tc.name, cfg)
+			assertNoError(err, t, "EvalVariable()")
+			assertVariable(t, variable, tc)
+		}
+	})
+}
+
 func TestVariableEvaluationShort(t *testing.T) {
 	testcases := []varTest{
 		{"a1", true, "\"foofoofoofoofoofoo\"", "", "string", nil},
@@ -298,8 +357,8 @@ func TestVariableEvaluationShort(t *testing.T) {
 		{"baz", true, "\"bazburzum\"", "", "string", nil},
 		{"neg", true, "-1", "", "int", nil},
 		{"f32", true, "1.2", "", "float32", nil},
-		{"c64", true, "(1 + 2i)", "", "complex64", nil},
-		{"c128", true, "(2 + 3i)", "", "complex128", nil},
+		{"c64", true, "(1+2i)", "", "complex64", nil},
+		{"c128", true, "(2+3i)", "", "complex128", nil},
 		{"a6.Baz", true, "8", "", "int", nil},
 		{"a7.Baz", true, "5", "", "int", nil},
 		{"a8.Baz", true, "\"feh\"", "", "string", nil},
@@ -437,8 +496,8 @@ func TestLocalVariables(t *testing.T) {
 				{"b1", true, "true", "", "bool", nil},
 				{"b2", true, "false", "", "bool", nil},
 				{"ba", true, "[]int len: 200, cap: 200, [0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,...+136 more]", "", "[]int", nil},
-				{"c128", true, "(2 + 3i)", "", "complex128", nil},
-				{"c64", true, "(1 + 2i)", "", "complex64", nil},
+				{"c128", true, "(2+3i)", "", "complex128", nil},
+				{"c64", true, "(1+2i)", "", "complex64", nil},
 				{"f", true, "main.barfoo", "", "func()", nil},
 				{"f32", true, "1.2", "", "float32", nil},
 				{"i32", true, "[2]int32 [1,2]", "", "[2]int32", nil},
@@ -519,6 +578,15 @@ func TestEmbeddedStruct(t *testing.T) {
 			{"w4.I.F", false, `"T-inside-W1"`, `"T-inside-W1"`, "string", nil},
 			{"w4.F", false, ``, ``, "", errors.New("w4 has no member F")},
 			{"w5.F", false, ``, ``, "", errors.New("w5 has no member F")},
+
+			// type assertions on embedded interfaces
+			{"w3.I.(*main.W1)", false, `*main.W1 {T: main.T {F: "T-inside-W1"}}`, `(*main.W1)(0x…`, "*main.W1", nil},
+			{"w3.I.(*main.W1).F", false, `"T-inside-W1"`, `"T-inside-W1"`, "string", nil},
+			{"w4.I.(*main.W1).T.F", false, `"T-inside-W1"`, `"T-inside-W1"`, "string", nil},
+			{"w4.I.(main.I).F", false, `"T-inside-W1"`, `"T-inside-W1"`, "string", nil},
+			{"w3.I.(main.W1)", false, ``, ``, "", errors.New("interface conversion: main.I is *main.W1, not main.W1")},
+			{"w3.I.(*main.W2), ok", false, "struct { value *main.W2; ok bool } {value: (unreadable interface conversion: main.I is *main.W1, not *main.W2), ok: false}", "struct { value *main.W2; ok bool } {value: (unreadable interface conversion: main.I is *main.W1, not *main.W2), ok: false}", "struct { value *main.W2; ok bool }", nil},
+			{"w3.I.(*main.W1), ok", false, `struct { value *main.W1; ok bool } {value: *main.W1 {T: main.T {F: "T-inside-W1"}}, ok: true}`, "struct { value *main.W1; ok bool } {value: (*main.W1)(0x…", "struct { value *main.W1; ok bool }", nil},
 		}
 		assertNoError(p.Continue(), t, "Continue()")
 
@@ -564,10 +632,10 @@ func TestComplexSetting(t *testing.T) {
 			}
 		}
 
-		h("3.2i", "(0 + 3.2i)")
-		h("1.1", "(1.1 + 0i)")
-		h("1 + 3.3i", "(1 + 3.3i)")
-		h("complex(1.2, 3.4)", "(1.2 + 3.4i)")
+		h("3.2i", "(0+3.2i)")
+		h("1.1", "(1.1+0i)")
+		h("1 + 3.3i", "(1+3.3i)")
+		h("complex(1.2, 3.4)", "(1.2+3.4i)")
 	})
 }
 
@@ -624,6 +692,18 @@ func TestEvalExpression(t *testing.T) {
 		{"m2[c1.sa[2].B-4].A", false, "10", "10", "int", nil},
 		{"m2[*p1].B", false, "11", "11", "int", nil},
 		{"m3[as1]", false, "42", "42", "int", nil},
+		{"m3[main.astruct{1, 1}]", false, "42", "42", "int", nil},
+		{"m3[main.astruct{A: 2, B: 2}]", false, "43", "43", "int", nil},
+		{"m3[main.astruct{B: 1, A: 1}]", false, "42", "42", "int", nil},
+		{"m3[main.astruct{A: 1}]", false, "", "", "", fmt.Errorf("key not found")},
+		{"m4[main.astruct{2, 2}].A", false, "22", "22", "int", nil},
+		{"m3[main.astruct{C: 1}]", false, "", "", "", fmt.Errorf("can not use main.astruct{C: 1} as key of map[main.astruct]int: unknown field C in struct literal of type main.astruct")},
+		{"m3[main.astruct{1, \"a\"}]", false, "", "", "", fmt.Errorf("can not use main.astruct{1, \"a\"} as key of map[main.astruct]int: can not convert \"a\" constant to int")},
+		{"m3[1]", false, "", "", "", fmt.Errorf("can not use 1 (type int) as key of map[main.astruct]int: can not convert 1 constant to main.astruct")},
+		{"m2[\"a\"]", false, "", "", "", fmt.Errorf("can not use \"a\" (type string) as key of map[int]*main.astruct: can not convert \"a\" constant to int")},
+		{"m8[true]", false, "\"yes\"", "\"yes\"", "string", nil},
+		{"m8[1 == 2]", false, "\"no\"", "\"no\"", "string", nil},
+		{"m8[1]", false, "", "", "", fmt.Errorf("can not use 1 (type int) as key of map[bool]string: can not convert 1 constant to bool")},
 		{"mnil[\"Malone\"]", false, "", "", "", fmt.Errorf("key not found")},
 		{"m1[80:]", false, "", "", "", fmt.Errorf("map index out of bounds")},
 
@@ -646,6 +726,15 @@ func TestEvalExpression(t *testing.T) {
 		{"err1.(*main.astruct)", false, "*main.astruct {A: 1, B: 2}", "(*main.astruct)(0x…", "*main.astruct", nil},
 		{"err1.(*main.bstruct)", false, "", "", "", fmt.Errorf("interface conversion: error is *main.astruct, not *main.bstruct")},
 		{"errnil.(*main.astruct)", false, "", "", "", fmt.Errorf("interface conversion: error is nil, not *main.astruct")},
+		{"err1.(*main.astruct), ok", false, "struct { value *main.astruct; ok bool } {value: *main.astruct {A: 1, B: 2}, ok: true}", "struct { value *main.astruct; ok bool } {value: (*main.astruct)(0x…", "struct { value *main.astruct; ok bool }", nil},
+		{"err1.(*main.bstruct), ok", false, "struct { value *main.bstruct; ok bool } {value: (unreadable interface conversion: error is *main.astruct, not *main.bstruct), ok: false}", "struct { value *main.bstruct; ok bool } {value: (unreadable interface conversion: error is *main.astruct, not *main.bstruct), ok: false}", "struct { value *main.bstruct; ok bool }", nil},
+		{"errnil.(*main.astruct), ok", false, "struct { value *main.astruct; ok bool } {value: (unreadable interface conversion: error is nil, not *main.astruct), ok: false}", "struct { value *main.astruct; ok bool } {value: (unreadable interface conversion: error is nil, not *main.astruct), ok: false}", "struct { value *main.astruct; ok bool }", nil},
+		{"i1.(int), ok", false, "", "", "", fmt.Errorf("expression \"i1\" not an interface")},
+		{"iface1.(error)", false, "error(*main.astruct) *{A: 1, B: 2}", "error(*main.astruct) 0x…", "error", nil},
+		{"err1.(interface {})", false, "interface {}(*main.astruct) *{A: 1, B: 2}", "interface {}(*main.astruct) 0x…", "interface {}", nil},
+		{"iface1.(error).(*main.astruct).B", false, "2", "2", "int", nil},
+		{"errnil.(error)", false, "", "", "", fmt.Errorf("interface conversion: error is nil, not error")},
+		{"iface1.(error), ok", false, "struct { value error; ok bool } {value: error(*main.astruct) *{A: 1, B: 2}, ok: true}", "struct { value error; ok bool } {value: error(*main.astruct) 0x…", "struct { value error; ok bool }", nil},
 		{"const1", true, "go/constant.Value(go/constant.int64Val) 3", "go/constant.Value(go/constant.int64Val) 3", "go/constant.Value", nil},
 
 		// combined expressions
@@ -658,12 +747,19 @@ func TestEvalExpression(t *testing.T) {
 		// constants
 		{"1.1", false, "1.1", "1.1", "", nil},
 		{"10", false, "10", "10", "", nil},
-		{"1 + 2i", false, "(1 + 2i)", "(1 + 2i)", "", nil},
+		{"1 + 2i", false, "(1+2i)", "(1+2i)", "", nil},
 		{"true", false, "true", "true", "", nil},
 		{"\"test\"", false, "\"test\"", "\"test\"", "", nil},
 
 		// binary operators
 		{"i2 + i3", false, "5", "5", "int", nil},
+		{"c128 + c128", false, "(4+6i)", "(4+6i)", "complex128", nil},
+		{"c128 * 2i", false, "(-6+4i)", "(-6+4i)", "complex128", nil},
+		{"c128 / (1 + 1i)", false, "(2.5+0.5i)", "(2.5+0.5i)", "complex128", nil},
+		{"c64 * c64", false, "(-3+4i)", "(-3+4i)", "complex64", nil},
+		{"-c128", false, "(-2-3i)", "(-2-3i)", "complex128", nil},
+		{"c128 == complex(2, 3)", false, "true", "true", "", nil},
+		{"c64 + c128", false, "", "", "", errors.New("mismatched types \"complex64\" and \"complex128\"")},
 		{"i2 - i3", false, "-1", "-1", "int", nil},
 		{"i3 - i2", false, "1", "1", "int", nil},
 		{"i2 * i3", false, "6", "6", "int", nil},
@@ -774,7 +870,7 @@ func TestEvalExpression(t *testing.T) {
 		{"uint(i2)", false, "2", "2", "uint", nil},
 		{"int8(i2)", false, "2", "2", "int8", nil},
 		{"int(f1)", false, "3", "3", "int", nil},
-		{"complex128(f1)", false, "(3 + 0i)", "(3 + 0i)", "complex128", nil},
+		{"complex128(f1)", false, "(3+0i)", "(3+0i)", "complex128", nil},
 		{"uint8(i4)", false, "32", "32", "uint8", nil},
 		{"uint8(i5)", false, "253", "253", "uint8", nil},
 		{"int8(i5)", false, "-3", "-3", "int8", nil},
@@ -827,6 +923,8 @@ func TestEvalExpression(t *testing.T) {
 
 		{"afunc", true, `main.afunc`, `main.afunc`, `func()`, nil},
 		{"main.afunc2", true, `main.afunc2`, `main.afunc2`, `func()`, nil},
+		{"runtime.Breakpoint", true, `runtime.Breakpoint`, `runtime.Breakpoint`, `func()`, nil},
+		{"runtime.nonexistentsymbol", false, "", "", "", errors.New("package runtime has no function, variable or constant named nonexistentsymbol")},
 
 		{"s2[0].Error", false, "main.(*astruct).Error", "main.(*astruct).Error", "func() string", nil},
 		{"s2[0].NonPointerRecieverMethod", false, "main.astruct.NonPointerRecieverMethod", "main.astruct.NonPointerRecieverMethod", "func()", nil},
@@ -1129,6 +1227,136 @@ func TestConstants(t *testing.T) {
 	})
 }
 
+func TestPseudoVariables(t *testing.T) {
+	testcases := []varTest{
+		{"$GOOS", true, "\"" + runtime.GOOS + "\"", "", "", nil},
+		{"$GOARCH", true, "\"" + runtime.GOARCH + "\"", "", "", nil},
+		{"$GOOS == \"" + runtime.GOOS + "\"", false, "true", "", "", nil},
+		{"\"$GOOS\"", false, "\"$GOOS\"", "", "", nil},
+		{"$nonexistent", false, "", "", "", errors.New("unknown pseudo-variable $nonexistent")},
+	}
+	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue")
+		for _, testcase := range testcases {
+			variable, err := evalVariable(p, testcase.name, pnormalLoadConfig)
+			if testcase.err == nil {
+				assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", testcase.name))
+				assertVariable(t, variable, testcase)
+			} else {
+				if err == nil || err.Error() != testcase.err.Error() {
+					t.Fatalf("EvalVariable(%s): expected error %q, got %v", testcase.name, testcase.err, err)
+				}
+			}
+		}
+
+		variable, err := evalVariable(p, "$goversion", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable($goversion)")
+		if ver := constant.StringVal(variable.Value); !strings.HasPrefix(ver, "go") && !strings.HasPrefix(ver, "devel") {
+			t.Fatalf("unexpected value for $goversion: %q", ver)
+		}
+	})
+}
+
+func TestFieldProjection(t *testing.T) {
+	testcases := []varTest{
+		{"s2.#A", false, "[]int len: 8, cap: 8, [1,3,5,7,9,11,13,15]", "", "[]int", nil},
+		{"c1.sa.#B", false, "[]int len: 3, cap: 3, [2,3,5]", "", "[]int", nil},
+		{"s2.#B[3]", false, "8", "", "int", nil},
+		{"s2.#A[1:3]", false, "[]int len: 2, cap: 2, [3,5]", "", "[]int", nil},
+		{"len(s2.#B)", false, "8", "", "", nil},
+		{"s2.#C", false, "", "", "", errors.New("elements of s2 (type []main.astruct) have no field C")},
+		{"as1.#A", false, "", "", "", errors.New("as1 (type main.astruct) is not an array or a slice")},
+	}
+	protest.AllowRecording(t)
+	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue")
+		for _, testcase := range testcases {
+			variable, err := evalVariable(p, testcase.name, pnormalLoadConfig)
+			if testcase.err == nil {
+				assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", testcase.name))
+				assertVariable(t, variable, testcase)
+			} else {
+				if err == nil || err.Error() != testcase.err.Error() {
+					t.Fatalf("EvalVariable(%s): expected error %q, got %v", testcase.name, testcase.err, err)
+				}
+			}
+		}
+	})
+}
+
+func TestHmapBuiltin(t *testing.T) {
+	testcases := []varTest{
+		{"$hmap(m2).count", false, "1", "", "int", nil},
+		{"$hmap(m2).B", false, "0", "", "uint8", nil},
+		{"len($hmap(m2).buckets)", false, "1", "", "", nil},
+		{"$hmap(m2).buckets[0].keys[0]", false, "1", "", "int", nil},
+		{"$hmap(m2).buckets[0].values[0].A", false, "10", "", "int", nil},
+		{"$hmap(m2).buckets[0].overflow", false, "*bucket<int,*main.astruct> nil", "", "*bucket<int,*main.astruct>", nil},
+		{"$hmap(mnil)", false, "", "", "", errors.New("map mnil is nil")},
+		{"$hmap(as1)", false, "", "", "", errors.New("invalid argument as1 (type main.astruct) for $hmap")},
+	}
+	protest.AllowRecording(t)
+	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue")
+		for _, testcase := range testcases {
+			variable, err := evalVariable(p, testcase.name, pnormalLoadConfig)
+			if testcase.err == nil {
+				assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", testcase.name))
+				assertVariable(t, variable, testcase)
+			} else {
+				if err == nil || err.Error() != testcase.err.Error() {
+					t.Fatalf("EvalVariable(%s): expected error %q, got %v", testcase.name, testcase.err, err)
+				}
+			}
+		}
+	})
+}
+
+func TestPrettyKnownTypes(t *testing.T) {
+	testcases := []varTest{
+		{"wg", true, "sync.WaitGroup {counter: 2, waiters: 0}", "", "sync.WaitGroup", nil},
+		{"once", true, "sync.Once {done: true}", "", "sync.Once", nil},
+		{"notdone", true, "sync.Once {done: false}", "", "sync.Once", nil},
+		{"empty", true, "sync.Map []", "", "sync.Map", nil},
+	}
+	cfg := pnormalLoadConfig
+	cfg.PrettyKnownTypes = true
+	protest.AllowRecording(t)
+	withTestProcess("synctypes", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue")
+		for _, testcase := range testcases {
+			variable, err := evalVariable(p, testcase.name, cfg)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", testcase.name))
+			assertVariable(t, variable, testcase)
+		}
+
+		m, err := evalVariable(p, "m", cfg)
+		assertNoError(err, t, "EvalVariable(m)")
+		if m.Kind != reflect.Map || m.Len != 2 || len(m.Children) != 4 {
+			t.Fatalf("wrong sync.Map %v len=%d children=%d", m.Kind, m.Len, len(m.Children))
+		}
+		if _, ismap := m.RealType.(*godwarf.MapType); !ismap {
+			t.Errorf("wrong real type for sync.Map %s", m.RealType)
+		}
+		found := map[string]string{}
+		for i := 0; i < len(m.Children); i += 2 {
+			found[api.ConvertVar(&m.Children[i]).SinglelineString()] = api.ConvertVar(&m.Children[i+1]).SinglelineString()
+		}
+		if found[`interface {}(string) "a"`] != "interface {}(int) 1" || found[`interface {}(string) "c"`] != "interface {}(int) 3" {
+			t.Errorf("wrong sync.Map entries: %v", found)
+		}
+
+		// without PrettyKnownTypes the fields are returned
+		wg, err := evalVariable(p, "wg", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(wg)")
+		for _, child := range wg.Children {
+			if child.Name == "counter" {
+				t.Errorf("sync.WaitGroup decoded without PrettyKnownTypes")
+			}
+		}
+	})
+}
+
 func setFunctionBreakpoint(p *proc.Target, t testing.TB, fname string) *proc.Breakpoint {
 	_, f, l, _ := runtime.Caller(1)
 	f = filepath.Base(f)